@@ -4,4 +4,11 @@ go 1.23.0
 
 toolchain go1.23.8
 
-require golang.org/x/net v0.39.0
+require (
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/smacker/go-tree-sitter v0.0.0-20240827094217-dd81d9e9be82
+	golang.org/x/net v0.39.0
+)
+
+require golang.org/x/sys v0.32.0 // indirect
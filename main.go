@@ -0,0 +1,442 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "distiller/pkg/distiller"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "os/signal"
+    "strings"
+)
+
+func showHelp() {
+    fmt.Println(`Distiller by Philip Ferreira for AI-Assisted Development
+Version: ` + distiller.VERSION + `
+
+This tool analyzes Go, PHP, Python, JavaScript, TypeScript, HTML, CSS, SQL, Markdown, INI/TOML, Dockerfiles, Makefiles, and .env files to extract structural information about 
+your codebase in a format optimized for AI systems. It's designed to provide an AI with enough 
+context to understand code structure without needing the entire codebase.
+
+Usage: distiller [options]
+       distiller serve [options]   Answer HTTP queries on -listen: /summary, /file?path=, /symbol?name= and /search?q=
+
+Options:
+  -dir string       Directory to analyze (required)
+  -files string     Comma-separated list of specific files to analyze
+  -exclude string   Comma-separated list of exclude patterns (e.g., "vendor,node_modules,venv")
+  -include string   Comma-separated list of include patterns (e.g., "*.go,*.php,*.py,*.html")
+  -format string    Output format: "json", "pattern", "pattern2" keyed by symbol, "sarif" for code scanning findings, "dot" for a Graphviz call graph, "mermaid" for class and call diagrams, or "ndjson" streaming one line per file without the cross-file sections (default "json")
+  -compact          Output compact JSON without indentation (default true)
+  -filter-empty     Filter out empty arrays and slices (default true)
+  -relevant         Only include files relevant to target files (default false)
+  -max int          Maximum number of files to include (default 0 for all)
+  -max-functions int       Maximum functions kept per file, most important first (default 0 for all)
+  -max-types int           Maximum types/classes kept per file, most important first (default 0 for all)
+  -max-files-per-lang int  Maximum files kept per language, most important first (default 0 for all)
+  -output string    Output file (default stdout)
+  -version          Print version information
+  -verbose          Enable verbose output
+  -group-by string  Reorganize JSON output by "package" (Go package, PHP namespace, or directory)
+  -omit-functions string        Comma-separated function names to leave out (e.g., "String,Error")
+  -omit-functions-regex string  Regular expression of function names to leave out
+  -show-config-values           Include INI/TOML values instead of redacting them (default false)
+  -find-duplicates  Report functions that look reimplemented across languages (default false)
+  -preview int      Only output the first N files in walk order, noting how many were omitted
+  -schema-view      Output the field/JSON name/DB column/type mapping of tagged Go structs
+  -indent-size int  Columns a tab expands to in Python files (default 0 to detect from each file)
+  -exclude-empty-files  Drop files from which nothing was extracted (default false)
+  -smart-excludes   Skip common vendor, VCS and build directories (default true)
+  -top int          List the N most important symbols across the codebase as topSymbols
+  -native-paths     Keep OS-specific path separators instead of forward slashes (default false)
+  -summary-only     Output only counts, symbol names and files, without per-symbol details (any format)
+  -cluster          Group files that call each other or share types into clusters (default false)
+  -explain-imports  Record the members used from each import and flag unused imports (default false)
+  -deterministic    Omit the timestamp so identical input gives byte-identical output (default false)
+  -page-map         Map each HTML page to the endpoints and handlers its forms, links and HTMX calls reach (default false)
+  -group-imports-by-origin  Mark imports as stdlib, thirdparty or local and list third-party dependencies (default false)
+  -bundle           Output the full source of the -files targets plus the structure of the files they reference (default false)
+  -language-stats   Add a per-language breakdown of files, lines, functions and share of lines (default false)
+  -files-from string  Analyze exactly the newline-separated paths read from this file, or stdin with "-", without walking -dir
+  -baseline string  Earlier JSON or pattern output to diff against, reporting added, removed, changed and renamed functions
+  -trace string     Follow the include/require chain of this HTML/PHP page and output the files that compose it, in dependency order
+  -profile-types    Count how often each declared type is used as a field, parameter or return type (default false)
+  -exclude-symbols-regex string  Regular expression of functions, types, variables, CSS selectors and SQL tables to leave out
+  -collapse-imports List each import path once per language and refer to it by index from each file (default false)
+  -budget-report    Print the estimated token cost of each section of the output to stderr (default false)
+  -query string     Print only the values matched by a path into the output, e.g. "goFiles[*].functions[*].name"
+  -parser string    Parser for PHP, Python, CSS and SQL: "regex" or "treesitter" (needs a build with -tags treesitter) (default "regex")
+  -complexity-delta int  With -baseline, report functions whose complexity grew by more than N and exit with status 1 (default -1, off)
+  -promote-embedded Move inline <style> rules into CSS files named "page.html#style" and index inline script functions with the rest of the code (default false)
+  -lsp              Run as a Language Server over stdio, answering document symbol, workspace symbol and definition requests (default false)
+  -jobs int         Number of files analyzed in parallel (default 0, one per CPU)
+  -cache string     Directory keeping per-file results between runs, so unchanged files are not parsed again
+  -watch            Keep running and rewrite -output, or print an NDJSON event to stdout, whenever files change (default false)
+  -listen string    Address the serve subcommand listens on (default ":8080")
+  -output-db string  SQLite file to write files, symbols, calls, CSS selectors and SQL tables to, instead of stdout (needs a build with -tags sqlite)
+  -call-graph       Resolve each call to the function it reaches across files and list the edges as callGraph (default false)
+  -unreferenced     List the functions, classes, CSS selectors and SQL tables nothing else refers to (default false)
+  -go-packages      Group Go files by package, with each struct's methods from every file, as goPackages (default false)
+  -go-tags string   Comma-separated build tags, GOOS and GOARCH to mark Go files whose build constraints exclude them, e.g. linux,arm64,integration
+  -skip-build-excluded  With -go-tags, leave out Go files excluded by their build constraints instead of marking them (default false)
+  -include-tests    Analyze test files such as foo_test.go, test_foo.py, foo.spec.ts and FooTest.php (default true)
+
+Examples:
+  distiller -dir=./myproject
+  distiller -dir=./myproject -files=main.go,index.php,app.py -format=pattern
+  distiller -dir=./myproject -exclude=vendor,node_modules,venv -output=summary.json
+  git diff --name-only | distiller -files-from -
+  distiller -dir=./myproject -baseline=before.json
+  distiller -dir=./myproject -baseline=main.json -complexity-delta=3 -format=sarif
+  distiller -dir=./myproject -query "sqlFiles[*].statements[*].tables[*]"
+  distiller -dir=./myproject -watch -cache ~/.cache/distiller -output=summary.json
+  distiller serve -dir=./myproject -listen=:8080 -watch
+  distiller -dir=./myproject -output-db=summary.sqlite
+
+For bug reporting and feature requests, contact your system administrator.`)
+}
+
+func main() {
+    // The serve subcommand takes the same options as a normal run
+    serve := len(os.Args) > 1 && os.Args[1] == "serve"
+    if serve {
+        os.Args = append(os.Args[:1], os.Args[2:]...)
+    }
+
+    // Parse command line arguments
+    config := parseFlags()
+    config.Serve = serve
+
+    // Check if we should just print the version and exit
+    if config.PrintVersion {
+    fmt.Printf("Multi-Language Code Analyzer v%s\n", distiller.VERSION)
+    return
+    }
+
+    // The editor supplies the workspace, so -dir is optional here
+    if config.LSP {
+        distiller.RunLanguageServer(config, os.Stdin, os.Stdout)
+        return
+    }
+
+    // Validate config
+    if config.Directory == "" && config.FilesFrom == "" {
+    fmt.Println("Error: Directory is required")
+    showHelp()
+    os.Exit(1)
+    }
+    analyzer, err := distiller.NewAnalyzer(config)
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        os.Exit(1)
+    }
+
+    // Start the analyzer
+    if config.Verbose {
+    fmt.Printf("Analyzing directory: %s\n", config.Directory)
+    fmt.Printf("Output format: %s\n", config.OutputFormat)
+    fmt.Printf("Compact: %v\n", config.Compact)
+    fmt.Printf("Filter empty: %v\n", config.FilterEmpty)
+    if len(config.TargetFiles) > 0 {
+        fmt.Printf("Target files: %v\n", config.TargetFiles)
+    }
+    if len(config.ExcludePatterns) > 0 {
+        fmt.Printf("Exclude patterns: %v\n", config.ExcludePatterns)
+    }
+    if len(config.IncludePatterns) > 0 {
+        fmt.Printf("Include patterns: %v\n", config.IncludePatterns)
+    }
+    }
+
+    // Answer queries until interrupted
+    if config.Serve {
+        ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+        defer stop()
+        if config.Verbose {
+            fmt.Printf("Listening on %s\n", config.Listen)
+        }
+        if err := analyzer.Serve(ctx); err != nil && ctx.Err() == nil {
+            fmt.Printf("Error: %v\n", err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    // Keep the output up to date until interrupted
+    if config.Watch {
+        ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+        defer stop()
+        err := analyzer.Watch(ctx, func(summary distiller.Summary, changed []string) error {
+            return writeWatchUpdate(summary, changed, config)
+        })
+        if err != nil && ctx.Err() == nil {
+            fmt.Printf("Error: %v\n", err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    // Stream the files out as they are analyzed instead of building the whole summary
+    if config.OutputFormat == "ndjson" && config.OutputDB == "" {
+        if err := streamNDJSON(analyzer, config); err != nil {
+            fmt.Printf("Error: %v\n", err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    summary, err := analyzer.Analyze(context.Background())
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        os.Exit(1)
+    }
+    if summary.PreviewOmitted > 0 {
+        fmt.Fprintf(os.Stderr, "Preview: showing the first %d files, %d more omitted\n", config.Preview, summary.PreviewOmitted)
+    }
+    if config.ExcludeEmptyFiles && config.Verbose {
+        fmt.Printf("Excluded %d empty files\n", summary.ExcludedEmptyFiles)
+    }
+
+    // Prepare output based on format
+    outputData, err := renderOutput(summary, config)
+    if err != nil {
+    fmt.Printf("Error: %v\n", err)
+    os.Exit(1)
+    }
+
+    // Store the summary for SQL queries; the database takes the place of stdout
+    if config.OutputDB != "" {
+        if config.Verbose {
+            fmt.Printf("Writing database: %s\n", config.OutputDB)
+        }
+        if err := distiller.WriteDatabase(summary, config.OutputDB); err != nil {
+            fmt.Printf("Error writing database: %v\n", err)
+            os.Exit(1)
+        }
+    }
+
+    // Output the result
+    if config.OutputFile != "" {
+    if config.Verbose {
+        fmt.Printf("Writing output to file: %s\n", config.OutputFile)
+    }
+    err = ioutil.WriteFile(config.OutputFile, outputData, 0644)
+    if err != nil {
+        fmt.Printf("Error writing to file: %v\n", err)
+        os.Exit(1)
+    }
+    } else if config.OutputDB == "" {
+    fmt.Println(string(outputData))
+    }
+
+    if config.Verbose {
+    fmt.Printf("Analysis complete. Processed:\n")
+    fmt.Printf("- %d Go files\n", len(summary.GoFiles))
+    fmt.Printf("- %d PHP files\n", len(summary.PhpFiles))
+    fmt.Printf("- %d Python files\n", len(summary.PythonFiles))
+    fmt.Printf("- %d JavaScript files\n", len(summary.JsFiles))
+    fmt.Printf("- %d TypeScript files\n", len(summary.TsFiles))
+    fmt.Printf("- %d HTML files\n", len(summary.HtmlFiles))
+    fmt.Printf("- %d CSS files\n", len(summary.CssFiles))
+    fmt.Printf("- %d SQL files\n", len(summary.SqlFiles))
+    fmt.Printf("- %d Markdown files\n", len(summary.MarkdownFiles))
+    fmt.Printf("- %d config files\n", len(summary.ConfigFiles))
+    fmt.Printf("- %d Dockerfiles\n", len(summary.Dockerfiles))
+    fmt.Printf("- %d Makefiles\n", len(summary.Makefiles))
+    fmt.Printf("- %d env files\n", len(summary.EnvFiles))
+    }
+
+    // Fail the build when the complexity gate is exceeded
+    if summary.Diff != nil && len(summary.Diff.ComplexityRegressions) > 0 {
+        fmt.Fprintf(os.Stderr, "Complexity gate failed: %d function(s) grew by more than -complexity-delta=%d\n", len(summary.Diff.ComplexityRegressions), config.ComplexityDelta)
+        os.Exit(1)
+    }
+}
+
+// renderOutput renders a summary in the configured format, reports the token budget
+// and applies the -query
+func renderOutput(summary distiller.Summary, config distiller.Config) ([]byte, error) {
+    outputData, err := distiller.Render(summary, config)
+    if err != nil {
+        return nil, err
+    }
+
+    // Show where the context budget goes
+    if config.BudgetReport {
+        fmt.Fprint(os.Stderr, distiller.BudgetReport(summary, outputData))
+    }
+
+    // Narrow the output down to the queried values
+    if config.Query != "" {
+        return distiller.Query(outputData, config.Query)
+    }
+    return outputData, nil
+}
+
+// watchEvent is one line of the NDJSON stream -watch writes to stdout without -output
+type watchEvent struct {
+    Event   string          `json:"event"`             // "analyzed" for the first summary, "changed" for the later ones
+    Changed []string        `json:"changed,omitempty"` // Paths relative to -dir that triggered the analysis
+    Output  json.RawMessage `json:"output"`            // What distiller would print with the same flags
+}
+
+// writeWatchUpdate rewrites the output file with a fresh summary, or prints it to stdout
+// as an NDJSON event
+func writeWatchUpdate(summary distiller.Summary, changed []string, config distiller.Config) error {
+    outputData, err := renderOutput(summary, config)
+    if err != nil {
+        return err
+    }
+    if config.OutputFile != "" {
+        if config.Verbose {
+            fmt.Printf("Updating %s (%d changed)\n", config.OutputFile, len(changed))
+        }
+        return ioutil.WriteFile(config.OutputFile, outputData, 0644)
+    }
+
+    // Formats that aren't JSON, such as dot, travel as a string
+    event := watchEvent{Event: "analyzed", Changed: changed, Output: outputData}
+    if !json.Valid(outputData) {
+        event.Output, _ = json.Marshal(string(outputData))
+    }
+    if changed != nil {
+        event.Event = "changed"
+    }
+    line, err := json.Marshal(event)
+    if err != nil {
+        return err
+    }
+    fmt.Println(string(line))
+    return nil
+}
+
+// streamNDJSON writes each file summary as a line of JSON as soon as it is analyzed,
+// to the -output file or stdout
+func streamNDJSON(analyzer *distiller.Analyzer, config distiller.Config) error {
+    out := bufio.NewWriter(os.Stdout)
+    if config.OutputFile != "" {
+        file, err := os.Create(config.OutputFile)
+        if err != nil {
+            return err
+        }
+        defer file.Close()
+        out = bufio.NewWriter(file)
+    }
+    err := analyzer.Stream(context.Background(), func(file distiller.Summary) error {
+        line, err := json.Marshal(file)
+        if err != nil {
+            return err
+        }
+        out.Write(line)
+        out.WriteByte('\n')
+
+        // Consumers reading stdout get each file right away
+        if config.OutputFile == "" {
+            return out.Flush()
+        }
+        return nil
+    })
+    if err != nil {
+        return err
+    }
+    return out.Flush()
+}
+
+// parseFlags parses command line flags and returns a Config
+func parseFlags() distiller.Config {
+    config := distiller.Config{}
+
+    // Define flags
+    flag.StringVar(&config.Directory, "dir", "", "Directory to analyze")
+    
+    files := flag.String("files", "", "Comma-separated list of specific files to analyze")
+    exclude := flag.String("exclude", "", "Comma-separated list of exclude patterns")
+    include := flag.String("include", "", "Comma-separated list of include patterns")
+    
+    flag.StringVar(&config.OutputFormat, "format", "json", "Output format: json, pattern, pattern2, sarif, dot, mermaid or ndjson")
+    flag.BoolVar(&config.Compact, "compact", true, "Output compact JSON without indentation")
+    flag.BoolVar(&config.FilterEmpty, "filter-empty", true, "Filter out empty arrays and slices")
+    flag.BoolVar(&config.OnlyRelevant, "relevant", false, "Only include files relevant to target files")
+    flag.IntVar(&config.MaxResults, "max", 0, "Maximum number of files to include (0 for all)")
+    flag.IntVar(&config.MaxFunctions, "max-functions", 0, "Maximum number of functions per file, most important first (0 for all)")
+    flag.IntVar(&config.MaxTypes, "max-types", 0, "Maximum number of types per file, most important first (0 for all)")
+    flag.IntVar(&config.MaxFilesPerLang, "max-files-per-lang", 0, "Maximum number of files per language, most important first (0 for all)")
+    flag.StringVar(&config.OutputFile, "output", "", "Output file (default stdout)")
+    flag.BoolVar(&config.PrintVersion, "version", false, "Print version information")
+    flag.BoolVar(&config.Verbose, "verbose", false, "Enable verbose output")
+    flag.StringVar(&config.GroupBy, "group-by", "", "Reorganize output by: package")
+    omitFunctions := flag.String("omit-functions", "", "Comma-separated list of function names to omit")
+    flag.StringVar(&config.OmitFunctionsRegex, "omit-functions-regex", "", "Regular expression of function names to omit")
+    flag.BoolVar(&config.ShowConfigValues, "show-config-values", false, "Include INI/TOML values instead of redacting them")
+    flag.BoolVar(&config.FindDuplicates, "find-duplicates", false, "Report functions that look reimplemented across languages")
+    flag.IntVar(&config.Preview, "preview", 0, "Only output the first N files in walk order (0 for all)")
+    flag.BoolVar(&config.SchemaView, "schema-view", false, "Output the field/JSON name/DB column/type mapping of tagged Go structs")
+    flag.IntVar(&config.IndentSize, "indent-size", 0, "Columns a tab expands to in Python files (0 to detect)")
+    flag.BoolVar(&config.ExcludeEmptyFiles, "exclude-empty-files", false, "Drop files from which nothing was extracted")
+    flag.BoolVar(&config.SmartExcludes, "smart-excludes", true, "Skip common vendor, VCS and build directories")
+    flag.IntVar(&config.Top, "top", 0, "List the N most important symbols across the codebase (0 for none)")
+    flag.BoolVar(&config.NativePaths, "native-paths", false, "Keep OS-specific path separators instead of forward slashes")
+    flag.BoolVar(&config.SummaryOnly, "summary-only", false, "Output only counts, symbol names and files, without per-symbol details")
+    flag.BoolVar(&config.Cluster, "cluster", false, "Group files that call each other or share types into clusters")
+    flag.BoolVar(&config.ExplainImports, "explain-imports", false, "Record the members used from each import and flag unused imports")
+    flag.BoolVar(&config.Deterministic, "deterministic", false, "Omit the timestamp so identical input gives byte-identical output")
+    flag.BoolVar(&config.PageMap, "page-map", false, "Map each HTML page to the endpoints and handlers its forms, links and HTMX calls reach")
+    flag.BoolVar(&config.GroupImportsByOrigin, "group-imports-by-origin", false, "Mark imports as stdlib, thirdparty or local and list third-party dependencies")
+    flag.BoolVar(&config.Bundle, "bundle", false, "Output the full source of the -files targets plus the structure of the files they reference")
+    flag.BoolVar(&config.LanguageStats, "language-stats", false, "Add a per-language breakdown of files, lines, functions and share of lines")
+    flag.StringVar(&config.FilesFrom, "files-from", "", "Analyze exactly the newline-separated paths read from this file, or stdin with \"-\"")
+    flag.StringVar(&config.Baseline, "baseline", "", "Earlier JSON or pattern output to diff against")
+    flag.StringVar(&config.Trace, "trace", "", "Follow the include/require chain of this HTML/PHP page")
+    flag.BoolVar(&config.ProfileTypes, "profile-types", false, "Count how often each declared type is used as a field, parameter or return type")
+    flag.StringVar(&config.ExcludeSymbolsRegex, "exclude-symbols-regex", "", "Regular expression of functions, types, variables, CSS selectors and SQL tables to leave out")
+    flag.BoolVar(&config.CollapseImports, "collapse-imports", false, "List each import path once per language and refer to it by index from each file")
+    flag.BoolVar(&config.BudgetReport, "budget-report", false, "Print the estimated token cost of each section of the output to stderr")
+    flag.StringVar(&config.Query, "query", "", "Print only the values matched by a path into the output, e.g. goFiles[*].functions[*].name")
+    flag.StringVar(&config.Parser, "parser", "regex", "Parser for PHP, Python, CSS and SQL: regex or treesitter (needs a build with -tags treesitter)")
+    flag.IntVar(&config.ComplexityDelta, "complexity-delta", -1, "With -baseline, report functions whose complexity grew by more than N and exit with status 1 (-1 to not check)")
+    flag.BoolVar(&config.LSP, "lsp", false, "Run as a Language Server over stdio, answering document symbol, workspace symbol and definition requests")
+    flag.BoolVar(&config.PromoteEmbedded, "promote-embedded", false, "Move inline <style> rules into CSS files named page.html#style and index inline script functions with the rest of the code")
+    flag.IntVar(&config.Jobs, "jobs", 0, "Number of files analyzed in parallel, 0 for one per CPU")
+    flag.StringVar(&config.CacheDir, "cache", "", "Directory keeping per-file results between runs, so unchanged files are not parsed again")
+    flag.BoolVar(&config.Watch, "watch", false, "Keep running and rewrite -output, or print an NDJSON event to stdout, whenever files change")
+    flag.StringVar(&config.Listen, "listen", ":8080", "Address the serve subcommand listens on")
+    flag.StringVar(&config.OutputDB, "output-db", "", "SQLite file to write files, symbols, calls, CSS selectors and SQL tables to (needs a build with -tags sqlite)")
+    flag.BoolVar(&config.CallGraph, "call-graph", false, "Resolve each call to the function it reaches across files and list the edges as callGraph")
+    flag.BoolVar(&config.Unreferenced, "unreferenced", false, "List the functions, classes, CSS selectors and SQL tables nothing else refers to")
+    flag.BoolVar(&config.GoPackages, "go-packages", false, "Group Go files by package, with each struct's methods from every file, as goPackages")
+    goTags := flag.String("go-tags", "", "Comma-separated build tags, GOOS and GOARCH to mark Go files whose build constraints exclude them, e.g. linux,arm64,integration")
+    flag.BoolVar(&config.SkipBuildExcluded, "skip-build-excluded", false, "With -go-tags, leave out Go files excluded by their build constraints instead of marking them")
+    includeTests := flag.Bool("include-tests", true, "Analyze test files such as foo_test.go, test_foo.py, foo.spec.ts and FooTest.php")
+
+    // Parse the flags
+    flag.Parse()
+
+    // Check if help was requested
+    if len(os.Args) > 1 && (os.Args[1] == "--help" || os.Args[1] == "-h" || os.Args[1] == "help") {
+    showHelp()
+    os.Exit(0)
+    }
+
+    // Process comma-separated lists
+    if *files != "" {
+    config.TargetFiles = strings.Split(*files, ",")
+    }
+    if *exclude != "" {
+    config.ExcludePatterns = strings.Split(*exclude, ",")
+    }
+    if *include != "" {
+    config.IncludePatterns = strings.Split(*include, ",")
+    }
+    if *omitFunctions != "" {
+        config.OmitFunctions = strings.Split(*omitFunctions, ",")
+    }
+    if *goTags != "" {
+        config.GoTags = strings.Split(*goTags, ",")
+    }
+    config.ExcludeTests = !*includeTests
+
+    return config
+}
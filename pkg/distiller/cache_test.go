@@ -0,0 +1,65 @@
+package distiller
+
+import (
+    "bytes"
+    "context"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+// analyzeAndRender runs a full analysis of config.Directory and renders it as JSON
+func analyzeAndRender(t *testing.T, config Config) []byte {
+    t.Helper()
+    analyzer, err := NewAnalyzer(config)
+    if err != nil {
+        t.Fatal(err)
+    }
+    summary, err := analyzer.Analyze(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    output, err := Render(summary, config)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return output
+}
+
+// A warm -cache run must give the same output as a cold one, including the findings
+// worked out across files after the per-file results are cached
+func TestCachedOutputMatchesUncached(t *testing.T) {
+    dir := t.TempDir()
+    source := `package main
+
+import "context"
+
+func fetch(ctx context.Context, id int) error { return nil }
+
+func handle(ctx context.Context) error {
+    return fetch(context.Background(), 1)
+}
+`
+    if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    config := Config{Directory: dir, OutputFormat: "json", Deterministic: true, Compact: true, ComplexityDelta: -1}
+    uncached := analyzeAndRender(t, config)
+    if !bytes.Contains(uncached, []byte(`"contextNotPropagated":["handle: fetch"]`)) {
+        t.Fatalf("expected handle: fetch to be flagged, got %s", uncached)
+    }
+    if bytes.Contains(uncached, []byte("callsWithoutContext")) {
+        t.Errorf("callsWithoutContext leaked into the output: %s", uncached)
+    }
+
+    config.CacheDir = t.TempDir()
+    cold := analyzeAndRender(t, config)
+    warm := analyzeAndRender(t, config)
+    if !bytes.Equal(cold, uncached) {
+        t.Errorf("cold cached output differs from uncached output:\n%s\n%s", cold, uncached)
+    }
+    if !bytes.Equal(warm, cold) {
+        t.Errorf("warm cached output differs from cold output:\n%s\n%s", warm, cold)
+    }
+}
@@ -0,0 +1,71 @@
+package distiller
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+)
+
+// A call to the parent implementation goes to the base class, not back to the overriding method
+func TestCallGraphResolvesParentCalls(t *testing.T) {
+    dir := t.TempDir()
+    files := map[string]string{
+        "X.php": "<?php\nclass X { public function y() { return 1; } }\n",
+        "Z.php": "<?php\nclass Z extends X { public function y() { return parent::y(); } }\n",
+        "p.py":  "class P:\n    def run(self):\n        return 1\n",
+        "q.py":  "from p import P\n\nclass Q(P):\n    def run(self):\n        return super().run()\n",
+        "a.js":  "class A { render() { return 1; } }\n",
+        "b.js":  "class B extends A { render() { return super.render(); } }\n",
+    }
+    for name, source := range files {
+        if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0o644); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    analyzer, err := NewAnalyzer(Config{Directory: dir, CallGraph: true, ComplexityDelta: -1})
+    if err != nil {
+        t.Fatal(err)
+    }
+    summary, err := analyzer.Analyze(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    var edges []string
+    for _, edge := range summary.CallGraph {
+        edges = append(edges, edge.Caller.Name+" -> "+edge.Callee.Name)
+    }
+    want := []string{"Z.y -> X.y", "Q.run -> P.run", "B.render -> A.render"}
+    for _, edge := range want {
+        found := false
+        for _, got := range edges {
+            found = found || got == edge
+        }
+        if !found {
+            t.Errorf("missing edge %q in %q", edge, edges)
+        }
+    }
+    for _, edge := range summary.CallGraph {
+        if edge.Caller.Name == edge.Callee.Name {
+            t.Errorf("%s resolves to itself", edge.Caller.Name)
+        }
+    }
+
+    calledBy := make(map[string][]string)
+    for _, f := range summary.PhpFiles {
+        for _, class := range f.Classes {
+            for _, method := range class.Methods {
+                calledBy[class.Name+"."+method.Name] = method.CalledBy
+            }
+        }
+    }
+    if got := calledBy["X.y"]; !reflect.DeepEqual(got, []string{"Z.y"}) {
+        t.Errorf("X.y calledBy = %q, want [Z.y]", got)
+    }
+    if got := calledBy["Z.y"]; len(got) != 0 {
+        t.Errorf("Z.y calledBy = %q, want none", got)
+    }
+}
@@ -0,0 +1,14763 @@
+// Package distiller extracts the structure of a codebase - functions, types, imports,
+// routes, schemas and how they relate - into a compact summary for AI assistants.
+// The distiller command is a thin wrapper around Analyze and Render.
+package distiller
+
+import (
+    "context"
+    "crypto/sha1"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "go/ast"
+    "go/build/constraint"
+    "go/constant"
+    "go/parser"
+    "go/scanner"
+    "go/token"
+    "golang.org/x/net/html"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "regexp"
+    "runtime"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+    "sort"
+)
+
+// Route represents an HTTP route registered with a web framework
+type Route struct {
+    Method     string   `json:"method"` // "GET", "POST", ..., "ANY", or "GET|POST" for several
+    Path       string   `json:"path"`   // Including group and prefix paths
+    Handler    string   `json:"handler,omitempty"`
+    Middleware []string `json:"middleware,omitempty"` // Outermost first: app, group, then route middleware
+    Line       int      `json:"line"`
+}
+
+// RealtimeEndpoint represents a WebSocket, Socket.IO or Server-Sent Events endpoint,
+// served by a handler or connected to by a client script
+type RealtimeEndpoint struct {
+    Kind    string `json:"kind"`              // "websocket", "socketio" or "sse"
+    Path    string `json:"path,omitempty"`    // Route path or URL, when known
+    Event   string `json:"event,omitempty"`   // Socket.IO or message event handled
+    Handler string `json:"handler,omitempty"` // Function serving the endpoint
+    Line    int    `json:"line"`
+}
+
+// ScheduledJob represents a cron entry or a job registered with a scheduler library
+type ScheduledJob struct {
+    Schedule string `json:"schedule"`           // Cron expression, @-shortcut, or the scheduler's own spec such as "daily()->at('13:00')"
+    Handler  string `json:"handler,omitempty"`  // Function, task, job class or command run
+    Line     int    `json:"line"`
+    FilePath string `json:"filePath,omitempty"` // Only set for crontab entries, which have no file summary of their own
+}
+
+// Variable represents a variable declaration in code
+type Variable struct {
+    Name  string `json:"name"`
+    Type  string `json:"type"`
+    Scope string `json:"scope"` // "global", "local", "struct", "property", etc.
+    Line  int    `json:"line"`
+    Tag   string `json:"tag,omitempty"` // Raw Go struct tag, e.g. json:"id" db:"user_id"
+    Tags  map[string]string `json:"tags,omitempty"` // Go struct tag by key, e.g. "json": "id", "db": "user_id"
+    Mutations int `json:"mutations,omitempty"` // Assignments within the scope, including the initializer
+    Ordinal   int `json:"ordinal,omitempty"`   // 1-based declaration position of a Go struct field
+}
+
+// Function represents a function declaration in code
+type Function struct {
+    ID       string     `json:"id,omitempty"` // Stable identifier derived from file, qualified name and kind
+    Name     string     `json:"name"`
+    Doc      string     `json:"doc,omitempty"` // Doc comment, docstring or PHPDoc/JSDoc block, comment markers removed
+    Args     []Variable `json:"args"`
+    Returns  []string   `json:"returns"`
+    Receiver string     `json:"receiver,omitempty"` // For methods
+    Line     int        `json:"line"`
+    Calls    []string   `json:"calls,omitempty"` // Functions called within this function
+    CalledBy []string   `json:"calledBy,omitempty"` // Qualified names of the functions whose calls resolve to this one
+    ContextManagers []string `json:"contextManagers,omitempty"` // Python "with" resources, e.g. "open(path) as f"
+    HasRecover bool         `json:"hasRecover,omitempty"`      // Go function calls recover(), containing panics
+    ReturnsField string     `json:"returnsField,omitempty"`    // Field returned by a simple getter
+    TakesContext bool       `json:"takesContext,omitempty"`    // Go function whose first parameter is a context.Context
+    IsStatic     bool       `json:"isStatic,omitempty"`
+    IsAbstract   bool       `json:"isAbstract,omitempty"`
+    Modifiers    []string   `json:"modifiers,omitempty"` // PHP method modifiers, e.g. "public", "static", "final"
+    Kind         string     `json:"kind,omitempty"`      // Go test function: "test", "benchmark", "fuzz" or "example"
+    Assertions   []string   `json:"assertions,omitempty"`      // Assertion calls made by a test
+    TestedFunctions []string `json:"testedFunctions,omitempty"` // Functions a test exercises, from its name and calls
+    TypeParams   []TypeParam `json:"typeParams,omitempty"` // Go generic type parameters
+    FStringRefs  []string   `json:"fStringRefs,omitempty"` // Names interpolated into Python f-strings, e.g. "user.name"
+    IsDeprecated bool       `json:"isDeprecated,omitempty"`
+    Deprecation  string     `json:"deprecation,omitempty"` // Deprecation message, e.g. "use Fetch instead"
+    IsHTTPHandler bool      `json:"isHTTPHandler,omitempty"` // Go function with a net/http, Gin, Echo or Fiber handler signature
+    Overrides    string     `json:"overrides,omitempty"`  // Nearest base class defining a method of the same name
+    SuperCalls   []string   `json:"superCalls,omitempty"` // Base implementations invoked through super() or parent::
+    Complexity   int        `json:"complexity,omitempty"`  // Cyclomatic complexity, one plus the branches and loops in the body
+    EndLine      int        `json:"endLine,omitempty"`     // Line the body ends on, Line being where the declaration starts
+    Statements   int        `json:"statements,omitempty"`  // Statements in the body, nested ones included
+    MaxNesting   int        `json:"maxNesting,omitempty"`  // Deepest nesting of blocks within the body
+    SideEffects  []string   `json:"sideEffects,omitempty"` // Heuristic effects: "io", "db", "global-mutation", "print", "panic"
+    Pure         bool       `json:"pure,omitempty"`        // No side effect was detected in it or in the functions it calls
+
+    // Calls made without passing the function's own context. Serialized so cached files keep
+    // it, and cleared once ContextNotPropagated has been worked out from it.
+    CallsWithoutContext []string `json:"callsWithoutContext,omitempty"`
+}
+
+// ControlFlow represents control flow structures in code
+type ControlFlow struct {
+    Type     string        `json:"type"` // "if", "for", "switch", "select", "while", "foreach", etc.
+    Line     int           `json:"line"`
+    EndLine  int           `json:"endLine,omitempty"` // Line of the closing brace (Go/PHP) or last indented line (Python)
+    Children []ControlFlow `json:"children,omitempty"` // Nested control flow
+}
+
+// Struct represents a struct/class definition in code
+type Struct struct {
+    ID      string     `json:"id,omitempty"` // Stable identifier derived from file, qualified name and kind
+    Name    string     `json:"name"`
+    Doc     string     `json:"doc,omitempty"` // Doc comment, docstring or PHPDoc/JSDoc block, comment markers removed
+    Fields  []Variable `json:"fields"`
+    Methods []Function `json:"methods,omitempty"`
+    Line    int        `json:"line"`        // Add this field
+    Nested  []Struct   `json:"nested,omitempty"` // Classes defined inside this class or its methods
+    IsAbstract bool    `json:"isAbstract,omitempty"` // Python ABC or class with abstract methods
+    IsProtocol bool    `json:"isProtocol,omitempty"` // Python typing.Protocol, a structural interface
+    TypeParams []TypeParam `json:"typeParams,omitempty"` // Go generic type parameters
+    IsDeprecated bool      `json:"isDeprecated,omitempty"`
+    Deprecation  string    `json:"deprecation,omitempty"` // Deprecation message, e.g. "use Client instead"
+    Bases        []string  `json:"bases,omitempty"` // Python base classes, or the PHP parent class and interfaces
+    Decorators   []string  `json:"decorators,omitempty"`   // TypeScript class decorators, e.g. "Component", "Injectable"
+    Dependencies []string  `json:"dependencies,omitempty"` // Types injected through a TypeScript constructor, e.g. "HttpClient"
+}
+
+// TypeParam represents a Go type parameter and the constraint bounding it
+type TypeParam struct {
+    Name       string `json:"name"`
+    Constraint string `json:"constraint"`          // e.g. "any", "comparable", "~int | ~float64"
+    DefinedIn  string `json:"definedIn,omitempty"` // File declaring the constraint interface, when it is local
+}
+
+// Interface represents an interface definition in code
+type Interface struct {
+    Name    string     `json:"name"`
+    Doc     string     `json:"doc,omitempty"` // Doc comment or JSDoc block, comment markers removed
+    Methods []Function `json:"methods"`
+    Embeds  []string   `json:"embeds,omitempty"` // Embedded interfaces and type set terms, e.g. "io.Reader"
+    Fields  []Variable `json:"fields,omitempty"` // TypeScript interface properties
+    Line    int        `json:"line,omitempty"`
+    TypeParams []TypeParam `json:"typeParams,omitempty"` // Go generic type parameters
+}
+
+// ExternalCall represents an outbound HTTP call to an external service
+type ExternalCall struct {
+    Client string `json:"client"`           // e.g. "net/http", "requests", "curl", "fetch"
+    Method string `json:"method,omitempty"` // HTTP method when it can be determined
+    URL    string `json:"url,omitempty"`    // Literal URL or endpoint, if any
+    Line   int    `json:"line"`
+}
+
+// Import represents an import/include/require statement in code
+type Import struct {
+    Path string `json:"path"`
+    UsedSymbols []string `json:"usedSymbols,omitempty"` // Members referenced through the import, with -explain-imports
+    Unused      bool     `json:"unused,omitempty"`      // Nothing in the file refers to the import, with -explain-imports
+    Origin      string   `json:"origin,omitempty"`      // "stdlib", "thirdparty" or "local", with -group-imports-by-origin
+}
+
+// GoFileSummary represents a summary of a Go file
+type GoFileSummary struct {
+    FilePath     string        `json:"filePath"`
+    Package      string        `json:"package,omitempty"`
+    ParseError   string        `json:"parseError,omitempty"` // Why the file could not be parsed, leaving the rest empty
+    ParseErrorLine   int       `json:"parseErrorLine,omitempty"`   // 1-based position of the first syntax error
+    ParseErrorColumn int       `json:"parseErrorColumn,omitempty"`
+    BuildConstraint string     `json:"buildConstraint,omitempty"` // //go:build expression, with the GOOS/GOARCH of a name like main_windows.go
+    BuildExcluded   bool       `json:"buildExcluded,omitempty"`   // The constraint does not hold for -go-tags
+    Variables    []Variable    `json:"variables,omitempty"`
+    Constants    []Constant    `json:"constants,omitempty"`
+    Functions    []Function    `json:"functions,omitempty"`
+    ControlFlows []ControlFlow `json:"controlFlows,omitempty"`
+    Structs      []Struct      `json:"structs,omitempty"`
+    Interfaces   []Interface   `json:"interfaces,omitempty"`
+    Imports      []Import      `json:"imports,omitempty"`
+    ImportRefs   []int         `json:"importRefs,omitempty"` // Indices into Summary.ImportIndex, with -collapse-imports
+    ExternalCalls []ExternalCall `json:"externalCalls,omitempty"`
+    BuildInfoVars []string     `json:"buildInfoVars,omitempty"` // Package-level strings typically set via -ldflags -X
+    EventsEmitted []string     `json:"eventsEmitted,omitempty"`
+    EventsHandled []string     `json:"eventsHandled,omitempty"`
+    PanicSites   []int         `json:"panicSites,omitempty"` // Lines of explicit panic(...) calls
+    ContextNotPropagated []string `json:"contextNotPropagated,omitempty"` // "caller: callee" pairs that drop the caller's context
+    EnvVars      []string      `json:"envVars,omitempty"` // Environment variables read by the code
+    SQLConcatenations []int    `json:"sqlConcatenations,omitempty"` // Lines building SQL from values by concatenation or interpolation
+    SQLTables    []string      `json:"sqlTables,omitempty"` // Tables named by SQL queries in string literals
+    Routes       []Route       `json:"routes,omitempty"`
+    RealtimeEndpoints []RealtimeEndpoint `json:"realtimeEndpoints,omitempty"`
+    ScheduledJobs []ScheduledJob `json:"scheduledJobs,omitempty"`
+    Handlers     []string      `json:"handlers,omitempty"` // HTTP handlers found by signature, however they are registered
+}
+
+// PhpFileSummary represents a summary of a PHP file
+type PhpFileSummary struct {
+    FilePath     string        `json:"filePath"`
+    Namespace    string        `json:"namespace,omitempty"`
+    Variables    []Variable    `json:"variables,omitempty"`
+    Functions    []Function    `json:"functions,omitempty"`
+    ControlFlows []ControlFlow `json:"controlFlows,omitempty"`
+    Classes      []Struct      `json:"classes,omitempty"`
+    Interfaces   []Interface   `json:"interfaces,omitempty"`
+    Imports      []Import      `json:"imports,omitempty"`
+    ImportRefs   []int         `json:"importRefs,omitempty"` // Indices into Summary.ImportIndex, with -collapse-imports
+    ExternalCalls []ExternalCall `json:"externalCalls,omitempty"`
+    LikelyMinified  bool       `json:"likelyMinified,omitempty"`
+    ReadableSymbols []string   `json:"readableSymbols,omitempty"` // Recoverable names in minified code
+    EventsEmitted []string     `json:"eventsEmitted,omitempty"`
+    EventsHandled []string     `json:"eventsHandled,omitempty"`
+    EnvVars      []string      `json:"envVars,omitempty"` // Environment variables read by the code
+    SQLConcatenations []int    `json:"sqlConcatenations,omitempty"` // Lines building SQL from values by concatenation or interpolation
+    SQLTables    []string      `json:"sqlTables,omitempty"` // Tables named by SQL queries in string literals
+    Routes       []Route       `json:"routes,omitempty"`
+    RealtimeEndpoints []RealtimeEndpoint `json:"realtimeEndpoints,omitempty"`
+    ScheduledJobs []ScheduledJob `json:"scheduledJobs,omitempty"`
+}
+
+// PythonFileSummary represents a summary of a Python file
+type PythonFileSummary struct {
+    FilePath     string        `json:"filePath"`
+    Variables    []Variable    `json:"variables,omitempty"`
+    Functions    []Function    `json:"functions,omitempty"`
+    ControlFlows []ControlFlow `json:"controlFlows,omitempty"`
+    Classes      []Struct      `json:"classes,omitempty"`
+    Imports      []Import      `json:"imports,omitempty"`
+    ImportRefs   []int         `json:"importRefs,omitempty"` // Indices into Summary.ImportIndex, with -collapse-imports
+    Decorators   []string      `json:"decorators,omitempty"`
+    ExternalCalls []ExternalCall `json:"externalCalls,omitempty"`
+    ContextManagers []string   `json:"contextManagers,omitempty"`
+    EventsEmitted []string     `json:"eventsEmitted,omitempty"`
+    EventsHandled []string     `json:"eventsHandled,omitempty"`
+    EnvVars      []string      `json:"envVars,omitempty"` // Environment variables read by the code
+    SQLConcatenations []int    `json:"sqlConcatenations,omitempty"` // Lines building SQL from values by concatenation or interpolation
+    SQLTables    []string      `json:"sqlTables,omitempty"` // Tables named by SQL queries in string literals
+    Routes       []Route       `json:"routes,omitempty"`
+    RealtimeEndpoints []RealtimeEndpoint `json:"realtimeEndpoints,omitempty"`
+    ScheduledJobs []ScheduledJob `json:"scheduledJobs,omitempty"`
+}
+
+// JsFileSummary represents a summary of a JavaScript file
+type JsFileSummary struct {
+    FilePath     string        `json:"filePath"`
+    Functions    []Function    `json:"functions,omitempty"` // Function declarations, and functions assigned to top-level variables
+    Classes      []Struct      `json:"classes,omitempty"`
+    Constants    []Variable    `json:"constants,omitempty"` // Top-level const declarations that aren't functions
+    ControlFlows []ControlFlow `json:"controlFlows,omitempty"`
+    Imports      []Import      `json:"imports,omitempty"` // ES module imports, require() calls and dynamic imports
+    ImportRefs   []int         `json:"importRefs,omitempty"` // Indices into Summary.ImportIndex, with -collapse-imports
+    Exports      []string      `json:"exports,omitempty"` // Exported names, "default" for an anonymous default export
+    ExternalCalls []ExternalCall `json:"externalCalls,omitempty"`
+    LikelyMinified  bool       `json:"likelyMinified,omitempty"`
+    ReadableSymbols []string   `json:"readableSymbols,omitempty"` // Recoverable names in minified code
+    EventsEmitted []string     `json:"eventsEmitted,omitempty"`
+    EventsHandled []string     `json:"eventsHandled,omitempty"`
+    EnvVars      []string      `json:"envVars,omitempty"` // Environment variables read through process.env or import.meta.env
+    SQLConcatenations []int    `json:"sqlConcatenations,omitempty"` // Lines building SQL from values by concatenation or interpolation
+    SQLTables    []string      `json:"sqlTables,omitempty"` // Tables named by SQL queries in string literals
+    RealtimeEndpoints []RealtimeEndpoint `json:"realtimeEndpoints,omitempty"`
+}
+
+// TsFileSummary represents a summary of a TypeScript file
+type TsFileSummary struct {
+    FilePath     string        `json:"filePath"`
+    Functions    []Function    `json:"functions,omitempty"` // Function declarations, and functions assigned to top-level variables
+    Classes      []Struct      `json:"classes,omitempty"`
+    Interfaces   []Interface   `json:"interfaces,omitempty"`
+    TypeAliases  []TypeAlias   `json:"typeAliases,omitempty"`
+    Enums        []Enum        `json:"enums,omitempty"`
+    Constants    []Variable    `json:"constants,omitempty"` // Top-level const declarations that aren't functions
+    ControlFlows []ControlFlow `json:"controlFlows,omitempty"`
+    Imports      []Import      `json:"imports,omitempty"` // ES module imports, require() calls and dynamic imports
+    ImportRefs   []int         `json:"importRefs,omitempty"` // Indices into Summary.ImportIndex, with -collapse-imports
+    Exports      []string      `json:"exports,omitempty"` // Exported names, "default" for an anonymous default export
+    ExternalCalls []ExternalCall `json:"externalCalls,omitempty"`
+    LikelyMinified  bool       `json:"likelyMinified,omitempty"`
+    ReadableSymbols []string   `json:"readableSymbols,omitempty"` // Recoverable names in minified code
+    EventsEmitted []string     `json:"eventsEmitted,omitempty"`
+    EventsHandled []string     `json:"eventsHandled,omitempty"`
+    EnvVars      []string      `json:"envVars,omitempty"` // Environment variables read through process.env or import.meta.env
+    SQLConcatenations []int    `json:"sqlConcatenations,omitempty"` // Lines building SQL from values by concatenation or interpolation
+    SQLTables    []string      `json:"sqlTables,omitempty"` // Tables named by SQL queries in string literals
+    RealtimeEndpoints []RealtimeEndpoint `json:"realtimeEndpoints,omitempty"`
+}
+
+// TypeAlias represents a TypeScript type alias, e.g. "type ID = string | number"
+type TypeAlias struct {
+    Name string `json:"name"`
+    Type string `json:"type"` // The aliased type, with whitespace collapsed
+    Line int    `json:"line"`
+}
+
+// Constant represents a Go constant from a top-level const declaration
+type Constant struct {
+    Name  string `json:"name"`
+    Type  string `json:"type,omitempty"`  // Declared type, repeated for the specs of a block that leave it out
+    Value string `json:"value,omitempty"` // Evaluated value where it can be worked out, otherwise the expression
+    Iota  bool   `json:"iota,omitempty"`  // The value is derived from iota, as in an enum
+    Group int    `json:"group,omitempty"` // Line of the const ( ... ) block declaring it, 0 when declared alone
+    Line  int    `json:"line"`
+}
+
+// Enum represents a TypeScript enum and the names of its members
+type Enum struct {
+    Name    string   `json:"name"`
+    Members []string `json:"members,omitempty"`
+    IsConst bool     `json:"isConst,omitempty"` // Declared as "const enum"
+    Line    int      `json:"line"`
+}
+
+// HtmlElement represents an HTML element
+type HtmlElement struct {
+    ID                string            `json:"id,omitempty"`
+    Classes           []string          `json:"classes,omitempty"`
+    Attributes        map[string]string `json:"attributes,omitempty"`
+    Line              int               `json:"line"`
+    LinkedFunctions   []string          `json:"linkedFunctions,omitempty"`
+}
+
+// HtmlFileSummary represents a summary of an HTML file
+type HtmlFileSummary struct {
+    FilePath   string        `json:"filePath"`
+    Elements   []HtmlElement `json:"elements"`
+    EmbeddedJS []Function    `json:"embeddedJS,omitempty"`
+    EmbeddedCSS []CSSRule    `json:"embeddedCSS,omitempty"`
+    Includes   []string      `json:"includes,omitempty"`
+    ExternalCalls []ExternalCall `json:"externalCalls,omitempty"`
+    LikelyMinified  bool     `json:"likelyMinified,omitempty"`
+    ReadableSymbols []string `json:"readableSymbols,omitempty"` // Recoverable names in minified code
+    EventsEmitted []string   `json:"eventsEmitted,omitempty"`
+    EventsHandled []string   `json:"eventsHandled,omitempty"`
+    RealtimeEndpoints []RealtimeEndpoint `json:"realtimeEndpoints,omitempty"` // WebSocket, Socket.IO and EventSource connections opened by scripts
+}
+
+// CSSRule represents a CSS rule
+type CSSRule struct {
+    Selector string            `json:"selector"`
+    Properties map[string]string `json:"properties"`
+    Line     int               `json:"line"`
+    MediaQuery string          `json:"mediaQuery,omitempty"`
+}
+
+// CSSFileSummary represents a summary of a CSS file
+type CSSFileSummary struct {
+    FilePath string    `json:"filePath"`
+    Rules    []CSSRule `json:"rules"`
+    Imports  []string  `json:"imports,omitempty"`
+    LikelyMinified  bool     `json:"likelyMinified,omitempty"`
+    ReadableSymbols []string `json:"readableSymbols,omitempty"` // Recoverable names in minified code
+    DuplicateSelectors []string `json:"duplicateSelectors,omitempty"` // Selectors defined by more than one rule
+    Conflicts  []Conflict `json:"conflicts,omitempty"`
+    ZIndexValues []ZIndexEntry `json:"zIndexValues,omitempty"` // Stacking order set by the rules, lowest first
+}
+
+// ZIndexEntry represents a z-index declaration
+type ZIndexEntry struct {
+    Selector   string `json:"selector"`
+    Value      string `json:"value"` // e.g. "1000", "-1", "auto" or "var(--z-modal)"
+    Line       int    `json:"line"`
+    MediaQuery string `json:"mediaQuery,omitempty"`
+    FilePath   string `json:"filePath,omitempty"` // Set in the pattern format's codebase-wide list
+}
+
+// Conflict represents a CSS property given different values by rules for the same selector
+type Conflict struct {
+    Selector   string   `json:"selector"`
+    MediaQuery string   `json:"mediaQuery,omitempty"`
+    Property   string   `json:"property"`
+    Values     []string `json:"values"` // In source order; the last one wins
+    Lines      []int    `json:"lines"`
+}
+
+// SQLStatement represents a SQL statement
+type SQLStatement struct {
+    Type      string   `json:"type"` // "SELECT", "INSERT", "UPDATE", "DELETE", "CREATE", "ALTER", etc.
+    Tables    []string `json:"tables"` 
+    Columns   []string `json:"columns,omitempty"`
+    Line      int      `json:"line"`
+    RawQuery  string   `json:"rawQuery,omitempty"`
+    ParamStyle string   `json:"paramStyle,omitempty"` // "?", "$n", ":name", "@name", "%s", "%(name)s" or "mixed"
+    ParamCount int      `json:"paramCount,omitempty"` // Number of arguments that must be bound
+    Params     []string `json:"params,omitempty"`     // Distinct numbered or named placeholders
+    ForeignKeys []ForeignKey `json:"foreignKeys,omitempty"`
+    Aggregates []string `json:"aggregates,omitempty"` // Aggregate calls such as "COUNT(*)" or "SUM(total)"
+    GroupBy    []string `json:"groupBy,omitempty"`    // GROUP BY columns or expressions
+    HasLimit   bool     `json:"hasLimit,omitempty"`   // LIMIT, TOP or FETCH FIRST on the outer query
+    Limit      int      `json:"limit,omitempty"`      // Row limit when it is a literal
+    Offset     int      `json:"offset,omitempty"`     // Rows skipped when it is a literal
+    Unbounded  bool     `json:"unbounded,omitempty"`  // SELECT with neither LIMIT nor WHERE, a potential full table scan
+}
+
+// ForeignKey represents a column referencing another table
+type ForeignKey struct {
+    Column    string `json:"column"`
+    RefTable  string `json:"refTable"`
+    RefColumn string `json:"refColumn,omitempty"` // Empty when the referenced primary key is implied
+}
+
+// Relationship is an edge of the schema graph, from a referencing column to the referenced table
+type Relationship struct {
+    FromTable  string `json:"fromTable"`
+    FromColumn string `json:"fromColumn"`
+    ToTable    string `json:"toTable"`
+    ToColumn   string `json:"toColumn,omitempty"`
+}
+
+// SQLFileSummary represents a summary of a SQL file
+type SQLFileSummary struct {
+    FilePath   string         `json:"filePath"`
+    Statements []SQLStatement `json:"statements"`
+}
+
+// GoModule represents a go.mod or go.work file
+type GoModule struct {
+    FilePath  string          `json:"filePath"`
+    Module    string          `json:"module,omitempty"`    // Module path, not set for go.work
+    GoVersion string          `json:"goVersion,omitempty"`
+    Toolchain string          `json:"toolchain,omitempty"`
+    Requires  []GoRequirement `json:"requires,omitempty"`
+    Replaces  []string        `json:"replaces,omitempty"`  // e.g. "example.com/lib => ../lib"
+    Uses      []string        `json:"uses,omitempty"`      // Module directories of a go.work workspace
+}
+
+// GoRequirement represents a module required by a go.mod file
+type GoRequirement struct {
+    Path     string `json:"path"`
+    Version  string `json:"version,omitempty"`
+    Indirect bool   `json:"indirect,omitempty"` // Marked "// indirect", needed only by other requirements
+}
+
+// Migration represents a database migration file and the schema changes it makes
+type Migration struct {
+    FilePath  string   `json:"filePath"`
+    Version   string   `json:"version"`             // Ordering prefix of the file name, e.g. "0001" or "20240105120000"
+    Name      string   `json:"name,omitempty"`      // Descriptive part of the file name, e.g. "create_users"
+    Framework string   `json:"framework"`           // "sql", "flyway", "rails", "django" or "laravel"
+    Direction string   `json:"direction,omitempty"` // "up" or "down" for paired SQL migrations
+    Changes   []string `json:"changes,omitempty"`   // e.g. "CREATE TABLE users", "add_column users.email", "AddField book.author"
+}
+
+// CodeBlock represents a fenced code block embedded in a Markdown file
+type CodeBlock struct {
+    Language string      `json:"language,omitempty"`
+    Line     int         `json:"line"`              // Line of the first code line within the document
+    Summary  interface{} `json:"summary,omitempty"` // Language-specific file summary of the block
+}
+
+// UnmarshalJSON decodes the block summary into the type analyzeCodeBlock returns for the
+// block's language, so summaries read back from the cache keep their type
+func (block *CodeBlock) UnmarshalJSON(data []byte) error {
+    var raw struct {
+        Language string          `json:"language"`
+        Line     int             `json:"line"`
+        Summary  json.RawMessage `json:"summary"`
+    }
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return err
+    }
+    block.Language = raw.Language
+    block.Line = raw.Line
+    block.Summary = nil
+    if len(raw.Summary) == 0 || string(raw.Summary) == "null" {
+        return nil
+    }
+
+    var err error
+    switch raw.Language {
+    case "go", "golang":
+        var summary GoFileSummary
+        err = json.Unmarshal(raw.Summary, &summary)
+        block.Summary = summary
+    case "php":
+        var summary PhpFileSummary
+        err = json.Unmarshal(raw.Summary, &summary)
+        block.Summary = summary
+    case "python", "py", "python3":
+        var summary PythonFileSummary
+        err = json.Unmarshal(raw.Summary, &summary)
+        block.Summary = summary
+    case "javascript", "js", "jsx", "mjs":
+        var summary JsFileSummary
+        err = json.Unmarshal(raw.Summary, &summary)
+        block.Summary = summary
+    case "typescript", "ts", "tsx":
+        var summary TsFileSummary
+        err = json.Unmarshal(raw.Summary, &summary)
+        block.Summary = summary
+    case "html", "htm":
+        var summary HtmlFileSummary
+        err = json.Unmarshal(raw.Summary, &summary)
+        block.Summary = summary
+    case "css":
+        var summary CSSFileSummary
+        err = json.Unmarshal(raw.Summary, &summary)
+        block.Summary = summary
+    case "sql":
+        var summary SQLFileSummary
+        err = json.Unmarshal(raw.Summary, &summary)
+        block.Summary = summary
+    default:
+        err = json.Unmarshal(raw.Summary, &block.Summary)
+    }
+    return err
+}
+
+// MarkdownFileSummary represents a summary of a Markdown file
+type MarkdownFileSummary struct {
+    FilePath   string      `json:"filePath"`
+    CodeBlocks []CodeBlock `json:"codeBlocks,omitempty"`
+}
+
+// ConfigSection represents a [section] of an INI or TOML file and the keys it defines
+type ConfigSection struct {
+    Name   string            `json:"name"` // Empty for keys defined before the first section header
+    Line   int               `json:"line"`
+    Keys   []string          `json:"keys,omitempty"`
+    Values map[string]string `json:"values,omitempty"` // Only populated when values are not redacted
+}
+
+// ConfigFileSummary represents a summary of an INI or TOML configuration file
+type ConfigFileSummary struct {
+    FilePath string          `json:"filePath"`
+    Format   string          `json:"format"` // "ini" or "toml"
+    Sections []ConfigSection `json:"sections,omitempty"`
+}
+
+// DockerCopy represents a COPY or ADD instruction in a Dockerfile
+type DockerCopy struct {
+    Instruction string   `json:"instruction"` // "COPY" or "ADD"
+    Sources     []string `json:"sources"`
+    Dest        string   `json:"dest"`
+    FromStage   string   `json:"fromStage,omitempty"` // Build stage given via --from
+    Line        int      `json:"line"`
+}
+
+// DockerfileSummary represents a summary of a Dockerfile
+type DockerfileSummary struct {
+    FilePath     string       `json:"filePath"`
+    BaseImages   []string     `json:"baseImages,omitempty"`
+    Stages       []string     `json:"stages,omitempty"` // Named build stages ("FROM image AS name")
+    ExposedPorts []string     `json:"exposedPorts,omitempty"`
+    EnvVars      []string     `json:"envVars,omitempty"`
+    BuildArgs    []string     `json:"buildArgs,omitempty"`
+    Copies       []DockerCopy `json:"copies,omitempty"`
+    Cmd          string       `json:"cmd,omitempty"`
+    Entrypoint   string       `json:"entrypoint,omitempty"`
+}
+
+// MakeTarget represents a rule in a Makefile
+type MakeTarget struct {
+    Name          string   `json:"name"`
+    Prerequisites []string `json:"prerequisites,omitempty"`
+    Commands      []string `json:"commands,omitempty"` // Recipe lines as written, without the leading tab
+    Phony         bool     `json:"phony,omitempty"`    // Listed in .PHONY
+    Line          int      `json:"line"`
+}
+
+// MakeVariable represents a variable defined in a Makefile
+type MakeVariable struct {
+    Name     string `json:"name"`
+    Operator string `json:"operator"` // "=", ":=", "::=", "?=", "+=", "!=" or "define"
+    Value    string `json:"value,omitempty"`
+    Line     int    `json:"line"`
+}
+
+// MakefileSummary represents a summary of a Makefile
+type MakefileSummary struct {
+    FilePath      string         `json:"filePath"`
+    DefaultTarget string         `json:"defaultTarget,omitempty"` // Target run by a bare "make"
+    Targets       []MakeTarget   `json:"targets,omitempty"`
+    Variables     []MakeVariable `json:"variables,omitempty"`
+    Includes      []string       `json:"includes,omitempty"`
+}
+
+// RankedSymbol is a function or type ranked by its importance to the codebase
+type RankedSymbol struct {
+    ID         string `json:"id,omitempty"`
+    Name       string `json:"name"` // Qualified name, e.g. "Server.Start"
+    Kind       string `json:"kind"` // "function", "method" or "type"
+    FilePath   string `json:"filePath"`
+    Line       int    `json:"line"`
+    Score      int    `json:"score"`
+    Callers    int    `json:"callers"`    // Functions calling it (or uses, for types) across the codebase
+    Complexity int    `json:"complexity"` // Cyclomatic complexity, or number of methods for types
+    Exported   bool   `json:"exported,omitempty"`
+}
+
+// EnvFileSummary represents the keys documented by a .env or .env.example file
+type EnvFileSummary struct {
+    FilePath string   `json:"filePath"`
+    EnvKeys  []string `json:"envKeys,omitempty"` // Values are never recorded
+}
+
+// Summary represents a summary of all analyzed files
+type Summary struct {
+    GoFiles      []GoFileSummary     `json:"goFiles,omitempty"`
+    PhpFiles     []PhpFileSummary    `json:"phpFiles,omitempty"`
+    PythonFiles  []PythonFileSummary `json:"pythonFiles,omitempty"`
+    JsFiles      []JsFileSummary     `json:"jsFiles,omitempty"`
+    TsFiles      []TsFileSummary     `json:"tsFiles,omitempty"`
+    HtmlFiles    []HtmlFileSummary   `json:"htmlFiles,omitempty"`
+    CssFiles     []CSSFileSummary    `json:"cssFiles,omitempty"`
+    SqlFiles     []SQLFileSummary    `json:"sqlFiles,omitempty"`
+    MarkdownFiles []MarkdownFileSummary `json:"markdownFiles,omitempty"`
+    ConfigFiles  []ConfigFileSummary `json:"configFiles,omitempty"`
+    Dockerfiles  []DockerfileSummary `json:"dockerfiles,omitempty"`
+    Makefiles    []MakefileSummary   `json:"makefiles,omitempty"`
+    EnvFiles     []EnvFileSummary    `json:"envFiles,omitempty"`
+    UndocumentedEnvVars []string     `json:"undocumentedEnvVars,omitempty"` // Read in code but missing from every env file
+    UnusedEnvKeys       []string     `json:"unusedEnvKeys,omitempty"`       // Documented in an env file but never read in code
+    CrossLangDuplicates [][]string   `json:"crossLangDuplicates,omitempty"` // Likely reimplementations, as "language:file:function"
+    Conventions  map[string]string   `json:"conventions,omitempty"` // Formatting settings from .editorconfig, ESLint and Prettier configs
+    TopSymbols   []RankedSymbol      `json:"topSymbols,omitempty"`  // Most important symbols, best first
+    Counts       map[string]int      `json:"counts,omitempty"`      // Inventory totals, only set with -summary-only
+    Clusters     [][]string          `json:"clusters,omitempty"`    // Files connected by calls and type references
+    PageHandlerMap map[string][]string `json:"pageHandlerMap,omitempty"` // Endpoints each HTML page invokes, as "METHOD target: handler"
+    ThirdPartyDependencies map[string][]string `json:"thirdPartyDependencies,omitempty"` // External packages imported, by language
+    LanguageStats []LanguageStat `json:"languageStats,omitempty"` // Codebase breakdown by language, largest first
+    Diff         *Diff               `json:"diff,omitempty"`        // Structural changes since the -baseline output
+    TypeUsage    map[string]int      `json:"typeUsage,omitempty"`   // References to each declared type from fields, parameters and returns
+    ImportIndex  map[string][]string `json:"importIndex,omitempty"` // Distinct import paths by language, with -collapse-imports
+    Migrations   []Migration         `json:"migrations,omitempty"`  // Migration files in the order they apply
+    ScheduledJobs []ScheduledJob     `json:"scheduledJobs,omitempty"` // Entries from crontab files
+    Modules      []GoModule          `json:"modules,omitempty"`     // go.mod and go.work files
+    CallGraph    []CallEdge          `json:"callGraph,omitempty"`   // Calls resolved to the functions they reach, with -call-graph
+    Unreferenced *Unreferenced       `json:"unreferenced,omitempty"` // Symbols nothing else refers to, with -unreferenced
+    GoPackages   []GoPackage         `json:"goPackages,omitempty"`  // Go files grouped by package, with -go-packages
+    PreviewOmitted     int `json:"-"` // Files left out by -preview, for the caller to report
+    ExcludedEmptyFiles int `json:"-"` // Files dropped by -exclude-empty-files, for the caller to report
+}
+
+// GoPackage gathers the declarations of the Go files making up one package
+type GoPackage struct {
+    Name       string      `json:"name"`
+    Dir        string      `json:"dir"`
+    Files      []string    `json:"files"`
+    Imports    []string    `json:"imports,omitempty"`    // Distinct import paths of the files
+    Structs    []Struct    `json:"structs,omitempty"`    // With their methods from every file of the package
+    Interfaces []Interface `json:"interfaces,omitempty"`
+    Functions  []Function  `json:"functions,omitempty"`  // Functions, and methods on types that aren't structs
+    Constants  []Constant  `json:"constants,omitempty"`
+    Variables  []Variable  `json:"variables,omitempty"`
+}
+
+// Unreferenced lists the symbols nothing else in the analyzed tree refers to, as "file:name"
+type Unreferenced struct {
+    Functions    []string `json:"functions,omitempty"`
+    Classes      []string `json:"classes,omitempty"`
+    CSSSelectors []string `json:"cssSelectors,omitempty"` // Selectors needing a class or ID no HTML element has
+    SQLTables    []string `json:"sqlTables,omitempty"`    // Tables created but never queried, altered or referenced
+}
+
+// CallEdge is a call from one function or method to the definition it resolves to
+type CallEdge struct {
+    Caller CallSymbol `json:"caller"`
+    Callee CallSymbol `json:"callee"`
+}
+
+// CallSymbol locates a function or method taking part in a call
+type CallSymbol struct {
+    ID       string `json:"id,omitempty"`
+    Name     string `json:"name"`     // Qualified name, e.g. "Server.Start"
+    Language string `json:"language"` // "go", "php", "python", "javascript" or "typescript"
+    FilePath string `json:"filePath"`
+    Line     int    `json:"line"`
+}
+
+// Diff lists the functions and methods that changed since a baseline run, as "file:name"
+type Diff struct {
+    Added   []string     `json:"added,omitempty"`
+    Removed []string     `json:"removed,omitempty"`
+    Changed []string     `json:"changed,omitempty"` // Same name and file, different signature or calls
+    Renamed []RenamePair `json:"renamed,omitempty"` // Structurally identical functions that were renamed or moved
+    ComplexityRegressions []ComplexityChange `json:"complexityRegressions,omitempty"` // Functions that grew more complex than -complexity-delta allows
+}
+
+// ComplexityChange records a function whose cyclomatic complexity rose since the baseline
+type ComplexityChange struct {
+    Function string `json:"function"` // "file:name" in the current run
+    Line     int    `json:"line"`
+    Before   int    `json:"before"`
+    After    int    `json:"after"`
+}
+
+// RenamePair records a function that reappeared under a new name or in another file
+type RenamePair struct {
+    From string `json:"from"`
+    To   string `json:"to"`
+    Kind string `json:"kind"` // "rename", "move" or "move+rename"
+}
+
+// LanguageStat summarizes how much of the analyzed code is written in one language
+type LanguageStat struct {
+    Language  string  `json:"language"`
+    Files     int     `json:"files"`
+    Lines     int     `json:"lines"`
+    Functions int     `json:"functions,omitempty"`
+    Percent   float64 `json:"percent"` // Share of all analyzed lines
+}
+
+// PackageGroup represents the files that make up a single package, namespace, or module directory
+type PackageGroup struct {
+    Name      string   `json:"name"`      // Declared package/namespace name, or the directory name
+    Directory string   `json:"directory"`
+    Languages []string `json:"languages"`
+    Files     Summary  `json:"files"`
+}
+
+// PackageGroupedSummary represents the analyzed files reorganized by package
+type PackageGroupedSummary struct {
+    Packages map[string]*PackageGroup `json:"packages"`
+}
+
+// SchemaField maps a Go struct field to its serialized JSON name and database column
+type SchemaField struct {
+    Field     string `json:"field"`
+    JSONName  string `json:"jsonName,omitempty"` // Empty when the field is excluded with json:"-"
+    OmitEmpty bool   `json:"omitEmpty,omitempty"`
+    DBColumn  string `json:"dbColumn,omitempty"`
+    Type      string `json:"type"`
+    EmbeddedFrom string `json:"embeddedFrom,omitempty"` // Embedded struct the field was flattened from
+}
+
+// SchemaStruct is the data contract of a tagged Go struct
+type SchemaStruct struct {
+    Struct   string        `json:"struct"`
+    FilePath string        `json:"filePath"`
+    Fields   []SchemaField `json:"fields"`
+}
+
+// PatternSummary represents a more concise pattern-based summary format
+type PatternSummary struct {
+    Timestamp   string           `json:"timestamp,omitempty"`
+    AnalyzedDir string           `json:"analyzedDir"`
+    Types       []string         `json:"types,omitempty"`    // All types defined across files
+    Functions   []string         `json:"functions,omitempty"` // All function names across files
+    FileMap     map[string][]int `json:"fileMap"`           // Maps symbol IDs (or names for unidentified symbols) to file indices
+    Symbols     map[string]string `json:"symbols,omitempty"` // Maps symbol IDs to qualified names
+    Files       []string         `json:"files"`             // All file paths
+    CSSSelectors []string        `json:"cssSelectors,omitempty"` // All CSS selectors
+    SQLTables   []string         `json:"sqlTables,omitempty"`   // All SQL tables
+    ConfigSections []string      `json:"configSections,omitempty"` // All INI/TOML section names
+    SchemaRelationships []Relationship `json:"schemaRelationships,omitempty"` // Foreign keys across all SQL files
+    ThirdPartyDependencies map[string][]string `json:"thirdPartyDependencies,omitempty"` // External packages imported, by language
+    Deprecated  []string         `json:"deprecated,omitempty"` // Deprecated functions and types, as "name" or "name: message"
+    ImportIndex map[string][]string `json:"importIndex,omitempty"` // Distinct import paths by language, with -collapse-imports
+    ZIndexValues []ZIndexEntry   `json:"zIndexValues,omitempty"` // z-index declarations across all CSS files, lowest first
+    Details     *Summary         `json:"details,omitempty"` // Original full summary, left out with -summary-only
+}
+
+// Pattern2Summary is the symbol-keyed pattern format: one entry per qualified symbol
+// name instead of parallel type, function and file map lists
+type Pattern2Summary struct {
+    Timestamp   string                 `json:"timestamp,omitempty"`
+    AnalyzedDir string                 `json:"analyzedDir"`
+    Files       []string               `json:"files"`   // All file paths, referred to by index
+    Symbols     map[string]*SymbolEntry `json:"symbols"` // Keyed by qualified name, e.g. "Server.Start", ".btn" or "[database]"
+    SchemaRelationships []Relationship `json:"schemaRelationships,omitempty"` // Foreign keys across all SQL files
+    ThirdPartyDependencies map[string][]string `json:"thirdPartyDependencies,omitempty"` // External packages imported, by language
+}
+
+// SymbolEntry describes one symbol in the pattern2 format
+type SymbolEntry struct {
+    Kind      string   `json:"kind"`  // "function", "method", "struct", "interface", "class", "decorator", "element", "selector", "table" or "section"
+    Files     []int    `json:"files"` // Indices into Pattern2Summary.Files of every file defining the symbol
+    Line      int      `json:"line,omitempty"`      // Line of the first definition
+    Signature string   `json:"signature,omitempty"` // Arguments and returns, e.g. "(ctx context.Context, id int) (*User, error)"
+    Calls     []string `json:"calls,omitempty"`
+    CalledBy  []string `json:"calledBy,omitempty"` // Functions the call graph resolves to calling this one
+}
+
+// analysisContext holds the symbol tables shared across files during one run.
+// Analyzers only return per-file summaries; the results are merged in through
+// the locked merge methods so files can be analyzed independently.
+type analysisContext struct {
+    mu            sync.Mutex
+    functions     map[string]Function
+    structs       map[string]Struct
+    classes       map[string]Struct // PHP classes
+    pythonClasses map[string]Struct
+    cssSelectors  map[string]bool
+    sqlTables     map[string]bool
+}
+
+// newAnalysisContext creates an empty analysisContext
+func newAnalysisContext() *analysisContext {
+    return &analysisContext{
+        functions:     make(map[string]Function),
+        structs:       make(map[string]Struct),
+        classes:       make(map[string]Struct),
+        pythonClasses: make(map[string]Struct),
+        cssSelectors:  make(map[string]bool),
+        sqlTables:     make(map[string]bool),
+    }
+}
+
+// mergeGoFile records the functions and structs of a Go file
+func (ctx *analysisContext) mergeGoFile(file GoFileSummary) {
+    ctx.mu.Lock()
+    defer ctx.mu.Unlock()
+    for _, fn := range file.Functions {
+        ctx.functions[fn.Name] = fn
+    }
+    for _, str := range file.Structs {
+        ctx.structs[str.Name] = str
+    }
+}
+
+// mergePhpFile records the functions and classes of a PHP file
+func (ctx *analysisContext) mergePhpFile(file PhpFileSummary) {
+    ctx.mu.Lock()
+    defer ctx.mu.Unlock()
+    for _, fn := range file.Functions {
+        ctx.functions[fn.Name] = fn
+    }
+    for _, cls := range file.Classes {
+        ctx.classes[cls.Name] = cls
+    }
+}
+
+// mergePythonFile records the functions and classes of a Python file
+func (ctx *analysisContext) mergePythonFile(file PythonFileSummary) {
+    ctx.mu.Lock()
+    defer ctx.mu.Unlock()
+    for _, fn := range file.Functions {
+        ctx.functions[fn.Name] = fn
+    }
+    for _, cls := range file.Classes {
+        ctx.pythonClasses[cls.Name] = cls
+    }
+}
+
+// mergeJsFile records the functions of a JavaScript file, so page elements can link to them
+func (ctx *analysisContext) mergeJsFile(file JsFileSummary) {
+    ctx.mu.Lock()
+    defer ctx.mu.Unlock()
+    for _, fn := range file.Functions {
+        ctx.functions[fn.Name] = fn
+    }
+}
+
+// mergeTsFile records the functions of a TypeScript file, so page elements can link to them
+func (ctx *analysisContext) mergeTsFile(file TsFileSummary) {
+    ctx.mu.Lock()
+    defer ctx.mu.Unlock()
+    for _, fn := range file.Functions {
+        ctx.functions[fn.Name] = fn
+    }
+}
+
+// mergeCssFile records the selectors of a CSS file
+func (ctx *analysisContext) mergeCssFile(file CSSFileSummary) {
+    ctx.mu.Lock()
+    defer ctx.mu.Unlock()
+    for _, rule := range file.Rules {
+        ctx.cssSelectors[rule.Selector] = true
+    }
+}
+
+// mergeEmbeddedJS records the functions of a page's inline scripts
+func (ctx *analysisContext) mergeEmbeddedJS(functions []Function) {
+    ctx.mu.Lock()
+    defer ctx.mu.Unlock()
+    for _, fn := range functions {
+        ctx.functions[fn.Name] = fn
+    }
+}
+
+// mergeSqlFile records the tables referenced by a SQL file
+func (ctx *analysisContext) mergeSqlFile(file SQLFileSummary) {
+    ctx.mu.Lock()
+    defer ctx.mu.Unlock()
+    for _, stmt := range file.Statements {
+        for _, table := range stmt.Tables {
+            ctx.sqlTables[table] = true
+        }
+    }
+}
+
+// mergeCachedFile records the symbols of a file summary read back from the cache,
+// as analyzeFile would have while analyzing the file
+func (ctx *analysisContext) mergeCachedFile(file Summary, promoteEmbedded bool) {
+    for _, goFile := range file.GoFiles {
+        ctx.mergeGoFile(goFile)
+    }
+    for _, phpFile := range file.PhpFiles {
+        ctx.mergePhpFile(phpFile)
+    }
+    for _, pyFile := range file.PythonFiles {
+        ctx.mergePythonFile(pyFile)
+    }
+    for _, jsFile := range file.JsFiles {
+        ctx.mergeJsFile(jsFile)
+    }
+    for _, tsFile := range file.TsFiles {
+        ctx.mergeTsFile(tsFile)
+    }
+    for _, cssFile := range file.CssFiles {
+        ctx.mergeCssFile(cssFile)
+    }
+    for _, sqlFile := range file.SqlFiles {
+        ctx.mergeSqlFile(sqlFile)
+    }
+    if promoteEmbedded {
+        for _, htmlFile := range file.HtmlFiles {
+            ctx.mergeEmbeddedJS(htmlFile.EmbeddedJS)
+        }
+    }
+}
+
+// Configuration options
+type Config struct {
+    Directory       string
+    OutputFormat    string // "json", "pattern", "pattern2", "sarif", "dot", "mermaid" or "ndjson"
+    Compact         bool
+    FilterEmpty     bool
+    OnlyRelevant    bool
+    MaxResults      int
+    TargetFiles     []string
+    ExcludePatterns []string
+    IncludePatterns []string
+    OutputFile      string
+    PrintVersion    bool
+    Verbose         bool
+    GroupBy         string // "" or "package"
+    OmitFunctions   []string
+    OmitFunctionsRegex string
+    ShowConfigValues bool
+    MaxFunctions    int
+    MaxTypes        int
+    MaxFilesPerLang int
+    FindDuplicates  bool
+    Preview         int
+    SchemaView      bool
+    IndentSize      int // Width of a tab in Python files, 0 to detect
+    ExcludeEmptyFiles bool
+    SmartExcludes   bool
+    Top             int
+    NativePaths     bool
+    SummaryOnly     bool
+    Cluster         bool
+    ExplainImports  bool
+    Deterministic   bool
+    PageMap         bool
+    GroupImportsByOrigin bool
+    Bundle          bool
+    LanguageStats   bool
+    FilesFrom       string // "-" for stdin, or a file listing the paths to analyze
+    FileList        []string
+    Baseline        string // Earlier JSON or pattern output to diff against
+    Trace           string // Entry HTML/PHP file whose include chain is followed
+    ProfileTypes    bool
+    ExcludeSymbolsRegex string
+    CollapseImports bool
+    BudgetReport    bool
+    Query           string // JSONPath-like expression selecting what to print, e.g. goFiles[*].functions[*].name
+    Parser          string // "regex", or "treesitter" when built with -tags treesitter
+    ComplexityDelta int    // Largest allowed complexity increase against -baseline, -1 to not check
+    PromoteEmbedded bool
+    LSP             bool // Serve the Language Server Protocol over stdio instead of writing a summary
+    Jobs            int  // Files analyzed in parallel, 0 for one per CPU
+    CacheDir        string // Directory keeping per-file results between runs, keyed by path and content hash
+    Watch           bool   // Keep running and produce a new summary whenever files change
+    Serve           bool   // Answer HTTP queries about the summary instead of writing it
+    Listen          string // Address the HTTP server listens on, e.g. ":8080"
+    OutputDB        string // SQLite file to write the summary to, needs a build with -tags sqlite
+    CallGraph       bool   // Resolve calls across files and list them as the CallGraph section
+    Unreferenced    bool   // List the functions, classes, CSS selectors and SQL tables nothing refers to
+    GoPackages      bool   // Group the Go files by package as the GoPackages section
+    GoTags          []string // Build tags, GOOS and GOARCH to check Go build constraints against
+    SkipBuildExcluded bool   // Leave out Go files whose build constraint does not hold for GoTags
+    ExcludeTests    bool   // Skip test files, such as foo_test.go, test_foo.py and foo.spec.ts, while walking
+}
+
+// treeSitterBackend re-parses PHP, Python, CSS and SQL files with tree-sitter grammars
+// and corrects the structure the regex analyzers extracted
+type treeSitterBackend interface {
+    refinePhp(summary *PhpFileSummary, content []byte) error
+    refinePython(summary *PythonFileSummary, content []byte) error
+    refineCss(summary *CSSFileSummary, content []byte) error
+    refineSql(summary *SQLFileSummary, content []byte) error
+}
+
+// treeSitter is registered by treesitter.go and stays nil in builds without the treesitter tag
+var treeSitter treeSitterBackend
+
+// databaseWriter is registered by sqlite.go and stays nil in builds without the sqlite tag
+var databaseWriter func(summary Summary, path string) error
+
+// WriteDatabase stores the files, symbols, calls, CSS selectors and SQL tables of a
+// summary as normalized SQLite tables, replacing the file at path
+func WriteDatabase(summary Summary, path string) error {
+    if databaseWriter == nil {
+        return fmt.Errorf("-output-db needs distiller built with -tags sqlite")
+    }
+    return databaseWriter(summary, path)
+}
+
+// smartExcludeDirs are dependency, VCS and build output directories skipped by default
+var smartExcludeDirs = []string{
+    ".git", ".hg", ".svn",
+    "node_modules", "bower_components", "vendor",
+    "venv", ".venv", "__pycache__", ".tox", ".mypy_cache", ".pytest_cache",
+    "dist", "build", "target", ".next", ".cache",
+}
+
+// Version information
+const (
+    VERSION = "3.0.2"
+)
+
+// Analyzer runs the analysis a Config describes. NewAnalyzer validates the configuration
+// and loads the file list and baseline once, so the same Analyzer can be run repeatedly.
+// Every run builds its own symbol tables, so runs don't share state.
+type Analyzer struct {
+    config       Config
+    omitRegex    *regexp.Regexp
+    excludeRegex *regexp.Regexp
+    baseline     Summary
+    baselineDir  string
+}
+
+// NewAnalyzer validates config and prepares an Analyzer for it
+func NewAnalyzer(config Config) (*Analyzer, error) {
+    analyzer := &Analyzer{config: config}
+
+    // Read the file list up front; the directory only anchors relative paths and IDs
+    if config.FilesFrom != "" && config.FileList == nil {
+        fileList, err := readFileList(config.FilesFrom)
+        if err != nil {
+            return nil, fmt.Errorf("reading file list: %v", err)
+        }
+        analyzer.config.FileList = fileList
+    }
+    if config.FilesFrom != "" && config.Directory == "" {
+        analyzer.config.Directory = "."
+    }
+
+    if analyzer.config.Directory == "" {
+        return nil, fmt.Errorf("directory is required")
+    }
+    if config.Watch && config.FilesFrom != "" {
+        return nil, fmt.Errorf("-watch needs a -dir to watch and cannot be combined with -files-from")
+    }
+    if config.OutputDB != "" && databaseWriter == nil {
+        return nil, fmt.Errorf("-output-db needs distiller built with -tags sqlite")
+    }
+    if config.OutputFormat == "ndjson" && (config.Bundle || config.Trace != "") {
+        return nil, fmt.Errorf("-format=ndjson lists files one by one and cannot be combined with -bundle or -trace")
+    }
+    if config.Bundle && len(config.TargetFiles) == 0 {
+        return nil, fmt.Errorf("-bundle needs the target files given with -files")
+    }
+    if config.ComplexityDelta >= 0 && config.Baseline == "" {
+        return nil, fmt.Errorf("-complexity-delta needs a -baseline to compare against")
+    }
+    if config.Baseline != "" {
+        var err error
+        analyzer.baseline, analyzer.baselineDir, err = readBaseline(config.Baseline)
+        if err != nil {
+            return nil, fmt.Errorf("reading baseline: %v", err)
+        }
+        if analyzer.baselineDir == "" {
+            analyzer.baselineDir = analyzer.config.Directory
+        }
+    }
+    if config.CacheDir != "" {
+        if strings.HasPrefix(config.CacheDir, "~/") {
+            if home, err := os.UserHomeDir(); err == nil {
+                analyzer.config.CacheDir = filepath.Join(home, config.CacheDir[2:])
+            }
+        }
+        if err := os.MkdirAll(analyzer.config.CacheDir, 0755); err != nil {
+            return nil, fmt.Errorf("creating cache directory: %v", err)
+        }
+    }
+    if config.GroupBy != "" && config.GroupBy != "package" {
+        return nil, fmt.Errorf("unsupported -group-by value: %s", config.GroupBy)
+    }
+    switch config.Parser {
+    case "", "regex":
+    case "treesitter":
+        if treeSitter == nil {
+            return nil, fmt.Errorf("-parser=treesitter needs distiller built with -tags treesitter")
+        }
+    default:
+        return nil, fmt.Errorf("unsupported -parser value: %s", config.Parser)
+    }
+    if config.OmitFunctionsRegex != "" {
+        var err error
+        analyzer.omitRegex, err = regexp.Compile(config.OmitFunctionsRegex)
+        if err != nil {
+            return nil, fmt.Errorf("invalid -omit-functions-regex: %v", err)
+        }
+    }
+    if config.ExcludeSymbolsRegex != "" {
+        var err error
+        analyzer.excludeRegex, err = regexp.Compile(config.ExcludeSymbolsRegex)
+        if err != nil {
+            return nil, fmt.Errorf("invalid -exclude-symbols-regex: %v", err)
+        }
+    }
+
+    // Add the common vendor and generated directories to the exclude patterns
+    if config.SmartExcludes {
+        analyzer.config.ExcludePatterns = append([]string(nil), config.ExcludePatterns...)
+        for _, dir := range smartExcludeDirs {
+            analyzer.config.ExcludePatterns = appendIfNotExists(analyzer.config.ExcludePatterns, dir)
+        }
+    }
+    return analyzer, nil
+}
+
+// Analyze validates config and analyzes the codebase it describes
+func Analyze(ctx context.Context, config Config) (Summary, error) {
+    analyzer, err := NewAnalyzer(config)
+    if err != nil {
+        return Summary{}, err
+    }
+    return analyzer.Analyze(ctx)
+}
+
+// Analyze analyzes the codebase and applies the configured post-processing: import
+// classification, baseline diff, omissions, caps, rankings and output reductions.
+// It stops early with ctx's error when ctx is cancelled.
+func (analyzer *Analyzer) Analyze(ctx context.Context) (Summary, error) {
+    config := analyzer.config
+
+    // Analyze the directory; a bundle or trace needs the whole tree to see what the targets reference
+    analysisConfig := config
+    if config.Bundle || config.Trace != "" {
+        analysisConfig.TargetFiles = nil
+    }
+    summary, err := analyzeDirRecursive(ctx, analysisConfig)
+    if err != nil {
+        return Summary{}, err
+    }
+
+    // Import usage is always gathered but only reported when asked for
+    if !config.ExplainImports {
+        clearImportUsage(&summary)
+    }
+
+    // Tell external dependencies apart from internal coupling
+    if config.GroupImportsByOrigin {
+        summary.ThirdPartyDependencies = classifyImportOrigins(&summary, config.Directory)
+    }
+
+    // Break the codebase down by language before anything is trimmed
+    if config.LanguageStats {
+        summary.LanguageStats = computeLanguageStats(summary)
+    }
+
+    // Find the core domain types by how often they are referenced
+    if config.ProfileTypes {
+        summary.TypeUsage = profileTypeUsage(summary)
+    }
+
+    // Look for dead code while every reference is still in the summary
+    if config.Unreferenced {
+        summary.Unreferenced = findUnreferenced(summary)
+    }
+
+    // Compare against the baseline before anything is trimmed
+    if config.Baseline != "" {
+        summary.Diff = diffSummaries(analyzer.baseline, analyzer.baselineDir, summary, config.Directory)
+        if config.ComplexityDelta >= 0 {
+            summary.Diff.ComplexityRegressions = complexityRegressions(summary.Diff, analyzer.baseline, analyzer.baselineDir, summary, config.Directory, config.ComplexityDelta)
+        }
+    }
+
+    // Drop noise functions if requested
+    if len(config.OmitFunctions) > 0 || analyzer.omitRegex != nil {
+        omitFunctions(&summary, config.OmitFunctions, analyzer.omitRegex)
+    }
+
+    // Drop every kind of symbol matching the exclusion pattern
+    if analyzer.excludeRegex != nil {
+        excludeSymbols(&summary, analyzer.excludeRegex)
+    }
+
+    // Trim to the most important symbols if caps were given
+    if config.MaxFunctions > 0 || config.MaxTypes > 0 || config.MaxFilesPerLang > 0 {
+        applyImportanceCaps(&summary, config)
+    }
+
+    // Look for logic duplicated across languages
+    if config.FindDuplicates {
+        summary.CrossLangDuplicates = findCrossLanguageDuplicates(summary)
+    }
+
+    // Rank the symbols an AI should look at first
+    if config.Top > 0 {
+        summary.TopSymbols = rankTopSymbols(&summary, config.Top)
+    }
+
+    // Reveal the de-facto modules
+    if config.Cluster {
+        summary.Clusters = clusterFiles(summary)
+    }
+
+    // Trace calls across files to the definitions they reach
+    if config.CallGraph {
+        summary.CallGraph = resolveCallGraph(summary)
+    }
+
+    // Show Go code the way Go organizes it, by package
+    if config.GoPackages {
+        summary.GoPackages = groupGoPackages(summary)
+    }
+
+    // Map each page to the backend code it calls
+    if config.PageMap {
+        summary.PageHandlerMap = buildPageMap(summary)
+    }
+
+    // Cut the output down to a quick preview if requested
+    if config.Preview > 0 {
+        var omitted int
+        summary, omitted = previewSummary(summary, config.Preview)
+        summary.PreviewOmitted = omitted
+    }
+
+    // Filter empty slices if requested
+    if config.FilterEmpty {
+        summary = filterEmptySlices(summary)
+    }
+
+    // Drop files that yielded nothing at all
+    if config.ExcludeEmptyFiles {
+        summary.ExcludedEmptyFiles = excludeEmptyFiles(&summary)
+    }
+
+    // Reduce the output to a bare inventory if requested
+    if config.SummaryOnly {
+        summary = summaryOnly(summary)
+    }
+
+    // Deduplicate imports last; bundles, traces and findings still need them per file
+    if config.CollapseImports && !config.Bundle && config.Trace == "" && config.OutputFormat != "sarif" {
+        collapseImports(&summary)
+    }
+
+    return summary, nil
+}
+
+// Stream analyzes the files like Analyze but hands each one to emit as soon as it and the
+// files walked before it are done, as a summary holding just that file. Only the steps
+// that look at one file at a time apply: cross-file results such as diffs, clusters,
+// linked functions and top symbols are left out, so memory stays bounded on huge trees.
+func (analyzer *Analyzer) Stream(ctx context.Context, emit func(file Summary) error) error {
+    config := analyzer.config
+    return analyzeFiles(ctx, config, nil, func(file Summary) error {
+        if !config.NativePaths {
+            normalizePaths(&file)
+        }
+        assignSymbolIDs(&file, config.Directory)
+        if !config.ExplainImports {
+            clearImportUsage(&file)
+        }
+        if len(config.OmitFunctions) > 0 || analyzer.omitRegex != nil {
+            omitFunctions(&file, config.OmitFunctions, analyzer.omitRegex)
+        }
+        if analyzer.excludeRegex != nil {
+            excludeSymbols(&file, analyzer.excludeRegex)
+        }
+        if config.FilterEmpty {
+            file = filterEmptySlices(file)
+        }
+        if config.ExcludeEmptyFiles {
+            excludeEmptyFiles(&file)
+        }
+        if len(summaryFilePaths(file)) == 0 && len(file.Migrations) == 0 && len(file.ScheduledJobs) == 0 && len(file.Conventions) == 0 {
+            return nil
+        }
+        return emit(file)
+    })
+}
+
+// Render formats a summary as the output config asks for: a bundle, a trace, SARIF
+// findings, the pattern formats, the schema view, a package grouping or plain JSON
+func Render(summary Summary, config Config) ([]byte, error) {
+    var output interface{}
+    switch {
+    case config.Bundle:
+        // Combine the targets' source with the structure around them
+        output = buildBundle(summary, config)
+    case config.Trace != "":
+        // Only the files that make up the page
+        trace, err := buildTrace(summary, config)
+        if err != nil {
+            return nil, err
+        }
+        output = trace
+    case config.OutputFormat == "sarif":
+        // Findings only, for code scanning in CI
+        return renderSARIF(summary, config.Directory), nil
+    case config.OutputFormat == "dot":
+        // The call graph, for Graphviz
+        return renderDOT(summary), nil
+    case config.OutputFormat == "mermaid":
+        // Class and call diagrams that render inline in Markdown
+        return renderMermaid(summary), nil
+    case config.OutputFormat == "ndjson":
+        // One line per file, as Stream produces them
+        return renderNDJSON(summary)
+    case config.OutputFormat == "pattern":
+        // Convert to pattern format for more efficient AI consumption
+        output = convertToPatternFormat(summary, config)
+    case config.OutputFormat == "pattern2":
+        // One entry per symbol, with its files and call references
+        output = convertToPattern2Format(summary, config)
+    case config.SchemaView:
+        // Reduce Go structs to their serialization contracts
+        output = buildSchemaView(summary)
+    case config.GroupBy == "package":
+        // Reorganize the files by package before output
+        output = groupByPackage(summary)
+    default:
+        output = summary
+    }
+
+    if config.Compact {
+        return json.Marshal(output)
+    }
+    return json.MarshalIndent(output, "", "  ")
+}
+
+// analyzeDirRecursive analyzes all relevant files in a directory and its subdirectories,
+// stopping with ctx's error when ctx is cancelled
+func analyzeDirRecursive(ctx context.Context, config Config) (Summary, error) {
+    var summary Summary
+    tables := newAnalysisContext()
+
+    // First pass: collect all functions, structs, classes, etc.
+    err := analyzeFiles(ctx, config, tables, func(file Summary) error {
+        mergeFileSummary(&summary, file)
+        return nil
+    })
+    if err != nil {
+        return Summary{}, err
+    }
+
+    // Use forward slashes everywhere so output is comparable across platforms
+    if !config.NativePaths {
+        normalizePaths(&summary)
+    }
+
+    // Assign stable identifiers to functions and types
+    assignSymbolIDs(&summary, config.Directory)
+
+    // Put migrations in the order they are applied
+    sortMigrations(summary.Migrations)
+
+    // Flag Go functions that drop their context on the way to a callee
+    flagContextNotPropagated(&summary)
+
+    // Link tests to the functions they exercise
+    annotateTests(&summary)
+
+    // Point type parameters at the local interfaces constraining them
+    linkTypeParamConstraints(&summary)
+
+    // Connect methods to the base class implementations they override
+    linkMethodOverrides(&summary)
+
+    // Compare the documented configuration with what the code reads
+    if len(summary.EnvFiles) > 0 {
+        summary.UndocumentedEnvVars, summary.UnusedEnvKeys = crossReferenceEnvKeys(summary)
+    }
+
+    // Second pass: establish cross-file relationships and references
+    linkCallers(&summary)
+    propagateSideEffects(&summary)
+    for i := range summary.HtmlFiles {
+    for j, element := range summary.HtmlFiles[i].Elements {
+        linkedFunctions := findLinkedFunctions(element, tables.functions, tables.classes)
+        summary.HtmlFiles[i].Elements[j].LinkedFunctions = linkedFunctions
+    }
+    }
+
+    // Limit results if needed
+    if config.MaxResults > 0 {
+    if len(summary.GoFiles) > config.MaxResults {
+        summary.GoFiles = summary.GoFiles[:config.MaxResults]
+    }
+    if len(summary.PhpFiles) > config.MaxResults {
+        summary.PhpFiles = summary.PhpFiles[:config.MaxResults]
+    }
+        if len(summary.PythonFiles) > config.MaxResults {
+            summary.PythonFiles = summary.PythonFiles[:config.MaxResults]
+        }
+        if len(summary.JsFiles) > config.MaxResults {
+            summary.JsFiles = summary.JsFiles[:config.MaxResults]
+        }
+        if len(summary.TsFiles) > config.MaxResults {
+            summary.TsFiles = summary.TsFiles[:config.MaxResults]
+        }
+    if len(summary.HtmlFiles) > config.MaxResults {
+        summary.HtmlFiles = summary.HtmlFiles[:config.MaxResults]
+    }
+    if len(summary.CssFiles) > config.MaxResults {
+        summary.CssFiles = summary.CssFiles[:config.MaxResults]
+    }
+    if len(summary.SqlFiles) > config.MaxResults {
+        summary.SqlFiles = summary.SqlFiles[:config.MaxResults]
+    }
+        if len(summary.MarkdownFiles) > config.MaxResults {
+            summary.MarkdownFiles = summary.MarkdownFiles[:config.MaxResults]
+        }
+        if len(summary.ConfigFiles) > config.MaxResults {
+            summary.ConfigFiles = summary.ConfigFiles[:config.MaxResults]
+        }
+        if len(summary.Dockerfiles) > config.MaxResults {
+            summary.Dockerfiles = summary.Dockerfiles[:config.MaxResults]
+        }
+        if len(summary.Makefiles) > config.MaxResults {
+            summary.Makefiles = summary.Makefiles[:config.MaxResults]
+        }
+        if len(summary.EnvFiles) > config.MaxResults {
+            summary.EnvFiles = summary.EnvFiles[:config.MaxResults]
+        }
+    }
+
+    return summary, nil
+}
+
+// analyzeFiles analyzes every file walkSourceFiles selects and hands the per-file summaries
+// to collect in walk order. One walker finds the files and a pool of -jobs workers analyzes
+// them, so the order doesn't depend on which worker finishes first. The symbol tables are
+// shared across files, or made afresh for each file when tables is nil.
+func analyzeFiles(ctx context.Context, config Config, tables *analysisContext, collect func(file Summary) error) error {
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+    jobs := config.Jobs
+    if jobs <= 0 {
+        jobs = runtime.NumCPU()
+    }
+    type fileJob struct {
+        index int
+        path  string
+    }
+    type fileResult struct {
+        index   int
+        summary Summary
+    }
+    queue := make(chan fileJob, jobs)
+    results := make(chan fileResult, jobs)
+    var walkErr error
+    go func() {
+        defer close(queue)
+        index := 0
+        walkErr = walkSourceFiles(ctx, config, func(path string) error {
+            select {
+            case queue <- fileJob{index, path}:
+                index++
+                return nil
+            case <-ctx.Done():
+                return ctx.Err()
+            }
+        })
+    }()
+
+    var workers sync.WaitGroup
+    for i := 0; i < jobs; i++ {
+        workers.Add(1)
+        go func() {
+            defer workers.Done()
+            for job := range queue {
+                fileTables := tables
+                if fileTables == nil {
+                    fileTables = newAnalysisContext()
+                }
+                var fileSummary Summary
+                if config.CacheDir != "" {
+                    analyzeFileCached(job.path, config, &fileSummary, fileTables)
+                } else {
+                    analyzeFile(job.path, config, &fileSummary, fileTables)
+                }
+                results <- fileResult{job.index, fileSummary}
+            }
+        }()
+    }
+    go func() {
+        workers.Wait()
+        close(results)
+    }()
+
+    // Keep draining the results after collect fails, so the workers and the walker can finish
+    var collectErr error
+    pending := make(map[int]Summary)
+    next := 0
+    for result := range results {
+        pending[result.index] = result.summary
+        for fileSummary, ok := pending[next]; ok; fileSummary, ok = pending[next] {
+            if collectErr == nil {
+                collectErr = collect(fileSummary)
+                if collectErr != nil {
+                    cancel()
+                }
+            }
+            delete(pending, next)
+            next++
+        }
+    }
+    if collectErr != nil {
+        return collectErr
+    }
+    return walkErr
+}
+
+// walkSourceFiles calls visit with every file the config selects: the -files-from list,
+// or the files under the directory that pass the target, include and exclude filters.
+// It stops at the first error visit returns, or when ctx is cancelled.
+func walkSourceFiles(ctx context.Context, config Config, visit func(path string) error) error {
+    // Prepare file filters
+    targetFilesMap := make(map[string]bool)
+    for _, f := range config.TargetFiles {
+    targetFilesMap[f] = true
+    }
+
+    if config.FilesFrom != "" {
+        // Analyze exactly the listed files, without walking the directory
+        for _, path := range config.FileList {
+            info, err := os.Stat(path)
+            if err != nil || info.IsDir() {
+                if config.Verbose {
+                    fmt.Printf("Skipping listed path %s: not a readable file\n", path)
+                }
+                continue
+            }
+            if err := visit(path); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+    return filepath.Walk(config.Directory, func(path string, info os.FileInfo, err error) error {
+    if cancelled := ctx.Err(); cancelled != nil {
+        return cancelled
+    }
+    if err != nil {
+        if config.Verbose {
+	fmt.Printf("Error accessing path %s: %v\n", path, err)
+        }
+        return nil
+    }
+
+    if info.IsDir() {
+        // Check if directory should be excluded
+        for _, pattern := range config.ExcludePatterns {
+	if matched, _ := filepath.Match(pattern, info.Name()); matched {
+	    if config.Verbose {
+	    fmt.Printf("Skipping directory: %s (matches exclude pattern: %s)\n", info.Name(), pattern)
+	    }
+	    return filepath.SkipDir
+	}
+        }
+        return nil
+    }
+
+    // Check if we should process this file
+    shouldProcess := false
+    
+    // Check if it's one of the target files (if specified)
+    if len(targetFilesMap) > 0 {
+        _, isTarget := targetFilesMap[info.Name()]
+        if isTarget {
+	shouldProcess = true
+        }
+    } else {
+        shouldProcess = true
+    }
+    
+    // Apply include/exclude patterns
+    for _, pattern := range config.ExcludePatterns {
+        if matched, _ := filepath.Match(pattern, info.Name()); matched {
+	if config.Verbose {
+	    fmt.Printf("Skipping file: %s (matches exclude pattern: %s)\n", info.Name(), pattern)
+	}
+	shouldProcess = false
+	break
+        }
+    }
+    
+    if len(config.IncludePatterns) > 0 {
+        included := false
+        for _, pattern := range config.IncludePatterns {
+	if matched, _ := filepath.Match(pattern, info.Name()); matched {
+	    included = true
+	    break
+	}
+        }
+        shouldProcess = shouldProcess && included
+    }
+
+    if shouldProcess && config.ExcludeTests && isTestFile(info.Name()) {
+        if config.Verbose {
+            fmt.Printf("Skipping test file: %s\n", info.Name())
+        }
+        shouldProcess = false
+    }
+
+    if !shouldProcess {
+        return nil
+    }
+
+    return visit(path)
+    })
+}
+
+// analyzeFileCached analyzes a file like analyzeFile, reusing the result cached in
+// config.CacheDir for the same path and content, and caching it otherwise
+func analyzeFileCached(path string, config Config, summary *Summary, ctx *analysisContext) {
+    content, err := ioutil.ReadFile(path)
+    if err != nil {
+        analyzeFile(path, config, summary, ctx)
+        return
+    }
+    cachePath := filepath.Join(config.CacheDir, fileCacheKey(path, content, config)+".json")
+    if data, err := ioutil.ReadFile(cachePath); err == nil {
+        var cached Summary
+        if err := json.Unmarshal(data, &cached); err == nil {
+            if config.Verbose {
+                fmt.Printf("Using cached analysis: %s\n", path)
+            }
+            ctx.mergeCachedFile(cached, config.PromoteEmbedded)
+            mergeFileSummary(summary, cached)
+            return
+        }
+    }
+
+    var fileSummary Summary
+    analyzeFile(path, config, &fileSummary, ctx)
+    mergeFileSummary(summary, fileSummary)
+
+    // Write through a temporary file so concurrent runs never read a partial entry
+    data, err := json.Marshal(fileSummary)
+    if err != nil {
+        return
+    }
+    tmp, err := ioutil.TempFile(config.CacheDir, "entry-*.tmp")
+    if err != nil {
+        return
+    }
+    _, err = tmp.Write(data)
+    if closeErr := tmp.Close(); err == nil {
+        err = closeErr
+    }
+    if err == nil {
+        err = os.Rename(tmp.Name(), cachePath)
+    }
+    if err != nil {
+        os.Remove(tmp.Name())
+    }
+}
+
+// fileCacheKey hashes a file's path and content together with the version and the
+// settings that change what analyzeFile extracts
+func fileCacheKey(path string, content []byte, config Config) string {
+    hash := sha1.New()
+    fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%s\x00%d\x00%t\x00%t\x00%s\x00%t\x00", VERSION, config.Directory, path,
+        config.Parser, config.IndentSize, config.ShowConfigValues, config.PromoteEmbedded,
+        strings.Join(config.GoTags, ","), config.SkipBuildExcluded)
+    hash.Write(content)
+    return hex.EncodeToString(hash.Sum(nil))
+}
+
+// mergeFileSummary appends the files, migrations, jobs and Go modules found in one file's analysis
+// to summary. Conventions already recorded win over those of later files.
+func mergeFileSummary(summary *Summary, file Summary) {
+    for key, value := range file.Conventions {
+        if summary.Conventions == nil {
+            summary.Conventions = make(map[string]string)
+        }
+        if _, exists := summary.Conventions[key]; !exists {
+            summary.Conventions[key] = value
+        }
+    }
+    summary.Migrations = append(summary.Migrations, file.Migrations...)
+    summary.ScheduledJobs = append(summary.ScheduledJobs, file.ScheduledJobs...)
+    summary.Modules = append(summary.Modules, file.Modules...)
+    summary.GoFiles = append(summary.GoFiles, file.GoFiles...)
+    summary.PhpFiles = append(summary.PhpFiles, file.PhpFiles...)
+    summary.PythonFiles = append(summary.PythonFiles, file.PythonFiles...)
+    summary.JsFiles = append(summary.JsFiles, file.JsFiles...)
+    summary.TsFiles = append(summary.TsFiles, file.TsFiles...)
+    summary.HtmlFiles = append(summary.HtmlFiles, file.HtmlFiles...)
+    summary.CssFiles = append(summary.CssFiles, file.CssFiles...)
+    summary.SqlFiles = append(summary.SqlFiles, file.SqlFiles...)
+    summary.MarkdownFiles = append(summary.MarkdownFiles, file.MarkdownFiles...)
+    summary.ConfigFiles = append(summary.ConfigFiles, file.ConfigFiles...)
+    summary.Dockerfiles = append(summary.Dockerfiles, file.Dockerfiles...)
+    summary.Makefiles = append(summary.Makefiles, file.Makefiles...)
+    summary.EnvFiles = append(summary.EnvFiles, file.EnvFiles...)
+}
+
+// refineWithTreeSitter hands a file's source to a tree-sitter refinement, keeping the
+// regex result when the file cannot be read or parsed
+func refineWithTreeSitter(path string, refine func(content []byte) error) {
+    content, err := ioutil.ReadFile(path)
+    if err == nil {
+        err = refine(content)
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Warning: tree-sitter failed on %s, keeping the regex result: %v\n", path, err)
+    }
+}
+
+// analyzeFile dispatches a file to the analyzer for its type and merges the
+// result into the summary and the shared symbol tables
+func analyzeFile(path string, config Config, summary *Summary, ctx *analysisContext) {
+    name := filepath.Base(path)
+    relPath, err := filepath.Rel(config.Directory, path)
+    if err != nil {
+        relPath = path
+    }
+
+    // Process different file types
+    ext := strings.ToLower(filepath.Ext(path))
+    
+    // Dockerfiles, Makefiles and env files are recognized by name rather than extension
+    if isDockerfile(name) {
+        ext = ".dockerfile"
+    } else if isMakefile(name) {
+        ext = ".makefile"
+    } else if isEnvFile(name) {
+        ext = ".env"
+    }
+
+    // Formatting conventions are merged into a single map, the first file seen winning
+    if source := conventionSource(name); source != "" {
+        if config.Verbose {
+            fmt.Printf("Reading %s conventions: %s\n", source, relPath)
+        }
+        if summary.Conventions == nil {
+            summary.Conventions = make(map[string]string)
+        }
+        for key, value := range extractConventions(path, source) {
+            if _, exists := summary.Conventions[key]; !exists {
+                summary.Conventions[key] = value
+            }
+        }
+        return
+    }
+
+    // Migrations are recognized by directory and file name, whatever their language
+    if migration, ok := analyzeMigration(path, relPath); ok {
+        if config.Verbose {
+            fmt.Printf("Reading migration: %s\n", relPath)
+        }
+        summary.Migrations = append(summary.Migrations, migration)
+    }
+
+    // Crontab files have no language of their own, so their entries are listed once for the codebase
+    if isCrontab(relPath) {
+        if config.Verbose {
+            fmt.Printf("Reading crontab: %s\n", relPath)
+        }
+        summary.ScheduledJobs = append(summary.ScheduledJobs, analyzeCrontab(path, relPath)...)
+        return
+    }
+
+    // Go module files are listed for the codebase too, telling first-party imports apart
+    if name == "go.mod" || name == "go.work" {
+        if config.Verbose {
+            fmt.Printf("Reading Go module: %s\n", relPath)
+        }
+        summary.Modules = append(summary.Modules, analyzeGoModFile(path))
+        return
+    }
+    
+    switch ext {
+    case ".go":
+        if config.Verbose {
+	fmt.Printf("Analyzing Go file: %s\n", relPath)
+        }
+        goFile := analyzeGoFile(path)
+        if len(config.GoTags) > 0 && goFile.BuildConstraint != "" && !goBuildSatisfied(goFile.BuildConstraint, config.GoTags) {
+            if config.SkipBuildExcluded {
+                if config.Verbose {
+                    fmt.Printf("Skipping Go file: %s (excluded by //go:build %s)\n", relPath, goFile.BuildConstraint)
+                }
+                return
+            }
+            goFile.BuildExcluded = true
+        }
+        summary.GoFiles = append(summary.GoFiles, goFile)
+
+        // Store functions and structs for later reference
+        ctx.mergeGoFile(goFile)
+        
+    case ".php":
+        if config.Verbose {
+	fmt.Printf("Analyzing PHP file: %s\n", relPath)
+        }
+        phpFile := analyzePhpFile(path)
+        if config.Parser == "treesitter" {
+            refineWithTreeSitter(path, func(content []byte) error { return treeSitter.refinePhp(&phpFile, content) })
+        }
+        summary.PhpFiles = append(summary.PhpFiles, phpFile)
+        
+        // Store functions and classes for later reference
+        ctx.mergePhpFile(phpFile)
+
+    case ".py":
+        if config.Verbose {
+            fmt.Printf("Analyzing Python file: %s\n", relPath)
+        }
+        pyFile := analyzePythonFile(path, config.IndentSize)
+        if config.Parser == "treesitter" {
+            refineWithTreeSitter(path, func(content []byte) error { return treeSitter.refinePython(&pyFile, content) })
+        }
+        summary.PythonFiles = append(summary.PythonFiles, pyFile)
+        
+        // Store functions and classes for later reference
+        ctx.mergePythonFile(pyFile)
+
+    case ".js", ".mjs", ".cjs", ".jsx":
+        if config.Verbose {
+            fmt.Printf("Analyzing JavaScript file: %s\n", relPath)
+        }
+        jsFile := analyzeJsFile(path)
+        summary.JsFiles = append(summary.JsFiles, jsFile)
+        ctx.mergeJsFile(jsFile)
+
+    case ".ts", ".tsx", ".mts", ".cts":
+        if config.Verbose {
+            fmt.Printf("Analyzing TypeScript file: %s\n", relPath)
+        }
+        tsFile := analyzeTsFile(path)
+        summary.TsFiles = append(summary.TsFiles, tsFile)
+        ctx.mergeTsFile(tsFile)
+        
+    case ".html", ".htm":
+        if config.Verbose {
+	fmt.Printf("Analyzing HTML file: %s\n", relPath)
+        }
+        htmlFile := analyzeHtmlFile(path)
+
+        // Lift inline code out of the page so it is analyzed with the other files
+        if config.PromoteEmbedded {
+            ctx.mergeEmbeddedJS(htmlFile.EmbeddedJS)
+            if len(htmlFile.EmbeddedCSS) > 0 {
+                styles := promoteEmbeddedCss(htmlFile)
+                summary.CssFiles = append(summary.CssFiles, styles)
+                ctx.mergeCssFile(styles)
+                htmlFile.EmbeddedCSS = nil
+            }
+        }
+        summary.HtmlFiles = append(summary.HtmlFiles, htmlFile)
+        
+    case ".css":
+        if config.Verbose {
+	fmt.Printf("Analyzing CSS file: %s\n", relPath)
+        }
+        cssFile := analyzeCssFile(path)
+        if config.Parser == "treesitter" {
+            refineWithTreeSitter(path, func(content []byte) error { return treeSitter.refineCss(&cssFile, content) })
+        }
+        summary.CssFiles = append(summary.CssFiles, cssFile)
+        
+        // Store CSS selectors for later reference
+        ctx.mergeCssFile(cssFile)
+        
+    case ".sql":
+        if config.Verbose {
+	fmt.Printf("Analyzing SQL file: %s\n", relPath)
+        }
+        sqlFile := analyzeSqlFile(path)
+        if config.Parser == "treesitter" {
+            refineWithTreeSitter(path, func(content []byte) error { return treeSitter.refineSql(&sqlFile, content) })
+        }
+        summary.SqlFiles = append(summary.SqlFiles, sqlFile)
+        
+        // Store SQL tables for later reference
+        ctx.mergeSqlFile(sqlFile)
+
+    case ".md", ".markdown":
+        if config.Verbose {
+            fmt.Printf("Analyzing Markdown file: %s\n", relPath)
+        }
+        mdFile := analyzeMarkdownFile(path)
+        summary.MarkdownFiles = append(summary.MarkdownFiles, mdFile)
+
+    case ".ini", ".cfg", ".toml":
+        if config.Verbose {
+            fmt.Printf("Analyzing config file: %s\n", relPath)
+        }
+        configFile := analyzeConfigFile(path, config.ShowConfigValues)
+        summary.ConfigFiles = append(summary.ConfigFiles, configFile)
+
+    case ".dockerfile":
+        if config.Verbose {
+            fmt.Printf("Analyzing Dockerfile: %s\n", relPath)
+        }
+        dockerfile := analyzeDockerfile(path)
+        summary.Dockerfiles = append(summary.Dockerfiles, dockerfile)
+
+    case ".makefile":
+        if config.Verbose {
+            fmt.Printf("Analyzing Makefile: %s\n", relPath)
+        }
+        makefile := analyzeMakefile(path)
+        summary.Makefiles = append(summary.Makefiles, makefile)
+
+    case ".env":
+        if config.Verbose {
+            fmt.Printf("Analyzing env file: %s\n", relPath)
+        }
+        envFile := analyzeEnvFile(path)
+        summary.EnvFiles = append(summary.EnvFiles, envFile)
+    }
+}
+
+// readFileList reads newline-separated file paths from a file, or from stdin when
+// source is "-", ignoring blank lines
+func readFileList(source string) ([]string, error) {
+    var data []byte
+    var err error
+    if source == "-" {
+        data, err = ioutil.ReadAll(os.Stdin)
+    } else {
+        data, err = ioutil.ReadFile(source)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    var paths []string
+    for _, line := range strings.Split(string(data), "\n") {
+        if line = strings.TrimSpace(line); line != "" {
+            paths = append(paths, line)
+        }
+    }
+    return paths, nil
+}
+
+// linkTypeParamConstraints sets DefinedIn on type parameters whose constraint
+// names an interface declared in the same Go package (directory)
+func linkTypeParamConstraints(summary *Summary) {
+    interfaces := make(map[string]string)
+    for _, f := range summary.GoFiles {
+        for _, intf := range f.Interfaces {
+            interfaces[filepath.Dir(f.FilePath)+"\x00"+intf.Name] = f.FilePath
+        }
+    }
+    link := func(dir string, params []TypeParam) {
+        for i := range params {
+            for _, term := range strings.Split(params[i].Constraint, "|") {
+                term = strings.TrimPrefix(strings.TrimSpace(term), "~")
+                term = strings.SplitN(term, "[", 2)[0] // Generic constraint, e.g. Ordered[T]
+                if path, exists := interfaces[dir+"\x00"+term]; exists {
+                    params[i].DefinedIn = path
+                    break
+                }
+            }
+        }
+    }
+    for _, f := range summary.GoFiles {
+        dir := filepath.Dir(f.FilePath)
+        for _, fn := range f.Functions {
+            link(dir, fn.TypeParams)
+        }
+        for _, st := range f.Structs {
+            link(dir, st.TypeParams)
+        }
+        for _, intf := range f.Interfaces {
+            link(dir, intf.TypeParams)
+        }
+    }
+}
+
+// symbolID computes a stable identifier for a symbol. Line numbers are deliberately
+// excluded so the ID survives edits that move code around within a file.
+func symbolID(filePath string, qualifiedName string, kind string) string {
+    hash := sha1.Sum([]byte(filePath + "\x00" + qualifiedName + "\x00" + kind))
+    return hex.EncodeToString(hash[:8])
+}
+
+// qualifiedFunctionName returns the receiver-qualified name of a function
+func qualifiedFunctionName(fn Function) string {
+    if fn.Receiver != "" {
+        return fn.Receiver + "." + fn.Name
+    }
+    return fn.Name
+}
+
+// functionKind returns "method" for functions with a receiver and "function" otherwise
+func functionKind(fn Function) string {
+    if fn.Receiver != "" {
+        return "method"
+    }
+    return "function"
+}
+
+// assignSymbolIDs sets the ID of every function, method and type in the summary
+func assignSymbolIDs(summary *Summary, baseDir string) {
+    // relPath makes IDs independent of how the analyzed directory was specified
+    relPath := func(filePath string) string {
+        if rel, err := filepath.Rel(baseDir, filePath); err == nil {
+            return filepath.ToSlash(rel)
+        }
+        return filepath.ToSlash(filePath)
+    }
+
+    assignFunctions := func(file string, functions []Function) {
+        for i := range functions {
+            functions[i].ID = symbolID(file, qualifiedFunctionName(functions[i]), functionKind(functions[i]))
+        }
+    }
+
+    assignTypes := func(file string, types []Struct, kind string) {
+        for i := range types {
+            types[i].ID = symbolID(file, types[i].Name, kind)
+            assignFunctions(file, types[i].Methods)
+        }
+    }
+
+    for i := range summary.GoFiles {
+        file := relPath(summary.GoFiles[i].FilePath)
+        assignFunctions(file, summary.GoFiles[i].Functions)
+        assignTypes(file, summary.GoFiles[i].Structs, "struct")
+    }
+    for i := range summary.PhpFiles {
+        file := relPath(summary.PhpFiles[i].FilePath)
+        assignFunctions(file, summary.PhpFiles[i].Functions)
+        assignTypes(file, summary.PhpFiles[i].Classes, "class")
+    }
+    for i := range summary.PythonFiles {
+        file := relPath(summary.PythonFiles[i].FilePath)
+        assignFunctions(file, summary.PythonFiles[i].Functions)
+        assignTypes(file, summary.PythonFiles[i].Classes, "class")
+    }
+    for i := range summary.JsFiles {
+        file := relPath(summary.JsFiles[i].FilePath)
+        assignFunctions(file, summary.JsFiles[i].Functions)
+        assignTypes(file, summary.JsFiles[i].Classes, "class")
+    }
+    for i := range summary.TsFiles {
+        file := relPath(summary.TsFiles[i].FilePath)
+        assignFunctions(file, summary.TsFiles[i].Functions)
+        assignTypes(file, summary.TsFiles[i].Classes, "class")
+    }
+    for i := range summary.HtmlFiles {
+        file := relPath(summary.HtmlFiles[i].FilePath)
+        assignFunctions(file, summary.HtmlFiles[i].EmbeddedJS)
+    }
+}
+
+// analyzeGoFile analyzes a Go file and returns a GoFileSummary
+func analyzeGoFile(filePath string) GoFileSummary {
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading Go file %s: %v\n", filePath, err)
+        return GoFileSummary{FilePath: filePath}
+    }
+
+    return analyzeGoSource(filePath, data)
+}
+
+// analyzeGoSource analyzes Go source code and returns a GoFileSummary
+func analyzeGoSource(filePath string, src []byte) GoFileSummary {
+    fset := token.NewFileSet()
+    node, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+    if err != nil {
+        summary := GoFileSummary{FilePath: filePath, ParseError: err.Error()}
+        var errs scanner.ErrorList
+        if errors.As(err, &errs) && len(errs) > 0 {
+            summary.ParseErrorLine, summary.ParseErrorColumn = errs[0].Pos.Line, errs[0].Pos.Column
+        }
+        return summary
+    }
+
+    summary := GoFileSummary{
+    FilePath: filePath,
+    Package:  node.Name.Name,
+    BuildConstraint: goBuildConstraint(node, filePath),
+    }
+
+    // Extract imports, remembering the name each one is referred to by
+    importNames := make(map[string]int)
+    for i, imp := range node.Imports {
+    path := strings.Trim(imp.Path.Value, "\"")
+    summary.Imports = append(summary.Imports, Import{Path: path})
+    importNames[goImportName(imp)] = i
+    }
+
+    // Extract global variables, remembering their objects to count assignments
+    globalObjects := make(map[*ast.Object]int)
+    for _, decl := range node.Decls {
+    if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.VAR {
+        for _, spec := range genDecl.Specs {
+	if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+	    for _, name := range valueSpec.Names {
+	    var typeStr string
+	    if valueSpec.Type != nil {
+	        typeStr = exprToString(valueSpec.Type)
+	    } else if len(valueSpec.Values) > 0 {
+	        // Infer type from value if possible
+	        typeStr = "inferred"
+	    }
+
+	    variable := Variable{
+	        Name:  name.Name,
+	        Type:  typeStr,
+	        Scope: "global",
+	        Line:  fset.Position(name.Pos()).Line,
+	    }
+	    if len(valueSpec.Values) > 0 {
+	        variable.Mutations = 1
+	    }
+	    if name.Obj != nil {
+	        globalObjects[name.Obj] = len(summary.Variables)
+	    }
+	    summary.Variables = append(summary.Variables, variable)
+
+	    // Note string variables that are conventionally stamped at build time
+	    if isGoBuildInfoVar(name.Name, valueSpec) {
+	        summary.BuildInfoVars = append(summary.BuildInfoVars, name.Name)
+	    }
+	    }
+	}
+        }
+    }
+    }
+
+    // Extract constants, working out the values of iota enums
+    summary.Constants = extractGoConstants(node, fset, src)
+
+    // Methods are attached to the structs of this file once all declarations are seen
+    methodsByReceiver := make(map[string][]Function)
+
+    // Ungrouped type declarations keep their doc comment on the enclosing GenDecl
+    typeDocs := make(map[*ast.TypeSpec]*ast.CommentGroup)
+    for _, decl := range node.Decls {
+        if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
+            for _, spec := range genDecl.Specs {
+                typeSpec := spec.(*ast.TypeSpec)
+                if typeSpec.Doc != nil {
+                    typeDocs[typeSpec] = typeSpec.Doc
+                } else if len(genDecl.Specs) == 1 && genDecl.Doc != nil {
+                    typeDocs[typeSpec] = genDecl.Doc
+                }
+            }
+        }
+    }
+
+    // Extract functions, structs, and interfaces
+    ast.Inspect(node, func(n ast.Node) bool {
+    switch x := n.(type) {
+    case *ast.SelectorExpr:
+        // Record what is used from each imported package
+        if ident, ok := x.X.(*ast.Ident); ok && ident.Obj == nil {
+	if i, ok := importNames[ident.Name]; ok {
+	    summary.Imports[i].UsedSymbols = appendIfNotExists(summary.Imports[i].UsedSymbols, x.Sel.Name)
+	}
+        }
+
+    case *ast.CallExpr:
+        if call, ok := extractGoExternalCall(x, fset); ok {
+	summary.ExternalCalls = append(summary.ExternalCalls, call)
+        }
+        if ident, ok := x.Fun.(*ast.Ident); ok && ident.Name == "panic" {
+	summary.PanicSites = append(summary.PanicSites, fset.Position(x.Pos()).Line)
+        }
+
+    case *ast.FuncDecl:
+        function := extractFunction(x, fset)
+        if strings.HasSuffix(filePath, "_test.go") {
+	function.Kind = goTestKind(x)
+        }
+        summary.Functions = append(summary.Functions, function)
+
+        // If this is a method, add it to the struct
+        if function.Receiver != "" {
+	// Store the method to add to the struct later
+	methodsByReceiver[function.Receiver] = append(methodsByReceiver[function.Receiver], function)
+        }
+
+    case *ast.TypeSpec:
+        if structType, ok := x.Type.(*ast.StructType); ok {
+	structure := Struct{
+	    Name:   x.Name.Name,
+	    Fields: extractStructFields(structType, fset),
+	    Line:   fset.Position(x.Pos()).Line,
+	    TypeParams: goTypeParams(x.TypeParams),
+	}
+	if doc := typeDocs[x]; doc != nil {
+	    structure.Doc = strings.TrimSpace(doc.Text())
+	    structure.IsDeprecated, structure.Deprecation = deprecationNote(doc.Text())
+	}
+	summary.Structs = append(summary.Structs, structure)
+
+        } else if interfaceType, ok := x.Type.(*ast.InterfaceType); ok {
+	intf := Interface{
+	    Name:    x.Name.Name,
+	    Methods: extractInterfaceMethods(interfaceType, fset),
+	    Embeds:  extractInterfaceEmbeds(interfaceType),
+	    Line:    fset.Position(x.Pos()).Line,
+	    TypeParams: goTypeParams(x.TypeParams),
+	}
+	if doc := typeDocs[x]; doc != nil {
+	    intf.Doc = strings.TrimSpace(doc.Text())
+	}
+	summary.Interfaces = append(summary.Interfaces, intf)
+        }
+
+    case *ast.IfStmt:
+        controlFlow := ControlFlow{
+	Type: "if",
+	Line: fset.Position(x.If).Line,
+	EndLine: fset.Position(x.End()).Line,
+        }
+        
+        // Extract nested control flow
+        if x.Body != nil {
+	nestedControls := extractNestedControlFlow(x.Body, fset)
+	if len(nestedControls) > 0 {
+	    controlFlow.Children = nestedControls
+	}
+        }
+        
+        summary.ControlFlows = append(summary.ControlFlows, controlFlow)
+
+    case *ast.ForStmt:
+        controlFlow := ControlFlow{
+	Type: "for",
+	Line: fset.Position(x.For).Line,
+	EndLine: fset.Position(x.End()).Line,
+        }
+        
+        // Extract nested control flow
+        if x.Body != nil {
+	nestedControls := extractNestedControlFlow(x.Body, fset)
+	if len(nestedControls) > 0 {
+	    controlFlow.Children = nestedControls
+	}
+        }
+        
+        summary.ControlFlows = append(summary.ControlFlows, controlFlow)
+        case *ast.SwitchStmt:
+        controlFlow := ControlFlow{
+	Type: "switch",
+	Line: fset.Position(x.Switch).Line,
+	EndLine: fset.Position(x.End()).Line,
+        }
+        
+        // Extract nested control flow from switch cases
+        if x.Body != nil {
+	for _, stmt := range x.Body.List {
+	    if caseClause, ok := stmt.(*ast.CaseClause); ok {
+	    for _, caseStmt := range caseClause.Body {
+	        if blockStmt, ok := caseStmt.(*ast.BlockStmt); ok {
+		nestedControls := extractNestedControlFlow(blockStmt, fset)
+		if len(nestedControls) > 0 {
+		    controlFlow.Children = append(controlFlow.Children, nestedControls...)
+		}
+	        }
+	    }
+	    }
+	}
+        }
+        
+        summary.ControlFlows = append(summary.ControlFlows, controlFlow)
+    case *ast.SelectStmt:
+        summary.ControlFlows = append(summary.ControlFlows, goSelectControlFlow(x, fset))
+    case *ast.AssignStmt:
+        // Count writes to globals; := always declares a new local
+        if x.Tok != token.DEFINE {
+	for _, lhs := range x.Lhs {
+	    if ident, ok := lhs.(*ast.Ident); ok && ident.Obj != nil {
+	    if i, ok := globalObjects[ident.Obj]; ok {
+	        summary.Variables[i].Mutations++
+	    }
+	    }
+	}
+        }
+    case *ast.IncDecStmt:
+        if ident, ok := x.X.(*ast.Ident); ok && ident.Obj != nil {
+	if i, ok := globalObjects[ident.Obj]; ok {
+	    summary.Variables[i].Mutations++
+	}
+        }
+    }
+    return true
+    })
+
+    // Extract event bus publish/subscribe names
+    summary.EventsEmitted, summary.EventsHandled = findEvents(string(src), goEventPatterns)
+
+    // Extract environment variables read through os.Getenv and friends
+    summary.EnvVars = findEnvVars(string(src), goEnvVarRegex)
+    summary.SQLConcatenations = findSQLConcatenations(string(src), "go")
+    summary.SQLTables = findSQLTables(string(src))
+
+    // Extract routes and the middleware wrapping them
+    summary.Routes = extractGoRoutes(string(src))
+    summary.RealtimeEndpoints = findRealtimeEndpoints(string(src), goRealtimePatterns, summary.Functions, summary.Routes)
+    summary.ScheduledJobs = findScheduledJobs(string(src), goSchedulePatterns)
+
+    // Blank and dot imports can't be traced through selectors
+    for i, imp := range node.Imports {
+    if imp.Name != nil && (imp.Name.Name == "_" || imp.Name.Name == ".") {
+        continue
+    }
+    summary.Imports[i].Unused = len(summary.Imports[i].UsedSymbols) == 0
+    }
+
+    // List the request-handling surface, including handlers registered dynamically
+    for _, fn := range summary.Functions {
+    if fn.IsHTTPHandler {
+        summary.Handlers = append(summary.Handlers, qualifiedFunctionName(fn))
+    }
+    }
+
+    // Update struct methods
+    for i, s := range summary.Structs {
+    if methods := methodsByReceiver[s.Name]; len(methods) > 0 {
+        summary.Structs[i].Methods = methods
+    }
+    }
+
+    return summary
+}
+
+// goBuildInfoVarNames lists the (lowercased) variable names conventionally set via -ldflags -X
+var goBuildInfoVarNames = map[string]bool{
+    "version":   true,
+    "commit":    true,
+    "builddate": true,
+    "gitsha":    true,
+}
+
+// extractGoConstants lists the constants of a file's top-level const declarations. Specs
+// without values repeat the type and expressions before them, with iota counting the specs
+// of the block, so the members of an iota enum get their values.
+func extractGoConstants(node *ast.File, fset *token.FileSet, src []byte) []Constant {
+    var constants []Constant
+    values := make(map[string]constant.Value)
+    for _, decl := range node.Decls {
+        genDecl, ok := decl.(*ast.GenDecl)
+        if !ok || genDecl.Tok != token.CONST {
+            continue
+        }
+        group := 0
+        if genDecl.Lparen.IsValid() {
+            group = fset.Position(genDecl.Pos()).Line
+        }
+        var typeExpr ast.Expr
+        var exprs []ast.Expr
+        for iota, spec := range genDecl.Specs {
+            valueSpec := spec.(*ast.ValueSpec)
+            if len(valueSpec.Values) > 0 {
+                typeExpr, exprs = valueSpec.Type, valueSpec.Values
+            }
+            for i, name := range valueSpec.Names {
+                if name.Name == "_" {
+                    continue
+                }
+                c := Constant{
+                    Name:  name.Name,
+                    Group: group,
+                    Line:  fset.Position(name.Pos()).Line,
+                }
+                if typeExpr != nil {
+                    c.Type = exprToString(typeExpr)
+                }
+                if i < len(exprs) {
+                    expr := exprs[i]
+                    c.Value = string(src[fset.Position(expr.Pos()).Offset:fset.Position(expr.End()).Offset])
+                    ast.Inspect(expr, func(n ast.Node) bool {
+                        if ident, ok := n.(*ast.Ident); ok && ident.Name == "iota" {
+                            c.Iota = true
+                        }
+                        return !c.Iota
+                    })
+                    if value := goConstantValue(expr, iota, values); value != nil {
+                        values[name.Name] = value
+                        if value.Kind() == constant.Float {
+                            c.Value = value.String()
+                        } else {
+                            c.Value = value.ExactString()
+                        }
+                    }
+                }
+                constants = append(constants, c)
+            }
+        }
+    }
+    return constants
+}
+
+// goConstantValue evaluates a constant expression made of literals, iota and constants
+// declared earlier in the file, returning nil for anything else
+func goConstantValue(expr ast.Expr, iota int, known map[string]constant.Value) constant.Value {
+    switch x := expr.(type) {
+    case *ast.BasicLit:
+        if value := constant.MakeFromLiteral(x.Value, x.Kind, 0); value.Kind() != constant.Unknown {
+            return value
+        }
+    case *ast.Ident:
+        switch x.Name {
+        case "iota":
+            return constant.MakeInt64(int64(iota))
+        case "true", "false":
+            return constant.MakeBool(x.Name == "true")
+        }
+        return known[x.Name]
+    case *ast.ParenExpr:
+        return goConstantValue(x.X, iota, known)
+    case *ast.CallExpr:
+        // Conversions such as State(2) keep the value; len is the only builtin worked out
+        if len(x.Args) != 1 {
+            return nil
+        }
+        arg := goConstantValue(x.Args[0], iota, known)
+        if arg == nil {
+            return nil
+        }
+        switch exprToString(x.Fun) {
+        case "len":
+            if arg.Kind() == constant.String {
+                return constant.MakeInt64(int64(len(constant.StringVal(arg))))
+            }
+            return nil
+        case "cap", "real", "imag", "min", "max", "unsafe.Sizeof", "unsafe.Alignof", "unsafe.Offsetof":
+            return nil
+        }
+        return arg
+    case *ast.UnaryExpr:
+        value := goConstantValue(x.X, iota, known)
+        if value == nil {
+            return nil
+        }
+        switch {
+        case x.Op == token.NOT && value.Kind() == constant.Bool,
+            (x.Op == token.ADD || x.Op == token.SUB) && value.Kind() != constant.Bool && value.Kind() != constant.String,
+            x.Op == token.XOR && value.Kind() == constant.Int:
+            return constant.UnaryOp(x.Op, value, 0)
+        }
+    case *ast.BinaryExpr:
+        left, right := goConstantValue(x.X, iota, known), goConstantValue(x.Y, iota, known)
+        if left == nil || right == nil {
+            return nil
+        }
+        numeric := func(v constant.Value) bool {
+            return v.Kind() == constant.Int || v.Kind() == constant.Float
+        }
+        switch x.Op {
+        case token.SHL, token.SHR:
+            if shift, ok := constant.Uint64Val(right); ok && left.Kind() == constant.Int && shift < 1024 {
+                return constant.Shift(left, x.Op, uint(shift))
+            }
+        case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+            if left.Kind() == right.Kind() || numeric(left) && numeric(right) {
+                return constant.MakeBool(constant.Compare(left, x.Op, right))
+            }
+        case token.LAND, token.LOR:
+            if left.Kind() == constant.Bool && right.Kind() == constant.Bool {
+                return constant.BinaryOp(left, x.Op, right)
+            }
+        case token.ADD:
+            if left.Kind() == constant.String && right.Kind() == constant.String {
+                return constant.BinaryOp(left, x.Op, right)
+            }
+            fallthrough
+        case token.SUB, token.MUL, token.QUO:
+            if !numeric(left) || !numeric(right) || x.Op == token.QUO && constant.Sign(right) == 0 {
+                return nil
+            }
+            op := x.Op
+            if op == token.QUO && left.Kind() == constant.Int && right.Kind() == constant.Int {
+                // Integer operands divide as integers
+                op = token.QUO_ASSIGN
+            }
+            return constant.BinaryOp(left, op, right)
+        case token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+            if left.Kind() == constant.Int && right.Kind() == constant.Int && (x.Op != token.REM || constant.Sign(right) != 0) {
+                return constant.BinaryOp(left, x.Op, right)
+            }
+        }
+    }
+    return nil
+}
+
+var (
+    goKnownOS = map[string]bool{
+        "aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+        "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true, "netbsd": true,
+        "openbsd": true, "plan9": true, "solaris": true, "wasip1": true, "windows": true, "zos": true,
+    }
+    goUnixOS = map[string]bool{
+        "aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+        "illumos": true, "ios": true, "linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+    }
+    goKnownArch = map[string]bool{
+        "386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+        "arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+        "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true, "riscv": true,
+        "riscv64": true, "s390": true, "s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+    }
+)
+
+// goBuildConstraint returns the build constraint of a Go file: its //go:build line, or
+// its // +build lines in older code, together with the GOOS and GOARCH that a file name
+// such as main_windows.go or asm_linux_amd64.go implies
+func goBuildConstraint(node *ast.File, filePath string) string {
+    var expr constraint.Expr
+    var plusBuild []constraint.Expr
+    for _, group := range node.Comments {
+        if group.Pos() >= node.Package {
+            break
+        }
+        for _, comment := range group.List {
+            switch {
+            case constraint.IsGoBuild(comment.Text):
+                if parsed, err := constraint.Parse(comment.Text); err == nil {
+                    expr = parsed
+                }
+            case constraint.IsPlusBuild(comment.Text):
+                if parsed, err := constraint.Parse(comment.Text); err == nil {
+                    plusBuild = append(plusBuild, parsed)
+                }
+            }
+        }
+    }
+    if expr == nil {
+        for _, parsed := range plusBuild {
+            expr = andConstraint(expr, parsed)
+        }
+    }
+
+    // Only the parts after the first underscore count, so linux.go has no constraint. A
+    // tag the //go:build line already requires, as in lin_linux.go, isn't added again.
+    name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(filePath), ".go"), "_test")
+    if i := strings.Index(name, "_"); i >= 0 {
+        parts := strings.Split(name[i:], "_")
+        n := len(parts)
+        var tags []string
+        switch {
+        case n >= 2 && goKnownOS[parts[n-2]] && goKnownArch[parts[n-1]]:
+            tags = parts[n-2:]
+        case goKnownOS[parts[n-1]] || goKnownArch[parts[n-1]]:
+            tags = parts[n-1:]
+        }
+        for _, tag := range tags {
+            if !constraintRequires(expr, tag) {
+                expr = andConstraint(expr, &constraint.TagExpr{Tag: tag})
+            }
+        }
+    }
+    if expr == nil {
+        return ""
+    }
+    return expr.String()
+}
+
+// constraintRequires reports whether a build constraint can only hold when the tag is set,
+// because the tag is the whole constraint or one side of an && at the top level
+func constraintRequires(expr constraint.Expr, tag string) bool {
+    switch x := expr.(type) {
+    case *constraint.TagExpr:
+        return x.Tag == tag
+    case *constraint.AndExpr:
+        return constraintRequires(x.X, tag) || constraintRequires(x.Y, tag)
+    }
+    return false
+}
+
+// andConstraint joins two build constraints, either of which may be nil
+func andConstraint(x constraint.Expr, y constraint.Expr) constraint.Expr {
+    if x == nil {
+        return y
+    }
+    return &constraint.AndExpr{X: x, Y: y}
+}
+
+// goBuildSatisfied reports whether a build constraint holds for the given tags. The tags
+// may name a GOOS and GOARCH, which otherwise default to those of the running system,
+// and go1.N release tags always hold.
+func goBuildSatisfied(buildConstraint string, tags []string) bool {
+    expr, err := constraint.Parse("//go:build " + buildConstraint)
+    if err != nil {
+        return true
+    }
+    goos, goarch := runtime.GOOS, runtime.GOARCH
+    set := make(map[string]bool)
+    for _, tag := range tags {
+        tag = strings.TrimSpace(tag)
+        switch {
+        case goKnownOS[tag]:
+            goos = tag
+        case goKnownArch[tag]:
+            goarch = tag
+        }
+        set[tag] = true
+    }
+    return expr.Eval(func(tag string) bool {
+        switch {
+        case tag == goos || tag == goarch || strings.HasPrefix(tag, "go1."):
+            return true
+        case goKnownOS[tag] || goKnownArch[tag]:
+            // Android builds use linux files, illumos solaris files and iOS darwin files
+            return tag == "linux" && goos == "android" || tag == "solaris" && goos == "illumos" || tag == "darwin" && goos == "ios"
+        case tag == "unix":
+            return goUnixOS[goos]
+        }
+        return set[tag]
+    })
+}
+
+// goTestKind tells the functions "go test" runs apart from the helpers of a _test.go
+// file: TestXxx(*testing.T), BenchmarkXxx(*testing.B), FuzzXxx(*testing.F) and ExampleXxx()
+func goTestKind(funcDecl *ast.FuncDecl) string {
+    if funcDecl.Recv != nil || funcDecl.Type.TypeParams != nil {
+        return ""
+    }
+    var params []string
+    for _, field := range funcDecl.Type.Params.List {
+        for i := 0; i < max(1, len(field.Names)); i++ {
+            params = append(params, exprToString(field.Type))
+        }
+    }
+    for _, kind := range []struct{ prefix, kind, param string }{
+        {"Test", "test", "T"},
+        {"Benchmark", "benchmark", "B"},
+        {"Fuzz", "fuzz", "F"},
+        {"Example", "example", ""},
+    } {
+        // The name goes on with anything but a lowercase letter, so Testify is no test
+        name := funcDecl.Name.Name
+        if !strings.HasPrefix(name, kind.prefix) {
+            continue
+        }
+        if rest := name[len(kind.prefix):]; rest != "" && rest[0] >= 'a' && rest[0] <= 'z' {
+            return ""
+        }
+        if kind.param == "" {
+            if len(params) == 0 && funcDecl.Type.Results == nil {
+                return kind.kind
+            }
+            return ""
+        }
+        if len(params) == 1 && strings.HasPrefix(params[0], "*") && strings.HasSuffix(params[0], "."+kind.param) {
+            return kind.kind
+        }
+        return ""
+    }
+    return ""
+}
+
+// isTestFile reports whether a file holds tests by the naming conventions of its
+// language: foo_test.go, test_foo.py or foo_test.py, foo.test.js or foo.spec.ts, FooTest.php
+func isTestFile(name string) bool {
+    ext := filepath.Ext(name)
+    base := strings.TrimSuffix(name, ext)
+    switch strings.ToLower(ext) {
+    case ".go":
+        return strings.HasSuffix(base, "_test")
+    case ".py":
+        return strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test")
+    case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx":
+        return strings.HasSuffix(base, ".test") || strings.HasSuffix(base, ".spec")
+    case ".php":
+        return strings.HasSuffix(base, "Test")
+    }
+    return false
+}
+
+// isGoBuildInfoVar reports whether a package-level variable looks like build-time version metadata
+func isGoBuildInfoVar(name string, valueSpec *ast.ValueSpec) bool {
+    if !goBuildInfoVarNames[strings.ToLower(name)] {
+        return false
+    }
+
+    // -ldflags -X can only set string variables
+    if valueSpec.Type != nil {
+        return exprToString(valueSpec.Type) == "string"
+    }
+    for _, value := range valueSpec.Values {
+        if lit, ok := value.(*ast.BasicLit); !ok || lit.Kind != token.STRING {
+            return false
+        }
+    }
+    return len(valueSpec.Values) > 0
+}
+
+// extractGoExternalCall recognizes net/http client calls such as http.Get, http.NewRequest and client.Do
+func extractGoExternalCall(callExpr *ast.CallExpr, fset *token.FileSet) (ExternalCall, bool) {
+    selExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+    if !ok {
+        return ExternalCall{}, false
+    }
+
+    call := ExternalCall{
+        Client: "net/http",
+        Line:   fset.Position(callExpr.Pos()).Line,
+    }
+    receiver := exprToString(selExpr.X)
+
+    // stringArg returns the literal value of the argument at index i, if it is a string literal
+    stringArg := func(i int) string {
+        if i < len(callExpr.Args) {
+            if lit, ok := callExpr.Args[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+                return strings.Trim(lit.Value, "`\"")
+            }
+        }
+        return ""
+    }
+
+    switch {
+    case receiver == "http" && (selExpr.Sel.Name == "Get" || selExpr.Sel.Name == "Head"):
+        call.Method = strings.ToUpper(selExpr.Sel.Name)
+        call.URL = stringArg(0)
+    case receiver == "http" && (selExpr.Sel.Name == "Post" || selExpr.Sel.Name == "PostForm"):
+        call.Method = "POST"
+        call.URL = stringArg(0)
+    case receiver == "http" && selExpr.Sel.Name == "NewRequest":
+        call.Method = stringArg(0)
+        call.URL = stringArg(1)
+    case receiver == "http" && selExpr.Sel.Name == "NewRequestWithContext":
+        call.Method = stringArg(1)
+        call.URL = stringArg(2)
+    case selExpr.Sel.Name == "Do" && len(callExpr.Args) == 1 &&
+        (receiver == "http.DefaultClient" || strings.Contains(strings.ToLower(receiver), "client")):
+        // The request was built elsewhere, so only the call site is known
+    default:
+        return ExternalCall{}, false
+    }
+
+    return call, true
+}
+
+// extractNestedControlFlow extracts control flow statements from a block
+func extractNestedControlFlow(block *ast.BlockStmt, fset *token.FileSet) []ControlFlow {
+    var nestedControls []ControlFlow
+    
+    for _, stmt := range block.List {
+    switch x := stmt.(type) {
+    case *ast.IfStmt:
+        control := ControlFlow{
+	Type: "if",
+	Line: fset.Position(x.If).Line,
+	EndLine: fset.Position(x.End()).Line,
+        }
+        
+        if x.Body != nil {
+	innerNested := extractNestedControlFlow(x.Body, fset)
+	if len(innerNested) > 0 {
+	    control.Children = innerNested
+	}
+        }
+        
+        nestedControls = append(nestedControls, control)
+        
+    case *ast.ForStmt:
+        control := ControlFlow{
+	Type: "for",
+	Line: fset.Position(x.For).Line,
+	EndLine: fset.Position(x.End()).Line,
+        }
+        
+        if x.Body != nil {
+	innerNested := extractNestedControlFlow(x.Body, fset)
+	if len(innerNested) > 0 {
+	    control.Children = innerNested
+	}
+        }
+        
+        nestedControls = append(nestedControls, control)
+        
+    case *ast.SwitchStmt:
+        control := ControlFlow{
+	Type: "switch",
+	Line: fset.Position(x.Switch).Line,
+	EndLine: fset.Position(x.End()).Line,
+        }
+        
+        if x.Body != nil {
+	for _, switchStmt := range x.Body.List {
+	    if caseClause, ok := switchStmt.(*ast.CaseClause); ok {
+	    for _, caseStmt := range caseClause.Body {
+	        if blockStmt, ok := caseStmt.(*ast.BlockStmt); ok {
+		innerNested := extractNestedControlFlow(blockStmt, fset)
+		if len(innerNested) > 0 {
+		    control.Children = append(control.Children, innerNested...)
+		}
+	        }
+	    }
+	    }
+	}
+        }
+        
+        nestedControls = append(nestedControls, control)
+
+    case *ast.SelectStmt:
+        nestedControls = append(nestedControls, goSelectControlFlow(x, fset))
+    }
+    }
+    
+    return nestedControls
+}
+
+// goSelectControlFlow describes a select statement with one child per communication
+// case, typed "send", "receive" or "default", holding the control flow of its body
+func goSelectControlFlow(selectStmt *ast.SelectStmt, fset *token.FileSet) ControlFlow {
+    control := ControlFlow{
+        Type:    "select",
+        Line:    fset.Position(selectStmt.Select).Line,
+        EndLine: fset.Position(selectStmt.End()).Line,
+    }
+
+    for _, stmt := range selectStmt.Body.List {
+        clause, ok := stmt.(*ast.CommClause)
+        if !ok {
+            continue
+        }
+        caseType := "receive"
+        if clause.Comm == nil {
+            caseType = "default"
+        } else if _, ok := clause.Comm.(*ast.SendStmt); ok {
+            caseType = "send"
+        }
+        child := ControlFlow{
+            Type:    caseType,
+            Line:    fset.Position(clause.Case).Line,
+            EndLine: fset.Position(clause.End()).Line,
+        }
+        child.Children = extractNestedControlFlow(&ast.BlockStmt{List: clause.Body}, fset)
+        control.Children = append(control.Children, child)
+    }
+
+    return control
+}
+
+// goTypeParams returns the type parameters declared in a generic type or function
+func goTypeParams(fields *ast.FieldList) []TypeParam {
+    if fields == nil {
+        return nil
+    }
+    var params []TypeParam
+    for _, field := range fields.List {
+        constraint := exprToString(field.Type)
+        for _, name := range field.Names {
+            params = append(params, TypeParam{Name: name.Name, Constraint: constraint})
+        }
+    }
+    return params
+}
+
+// goReceiverType returns the name of the type a method is declared on, without the
+// pointer or the type parameters of a generic receiver such as *List[T]
+func goReceiverType(expr ast.Expr) string {
+    for {
+        switch x := expr.(type) {
+        case *ast.StarExpr:
+            expr = x.X
+        case *ast.ParenExpr:
+            expr = x.X
+        case *ast.IndexExpr:
+            expr = x.X
+        case *ast.IndexListExpr:
+            expr = x.X
+        default:
+            return exprToString(expr)
+        }
+    }
+}
+
+// extractFunction extracts function details
+func extractFunction(funcDecl *ast.FuncDecl, fset *token.FileSet) Function {
+    function := Function{
+    Name: funcDecl.Name.Name,
+    Line: fset.Position(funcDecl.Pos()).Line,
+    }
+
+    // Extract receiver for methods
+    if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+    function.Receiver = goReceiverType(funcDecl.Recv.List[0].Type)
+    }
+
+    // Extract type parameters of generic functions
+    function.TypeParams = goTypeParams(funcDecl.Type.TypeParams)
+
+    // A "Deprecated:" paragraph in the doc comment marks the function deprecated
+    if funcDecl.Doc != nil {
+        function.Doc = strings.TrimSpace(funcDecl.Doc.Text())
+        function.IsDeprecated, function.Deprecation = deprecationNote(funcDecl.Doc.Text())
+    }
+
+    // Extract arguments and return types
+    function.Args = goParams(funcDecl.Type.Params, fset)
+    function.Returns = goResults(funcDecl.Type.Results, false)
+
+    // Detect simple getters returning a receiver field
+    function.ReturnsField = goReturnedField(funcDecl)
+
+    // Recognize HTTP handlers by their signature alone
+    function.IsHTTPHandler = isGoHTTPHandler(function)
+
+    // Note whether the function accepts a context, and which calls don't receive it
+    ctxName := ""
+    if params := funcDecl.Type.Params; params != nil && len(params.List) > 0 && exprToString(params.List[0].Type) == "context.Context" {
+        function.TakesContext = true
+        if len(params.List[0].Names) > 0 {
+            ctxName = params.List[0].Names[0].Name
+        }
+    }
+    if ctxName != "" && ctxName != "_" && funcDecl.Body != nil {
+        ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+            if callExpr, ok := n.(*ast.CallExpr); ok && !goCallUsesIdent(callExpr, ctxName) {
+                function.CallsWithoutContext = appendIfNotExists(function.CallsWithoutContext, exprToString(callExpr.Fun))
+            }
+            return true
+        })
+    }
+
+    // Extract function calls
+    if funcDecl.Body != nil {
+    ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+        if callExpr, ok := n.(*ast.CallExpr); ok {
+	// Explicitly instantiated generic functions, e.g. Map[string, int](xs, f), count as calls to Map
+	fun := callExpr.Fun
+	switch x := fun.(type) {
+	case *ast.IndexExpr:
+	    fun = x.X
+	case *ast.IndexListExpr:
+	    fun = x.X
+	}
+	if ident, ok := fun.(*ast.Ident); ok {
+	    // Direct function call
+	    function.Calls = appendIfNotExists(function.Calls, ident.Name)
+	    
+	    // recover() anywhere in the body, typically in a deferred closure, contains panics
+	    if ident.Name == "recover" {
+	        function.HasRecover = true
+	    }
+	} else if selExpr, ok := fun.(*ast.SelectorExpr); ok {
+	    // Method call or package function
+	    function.Calls = appendIfNotExists(function.Calls, exprToString(selExpr))
+	}
+        }
+        return true
+    })
+    classifySideEffects(&function, nil, goMutatesState(funcDecl), false)
+    for _, effect := range goFprintEffects(funcDecl.Body) {
+        function.SideEffects = appendIfNotExists(function.SideEffects, effect)
+        function.Pure = false
+    }
+    sort.Strings(function.SideEffects)
+    function.Complexity = goComplexity(funcDecl.Body)
+    function.EndLine = fset.Position(funcDecl.End()).Line
+    function.Statements, function.MaxNesting = goBodyMetrics(funcDecl.Body)
+    }
+
+    return function
+}
+
+// goComplexity returns the cyclomatic complexity of a function body: one plus a branch
+// for every if, for, range, non-default case or select clause, && and ||
+func goComplexity(body *ast.BlockStmt) int {
+    complexity := 1
+    ast.Inspect(body, func(n ast.Node) bool {
+        switch x := n.(type) {
+        case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+            complexity++
+        case *ast.CaseClause:
+            if x.List != nil {
+                complexity++
+            }
+        case *ast.CommClause:
+            if x.Comm != nil {
+                complexity++
+            }
+        case *ast.BinaryExpr:
+            if x.Op == token.LAND || x.Op == token.LOR {
+                complexity++
+            }
+        }
+        return true
+    })
+    return complexity
+}
+
+// goBodyMetrics counts the statements of a Go function body and the deepest nesting of
+// if, for, switch and select statements and closures within it; else-if chains stay at
+// the depth of their first if
+func goBodyMetrics(body *ast.BlockStmt) (statements int, nesting int) {
+    var nests []bool // Whether each node on the path from the body adds a level
+    depth := 0
+    elseIfs := make(map[ast.Node]bool)
+    ast.Inspect(body, func(n ast.Node) bool {
+        if n == nil {
+            if nests[len(nests)-1] {
+                depth--
+            }
+            nests = nests[:len(nests)-1]
+            return true
+        }
+        nested := false
+        switch x := n.(type) {
+        case *ast.IfStmt:
+            if elseIf, ok := x.Else.(*ast.IfStmt); ok {
+                elseIfs[elseIf] = true
+            }
+            nested = !elseIfs[x]
+        case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.FuncLit:
+            nested = true
+        }
+        switch n.(type) {
+        case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause, *ast.EmptyStmt, *ast.LabeledStmt:
+        case ast.Stmt:
+            statements++
+        }
+        if nested {
+            depth++
+            if depth > nesting {
+                nesting = depth
+            }
+        }
+        nests = append(nests, nested)
+        return true
+    })
+    return statements, nesting
+}
+
+// braceStatementOpenerRegex matches the code in front of a brace that opens a statement
+// block, as opposed to an object literal or an else or finally block
+var braceStatementOpenerRegex = regexp.MustCompile(`(?:\)|\bdo|\btry)$`)
+
+// braceBodyMetrics counts the statements of a masked PHP or JavaScript function body and
+// how deeply its blocks nest. A statement ends with a semicolon outside parentheses, at a
+// closing brace, or at a line break after a complete expression, and a braced control
+// statement counts once; object literal braces don't add nesting.
+func braceBodyMetrics(masked string) (statements int, nesting int) {
+    type brace struct {
+        block   bool // A block rather than an object literal
+        parens  int  // Open parentheses around the brace
+        pending bool // The block is a callback inside a statement that goes on after it
+    }
+    var braces []brace
+    parens, depth := 0, 0
+    pending := false // Code seen since the last statement ended
+    for i := 0; i < len(masked); i++ {
+        c := masked[i]
+        switch {
+        case c == '(' || c == '[':
+            parens++
+            pending = true
+        case c == ')' || c == ']':
+            if parens > 0 {
+                parens--
+            }
+        case c == ';' && parens == 0:
+            if pending {
+                statements++
+            }
+            pending = false
+        case c == '{':
+            before := strings.TrimRight(masked[:i], " \t\r\n")
+            opener := braceStatementOpenerRegex.MatchString(before)
+            block := opener || before == "" || strings.HasSuffix(before, "=>") || strings.HasSuffix(before, "else") ||
+                strings.HasSuffix(before, "finally") || strings.HasSuffix(before, ";") || strings.HasSuffix(before, "{") || strings.HasSuffix(before, "}")
+            outer := pending && (parens > 0 || strings.HasSuffix(before, "=>"))
+            if block {
+                if opener && parens == 0 {
+                    statements++
+                }
+                pending = false
+                depth++
+                if depth > nesting {
+                    nesting = depth
+                }
+            }
+            braces = append(braces, brace{block, parens, outer})
+            parens = 0
+        case c == '}':
+            if len(braces) > 0 {
+                top := braces[len(braces)-1]
+                if top.block {
+                    if pending {
+                        statements++
+                    }
+                    pending = top.pending
+                    depth--
+                }
+                braces, parens = braces[:len(braces)-1], top.parens
+            }
+        case c == '\n':
+            inBlock := len(braces) == 0 || braces[len(braces)-1].block
+            line := strings.TrimRight(masked[:i], " \t\r")
+            if pending && parens == 0 && inBlock && !strings.ContainsAny(line[len(line)-1:], ",([{+-*/%=&|?:.<>!") {
+                statements++
+                pending = false
+            }
+        case c != ' ' && c != '\t' && c != '\r':
+            pending = true
+        }
+    }
+    if pending {
+        statements++
+    }
+    return statements, nesting
+}
+
+// pythonBodyMetrics counts the logical lines of a masked Python function body and how
+// deeply its indented blocks nest
+func pythonBodyMetrics(masked string) (statements int, nesting int) {
+    var headers []int // Indentation of the enclosing block headers
+    parens, indent := 0, 0
+    continued := false
+    for _, line := range strings.Split(masked, "\n") {
+        trimmed := strings.TrimSpace(line)
+        if trimmed == "" {
+            continue
+        }
+        if parens == 0 && !continued {
+            indent = len(line) - len(strings.TrimLeft(line, " \t"))
+            for len(headers) > 0 && indent <= headers[len(headers)-1] {
+                headers = headers[:len(headers)-1]
+            }
+            statements++
+            if len(headers) > nesting {
+                nesting = len(headers)
+            }
+        }
+        parens += strings.Count(trimmed, "(") + strings.Count(trimmed, "[") + strings.Count(trimmed, "{") -
+            strings.Count(trimmed, ")") - strings.Count(trimmed, "]") - strings.Count(trimmed, "}")
+        if parens < 0 {
+            parens = 0
+        }
+        continued = strings.HasSuffix(trimmed, "\\")
+
+        // A header may span lines, so its colon ends the logical line
+        if parens == 0 && !continued && strings.HasSuffix(trimmed, ":") {
+            headers = append(headers, indent)
+        }
+    }
+    return statements, nesting
+}
+
+// sideEffectPrefixes classify calls by package or object prefix; the first match wins
+// and an empty effect marks calls known to be harmless. fmt.Fprint* depends on its
+// writer, so goFprintEffects classifies it from the call's arguments.
+var sideEffectPrefixes = []struct {
+    prefix string
+    effect string
+}{
+    {"os.Exit", "panic"}, {"sys.exit", "panic"}, {"log.Fatal", "panic"}, {"log.Panic", "panic"},
+    {"os.Getenv", ""}, {"os.LookupEnv", ""}, {"os.environ", ""}, {"os.path.join", ""},
+    {"os.", "io"}, {"ioutil.", "io"}, {"shutil.", "io"}, {"subprocess.", "io"}, {"socket.", "io"},
+    {"requests.", "io"}, {"httpx.", "io"}, {"urllib.", "io"}, {"net.Dial", "io"},
+    {"http.Get", "io"}, {"http.Post", "io"}, {"http.Head", "io"}, {"http.DefaultClient", "io"}, {"http.NewRequest", "io"},
+    {"fmt.Print", "print"}, {"fmt.Fprint", ""}, {"log.", "print"}, {"logging.", "print"}, {"logger.", "print"},
+}
+
+// sideEffectNames classify calls by their final name, lowercased
+var sideEffectNames = map[string]string{
+    "open": "io", "fopen": "io", "fwrite": "io", "fread": "io", "fputs": "io", "readfile": "io",
+    "file_get_contents": "io", "file_put_contents": "io", "unlink": "io", "mkdir": "io", "rmdir": "io",
+    "curl_init": "io", "curl_exec": "io", "curl_multi_exec": "io", "request": "io", "requestasync": "io", "fsockopen": "io", "urlopen": "io", "move_uploaded_file": "io", "input": "io",
+    "query": "db", "queryrow": "db", "querycontext": "db", "queryrowcontext": "db", "exec": "db", "execcontext": "db",
+    "execute": "db", "executemany": "db", "executescript": "db", "prepare": "db", "preparecontext": "db",
+    "begin": "db", "begintx": "db", "commit": "db", "rollback": "db", "fetchone": "db", "fetchall": "db",
+    "mysqli_query": "db", "mysql_query": "db", "pg_query": "db",
+    "print": "print", "println": "print", "printf": "print", "print_r": "print", "var_dump": "print", "pprint": "print", "echo": "print",
+    "panic": "panic", "die": "panic", "exit": "panic",
+}
+
+// callSideEffect returns the effect of calling the named function, or "" when none is known
+func callSideEffect(call string) string {
+    for _, known := range sideEffectPrefixes {
+        if strings.HasPrefix(call, known.prefix) {
+            return known.effect
+        }
+    }
+    // An HTTP client's Do sends a request; other Do methods, like sync.Once's, don't
+    if receiver, found := strings.CutSuffix(call, ".Do"); found && strings.HasSuffix(strings.ToLower(receiver), "client") {
+        return "io"
+    }
+    return sideEffectNames[strings.ToLower(call[strings.LastIndex(call, ".")+1:])]
+}
+
+// classifySideEffects sets SideEffects and Pure from the calls a function makes, plus
+// what its body shows beyond calls: builtins and language constructs (extra), writes to
+// globals or fields, and raised exceptions
+func classifySideEffects(fn *Function, extra []string, mutatesState bool, raises bool) {
+    var effects []string
+    for _, calls := range [][]string{fn.Calls, extra} {
+        for _, call := range calls {
+            if effect := callSideEffect(call); effect != "" {
+                effects = appendIfNotExists(effects, effect)
+            }
+        }
+    }
+    if mutatesState {
+        effects = appendIfNotExists(effects, "global-mutation")
+    }
+    if raises {
+        effects = appendIfNotExists(effects, "panic")
+    }
+    sort.Strings(effects)
+    fn.SideEffects = effects
+    fn.Pure = len(effects) == 0
+}
+
+// goFprintEffects classifies the fmt.Fprint, Fprintf and Fprintln calls in a body by their
+// writer: "print" for os.Stdout and os.Stderr, "io" for files, responses and the like
+func goFprintEffects(body *ast.BlockStmt) []string {
+    var effects []string
+    ast.Inspect(body, func(n ast.Node) bool {
+        call, ok := n.(*ast.CallExpr)
+        if !ok || len(call.Args) == 0 {
+            return true
+        }
+        if name := exprToString(call.Fun); !strings.HasPrefix(name, "fmt.Fprint") {
+            return true
+        }
+        switch exprToString(call.Args[0]) {
+        case "os.Stdout", "os.Stderr":
+            effects = appendIfNotExists(effects, "print")
+        default:
+            effects = appendIfNotExists(effects, "io")
+        }
+        return true
+    })
+    return effects
+}
+
+// goMutatesState reports whether a function assigns to a package-level variable, or to a
+// field or element reached through its receiver, its parameters or a global
+func goMutatesState(funcDecl *ast.FuncDecl) bool {
+    body := funcDecl.Body
+    writesOutside := func(lhs ast.Expr) bool {
+        direct := true
+        for {
+            switch x := lhs.(type) {
+            case *ast.SelectorExpr:
+                lhs, direct = x.X, false
+            case *ast.IndexExpr:
+                lhs, direct = x.X, false
+            case *ast.StarExpr:
+                lhs, direct = x.X, false
+            case *ast.ParenExpr:
+                lhs = x.X
+            case *ast.Ident:
+                if x.Name == "_" {
+                    return false
+                }
+                // Unresolved names are package-level variables declared in another file
+                if x.Obj == nil {
+                    return true
+                }
+                if x.Obj.Kind != ast.Var {
+                    return false
+                }
+                if direct {
+                    return x.Obj.Pos() < funcDecl.Pos() || x.Obj.Pos() >= funcDecl.End()
+                }
+                return x.Obj.Pos() < body.Pos() || x.Obj.Pos() >= body.End()
+            default:
+                return false
+            }
+        }
+    }
+
+    mutates := false
+    ast.Inspect(body, func(n ast.Node) bool {
+        switch x := n.(type) {
+        case *ast.AssignStmt:
+            if x.Tok != token.DEFINE {
+                for _, lhs := range x.Lhs {
+                    mutates = mutates || writesOutside(lhs)
+                }
+            }
+        case *ast.IncDecStmt:
+            mutates = mutates || writesOutside(x.X)
+        }
+        return !mutates
+    })
+    return mutates
+}
+
+var (
+    pythonEffectBuiltinRegex = regexp.MustCompile(`\b(print|open|input|exit)\s*\(`)
+    pythonStateWriteRegex    = regexp.MustCompile(`(?m)\b(?:self|cls)\.\w+(?:\[[^\]]*\])?\s*(?:[-+*/%|&^]|//|\*\*|<<|>>)?=[^=]|^\s*(?:global|nonlocal)\s+\w+`)
+    pythonRaiseRegex         = regexp.MustCompile(`(?m)^\s*raise\b`)
+    phpEffectConstructRegex  = regexp.MustCompile(`\b(echo|print|exit|die)\b`)
+    phpStateWriteRegex       = regexp.MustCompile(`\$this->\w+(?:\[[^\]]*\])*\s*(?:\+\+|--|(?:[-+*/.%|&^]|\?\?|\*\*)?=[^=>])|\b(?:self|static)::\$\w+\s*(?:[-+*/.]?=[^=]|\+\+|--)|\bglobal\s+\$|\$GLOBALS\s*\[`)
+    phpThrowRegex            = regexp.MustCompile(`\bthrow\b`)
+)
+
+// pythonSideEffects classifies a Python function from its body, catching the builtins
+// that are left out of its calls
+func pythonSideEffects(fn *Function, body string) {
+    var builtins []string
+    for _, match := range pythonEffectBuiltinRegex.FindAllStringSubmatch(body, -1) {
+        builtins = appendIfNotExists(builtins, match[1])
+    }
+    classifySideEffects(fn, builtins, pythonStateWriteRegex.MatchString(body), pythonRaiseRegex.MatchString(body))
+}
+
+// phpSideEffects classifies a PHP function from its body, catching echo, print, exit
+// and die, which are language constructs rather than calls
+func phpSideEffects(fn *Function, body string) {
+    var constructs []string
+    for _, match := range phpEffectConstructRegex.FindAllStringSubmatch(body, -1) {
+        constructs = appendIfNotExists(constructs, match[1])
+    }
+    classifySideEffects(fn, constructs, phpStateWriteRegex.MatchString(body), phpThrowRegex.MatchString(body))
+}
+
+// goHandlerSignatures are the parameter types of HTTP handlers in net/http and the
+// common frameworks, and whether the handler returns an error
+var goHandlerSignatures = []struct {
+    params       string
+    returnsError bool
+}{
+    {"http.ResponseWriter,*http.Request", false},
+    {"*gin.Context", false},
+    {"echo.Context", true},
+    {"*fiber.Ctx", true},
+}
+
+// isGoHTTPHandler reports whether a function has the shape of an HTTP handler
+func isGoHTTPHandler(fn Function) bool {
+    var params []string
+    for _, arg := range fn.Args {
+        params = append(params, arg.Type)
+    }
+    for _, signature := range goHandlerSignatures {
+        if strings.Join(params, ",") != signature.params {
+            continue
+        }
+        if signature.returnsError {
+            return len(fn.Returns) == 1 && fn.Returns[0] == "error"
+        }
+        return len(fn.Returns) == 0
+    }
+    return false
+}
+
+// goCallUsesIdent reports whether any argument of a call refers to the named identifier
+func goCallUsesIdent(callExpr *ast.CallExpr, name string) bool {
+    found := false
+    for _, arg := range callExpr.Args {
+        ast.Inspect(arg, func(n ast.Node) bool {
+            if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+                found = true
+            }
+            return !found
+        })
+    }
+    return found
+}
+
+// flagContextNotPropagated reports context-taking Go functions that call other
+// context-taking functions without handing over their own context
+func flagContextNotPropagated(summary *Summary) {
+    takesContext := make(map[string]bool)
+    for _, goFile := range summary.GoFiles {
+        for _, fn := range goFile.Functions {
+            if fn.TakesContext {
+                takesContext[fn.Name] = true
+            }
+        }
+    }
+
+    for i := range summary.GoFiles {
+        for _, fn := range summary.GoFiles[i].Functions {
+            for _, call := range fn.CallsWithoutContext {
+                // Resolve selector calls by their final name
+                callee := call
+                if idx := strings.LastIndex(callee, "."); idx >= 0 {
+                    callee = callee[idx+1:]
+                }
+                if takesContext[callee] {
+                    summary.GoFiles[i].ContextNotPropagated = append(summary.GoFiles[i].ContextNotPropagated, qualifiedFunctionName(fn)+": "+call)
+                }
+            }
+        }
+    }
+    rewriteFunctions(summary, func(functions []Function) []Function {
+        for i := range functions {
+            functions[i].CallsWithoutContext = nil
+        }
+        return functions
+    })
+}
+
+// goImportName returns the name a Go import is referred to by: its alias, or the last
+// path element without a version suffix or "go-" prefix
+func goImportName(imp *ast.ImportSpec) string {
+    if imp.Name != nil {
+        return imp.Name.Name
+    }
+    path := strings.Trim(imp.Path.Value, "\"")
+    name := path[strings.LastIndex(path, "/")+1:]
+    if regexp.MustCompile(`^v\d+$`).MatchString(name) && strings.Contains(path, "/") {
+        // Major version directory, e.g. github.com/x/y/v2
+        trimmed := path[:strings.LastIndex(path, "/")]
+        name = trimmed[strings.LastIndex(trimmed, "/")+1:]
+    }
+    name = regexp.MustCompile(`\.v\d+$`).ReplaceAllString(name, "")
+    name = strings.TrimPrefix(name, "go-")
+    return strings.ReplaceAll(name, "-", "")
+}
+
+// goReturnedField returns the receiver field name when a method body is a
+// single "return r.field" statement
+func goReturnedField(funcDecl *ast.FuncDecl) string {
+    if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 || len(funcDecl.Recv.List[0].Names) == 0 {
+        return ""
+    }
+    if funcDecl.Body == nil || len(funcDecl.Body.List) != 1 {
+        return ""
+    }
+
+    ret, ok := funcDecl.Body.List[0].(*ast.ReturnStmt)
+    if !ok || len(ret.Results) != 1 {
+        return ""
+    }
+    sel, ok := ret.Results[0].(*ast.SelectorExpr)
+    if !ok {
+        return ""
+    }
+    if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == funcDecl.Recv.List[0].Names[0].Name {
+        return sel.Sel.Name
+    }
+    return ""
+}
+
+// extractStructFields extracts fields from a struct definition
+func extractStructFields(structType *ast.StructType, fset *token.FileSet) []Variable {
+    var fields []Variable
+
+    if structType.Fields == nil {
+    return fields
+    }
+
+    for _, field := range structType.Fields.List {
+    typeStr := exprToString(field.Type)
+    
+    // Struct tags are kept unquoted
+    tag := ""
+    if field.Tag != nil {
+        if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+            tag = unquoted
+        }
+    }
+    
+    if len(field.Names) == 0 {
+        // Embedded field
+        fields = append(fields, Variable{
+	Name:  typeStr, // Use the type as the name for embedded fields
+	Type:  typeStr,
+	Scope: "struct",
+	Line:  fset.Position(field.Pos()).Line,
+	Tag:   tag,
+	Tags:  parseGoStructTag(tag),
+	Ordinal: len(fields) + 1,
+        })
+    } else {
+        for _, name := range field.Names {
+	fields = append(fields, Variable{
+	    Name:  name.Name,
+	    Type:  typeStr,
+	    Scope: "struct",
+	    Line:  fset.Position(name.Pos()).Line,
+	    Tag:   tag,
+	    Tags:  parseGoStructTag(tag),
+	    Ordinal: len(fields) + 1,
+	})
+        }
+    }
+    }
+
+    return fields
+}
+
+// parseGoStructTag splits a struct tag into its key:"value" pairs the way
+// reflect.StructTag.Lookup reads them, stopping at the first malformed pair
+func parseGoStructTag(tag string) map[string]string {
+    var tags map[string]string
+    for tag != "" {
+        tag = strings.TrimLeft(tag, " ")
+        i := 0
+        for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+            i++
+        }
+        if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+            break
+        }
+        key := tag[:i]
+        tag = tag[i+1:]
+
+        // The value is a Go string literal, so skip escaped quotes looking for its end
+        i = 1
+        for i < len(tag) && tag[i] != '"' {
+            if tag[i] == '\\' {
+                i++
+            }
+            i++
+        }
+        if i >= len(tag) {
+            break
+        }
+        value, err := strconv.Unquote(tag[:i+1])
+        if err != nil {
+            break
+        }
+        tag = tag[i+1:]
+        if tags == nil {
+            tags = make(map[string]string)
+        }
+        tags[key] = value
+    }
+    return tags
+}
+
+// extractInterfaceMethods extracts methods from an interface definition
+func extractInterfaceMethods(interfaceType *ast.InterfaceType, fset *token.FileSet) []Function {
+    var methods []Function
+
+    if interfaceType.Methods == nil {
+    return methods
+    }
+
+    for _, method := range interfaceType.Methods.List {
+    if len(method.Names) > 0 {
+        name := method.Names[0].Name
+        
+        function := Function{
+	Name: name,
+	Line: fset.Position(method.Pos()).Line,
+        }
+        if method.Doc != nil {
+	function.Doc = strings.TrimSpace(method.Doc.Text())
+        }
+        
+        // Keep parameter and result names so the signature can be written back out as Go
+        if funcType, ok := method.Type.(*ast.FuncType); ok {
+	function.Args = goParams(funcType.Params, fset)
+	function.Returns = goResults(funcType.Results, true)
+        }
+        
+        methods = append(methods, function)
+    }
+    }
+
+    return methods
+}
+
+// extractInterfaceEmbeds lists the interfaces and type set terms embedded in an interface
+func extractInterfaceEmbeds(interfaceType *ast.InterfaceType) []string {
+    var embeds []string
+    if interfaceType.Methods == nil {
+        return embeds
+    }
+    for _, method := range interfaceType.Methods.List {
+        if len(method.Names) == 0 {
+            embeds = append(embeds, exprToString(method.Type))
+        }
+    }
+    return embeds
+}
+
+// goParams lists the parameters of a function type, keeping unnamed parameters with
+// an empty name and variadic ones with their "...T" type
+func goParams(fields *ast.FieldList, fset *token.FileSet) []Variable {
+    var params []Variable
+    if fields == nil {
+        return params
+    }
+    for _, field := range fields.List {
+        typeStr := exprToString(field.Type)
+        if len(field.Names) == 0 {
+            params = append(params, Variable{Type: typeStr, Scope: "argument", Line: fset.Position(field.Pos()).Line})
+            continue
+        }
+        for _, name := range field.Names {
+            params = append(params, Variable{
+                Name:  name.Name,
+                Type:  typeStr,
+                Scope: "argument",
+                Line:  fset.Position(name.Pos()).Line,
+            })
+        }
+    }
+    return params
+}
+
+// goResults lists the result types of a function type, one per result even when names
+// share a type (a, b int). With withNames, named results are written "name type".
+func goResults(fields *ast.FieldList, withNames bool) []string {
+    var results []string
+    if fields == nil {
+        return results
+    }
+    for _, field := range fields.List {
+        typeStr := exprToString(field.Type)
+        if len(field.Names) == 0 {
+            results = append(results, typeStr)
+            continue
+        }
+        for _, name := range field.Names {
+            if withNames {
+                results = append(results, name.Name+" "+typeStr)
+            } else {
+                results = append(results, typeStr)
+            }
+        }
+    }
+    return results
+}
+
+// analyzePhpFile analyzes a PHP file and returns a PhpFileSummary
+func analyzePhpFile(filePath string) PhpFileSummary {
+    
+    // Read file content
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+    fmt.Fprintf(os.Stderr, "Error reading PHP file %s: %v\n", filePath, err)
+    return PhpFileSummary{FilePath: filePath}
+    }
+    
+    return analyzePhpContent(filePath, string(data))
+}
+
+// analyzePhpContent analyzes PHP source code and returns a PhpFileSummary
+func analyzePhpContent(filePath string, content string) PhpFileSummary {
+    summary := PhpFileSummary{
+    FilePath: filePath,
+    }
+    
+    // Parse namespace declaration
+    namespaceRegex := regexp.MustCompile(`(?m)^\s*namespace\s+([\w\\]+)\s*[;{]`)
+    if namespaceMatch := namespaceRegex.FindStringSubmatch(content); len(namespaceMatch) >= 2 {
+    summary.Namespace = namespaceMatch[1]
+    }
+    
+    // Parse includes/requires
+    includeRegex := regexp.MustCompile(`(?i)\b(include|require)(_once)?\s*\(?\s*['"]([^'"]+)['"]\s*\)?`)
+    includeMatches := includeRegex.FindAllStringSubmatch(content, -1)
+    
+    for _, match := range includeMatches {
+    if len(match) >= 4 {
+        summary.Imports = append(summary.Imports, Import{Path: match[3]})
+    }
+    }
+    
+    // Parse classes
+    classRegex := regexp.MustCompile(`(?i)class\s+(\w+)(?:\s+extends\s+(\w+))?(?:\s+implements\s+([\w,\s]+))?`)
+    classMatches := classRegex.FindAllStringSubmatchIndex(content, -1)
+    
+    for _, match := range classMatches {
+    if len(match) >= 2 {
+        startPos := match[0]
+        // Find class name
+        nameStart := match[2]
+        nameEnd := match[3]
+        className := content[nameStart:nameEnd]
+        
+        // Anonymous classes are handled below
+        if phpAnonymousClassPrefixRegex.MatchString(content[:startPos]) {
+	continue
+        }
+        
+         lineNumber := countLines(content[:startPos])
+        
+        // Stop at the end of the class so the next class's methods stay out
+        block := content
+        if openBrace := strings.Index(content[startPos:], "{"); openBrace != -1 {
+            if bodyEnd := startPos + openBrace + 1 + len(phpFunctionBody(content, startPos)) + 1; bodyEnd < len(content) {
+                block = content[:bodyEnd]
+            }
+        }
+        
+        class := Struct{
+            Name:    className,
+            Fields:  extractPhpProperties(block, startPos),
+            Methods: extractPhpMethods(block, startPos, className),
+            Line:    lineNumber,
+        }
+        class.Doc = phpDocComment(content, startPos)
+        class.IsDeprecated, class.Deprecation = phpDeprecation(content, startPos)
+        if match[4] != -1 {
+            class.Bases = append(class.Bases, content[match[4]:match[5]])
+        }
+        if match[6] != -1 {
+            for _, intf := range strings.Split(content[match[6]:match[7]], ",") {
+                if intf = strings.TrimSpace(intf); intf != "" {
+                    class.Bases = append(class.Bases, intf)
+                }
+            }
+        }
+        
+        // Now extract properties and methods
+        summary.Classes = append(summary.Classes, class)
+    }
+    }
+    
+    // Parse anonymous classes, named after their parent the way PHP does
+    for _, match := range phpAnonymousClassRegex.FindAllStringSubmatchIndex(content, -1) {
+    startPos := match[0]
+    lineNumber := countLines(content[:startPos])
+    
+    className := "class@anonymous"
+    if match[2] != -1 {
+        className = strings.TrimPrefix(content[match[2]:match[3]], "\\") + "@anonymous"
+    }
+    className += ":" + strconv.Itoa(lineNumber)
+    
+    // Only look inside the class body
+    bodyEnd := match[1] + len(phpFunctionBody(content, match[1]-1)) + 1
+    if bodyEnd > len(content) {
+        bodyEnd = len(content)
+    }
+    block := content[:bodyEnd]
+    
+    class := Struct{
+        Name:    className,
+        Fields:  extractPhpProperties(block, startPos),
+        Methods: extractPhpMethods(block, startPos, className),
+        Line:    lineNumber,
+    }
+    if match[2] != -1 {
+        class.Bases = []string{strings.TrimPrefix(content[match[2]:match[3]], "\\")}
+    }
+    summary.Classes = append(summary.Classes, class)
+    }
+    
+    // Parse functions
+    functionRegex := regexp.MustCompile(`function\s+(\w+)\s*\((.*?)\)`)
+    functionMatches := functionRegex.FindAllStringSubmatchIndex(content, -1)
+    
+    for _, match := range functionMatches {
+    if len(match) >= 4 {
+        startPos := match[0]
+        
+        // Skip functions that are part of a class
+        if isWithinClass(content, startPos) {
+	continue
+        }
+        
+        // Find function name
+        nameStart := match[2]
+        nameEnd := match[3]
+        functionName := content[nameStart:nameEnd]
+        
+        // Find function arguments
+        argsStart := match[4]
+        argsEnd := match[5]
+        argsStr := content[argsStart:argsEnd]
+        
+        lineNumber := countLines(content[:startPos])
+        
+        function := Function{
+	Name: functionName,
+	Line: lineNumber,
+	Args: parsePhpFunctionArgs(argsStr, lineNumber),
+        }
+        function.Doc = phpDocComment(content, startPos)
+        function.IsDeprecated, function.Deprecation = phpDeprecation(content, startPos)
+        
+        // Extract function calls
+        function.Calls = extractPhpFunctionCalls(content, startPos)
+        phpSideEffects(&function, phpFunctionBody(content, startPos))
+        function.Complexity = scriptComplexity(maskScriptSource(phpFunctionBody(content, startPos), "php"), phpDecisionRegex)
+        function.EndLine = phpFunctionEndLine(content, startPos)
+        function.Statements, function.MaxNesting = braceBodyMetrics(maskScriptSource(phpFunctionBody(content, startPos), "php"))
+        
+        summary.Functions = append(summary.Functions, function)
+    }
+    }
+    
+    // Parse control flow
+    summary.ControlFlows = extractPhpControlFlow(content)
+    
+    // Parse outbound HTTP calls
+    summary.ExternalCalls = findExternalCalls(content, phpExternalCallPatterns)
+    
+    // Note minified or generated code, which yields little structure
+    summary.LikelyMinified, summary.ReadableSymbols = detectMinified(content)
+    
+    // Parse emitted and handled events
+    summary.EventsEmitted, summary.EventsHandled = findEvents(content, phpEventPatterns)
+    
+    // Parse environment variables read through getenv, $_ENV or env()
+    summary.EnvVars = findEnvVars(content, phpEnvVarRegex)
+    summary.SQLConcatenations = findSQLConcatenations(content, "php")
+    summary.SQLTables = findSQLTables(content)
+    
+    // Parse Laravel routes and their middleware
+    summary.Routes = extractPhpRoutes(content)
+    summary.RealtimeEndpoints = findRealtimeEndpoints(content, phpRealtimePatterns, withMethods(summary.Functions, summary.Classes), summary.Routes)
+    summary.ScheduledJobs = findScheduledJobs(content, phpSchedulePatterns)
+    
+    // Parse global variables
+    globalVarRegex := regexp.MustCompile(`\$(\w+)\s*=`)
+    globalVarMatches := globalVarRegex.FindAllStringSubmatchIndex(content, -1)
+    
+    for _, match := range globalVarMatches {
+    if len(match) >= 2 {
+        startPos := match[0]
+        
+        // Skip variables that are within functions or classes
+        if isWithinFunction(content, startPos) || isWithinClass(content, startPos) {
+	continue
+        }
+        
+        // Find variable name
+        nameStart := match[2]
+        nameEnd := match[3]
+        varName := content[nameStart:nameEnd]
+        
+        lineNumber := countLines(content[:startPos])
+        
+        variable := Variable{
+	Name:  "$" + varName,
+	Type:  "inferred",
+	Scope: "global",
+	Line:  lineNumber,
+	Mutations: countAssignments(content, "$" + varName),
+        }
+        
+        summary.Variables = append(summary.Variables, variable)
+    }
+    }
+    
+    return summary
+}
+
+// analyzePythonFile analyzes a Python file and returns a PythonFileSummary
+func analyzePythonFile(filePath string, indentSize int) PythonFileSummary {
+    
+    // Read file content
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading Python file %s: %v\n", filePath, err)
+        return PythonFileSummary{FilePath: filePath}
+    }
+    
+    return analyzePythonContent(filePath, normalizePythonIndentation(string(data), indentSize))
+}
+
+// detectPythonIndentUnit returns the most common indentation step between
+// space-indented lines, falling back to 4 when the file has no nesting
+func detectPythonIndentUnit(content string) int {
+    counts := make(map[int]int)
+    previous := 0
+    for _, line := range strings.Split(content, "\n") {
+        trimmed := strings.TrimSpace(line)
+        if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+            continue
+        }
+        leading := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+        if strings.Contains(leading, "\t") {
+            continue
+        }
+        indent := len(leading)
+        if indent > previous {
+            counts[indent-previous]++
+        }
+        previous = indent
+    }
+
+    unit, best := 4, 0
+    for step, count := range counts {
+        if count > best || (count == best && step < unit) {
+            unit, best = step, count
+        }
+    }
+    return unit
+}
+
+// normalizePythonIndentation expands leading tabs to spaces so that the
+// indentation comparisons see one consistent width. A tab counts as one indent
+// unit, detected from the file unless indentSize is given.
+func normalizePythonIndentation(content string, indentSize int) string {
+    if !strings.Contains(content, "\t") {
+        return content
+    }
+    if indentSize <= 0 {
+        indentSize = detectPythonIndentUnit(content)
+    }
+    tab := strings.Repeat(" ", indentSize)
+
+    lines := strings.Split(content, "\n")
+    for i, line := range lines {
+        leading := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+        if strings.Contains(leading, "\t") {
+            lines[i] = strings.ReplaceAll(leading, "\t", tab) + line[len(leading):]
+        }
+    }
+    return strings.Join(lines, "\n")
+}
+
+// analyzePythonContent analyzes Python source code and returns a PythonFileSummary
+func analyzePythonContent(filePath string, content string) PythonFileSummary {
+    summary := PythonFileSummary{
+        FilePath: filePath,
+    }
+    
+    // Parse imports
+    importRegexes := []*regexp.Regexp{
+        regexp.MustCompile(`(?m)^import\s+([^#\n]+)`),                 // import module
+        regexp.MustCompile(`(?m)^from\s+([^\s]+)\s+import\s+([^#\n]+)`), // from module import ...
+    }
+    
+    for _, regex := range importRegexes {
+        matches := regex.FindAllStringSubmatch(content, -1)
+        
+        for _, match := range matches {
+            if len(match) >= 2 {
+                importPath := strings.TrimSpace(match[1])
+                
+                // Handle multiple imports on a single line
+                if strings.Contains(importPath, ",") {
+                    for _, imp := range strings.Split(importPath, ",") {
+                        imp = strings.TrimSpace(imp)
+                        if imp != "" {
+                            summary.Imports = append(summary.Imports, Import{Path: imp})
+                        }
+                    }
+                } else {
+                    summary.Imports = append(summary.Imports, Import{Path: importPath})
+                }
+                
+                // If it's a 'from ... import' statement
+                if len(match) >= 3 {
+                    imports := strings.TrimSpace(match[2])
+                    if imports == "*" {
+                        // import all
+                        summary.Imports = append(summary.Imports, Import{Path: importPath + ".*"})
+                    } else if strings.Contains(imports, ",") {
+                        for _, imp := range strings.Split(imports, ",") {
+                            imp = strings.TrimSpace(imp)
+                            if imp != "" {
+                                summary.Imports = append(summary.Imports, Import{Path: importPath + "." + imp})
+                            }
+                        }
+                    } else {
+                        summary.Imports = append(summary.Imports, Import{Path: importPath + "." + imports})
+                    }
+                }
+            }
+        }
+    }
+    
+    // Parse classes
+    classRegex := regexp.MustCompile(`(?m)^class\s+(\w+)(?:\s*\(\s*([^)]*)\s*\))?:`)
+    classMatches := classRegex.FindAllStringSubmatchIndex(content, -1)
+    
+    for _, match := range classMatches {
+        if len(match) >= 2 {
+            startPos := match[0]
+            // Find class name
+            nameStart := match[2]
+            nameEnd := match[3]
+            className := content[nameStart:nameEnd]
+            
+            // Find parent classes if any
+            var parentClasses []string
+            if len(match) >= 6 && match[4] != -1 && match[5] != -1 {
+                parentsStr := content[match[4]:match[5]]
+                parents := strings.Split(parentsStr, ",")
+                
+                for _, parent := range parents {
+                    parent = strings.TrimSpace(parent)
+                    if parent != "" {
+                        parentClasses = append(parentClasses, parent)
+                    }
+                }
+            }
+            
+            lineNumber := countLines(content[:startPos])
+            
+            // Find class body (everything indented after the class declaration),
+            // stopping where the class ends so later classes' methods stay out
+            block := pythonClassBlock(content, lineNumber)
+            classBodyStart := match[1] + 1 // Skip the colon
+            if classBodyStart > len(block) {
+                classBodyStart = len(block)
+            }
+            
+            // Extract class methods and fields
+            class := Struct{
+                Name:    className,
+                Fields:  extractPythonClassFields(content, classBodyStart),
+                Methods: extractPythonClassMethods(block, classBodyStart, className),
+                Line:    lineNumber,
+            }
+            class.Doc = pythonDocstring(content, match[1])
+            class.IsDeprecated, class.Deprecation = pythonDeprecation(content, startPos, match[1])
+            class.Bases = pythonBaseClasses(parentClasses)
+            markPythonAbstractClass(&class, parentClasses)
+            
+            summary.Classes = append(summary.Classes, class)
+        }
+    }
+    
+    // Parse nested classes, attaching them to the enclosing top-level class
+    nestedClassRegex := regexp.MustCompile(`(?m)^[ \t]+class\s+(\w+)(?:\s*\(\s*([^)]*)\s*\))?:`)
+    for _, match := range nestedClassRegex.FindAllStringSubmatchIndex(content, -1) {
+        startPos := match[0]
+        lineNumber := countLines(content[:startPos])
+        className := content[match[2]:match[3]]
+        
+        scopes := pythonEnclosingScopes(content, lineNumber)
+        if len(scopes) == 0 {
+            continue
+        }
+        
+        // Qualify the name like __qualname__, e.g. "Outer.Inner" or "build.<locals>.Inner"
+        var qualified []string
+        for _, scope := range scopes {
+            qualified = append(qualified, scope.name)
+            if scope.isFunction {
+                qualified = append(qualified, "<locals>")
+            }
+        }
+        qualifiedName := strings.Join(append(qualified, className), ".")
+        
+        // Only look inside the nested class body
+        block := pythonClassBlock(content, lineNumber)
+        classBodyStart := match[1] + 1
+        if classBodyStart > len(block) {
+            classBodyStart = len(block)
+        }
+        
+        class := Struct{
+            Name:    qualifiedName,
+            Fields:  extractPythonClassFields(block, classBodyStart),
+            Methods: extractPythonClassMethods(block, classBodyStart, qualifiedName),
+            Line:    lineNumber,
+        }
+        class.Doc = pythonDocstring(content, match[1])
+        class.IsDeprecated, class.Deprecation = pythonDeprecation(content, startPos, match[1])
+        if match[4] != -1 {
+            class.Bases = pythonBaseClasses(strings.Split(content[match[4]:match[5]], ","))
+            markPythonAbstractClass(&class, strings.Split(content[match[4]:match[5]], ","))
+        }
+        
+        attached := false
+        if !scopes[0].isFunction {
+            for i := len(summary.Classes) - 1; i >= 0; i-- {
+                if summary.Classes[i].Name == scopes[0].name {
+                    summary.Classes[i].Nested = append(summary.Classes[i].Nested, class)
+                    attached = true
+                    break
+                }
+            }
+        }
+        if !attached {
+            summary.Classes = append(summary.Classes, class)
+        }
+    }
+    
+    // Parse functions (outside classes)
+    funcRegex := regexp.MustCompile(`(?m)^def\s+(\w+)\s*\(\s*(.*?)\s*\)\s*(?:->\s*[^:\n]+)?:`)
+    funcMatches := funcRegex.FindAllStringSubmatchIndex(content, -1)
+    
+    for _, match := range funcMatches {
+        if len(match) >= 4 {
+            startPos := match[0]
+            
+            // Skip methods (functions inside classes)
+            if isPythonMethod(content, startPos) {
+                continue
+            }
+            
+            // Find function name
+            nameStart := match[2]
+            nameEnd := match[3]
+            functionName := content[nameStart:nameEnd]
+            
+            // Find function arguments
+            argsStart := match[4]
+            argsEnd := match[5]
+            argsStr := content[argsStart:argsEnd]
+            
+            lineNumber := countLines(content[:startPos])
+            
+            // Extract decorators
+            decorators := extractPythonDecorators(content, startPos)
+            for _, decorator := range decorators {
+                summary.Decorators = appendIfNotExists(summary.Decorators, decorator)
+            }
+            
+            // Create function
+            function := Function{
+                Name: functionName,
+                Line: lineNumber,
+                Args: parsePythonFunctionArgs(argsStr, lineNumber),
+            }
+            function.Doc = pythonDocstring(content, match[1])
+            function.IsDeprecated, function.Deprecation = pythonDeprecation(content, startPos, match[1])
+            
+            // Extract return type hints if present
+            returnTypeHint := extractPythonReturnType(content, match[1])
+            if returnTypeHint != "" {
+                function.Returns = append(function.Returns, returnTypeHint)
+            }
+            
+            // Extract function calls and managed resources
+            function.Calls = extractPythonFunctionCalls(content, startPos)
+            function.ContextManagers = extractPythonContextManagers(pythonFunctionBody(content, startPos))
+            
+            // Calls and names hidden in f-string replacement fields
+            fstringCalls, fstringRefs := extractPythonFStrings(pythonFunctionBody(content, startPos))
+            for _, call := range fstringCalls {
+                function.Calls = appendIfNotExists(function.Calls, call)
+            }
+            function.FStringRefs = fstringRefs
+            pythonSideEffects(&function, pythonFunctionBody(content, startPos))
+            function.Complexity = scriptComplexity(maskScriptSource(pythonFunctionBody(content, startPos), "python"), pythonDecisionRegex)
+            function.EndLine = pythonBlockEndLine(content, function.Line)
+            function.Statements, function.MaxNesting = pythonBodyMetrics(maskScriptSource(pythonFunctionBody(content, startPos), "python"))
+            
+            summary.Functions = append(summary.Functions, function)
+        }
+    }
+    
+    // Parse control flow
+    summary.ControlFlows = extractPythonControlFlow(content)
+    
+    // Parse outbound HTTP calls
+    summary.ExternalCalls = findExternalCalls(content, pythonExternalCallPatterns)
+    
+    // Parse context managers
+    summary.ContextManagers = extractPythonContextManagers(content)
+    
+    // Parse emitted and handled events
+    summary.EventsEmitted, summary.EventsHandled = findEvents(content, pythonEventPatterns)
+    summary.EnvVars = findEnvVars(content, pythonEnvVarRegex)
+    summary.SQLConcatenations = findSQLConcatenations(content, "python")
+    summary.SQLTables = findSQLTables(content)
+    summary.Routes = extractPythonRoutes(content)
+    summary.RealtimeEndpoints = findRealtimeEndpoints(content, pythonRealtimePatterns, withMethods(summary.Functions, summary.Classes), summary.Routes)
+    summary.ScheduledJobs = findScheduledJobs(content, pythonSchedulePatterns)
+    
+    // Work out what is used from each import
+    explainPythonImports(summary.Imports, content)
+    
+    // Parse global variables
+    globalVarRegex := regexp.MustCompile(`(?m)^(\w+)\s*=`)
+    globalVarMatches := globalVarRegex.FindAllStringSubmatchIndex(content, -1)
+    
+    for _, match := range globalVarMatches {
+        if len(match) >= 2 {
+            startPos := match[0]
+            
+            // Skip variables that are within functions or classes
+            if isPythonWithinFunction(content, startPos) || isPythonWithinClass(content, startPos) {
+                continue
+            }
+            
+            // Find variable name
+            nameStart := match[2]
+            nameEnd := match[3]
+            varName := content[nameStart:nameEnd]
+            
+            // Skip if it's an import statement
+            if varName == "import" || varName == "from" {
+                continue
+            }
+            
+            lineNumber := countLines(content[:startPos])
+            
+            // Try to infer type from the assignment
+            varType := "inferred"
+            lineEnd := strings.Index(content[startPos:], "\n")
+            if lineEnd == -1 {
+                lineEnd = len(content) - startPos
+            }
+            
+            // Check for type hints (varname: Type = value)
+            typeHintRegex := regexp.MustCompile(`(\w+)\s*:\s*([^=]+)`)
+            typeHintMatch := typeHintRegex.FindStringSubmatch(content[startPos:startPos+lineEnd])
+            if len(typeHintMatch) >= 3 {
+                varType = strings.TrimSpace(typeHintMatch[2])
+            }
+            
+            variable := Variable{
+                Name:  varName,
+                Type:  varType,
+                Scope: "global",
+                Line:  lineNumber,
+                Mutations: countAssignments(content, varName),
+            }
+            
+            summary.Variables = append(summary.Variables, variable)
+        }
+    }
+    
+    return summary
+}
+
+// Helper functions for Python analysis
+
+// countAssignments counts plain, augmented and increment/decrement assignments to a
+// variable in the content, which for PHP and Python globals is the whole file
+func countAssignments(content string, name string) int {
+    assignRegex := regexp.MustCompile(`(?:^|[^\w$.>])` + regexp.QuoteMeta(name) +
+        `\s*(?:\+\+|--|(?::[^=\n]*)?(?:[-+*/%.|&^]|\*\*|//|<<|>>|\?\?)?=(?:[^=]|$))`)
+    return len(assignRegex.FindAllStringIndex(content, -1))
+}
+
+// extractPythonClassFields extracts class fields (attributes)
+func extractPythonClassFields(content string, classBodyStart int) []Variable {
+    var fields []Variable
+    
+    // Find lines that could contain class variables
+    fieldRegex := regexp.MustCompile(`(?m)^\s+(\w+)\s*=`)
+    fieldMatches := fieldRegex.FindAllStringSubmatchIndex(content[classBodyStart:], -1)
+    
+    for _, match := range fieldMatches {
+        if len(match) >= 2 {
+            startPos := classBodyStart + match[0]
+            
+            // Skip if within a method
+            if isPythonWithinMethod(content, startPos) {
+                continue
+            }
+            
+            // Find variable name
+            nameStart := match[2]
+            nameEnd := match[3]
+            fieldName := content[classBodyStart+nameStart:classBodyStart+nameEnd]
+            
+            lineNumber := countLines(content[:startPos])
+            
+            // Try to determine type from hints if present
+            fieldType := "inferred"
+            lineEnd := strings.Index(content[startPos:], "\n")
+            if lineEnd == -1 {
+                lineEnd = len(content) - startPos
+            }
+            
+            typeHintRegex := regexp.MustCompile(`(\w+)\s*:\s*([^=]+)`)
+            typeHintMatch := typeHintRegex.FindStringSubmatch(content[startPos:startPos+lineEnd])
+            if len(typeHintMatch) >= 3 {
+                fieldType = strings.TrimSpace(typeHintMatch[2])
+            }
+            
+            field := Variable{
+                Name:  fieldName,
+                Type:  fieldType,
+                Scope: "class",
+                Line:  lineNumber,
+            }
+            
+            fields = append(fields, field)
+        }
+    }
+    
+    return fields
+}
+
+// extractPythonClassMethods extracts methods from a Python class
+func extractPythonClassMethods(content string, classBodyStart int, className string) []Function {
+    var methods []Function
+    
+    // Find method definitions
+    // Annotated signatures ("-> T:") are matched too; abstract and Protocol methods nearly always have them
+    methodRegex := regexp.MustCompile(`(?m)^[ \t]+def\s+(\w+)\s*\(\s*(.*?)\s*\)\s*(?:->\s*[^:\n]+)?:`)
+    methodMatches := methodRegex.FindAllStringSubmatchIndex(content[classBodyStart:], -1)
+    
+    for _, match := range methodMatches {
+        if len(match) >= 4 {
+            startPos := classBodyStart + match[0]
+            
+            // Find method name
+            nameStart := match[2]
+            nameEnd := match[3]
+            methodName := content[classBodyStart+nameStart:classBodyStart+nameEnd]
+            
+            // Find method arguments
+            argsStart := match[4]
+            argsEnd := match[5]
+            argsStr := content[classBodyStart+argsStart:classBodyStart+argsEnd]
+            
+            lineNumber := countLines(content[:startPos])
+            
+            // Extract decorators
+            decorators := extractPythonDecorators(content, startPos)
+            
+            // Check if it's a static/class method
+            isStatic := false
+            isClassMethod := false
+            for _, dec := range decorators {
+                if dec == "staticmethod" {
+                    isStatic = true
+                } else if dec == "classmethod" {
+                    isClassMethod = true
+                }
+            }
+            
+            // Create method
+            method := Function{
+                Name:     methodName,
+                Receiver: className,
+                Line:     lineNumber,
+                Args:     parsePythonFunctionArgs(argsStr, lineNumber),
+                IsAbstract: isPythonAbstractMethod(content, startPos),
+            }
+            method.Doc = pythonDocstring(content, classBodyStart+match[1])
+            method.IsDeprecated, method.Deprecation = pythonDeprecation(content, startPos, classBodyStart+match[1])
+            
+            // Process 'self' or 'cls' parameter if present
+            if len(method.Args) > 0 {
+                if isStatic {
+                    // Static methods don't have self/cls
+                } else if isClassMethod {
+                    // Class methods have cls as first parameter
+                    if method.Args[0].Name == "cls" {
+                        method.Args = method.Args[1:]
+                    }
+                } else {
+                    // Instance methods have self as first parameter
+                    if method.Args[0].Name == "self" {
+                        method.Args = method.Args[1:]
+                    }
+                }
+            }
+            
+            // Extract return type hints if present
+            returnTypeHint := extractPythonReturnType(content, classBodyStart+match[1])
+            if returnTypeHint != "" {
+                method.Returns = append(method.Returns, returnTypeHint)
+            }
+            
+            // Extract function calls and managed resources
+            method.Calls = extractPythonFunctionCalls(content, startPos)
+            method.ContextManagers = extractPythonContextManagers(pythonFunctionBody(content, startPos))
+            fstringCalls, fstringRefs := extractPythonFStrings(pythonFunctionBody(content, startPos))
+            for _, call := range fstringCalls {
+                method.Calls = appendIfNotExists(method.Calls, call)
+            }
+            method.FStringRefs = fstringRefs
+            method.ReturnsField = returnedField(pythonFunctionBody(content, startPos), pythonGetterRegex)
+            for _, call := range pythonSuperCallRegex.FindAllStringSubmatch(pythonFunctionBody(content, startPos), -1) {
+                method.SuperCalls = appendIfNotExists(method.SuperCalls, call[1])
+            }
+            if !method.IsAbstract {
+                pythonSideEffects(&method, pythonFunctionBody(content, startPos))
+                method.Complexity = scriptComplexity(maskScriptSource(pythonFunctionBody(content, startPos), "python"), pythonDecisionRegex)
+                method.EndLine = pythonBlockEndLine(content, method.Line)
+                method.Statements, method.MaxNesting = pythonBodyMetrics(maskScriptSource(pythonFunctionBody(content, startPos), "python"))
+            }
+            
+            methods = append(methods, method)
+        }
+    }
+    
+    return methods
+}
+
+// parsePythonFunctionArgs parses Python function arguments
+func parsePythonFunctionArgs(argsStr string, lineNumber int) []Variable {
+    var args []Variable
+    
+    if argsStr == "" {
+        return args
+    }
+    
+    // Split arguments by comma
+    argsList := strings.Split(argsStr, ",")
+    
+    for _, arg := range argsList {
+        arg = strings.TrimSpace(arg)
+        if arg == "" {
+            continue
+        }
+        
+        // Parse parameter with default value
+        parts := strings.SplitN(arg, "=", 2)
+        paramStr := strings.TrimSpace(parts[0])
+        
+        // Check for type hints (param: Type)
+        typeHintParts := strings.SplitN(paramStr, ":", 2)
+        paramName := strings.TrimSpace(typeHintParts[0])
+        paramType := "Any"
+        
+        if len(typeHintParts) > 1 {
+            paramType = strings.TrimSpace(typeHintParts[1])
+        }
+        
+        // Skip *args and **kwargs syntax
+        if strings.HasPrefix(paramName, "*") {
+            continue
+        }
+        
+        args = append(args, Variable{
+            Name:  paramName,
+            Type:  paramType,
+            Scope: "parameter",
+            Line:  lineNumber,
+        })
+    }
+    
+    return args
+}
+
+// extractPythonDecorators extracts decorators for a Python function or method
+func extractPythonDecorators(content string, funcPos int) []string {
+    var decorators []string
+    
+    // Look backwards from the function definition to find decorators
+    searchStart := 0
+    if funcPos > 200 {
+        searchStart = funcPos - 200 // Look at most 200 chars back
+    }
+    
+    searchText := content[searchStart:funcPos]
+    
+    // Find all decorators before this function
+    decoratorRegex := regexp.MustCompile(`@(\w+)(?:\(.*?\))?`)
+    decoratorMatches := decoratorRegex.FindAllStringSubmatch(searchText, -1)
+    
+    for _, match := range decoratorMatches {
+        if len(match) >= 2 {
+            decorators = append(decorators, match[1])
+        }
+    }
+    
+    return decorators
+}
+
+// pythonClassBlock returns the content up to the end of the class declared on the given line
+func pythonClassBlock(content string, line int) string {
+    endLine := pythonBlockEndLine(content, line)
+    if lines := strings.SplitAfter(content, "\n"); endLine < len(lines) {
+        return strings.Join(lines[:endLine], "")
+    }
+    return content
+}
+
+// isPythonAbstractMethod reports whether the def at defPos carries an
+// @abstractmethod-style decorator on the lines directly above it
+func isPythonAbstractMethod(content string, defPos int) bool {
+    lineStart := strings.LastIndex(content[:defPos], "\n") + 1
+    for lineStart > 0 {
+        prevStart := strings.LastIndex(content[:lineStart-1], "\n") + 1
+        line := strings.TrimSpace(content[prevStart : lineStart-1])
+        if !strings.HasPrefix(line, "@") {
+            break
+        }
+        name := strings.TrimPrefix(strings.SplitN(line, "(", 2)[0], "@")
+        name = name[strings.LastIndex(name, ".")+1:]
+        switch name {
+        case "abstractmethod", "abstractproperty", "abstractclassmethod", "abstractstaticmethod":
+            return true
+        }
+        lineStart = prevStart
+    }
+    return false
+}
+
+// pythonDeprecation checks the decorators directly above the def or class at defPos
+// for @deprecated (PEP 702, or the deprecated package) and the docstring after bodyStart
+// for a deprecation note
+func pythonDeprecation(content string, defPos int, bodyStart int) (bool, string) {
+    lineStart := strings.LastIndex(content[:defPos], "\n") + 1
+    for lineStart > 0 {
+        prevStart := strings.LastIndex(content[:lineStart-1], "\n") + 1
+        line := strings.TrimSpace(content[prevStart : lineStart-1])
+        if !strings.HasPrefix(line, "@") {
+            break
+        }
+        name := strings.TrimPrefix(strings.SplitN(line, "(", 2)[0], "@")
+        if name[strings.LastIndex(name, ".")+1:] == "deprecated" {
+            message := ""
+            if quoted := deprecationQuoteRegex.FindStringSubmatch(line); quoted != nil {
+                message = quoted[1]
+            }
+            return true, message
+        }
+        lineStart = prevStart
+    }
+
+    return deprecationNote(pythonDocstring(content, bodyStart))
+}
+
+// pythonDocstring returns the triple-quoted docstring opening the body at bodyStart,
+// with its indentation removed the way inspect.cleandoc does
+func pythonDocstring(content string, bodyStart int) string {
+    body := strings.TrimLeft(content[bodyStart:], " \t\r\n")
+    for _, quote := range []string{`"""`, `'''`} {
+        if !strings.HasPrefix(body, quote) {
+            continue
+        }
+        end := strings.Index(body[3:], quote)
+        if end < 0 {
+            return ""
+        }
+        lines := strings.Split(strings.ReplaceAll(body[3:3+end], "\r\n", "\n"), "\n")
+        indent := -1
+        for _, line := range lines[1:] {
+            if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" {
+                if width := len(line) - len(trimmed); indent < 0 || width < indent {
+                    indent = width
+                }
+            }
+        }
+        lines[0] = strings.TrimSpace(lines[0])
+        for i := 1; i < len(lines); i++ {
+            if len(lines[i]) >= indent && indent > 0 {
+                lines[i] = lines[i][indent:]
+            }
+            lines[i] = strings.TrimRight(lines[i], " \t")
+        }
+        return strings.TrimSpace(strings.Join(lines, "\n"))
+    }
+    return ""
+}
+
+// pythonBaseClasses cleans up the bases of a class statement, leaving out keyword
+// arguments such as metaclass=ABCMeta
+func pythonBaseClasses(parents []string) []string {
+    var bases []string
+    for _, parent := range parents {
+        parent = strings.TrimSpace(parent)
+        if parent != "" && !strings.Contains(parent, "=") {
+            bases = append(bases, parent)
+        }
+    }
+    return bases
+}
+
+var (
+    pythonSuperCallRegex = regexp.MustCompile(`\bsuper\s*\([^)]*\)\s*\.\s*(\w+)\s*\(`)
+    phpParentCallRegex   = regexp.MustCompile(`(?i)\bparent\s*::\s*(\w+)\s*\(`)
+)
+
+// linkMethodOverrides sets Overrides on PHP, Python and JavaScript methods that redefine a method
+// of a base class, searching the bases depth first so the nearest definition wins
+func linkMethodOverrides(summary *Summary) {
+    link := func(classes map[string]Struct, types []Struct) {
+        var visit func(types []Struct)
+        visit = func(types []Struct) {
+            for i := range types {
+                for j := range types[i].Methods {
+                    types[i].Methods[j].Overrides = findOverridden(classes, types[i].Bases, types[i].Methods[j].Name, map[string]bool{baseClassName(types[i].Name): true})
+                }
+                visit(types[i].Nested)
+            }
+        }
+        visit(types)
+    }
+
+    // Classes are looked up by their unqualified name, e.g. "Base" for "models.Base"
+    phpClasses := make(map[string]Struct)
+    pythonClasses := make(map[string]Struct)
+    jsClasses := make(map[string]Struct)
+    var index func(classes map[string]Struct, types []Struct)
+    index = func(classes map[string]Struct, types []Struct) {
+        for _, t := range types {
+            classes[baseClassName(t.Name)] = t
+            index(classes, t.Nested)
+        }
+    }
+    for _, f := range summary.PhpFiles {
+        index(phpClasses, f.Classes)
+    }
+    for _, f := range summary.PythonFiles {
+        index(pythonClasses, f.Classes)
+    }
+    // TypeScript classes can extend JavaScript ones and the other way around
+    for _, f := range summary.JsFiles {
+        index(jsClasses, f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        index(jsClasses, f.Classes)
+    }
+
+    for i := range summary.PhpFiles {
+        link(phpClasses, summary.PhpFiles[i].Classes)
+    }
+    for i := range summary.PythonFiles {
+        link(pythonClasses, summary.PythonFiles[i].Classes)
+    }
+    for i := range summary.JsFiles {
+        link(jsClasses, summary.JsFiles[i].Classes)
+    }
+    for i := range summary.TsFiles {
+        link(jsClasses, summary.TsFiles[i].Classes)
+    }
+}
+
+// linkCallers fills CalledBy by inverting the call graph, so each function lists the
+// functions that call it wherever they are defined
+func linkCallers(summary *Summary) {
+    graph := buildCallGraph(*summary)
+    callers := make(map[string][]string)
+    key := func(filePath string, fn Function) string {
+        return fmt.Sprintf("%s\x00%s\x00%d", filePath, qualifiedFunctionName(fn), fn.Line)
+    }
+    for _, edge := range graph.edges {
+        caller, callee := graph.nodes[edge[0]], graph.nodes[edge[1]]
+        k := key(graph.files[callee.file], callee.fn)
+        callers[k] = append(callers[k], qualifiedFunctionName(caller.fn))
+    }
+    if len(callers) == 0 {
+        return
+    }
+
+    link := func(filePath string, functions []Function) {
+        for i := range functions {
+            if names := callers[key(filePath, functions[i])]; len(names) > 0 {
+                functions[i].CalledBy = removeDuplicatesAndSort(names)
+            }
+        }
+    }
+    linkTypes := func(filePath string, functions []Function, types []Struct) {
+        link(filePath, functions)
+        for i := range types {
+            link(filePath, types[i].Methods)
+        }
+    }
+    for i := range summary.GoFiles {
+        linkTypes(summary.GoFiles[i].FilePath, summary.GoFiles[i].Functions, summary.GoFiles[i].Structs)
+    }
+    for i := range summary.PhpFiles {
+        linkTypes(summary.PhpFiles[i].FilePath, summary.PhpFiles[i].Functions, summary.PhpFiles[i].Classes)
+    }
+    for i := range summary.PythonFiles {
+        linkTypes(summary.PythonFiles[i].FilePath, summary.PythonFiles[i].Functions, summary.PythonFiles[i].Classes)
+    }
+    for i := range summary.JsFiles {
+        linkTypes(summary.JsFiles[i].FilePath, summary.JsFiles[i].Functions, summary.JsFiles[i].Classes)
+    }
+    for i := range summary.TsFiles {
+        linkTypes(summary.TsFiles[i].FilePath, summary.TsFiles[i].Functions, summary.TsFiles[i].Classes)
+    }
+}
+
+// propagateSideEffects clears Pure on functions that reach a function with side effects
+// through the call graph, however many calls away. SideEffects keeps only the effects
+// of each function's own body.
+func propagateSideEffects(summary *Summary) {
+    graph := buildCallGraph(*summary)
+    impure := make([]bool, len(graph.nodes))
+    for i, node := range graph.nodes {
+        impure[i] = len(node.fn.SideEffects) > 0
+    }
+    for changed := true; changed; {
+        changed = false
+        for _, edge := range graph.edges {
+            if impure[edge[1]] && !impure[edge[0]] {
+                impure[edge[0]] = true
+                changed = true
+            }
+        }
+    }
+
+    key := func(filePath string, fn Function) string {
+        return fmt.Sprintf("%s\x00%s\x00%d", filePath, qualifiedFunctionName(fn), fn.Line)
+    }
+    reached := make(map[string]bool)
+    for i, node := range graph.nodes {
+        if impure[i] && node.fn.Pure {
+            reached[key(graph.files[node.file], node.fn)] = true
+        }
+    }
+    if len(reached) == 0 {
+        return
+    }
+
+    markImpure := func(filePath string, functions []Function) {
+        for i := range functions {
+            if reached[key(filePath, functions[i])] {
+                functions[i].Pure = false
+            }
+        }
+    }
+    clearTypes := func(filePath string, functions []Function, types []Struct) {
+        markImpure(filePath, functions)
+        for i := range types {
+            markImpure(filePath, types[i].Methods)
+        }
+    }
+    for i := range summary.GoFiles {
+        clearTypes(summary.GoFiles[i].FilePath, summary.GoFiles[i].Functions, summary.GoFiles[i].Structs)
+    }
+    for i := range summary.PhpFiles {
+        clearTypes(summary.PhpFiles[i].FilePath, summary.PhpFiles[i].Functions, summary.PhpFiles[i].Classes)
+    }
+    for i := range summary.PythonFiles {
+        clearTypes(summary.PythonFiles[i].FilePath, summary.PythonFiles[i].Functions, summary.PythonFiles[i].Classes)
+    }
+    for i := range summary.JsFiles {
+        clearTypes(summary.JsFiles[i].FilePath, summary.JsFiles[i].Functions, summary.JsFiles[i].Classes)
+    }
+    for i := range summary.TsFiles {
+        clearTypes(summary.TsFiles[i].FilePath, summary.TsFiles[i].Functions, summary.TsFiles[i].Classes)
+    }
+}
+
+// baseClassName strips namespaces, modules and generic parameters from a class reference
+func baseClassName(name string) string {
+    if bracket := strings.Index(name, "["); bracket >= 0 {
+        name = name[:bracket]
+    }
+    name = strings.TrimSpace(name)
+    return name[strings.LastIndexAny(name, ".\\")+1:]
+}
+
+// findOverridden returns the first base class, walking up the hierarchy, that defines
+// a method with the given name
+func findOverridden(classes map[string]Struct, bases []string, method string, seen map[string]bool) string {
+    for _, base := range bases {
+        name := baseClassName(base)
+        class, ok := classes[name]
+        if !ok || seen[name] {
+            continue
+        }
+        seen[name] = true
+        for _, m := range class.Methods {
+            if m.Name == method {
+                return class.Name
+            }
+        }
+        if found := findOverridden(classes, class.Bases, method, seen); found != "" {
+            return found
+        }
+    }
+    return ""
+}
+
+// markPythonAbstractClass flags ABCs and Protocols from a class's bases; a class
+// declaring abstract methods is abstract whatever its bases
+func markPythonAbstractClass(class *Struct, bases []string) {
+    for _, base := range bases {
+        base = strings.TrimSpace(base)
+        if strings.HasPrefix(base, "metaclass") && strings.HasSuffix(base, "ABCMeta") {
+            class.IsAbstract = true
+            continue
+        }
+        base = strings.SplitN(base, "[", 2)[0]
+        switch base[strings.LastIndex(base, ".")+1:] {
+        case "ABC":
+            class.IsAbstract = true
+        case "Protocol":
+            class.IsProtocol = true
+        }
+    }
+    for _, method := range class.Methods {
+        if method.IsAbstract {
+            class.IsAbstract = true
+        }
+    }
+}
+
+// extractPythonReturnType extracts the return type hint from a Python function
+func extractPythonReturnType(content string, funcEnd int) string {
+    // Look for "-> Type:" pattern in function signature
+    endOfLine := strings.Index(content[funcEnd:], "\n")
+    if endOfLine == -1 {
+        endOfLine = len(content) - funcEnd
+    }
+    
+    contextStart := funcEnd - 20 // Include some context before
+    if contextStart < 0 {
+        contextStart = 0
+    }
+    searchText := content[contextStart:funcEnd+endOfLine]
+    
+    returnTypeRegex := regexp.MustCompile(`->\s*([^:]+)`)
+    returnTypeMatch := returnTypeRegex.FindStringSubmatch(searchText)
+    
+    if len(returnTypeMatch) >= 2 {
+        return strings.TrimSpace(returnTypeMatch[1])
+    }
+    
+    return ""
+}
+
+var (
+    phpGetterRegex    = regexp.MustCompile(`^return\s+\$this->(\w+)\s*;$`)
+    pythonGetterRegex = regexp.MustCompile(`^return\s+self\.(\w+)$`)
+)
+
+// returnedField returns the field captured by getterRegex when a method body
+// consists of nothing but a single return statement, ignoring comments and docstrings
+func returnedField(body string, getterRegex *regexp.Regexp) string {
+    var statements []string
+    for _, line := range strings.Split(body, "\n") {
+        line = strings.TrimSpace(line)
+        if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+            continue
+        }
+        // Single-line docstrings and block comments
+        if (strings.HasPrefix(line, `"""`) && len(line) >= 6 && strings.HasSuffix(line, `"""`)) ||
+            (strings.HasPrefix(line, "/*") && strings.HasSuffix(line, "*/")) {
+            continue
+        }
+        statements = append(statements, line)
+    }
+
+    if len(statements) != 1 {
+        return ""
+    }
+    if match := getterRegex.FindStringSubmatch(statements[0]); match != nil {
+        return match[1]
+    }
+    return ""
+}
+
+// explainPythonImports records the members referenced through each import and flags unused
+// ones. "from m import a" yields imports "m" and "m.a"; the module entry counts as used
+// when any of its imported names is.
+func explainPythonImports(imports []Import, content string) {
+    // Ignore the import statements themselves
+    code := regexp.MustCompile(`(?m)^\s*(import|from)\s.*$`).ReplaceAllString(content, "")
+    
+    for i, imp := range imports {
+        path := imp.Path
+        if strings.HasSuffix(path, ".*") {
+            continue
+        }
+        
+        // "import numpy as np" is referred to as np
+        localName := path[strings.LastIndex(path, ".")+1:]
+        if parts := strings.SplitN(path, " as ", 2); len(parts) == 2 {
+            path, localName = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+        }
+        
+        for _, prefix := range []string{path, localName} {
+            memberRegex := regexp.MustCompile(`\b` + regexp.QuoteMeta(prefix) + `\.(\w+)`)
+            for _, match := range memberRegex.FindAllStringSubmatch(code, -1) {
+                imports[i].UsedSymbols = appendIfNotExists(imports[i].UsedSymbols, match[1])
+            }
+        }
+        used := len(imports[i].UsedSymbols) > 0 || regexp.MustCompile(`\b`+regexp.QuoteMeta(localName)+`\b`).MatchString(code)
+        imports[i].Unused = !used
+    }
+    
+    // Module entries of from-imports take their usage from the imported names
+    for i := range imports {
+        if !imports[i].Unused {
+            continue
+        }
+        for _, other := range imports {
+            if strings.HasPrefix(other.Path, imports[i].Path+".") && !other.Unused {
+                imports[i].Unused = false
+                break
+            }
+        }
+    }
+}
+
+// pythonFunctionBody returns the indented body of the Python function defined at funcPos
+func pythonFunctionBody(content string, funcPos int) string {
+    // Find the function body by detecting indentation
+    lines := strings.Split(content[funcPos:], "\n")
+    if len(lines) < 2 {
+        return ""
+    }
+    
+    // Determine body indentation level from the first non-empty line after the def
+    indentLevel := 0
+    bodyStartLine := 1
+    
+    for bodyStartLine < len(lines) {
+        line := lines[bodyStartLine]
+        if strings.TrimSpace(line) == "" {
+            bodyStartLine++
+            continue
+        }
+        
+        // Count leading spaces to determine indentation
+        indentLevel = len(line) - len(strings.TrimLeft(line, " \t"))
+        break
+    }
+    
+    if bodyStartLine >= len(lines) || indentLevel == 0 {
+        return ""
+    }
+    
+    // Extract the function body based on indentation
+    var bodyLines []string
+    
+    for i := bodyStartLine; i < len(lines); i++ {
+        line := lines[i]
+        if strings.TrimSpace(line) == "" {
+            continue
+        }
+        
+        currentIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+        if currentIndent < indentLevel {
+            break // End of function body
+        }
+        
+        bodyLines = append(bodyLines, line)
+    }
+    
+    return strings.Join(bodyLines, "\n")
+}
+
+// extractPythonFunctionCalls finds function calls within a Python function
+func extractPythonFunctionCalls(content string, funcPos int) []string {
+    var calls []string
+    
+    bodyText := pythonFunctionBody(content, funcPos)
+    if bodyText == "" {
+        return calls
+    }
+    
+    // Calls inside f-strings are left to extractPythonFStrings, which keeps them qualified
+    masked := []byte(bodyText)
+    for _, span := range pythonFStringLiterals(bodyText) {
+        for i := span[0]; i < span[1]; i++ {
+            if masked[i] != '\n' {
+                masked[i] = ' '
+            }
+        }
+    }
+    bodyText = string(masked)
+    
+    // Find direct function calls (name(...))
+    callRegex := regexp.MustCompile(`(\w+)\s*\(`)
+    callMatches := callRegex.FindAllStringSubmatch(bodyText, -1)
+    
+    for _, match := range callMatches {
+        if len(match) >= 2 {
+            funcName := match[1]
+            
+            // Skip Python built-ins and keywords
+            if isPythonKeywordOrBuiltin(funcName) {
+                continue
+            }
+            
+            calls = appendIfNotExists(calls, funcName)
+        }
+    }
+    
+    // Find method calls (obj.method(...))
+    methodCallRegex := regexp.MustCompile(`(\w+)\.(\w+)\s*\(`)
+    methodCallMatches := methodCallRegex.FindAllStringSubmatch(bodyText, -1)
+    
+    for _, match := range methodCallMatches {
+        if len(match) >= 3 {
+            objName := match[1]
+            methodName := match[2]
+            
+            // Skip Python built-ins
+            if isPythonKeywordOrBuiltin(methodName) {
+                continue
+            }
+            
+            calls = appendIfNotExists(calls, objName+"."+methodName)
+        }
+    }
+    
+    return calls
+}
+
+var (
+    pythonFStringStartRegex = regexp.MustCompile(`(?i)\b(?:rf|fr|f)("""|\'\'\'|"|')`)
+    pythonNestedStringRegex = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+    pythonNameChainRegex    = regexp.MustCompile(`[A-Za-z_]\w*(?:\s*\.\s*[A-Za-z_]\w*)*`)
+)
+
+// pythonFStringKeywords are words that can appear in replacement fields without naming anything
+var pythonFStringKeywords = map[string]bool{
+    "if": true, "else": true, "for": true, "in": true, "and": true, "or": true, "not": true,
+    "is": true, "None": true, "True": true, "False": true, "lambda": true, "await": true,
+}
+
+// pythonFStringLiterals returns the start and end offsets of the text between the quotes
+// of each f-string literal in body
+func pythonFStringLiterals(body string) [][2]int {
+    var spans [][2]int
+
+    for _, match := range pythonFStringStartRegex.FindAllStringSubmatchIndex(body, -1) {
+        quote := body[match[2]:match[3]]
+        raw := strings.ContainsAny(body[match[0]:match[2]], "rR")
+        literalEnd := strings.Index(body[match[1]:], quote)
+        for !raw && literalEnd > 0 && body[match[1]+literalEnd-1] == '\\' {
+            next := strings.Index(body[match[1]+literalEnd+1:], quote)
+            if next < 0 {
+                literalEnd = -1
+                break
+            }
+            literalEnd += next + 1
+        }
+        if literalEnd < 0 {
+            continue
+        }
+        spans = append(spans, [2]int{match[1], match[1] + literalEnd})
+    }
+
+    return spans
+}
+
+// extractPythonFStrings parses the f-string literals in a body and returns the calls
+// made and the names referenced inside their {replacement fields}, leaving out
+// conversions and format specs
+func extractPythonFStrings(body string) ([]string, []string) {
+    var calls, refs []string
+
+    for _, span := range pythonFStringLiterals(body) {
+        literal := body[span[0]:span[1]]
+
+        // Walk the replacement fields, skipping {{ and }} escapes
+        for i := 0; i < len(literal); i++ {
+            if literal[i] != '{' {
+                continue
+            }
+            if i+1 < len(literal) && literal[i+1] == '{' {
+                i++
+                continue
+            }
+            depth, end := 1, i+1
+            for ; end < len(literal) && depth > 0; end++ {
+                switch literal[end] {
+                case '{', '[', '(':
+                    depth++
+                case '}', ']', ')':
+                    depth--
+                }
+            }
+            expr := literal[i+1 : end-1]
+            i = end - 1
+
+            // Drop the !conversion and :format spec at the top level
+            expr = pythonNestedStringRegex.ReplaceAllString(expr, `""`)
+            nesting := 0
+            for j, c := range expr {
+                if c == '(' || c == '[' || c == '{' {
+                    nesting++
+                } else if c == ')' || c == ']' || c == '}' {
+                    nesting--
+                } else if nesting == 0 && (c == '!' && !strings.HasPrefix(expr[j:], "!=") || c == ':') {
+                    expr = expr[:j]
+                    break
+                }
+            }
+
+            for _, name := range pythonNameChainRegex.FindAllStringIndex(expr, -1) {
+                chain := strings.Join(strings.Fields(expr[name[0]:name[1]]), "")
+                if pythonFStringKeywords[chain] || name[0] > 0 && expr[name[0]-1] == '.' {
+                    continue
+                }
+                rest := strings.TrimSpace(expr[name[1]:])
+                if !strings.HasPrefix(rest, "(") {
+                    refs = appendIfNotExists(refs, chain)
+                    continue
+                }
+                // Record calls as "func" or "obj.method", like the body call extractor
+                parts := strings.Split(chain, ".")
+                callee := parts[len(parts)-1]
+                if isPythonKeywordOrBuiltin(callee) {
+                    continue
+                }
+                if len(parts) > 1 {
+                    calls = appendIfNotExists(calls, parts[len(parts)-2]+"."+callee)
+                    refs = appendIfNotExists(refs, strings.Join(parts[:len(parts)-1], "."))
+                } else {
+                    calls = appendIfNotExists(calls, callee)
+                }
+            }
+        }
+    }
+
+    return calls, refs
+}
+
+// extractPythonContextManagers finds the context managers entered by "with" statements,
+// returning each as its expression plus the bound variable (e.g. "open(path) as f")
+func extractPythonContextManagers(content string) []string {
+    var managers []string
+    
+    withRegex := regexp.MustCompile(`(?m)^[ \t]*(?:async\s+)?with\s+(.+?):[ \t]*(?:#.*)?$`)
+    for _, match := range withRegex.FindAllStringSubmatch(content, -1) {
+        // Split multiple managers on top-level commas only
+        depth := 0
+        start := 0
+        items := match[1] + ","
+        for i, ch := range items {
+            switch ch {
+            case '(', '[', '{':
+                depth++
+            case ')', ']', '}':
+                depth--
+            case ',':
+                if depth == 0 {
+                    item := strings.Join(strings.Fields(items[start:i]), " ")
+                    if item != "" {
+                        managers = appendIfNotExists(managers, item)
+                    }
+                    start = i + 1
+                }
+            }
+        }
+    }
+    
+    return managers
+}
+
+// extractPythonControlFlow finds control flow structures in Python code
+func extractPythonControlFlow(content string) []ControlFlow {
+    var controls []ControlFlow
+    
+    // Define regex patterns for control structures
+    patterns := map[string]*regexp.Regexp{
+        "if":      regexp.MustCompile(`(?m)^(\s*)if\s+.+:`),
+        "for":     regexp.MustCompile(`(?m)^(\s*)for\s+.+:`),
+        "while":   regexp.MustCompile(`(?m)^(\s*)while\s+.+:`),
+        "try":     regexp.MustCompile(`(?m)^(\s*)try\s*:`),
+        "with":    regexp.MustCompile(`(?m)^(\s*)with\s+.+:`),
+    }
+    
+    for _, controlType := range sortedKeys(patterns) {
+        pattern := patterns[controlType]
+        matches := pattern.FindAllStringSubmatchIndex(content, -1)
+        
+        for _, match := range matches {
+            if len(match) >= 2 {
+                startPos := match[0]
+                lineNumber := countLines(content[:startPos])
+                
+                // Determine indentation level to find nested structures
+                indentStart := match[2]
+                indentEnd := match[3]
+                indentation := content[indentStart:indentEnd]
+                
+                control := ControlFlow{
+                    Type: controlType,
+                    Line: lineNumber,
+                    EndLine: pythonBlockEndLine(content, lineNumber),
+                }
+                
+                // Find nested control structures
+                children := findNestedPythonControlFlow(content, startPos, len(indentation))
+                if len(children) > 0 {
+                    control.Children = children
+                }
+                
+                controls = append(controls, control)
+            }
+        }
+    }
+    
+    return controls
+}
+
+// findNestedPythonControlFlow identifies nested control structures in Python
+func findNestedPythonControlFlow(content string, startPos int, parentIndent int) []ControlFlow {
+    var nested []ControlFlow
+    
+    // Define regex patterns for control structures
+    patterns := map[string]*regexp.Regexp{
+        "if":      regexp.MustCompile(`(?m)^(\s*)if\s+.+:`),
+        "for":     regexp.MustCompile(`(?m)^(\s*)for\s+.+:`),
+        "while":   regexp.MustCompile(`(?m)^(\s*)while\s+.+:`),
+        "try":     regexp.MustCompile(`(?m)^(\s*)try\s*:`),
+        "with":    regexp.MustCompile(`(?m)^(\s*)with\s+.+:`),
+    }
+    
+    // Find end of parent block
+    endPos := len(content)
+    lines := strings.Split(content[startPos:], "\n")
+    if len(lines) > 1 {
+        // Skip the first line (current control statement)
+        linePos := startPos + len(lines[0]) + 1
+        for i := 1; i < len(lines); i++ {
+            line := lines[i]
+            if strings.TrimSpace(line) == "" {
+                linePos += len(line) + 1
+                continue
+            }
+            
+            currentIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+            if currentIndent <= parentIndent {
+                endPos = linePos
+                break // End of parent block
+            }
+            
+            linePos += len(line) + 1
+        }
+    }
+    
+    // Make sure endPos is within bounds
+    if endPos > len(content) {
+        endPos = len(content)
+    }
+    
+    // Make sure startPos is within bounds and startPos <= endPos
+    if startPos >= len(content) {
+        return nested
+    }
+    
+    if startPos > endPos {
+        return nested
+    }
+    
+    // Look for nested control structures within the parent block
+    blockContent := content[startPos:endPos]
+    
+    for _, controlType := range sortedKeys(patterns) {
+        pattern := patterns[controlType]
+        matches := pattern.FindAllStringSubmatchIndex(blockContent, -1)
+        
+        for _, match := range matches {
+            if len(match) >= 4 {
+                nestedStartPos := startPos + match[0]
+                
+                // Get indentation of nested control
+                nestedIndentStart := match[2]
+                nestedIndentEnd := match[3]
+                
+                // Make sure indices are within bounds
+                if nestedIndentStart < 0 || nestedIndentEnd > len(blockContent) || nestedIndentStart > nestedIndentEnd {
+                    continue
+                }
+                
+                nestedIndent := len(blockContent[nestedIndentStart:nestedIndentEnd])
+                
+                // Ensure it's actually nested (more indented than parent)
+                if nestedIndent <= parentIndent {
+                    continue
+                }
+                
+                lineNumber := countLines(content[:nestedStartPos])
+                
+                control := ControlFlow{
+                    Type: controlType,
+                    Line: lineNumber,
+                    EndLine: pythonBlockEndLine(content, lineNumber),
+                }
+                
+                // Find nested control flow (recursively)
+                children := findNestedPythonControlFlow(content, nestedStartPos, nestedIndent)
+                if len(children) > 0 {
+                    control.Children = children
+                }
+                
+                nested = append(nested, control)
+            }
+        }
+    }
+    
+    return nested
+}
+
+// pythonScope is a class or function enclosing a line of Python code
+type pythonScope struct {
+    name       string
+    isFunction bool
+}
+
+// pythonEnclosingScopes returns the classes and functions enclosing the given line, outermost first
+func pythonEnclosingScopes(content string, line int) []pythonScope {
+    var scopes []pythonScope
+    lines := strings.Split(content, "\n")
+    if line < 1 || line > len(lines) {
+        return scopes
+    }
+    
+    scopeRegex := regexp.MustCompile(`^\s*(class|def|async\s+def)\s+(\w+)`)
+    indent := len(lines[line-1]) - len(strings.TrimLeft(lines[line-1], " \t"))
+    for i := line - 2; i >= 0 && indent > 0; i-- {
+        trimmed := strings.TrimSpace(lines[i])
+        if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+            continue
+        }
+        lineIndent := len(lines[i]) - len(strings.TrimLeft(lines[i], " \t"))
+        if lineIndent >= indent {
+            continue
+        }
+        
+        // Every less indented line opens an enclosing block, but only classes and functions are scopes
+        indent = lineIndent
+        if match := scopeRegex.FindStringSubmatch(lines[i]); match != nil {
+            scopes = append([]pythonScope{{name: match[2], isFunction: match[1] != "class"}}, scopes...)
+        }
+    }
+    
+    return scopes
+}
+
+// pythonBlockEndLine returns the last line of the indented block opened at the
+// given line, or the line itself when the block has no indented body
+func pythonBlockEndLine(content string, line int) int {
+    lines := strings.Split(content, "\n")
+
+    // The match may start on blank lines preceding the statement
+    start := line - 1
+    for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+        start++
+    }
+    if start >= len(lines) {
+        return line
+    }
+    indent := len(lines[start]) - len(strings.TrimLeft(lines[start], " \t"))
+
+    end := start + 1
+    for i := start + 1; i < len(lines); i++ {
+        if strings.TrimSpace(lines[i]) == "" {
+            continue
+        }
+        if len(lines[i])-len(strings.TrimLeft(lines[i], " \t")) <= indent {
+            break
+        }
+        end = i + 1
+    }
+    return end
+}
+
+// isPythonMethod checks if a function definition is inside a class
+func isPythonMethod(content string, pos int) bool {
+    // Get the line containing the function definition
+    lineStart := strings.LastIndex(content[:pos], "\n")
+    if lineStart == -1 {
+        lineStart = 0
+    } else {
+        lineStart++
+    }
+    
+    line := content[lineStart:pos]
+    
+    // Count indentation
+    indentLevel := len(line) - len(strings.TrimLeft(line, " \t"))
+    
+    // Functions at root level have no indentation
+    return indentLevel > 0
+}
+
+// isPythonWithinFunction checks if a position is inside a function
+// isPythonWithinFunction checks if a position is inside a function
+func isPythonWithinFunction(content string, pos int) bool {
+    // Look backwards for the nearest function definition at the same or lower indentation
+    lineStart := strings.LastIndex(content[:pos], "\n")
+    if lineStart == -1 {
+        lineStart = 0
+    } else {
+        lineStart++
+    }
+    
+    currentLine := content[lineStart:pos]
+    currentIndent := len(currentLine) - len(strings.TrimLeft(currentLine, " \t"))
+    
+    // Look back through the file to find the nearest def or class
+    lines := strings.Split(content[:lineStart], "\n")
+    
+    for i := len(lines) - 1; i >= 0; i-- {
+        line := lines[i]
+        if strings.TrimSpace(line) == "" {
+            continue
+        }
+        
+        lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+        
+        // If we find a line with less or equal indentation...
+        if lineIndent <= currentIndent {
+            // If it's a function definition, we're inside a function
+            trimmedLine := strings.TrimSpace(line)
+            if len(trimmedLine) >= 3 && trimmedLine[:3] == "def" {
+                return true
+            }
+            
+            // If we hit another block with less indentation, we're not in a function
+            if lineIndent < currentIndent && len(trimmedLine) > 0 && strings.HasSuffix(trimmedLine, ":") {
+                return false
+            }
+        }
+    }
+    
+    return false
+}
+
+// isPythonWithinClass checks if a position is inside a class
+func isPythonWithinClass(content string, pos int) bool {
+    // Look backwards for the nearest class definition at the same or lower indentation
+    lineStart := strings.LastIndex(content[:pos], "\n")
+    if lineStart == -1 {
+        lineStart = 0
+    } else {
+        lineStart++
+    }
+    
+    currentLine := content[lineStart:pos]
+    currentIndent := len(currentLine) - len(strings.TrimLeft(currentLine, " \t"))
+    
+    // Look back through the file to find the nearest def or class
+    lines := strings.Split(content[:lineStart], "\n")
+    
+    for i := len(lines) - 1; i >= 0; i-- {
+        line := lines[i]
+        if strings.TrimSpace(line) == "" {
+            continue
+        }
+        
+        lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+        
+        // If we find a line with less or equal indentation...
+        if lineIndent < currentIndent {
+            // If it's a class definition, we're inside a class
+            if strings.TrimSpace(line)[:5] == "class" {
+                return true
+            }
+            
+            // If we hit another block with less indentation, we're not in a class
+            if strings.HasSuffix(strings.TrimSpace(line), ":") {
+                return false
+            }
+        }
+    }
+    
+    return false
+}
+
+// isPythonWithinMethod checks if a position is inside a method
+func isPythonWithinMethod(content string, pos int) bool {
+    // First check if we're inside a function
+    if !isPythonWithinFunction(content, pos) {
+        return false
+    }
+    
+    // Now check if we're inside a class
+    return isPythonWithinClass(content, pos)
+}
+
+// isPythonKeywordOrBuiltin checks if a name is a Python keyword or builtin
+func isPythonKeywordOrBuiltin(name string) bool {
+    keywords := map[string]bool{
+        "False": true, "None": true, "True": true, "and": true, "as": true,
+        "assert": true, "async": true, "await": true, "break": true, "class": true,
+        "continue": true, "def": true, "del": true, "elif": true, "else": true,
+        "except": true, "finally": true, "for": true, "from": true, "global": true,
+        "if": true, "import": true, "in": true, "is": true, "lambda": true,
+        "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+        "return": true, "try": true, "while": true, "with": true, "yield": true,
+    }
+    
+    builtins := map[string]bool{
+        "abs": true, "all": true, "any": true, "bin": true, "bool": true,
+        "chr": true, "dict": true, "dir": true, "divmod": true, "enumerate": true,
+        "filter": true, "float": true, "format": true, "frozenset": true,
+        "getattr": true, "hasattr": true, "hash": true, "hex": true, "id": true,
+        "input": true, "int": true, "isinstance": true, "issubclass": true,
+        "iter": true, "len": true, "list": true, "map": true, "max": true,
+        "min": true, "next": true, "oct": true, "open": true, "ord": true,
+        "pow": true, "print": true, "property": true, "range": true, "repr": true,
+        "reversed": true, "round": true, "set": true, "slice": true, "sorted": true,
+        "staticmethod": true, "str": true, "sum": true, "super": true, "tuple": true,
+        "type": true, "vars": true, "zip": true,
+    }
+    
+    return keywords[name] || builtins[name]
+}
+
+// Helper functions for PHP analysis
+
+// extractPhpProperties finds class properties in PHP code
+func extractPhpProperties(content string, classStartPos int) []Variable {
+    var properties []Variable
+    
+    // Find the class body
+    openBracePos := strings.Index(content[classStartPos:], "{")
+    if openBracePos == -1 {
+    return properties
+    }
+    
+    classBodyStart := classStartPos + openBracePos + 1
+    
+    // Find property declarations (public, protected, private)
+    propertyRegex := regexp.MustCompile(`(?i)(public|protected|private)\s+(\$\w+)`)
+    propertyMatches := propertyRegex.FindAllStringSubmatchIndex(content[classBodyStart:], -1)
+    
+    for _, match := range propertyMatches {
+    if len(match) >= 4 {
+        propPos := classBodyStart + match[0]
+        
+        // Skip if this is within a method
+        if isWithinMethod(content, propPos) {
+	continue
+        }
+        
+        // Find visibility
+        visibilityStart := match[2]
+        visibilityEnd := match[3]
+        visibility := content[classBodyStart+visibilityStart:classBodyStart+visibilityEnd]
+        
+        // Find property name
+        nameStart := match[4]
+        nameEnd := match[5]
+        propName := content[classBodyStart+nameStart:classBodyStart+nameEnd]
+        
+        lineNumber := countLines(content[:propPos])
+        
+        property := Variable{
+	Name:  propName,
+	Type:  "inferred",
+	Scope: visibility,
+	Line:  lineNumber,
+        }
+        
+        properties = append(properties, property)
+    }
+    }
+    
+    return properties
+}
+
+// exprToString converts an ast.Expr to a string representation
+func exprToString(expr ast.Expr) string {
+    switch t := expr.(type) {
+    case *ast.Ident:
+        return t.Name
+    case *ast.SelectorExpr:
+        return exprToString(t.X) + "." + t.Sel.Name
+    case *ast.StarExpr:
+        return "*" + exprToString(t.X)
+    case *ast.ArrayType:
+        if t.Len == nil {
+            return "[]" + exprToString(t.Elt)
+        }
+        return "[" + exprToString(t.Len) + "]" + exprToString(t.Elt)
+    case *ast.BasicLit:
+        return t.Value
+    case *ast.MapType:
+        return "map[" + exprToString(t.Key) + "]" + exprToString(t.Value)
+    case *ast.InterfaceType:
+        return "interface{}"
+    case *ast.FuncType:
+        var params []string
+        if t.Params != nil {
+            for _, field := range t.Params.List {
+                // Parameter names are dropped, so func(a, b int) becomes func(int, int)
+                for i := 0; i < max(1, len(field.Names)); i++ {
+                    params = append(params, exprToString(field.Type))
+                }
+            }
+        }
+        signature := "func(" + strings.Join(params, ", ") + ")"
+        if results := goResults(t.Results, false); len(results) == 1 {
+            signature += " " + results[0]
+        } else if len(results) > 1 {
+            signature += " (" + strings.Join(results, ", ") + ")"
+        }
+        return signature
+    case *ast.ChanType:
+        switch t.Dir {
+        case ast.SEND:
+            return "chan<- " + exprToString(t.Value)
+        case ast.RECV:
+            return "<-chan " + exprToString(t.Value)
+        }
+        return "chan " + exprToString(t.Value)
+    case *ast.ParenExpr:
+        return "(" + exprToString(t.X) + ")"
+    case *ast.IndexExpr:
+        // Instantiated generic type or function, e.g. List[T]
+        return exprToString(t.X) + "[" + exprToString(t.Index) + "]"
+    case *ast.IndexListExpr:
+        var indices []string
+        for _, index := range t.Indices {
+            indices = append(indices, exprToString(index))
+        }
+        return exprToString(t.X) + "[" + strings.Join(indices, ", ") + "]"
+    case *ast.StructType:
+        return "struct{}"
+    case *ast.Ellipsis:
+        return "..." + exprToString(t.Elt)
+    case *ast.BinaryExpr:
+        // Union of constraint terms, e.g. ~int | ~float64
+        return exprToString(t.X) + " " + t.Op.String() + " " + exprToString(t.Y)
+    case *ast.UnaryExpr:
+        return t.Op.String() + exprToString(t.X)
+    default:
+        return fmt.Sprintf("<%T>", expr)
+    }
+}
+
+// extractPhpMethods finds methods in a PHP class
+func extractPhpMethods(content string, classStartPos int, className string) []Function {
+    var methods []Function
+    
+    // Find the class body
+    openBracePos := strings.Index(content[classStartPos:], "{")
+    if openBracePos == -1 {
+    return methods
+    }
+    
+    classBodyStart := classStartPos + openBracePos + 1
+    
+    // Find method declarations
+    methodRegex := regexp.MustCompile(`(?i)((?:(?:public|protected|private|static|abstract|final)\s+)*)function\s+(\w+)\s*\((.*?)\)`)
+    methodMatches := methodRegex.FindAllStringSubmatchIndex(content[classBodyStart:], -1)
+    
+    for _, match := range methodMatches {
+    if len(match) >= 6 {
+        methodPos := classBodyStart + match[0]
+        
+        // Find method name
+        nameStart := match[4]
+        nameEnd := match[5]
+        methodName := content[classBodyStart+nameStart:classBodyStart+nameEnd]
+        
+        // Find args
+        argsStart := match[6]
+        argsEnd := match[7]
+        argsStr := content[classBodyStart+argsStart:classBodyStart+argsEnd]
+        
+        lineNumber := countLines(content[:methodPos])
+        
+        method := Function{
+	Name:     methodName,
+	Receiver: className,
+	Line:     lineNumber,
+	Args:     parsePhpFunctionArgs(argsStr, lineNumber),
+        }
+        
+        // Record modifiers in declaration order
+        for _, modifier := range strings.Fields(strings.ToLower(content[classBodyStart+match[2]:classBodyStart+match[3]])) {
+	method.Modifiers = append(method.Modifiers, modifier)
+	switch modifier {
+	case "static":
+	    method.IsStatic = true
+	case "abstract":
+	    method.IsAbstract = true
+	}
+        }
+        method.Doc = phpDocComment(content, methodPos)
+        method.IsDeprecated, method.Deprecation = phpDeprecation(content, methodPos)
+        
+        // Record explicit calls to the parent implementation
+        if !method.IsAbstract {
+            for _, call := range phpParentCallRegex.FindAllStringSubmatch(phpFunctionBody(content, methodPos), -1) {
+                method.SuperCalls = appendIfNotExists(method.SuperCalls, call[1])
+            }
+        }
+        
+        // Abstract methods have no body to look into
+        if method.IsAbstract {
+	methods = append(methods, method)
+	continue
+        }
+        
+        // Extract function calls and getter fields
+        method.Calls = extractPhpFunctionCalls(content, methodPos)
+        method.ReturnsField = returnedField(phpFunctionBody(content, methodPos), phpGetterRegex)
+        phpSideEffects(&method, phpFunctionBody(content, methodPos))
+        method.Complexity = scriptComplexity(maskScriptSource(phpFunctionBody(content, methodPos), "php"), phpDecisionRegex)
+        method.EndLine = phpFunctionEndLine(content, methodPos)
+        method.Statements, method.MaxNesting = braceBodyMetrics(maskScriptSource(phpFunctionBody(content, methodPos), "php"))
+        
+        methods = append(methods, method)
+    }
+    }
+    
+    return methods
+}
+
+// phpDeprecation checks the docblock and attributes in front of the declaration at
+// declPos for @deprecated or a #[Deprecated] attribute
+func phpDeprecation(content string, declPos int) (bool, string) {
+    docblock, attributes := phpDocBlock(content, declPos)
+    for _, line := range attributes {
+        if strings.Contains(line, "Deprecated") {
+            message := ""
+            if quoted := deprecationQuoteRegex.FindStringSubmatch(line); quoted != nil {
+                message = quoted[1]
+            }
+            return true, message
+        }
+    }
+    return deprecationNote(docblock)
+}
+
+// phpDocBlock returns the /** */ docblock in front of the declaration at declPos, also
+// used for JSDoc, and the #[...] attribute lines passed over on the way up to it
+func phpDocBlock(content string, declPos int) (string, []string) {
+    // Modifiers such as "public static" may sit between the docblock and the declaration
+    before := strings.TrimRight(content[:strings.LastIndex(content[:declPos], "\n")+1], " \t\r\n")
+    var attributes []string
+    for {
+        lineStart := strings.LastIndex(before, "\n") + 1
+        line := strings.TrimSpace(before[lineStart:])
+        if !strings.HasPrefix(line, "#[") {
+            break
+        }
+        attributes = append(attributes, line)
+        before = strings.TrimRight(before[:lineStart], " \t\r\n")
+    }
+    if strings.HasSuffix(before, "*/") {
+        if start := strings.LastIndex(before, "/**"); start >= 0 {
+            return before[start:], attributes
+        }
+    }
+    return "", attributes
+}
+
+// phpDocComment returns the text of the docblock in front of the declaration at declPos
+// without the comment markers and leading asterisks
+func phpDocComment(content string, declPos int) string {
+    docblock, _ := phpDocBlock(content, declPos)
+    if docblock == "" {
+        return ""
+    }
+    lines := strings.Split(strings.TrimSuffix(strings.TrimPrefix(docblock, "/**"), "*/"), "\n")
+    for i, line := range lines {
+        line = strings.TrimSpace(line)
+        if strings.HasPrefix(line, "*") {
+            line = strings.TrimPrefix(strings.TrimPrefix(line, "*"), " ")
+        }
+        lines[i] = line
+    }
+    return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// parsePhpFunctionArgs parses PHP function arguments
+func parsePhpFunctionArgs(argsStr string, lineNumber int) []Variable {
+    var args []Variable
+    
+    // Split arguments by comma
+    argsSplit := strings.Split(argsStr, ",")
+    
+    for _, arg := range argsSplit {
+    arg = strings.TrimSpace(arg)
+    if arg == "" {
+        continue
+    }
+    
+    // Check for type hint
+    typeParts := strings.Fields(arg)
+    
+    if len(typeParts) >= 2 && strings.HasPrefix(typeParts[len(typeParts)-1], "$") {
+        // Has type hint
+        varName := typeParts[len(typeParts)-1]
+        typeHint := strings.Join(typeParts[:len(typeParts)-1], " ")
+        
+        args = append(args, Variable{
+	Name:  varName,
+	Type:  typeHint,
+	Scope: "parameter",
+	Line:  lineNumber,
+        })
+    } else if strings.HasPrefix(arg, "$") {
+        // No type hint
+        args = append(args, Variable{
+	Name:  arg,
+	Type:  "mixed",
+	Scope: "parameter",
+	Line:  lineNumber,
+        })
+    }
+    }
+    
+    return args
+}
+
+var (
+    phpAnonymousClassRegex       = regexp.MustCompile(`(?i)\bnew\s+class\b\s*(?:\([^)]*\))?(?:\s+extends\s+([\w\\]+))?(?:\s+implements\s+[\w\\,\s]+)?\s*\{`)
+    phpAnonymousClassPrefixRegex = regexp.MustCompile(`(?i)\bnew\s+$`)
+)
+
+// phpFunctionBody returns the text between the braces of the PHP function starting at funcStartPos
+func phpFunctionBody(content string, funcStartPos int) string {
+    // Find the function body
+    openBracePos := strings.Index(content[funcStartPos:], "{")
+    if openBracePos == -1 {
+    return ""
+    }
+    
+    funcBodyStart := funcStartPos + openBracePos + 1
+    
+    // Find the end of the function
+    braceCount := 1
+    funcBodyEnd := funcBodyStart
+    
+    for i := funcBodyStart; i < len(content) && braceCount > 0; i++ {
+    if content[i] == '{' {
+        braceCount++
+    } else if content[i] == '}' {
+        braceCount--
+    }
+    funcBodyEnd = i
+    }
+    
+    if funcBodyEnd <= funcBodyStart {
+    return ""
+    }
+    
+    return content[funcBodyStart:funcBodyEnd]
+}
+
+// phpFunctionEndLine returns the line of the brace closing the body of the function
+// declared at funcStartPos, or 0 when it has no body
+func phpFunctionEndLine(content string, funcStartPos int) int {
+    openBracePos := strings.Index(content[funcStartPos:], "{")
+    if openBracePos == -1 {
+        return 0
+    }
+    return countLines(content[:funcStartPos+openBracePos+1+len(phpFunctionBody(content, funcStartPos))])
+}
+
+// extractPhpFunctionCalls finds function calls within a PHP function
+func extractPhpFunctionCalls(content string, funcStartPos int) []string {
+    var calls []string
+    
+    funcBody := phpFunctionBody(content, funcStartPos)
+    if funcBody == "" {
+    return calls
+    }
+    
+    // Find function calls
+    callRegex := regexp.MustCompile(`(\$\w+->)?(\w+)\s*\(`)
+    callMatches := callRegex.FindAllStringSubmatch(funcBody, -1)
+    
+    for _, match := range callMatches {
+    if len(match) >= 3 {
+        callName := match[2]
+        // Skip language constructs
+        if callName == "if" || callName == "for" || callName == "while" || callName == "foreach" || callName == "switch" {
+	continue
+        }
+        
+        calls = appendIfNotExists(calls, callName)
+    }
+    }
+    
+    return calls
+}
+
+// extractPhpControlFlow finds control flow structures in PHP code
+func extractPhpControlFlow(content string) []ControlFlow {
+    var controls []ControlFlow
+    
+    // Define regex patterns for control structures
+    patterns := map[string]*regexp.Regexp{
+    "if":      regexp.MustCompile(`if\s*\(`),
+    "for":     regexp.MustCompile(`for\s*\(`),
+    "while":   regexp.MustCompile(`while\s*\(`),
+    "foreach": regexp.MustCompile(`foreach\s*\(`),
+    "switch":  regexp.MustCompile(`switch\s*\(`),
+    }
+    
+    for _, controlType := range sortedKeys(patterns) {
+    pattern := patterns[controlType]
+    matches := pattern.FindAllStringIndex(content, -1)
+    
+    for _, match := range matches {
+        startPos := match[0]
+        
+        // Skip if this is a string literal
+        if isWithinString(content, startPos) {
+	continue
+        }
+        
+        lineNumber := countLines(content[:startPos])
+        
+        control := ControlFlow{
+	Type: controlType,
+	Line: lineNumber,
+	EndLine: phpBlockEndLine(content, startPos),
+        }
+        
+        // Find nested control flow
+        children := findNestedPhpControlFlow(content, startPos)
+        if len(children) > 0 {
+	control.Children = children
+        }
+        
+        controls = append(controls, control)
+    }
+    }
+    
+    return controls
+}
+
+// phpBlockEndLine returns the line of the closing brace of the control structure
+// at startPos, or of the terminating semicolon for a brace-less body
+func phpBlockEndLine(content string, startPos int) int {
+    // Skip over the condition
+    i := startPos + strings.Index(content[startPos:], "(")
+    depth := 0
+    for ; i < len(content); i++ {
+        if content[i] == '(' {
+            depth++
+        } else if content[i] == ')' {
+            depth--
+            if depth == 0 {
+                break
+            }
+        }
+    }
+
+    // Find the start of the body
+    i++
+    for i < len(content) && (content[i] == ' ' || content[i] == '\t' || content[i] == '\r' || content[i] == '\n') {
+        i++
+    }
+    if i >= len(content) {
+        return countLines(content)
+    }
+
+    if content[i] != '{' {
+        if semi := strings.Index(content[i:], ";"); semi != -1 {
+            return countLines(content[:i+semi])
+        }
+        return countLines(content)
+    }
+
+    depth = 0
+    for ; i < len(content); i++ {
+        if content[i] == '{' {
+            depth++
+        } else if content[i] == '}' {
+            depth--
+            if depth == 0 {
+                return countLines(content[:i])
+            }
+        }
+    }
+    return countLines(content)
+}
+
+// findNestedPhpControlFlow identifies nested control structures in PHP
+func findNestedPhpControlFlow(content string, startPos int) []ControlFlow {
+    var nested []ControlFlow
+    
+    // Find the body of this control structure
+    openBracePos := strings.Index(content[startPos:], "{")
+    if openBracePos == -1 {
+    return nested
+    }
+    
+    bodyStart := startPos + openBracePos + 1
+    
+    // Find the end of the body
+    braceCount := 1
+    bodyEnd := bodyStart
+    
+    for i := bodyStart; i < len(content) && braceCount > 0; i++ {
+    if content[i] == '{' {
+        braceCount++
+    } else if content[i] == '}' {
+        braceCount--
+    }
+    bodyEnd = i
+    }
+    
+    if bodyEnd <= bodyStart {
+    return nested
+    }
+    
+    body := content[bodyStart:bodyEnd]
+    
+    // Search for control structures in this body
+    patterns := map[string]*regexp.Regexp{
+    "if":      regexp.MustCompile(`if\s*\(`),
+    "for":     regexp.MustCompile(`for\s*\(`),
+    "while":   regexp.MustCompile(`while\s*\(`),
+    "foreach": regexp.MustCompile(`foreach\s*\(`),
+    "switch":  regexp.MustCompile(`switch\s*\(`),
+    }
+    
+    for _, controlType := range sortedKeys(patterns) {
+    pattern := patterns[controlType]
+    matches := pattern.FindAllStringIndex(body, -1)
+    
+    for _, match := range matches {
+        nestedStartPos := bodyStart + match[0]
+        
+        // Skip if this is a string literal
+        if isWithinString(content, nestedStartPos) {
+	continue
+        }
+        
+        lineNumber := countLines(content[:nestedStartPos])
+        
+        control := ControlFlow{
+	Type: controlType,
+	Line: lineNumber,
+	EndLine: phpBlockEndLine(content, nestedStartPos),
+        }
+        
+        // Find nested control flow (recursively)
+        children := findNestedPhpControlFlow(content, nestedStartPos)
+        if len(children) > 0 {
+	control.Children = children
+        }
+        
+        nested = append(nested, control)
+    }
+    }
+    
+    return nested
+}
+
+// analyzeJsFile analyzes a JavaScript file and returns a JsFileSummary
+func analyzeJsFile(filePath string) JsFileSummary {
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading JavaScript file %s: %v\n", filePath, err)
+        return JsFileSummary{FilePath: filePath}
+    }
+
+    return analyzeJsContent(filePath, string(data))
+}
+
+var (
+    jsImportRegex    = regexp.MustCompile(`(?m)^[ \t]*import\s+(?:[\w$*{}\s,]+\s+from\s+)?['"]([^'"]+)['"]`)
+    jsRequireRegex   = regexp.MustCompile(`\b(?:require|import)\(\s*['"]([^'"]+)['"]\s*\)`)
+    jsClassRegex     = regexp.MustCompile(`\b(abstract\s+)?class\s+([\w$]+)(?:\s*<[^{]*?>)?(?:\s+extends\s+([\w$.]+)(?:\s*<[^{]*?>)?)?(?:\s+implements\s+([^{]+?))?\s*\{`)
+    jsFunctionRegex  = regexp.MustCompile(`\b(?:async\s+)?function\s*\*?\s*([\w$]+)\s*(?:<[^(]*>)?\s*\(([^)]*)\)`)
+    jsArrowRegex     = regexp.MustCompile(`(?m)^[ \t]*(?:export\s+)?(?:const|let|var)\s+([\w$]+)\s*(?::[^=\n]+?)?=\s*(?:async\s+)?(?:function\s*\*?\s*(?:<[^(]*>)?\(([^)]*)\)|(?:<[^(=]*>\s*)?\(([^)]*)\)(?:\s*:\s*[^=\n]+?)?\s*=>|([\w$]+)\s*=>)`)
+    jsConstRegex     = regexp.MustCompile(`(?m)^[ \t]*(?:export\s+)?(?:declare\s+)?const\s+([\w$]+)\s*(?::\s*([^=\n]+?)\s*)?=\s*`)
+    jsMethodRegex    = regexp.MustCompile(`(?m)^[ \t]*(?:@[\w$.]+(?:\([^)\n]*\))?\s+)*((?:(?:public|private|protected|static|async|abstract|override|readonly|declare|get|set)\s+)*)\*?\s*(#?[\w$]+)\s*\??\s*(?:<[^(\n]*>)?\s*\(([^)]*)\)`)
+    jsArrowFieldRegex = regexp.MustCompile(`(?m)^[ \t]*(?:@[\w$.]+(?:\([^)\n]*\))?\s+)*((?:(?:public|private|protected|static|readonly|override)\s+)*)(#?[\w$]+)\s*[?!]?\s*(?::[^=\n]+?)?=\s*(?:async\s+)?(?:\(([^)]*)\)(?:\s*:\s*[^=\n]+?)?|([\w$]+))\s*=>`)
+    jsFieldRegex     = regexp.MustCompile(`(?m)^[ \t]*(?:@[\w$.]+(?:\([^)\n]*\))?\s+)*((?:(?:public|private|protected|static|readonly|declare|override|abstract)\s+)*)(#?[\w$]+)\s*[?!]?\s*(?::\s*([^=;\n]+?))?[ \t]*(?:=[^=>]|;|$)`)
+    jsThisFieldRegex = regexp.MustCompile(`\bthis\.(#?[\w$]+)\s*=[^=]`)
+    jsParamPropertyRegex = regexp.MustCompile(`^\s*((?:public|private|protected|readonly)\s+(?:readonly\s+)?)([\w$]+)\s*\??\s*(?::\s*([^=]+))?`)
+    jsCallRegex      = regexp.MustCompile(`(?:\bnew\s+)?([\w$]+(?:\s*\.\s*#?[\w$]+)*)\s*\(`)
+    jsExportListRegex = regexp.MustCompile(`\bexport\s*(?:type\s*)?\{([^}]*)\}|\bmodule\.exports\s*=\s*\{([^}]*)\}`)
+    tsParamDecoratorRegex = regexp.MustCompile(`^\s*(?:@[\w$.]+\s*(?:\([^)]*\))?\s*)+`)
+    tsInjectRegex    = regexp.MustCompile(`@Inject\(\s*([\w$.]+)\s*\)`)
+    tsConstructorRegex = regexp.MustCompile(`\bconstructor\s*\(`)
+    jsSuperCallRegex = regexp.MustCompile(`\bsuper\s*\.\s*([\w$]+)\s*\(`)
+    jsEnvVarRegex    = regexp.MustCompile(`\b(?:process|import\.meta)\.env(?:\.|\[\s*['"])([A-Za-z_]\w*)`)
+)
+
+// jsExportRegexes match the names a module exports, as ES module exports or CommonJS
+// assignments, including TypeScript declarations. Anonymous default exports are recorded as "default".
+var jsExportRegexes = []*regexp.Regexp{
+    regexp.MustCompile(`(?m)^[ \t]*export\s+(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:async\s+)?(?:const\s+enum|function\s*\*?|class|const|let|var|interface|type|enum|namespace)\s+([\w$]+)`),
+    regexp.MustCompile(`(?m)^[ \t]*export\s+default\s+(?:async\s+)?(?:function\b\s*\*?\s*\(|class\s*\{|[^\w\s$])()`),
+    regexp.MustCompile(`(?m)^[ \t]*export\s+default\s+([\w$]+)\s*;?[ \t]*$`),
+    regexp.MustCompile(`(?m)\bmodule\.exports\s*=\s*([\w$]+)\s*;?[ \t]*$`),
+    regexp.MustCompile(`\b(?:module\.)?exports\.([\w$]+)\s*=[^=]`),
+}
+
+// jsKeywords are words followed by a parenthesis that are not function calls or declarations
+var jsKeywords = map[string]bool{
+    "if": true, "for": true, "while": true, "switch": true, "catch": true, "function": true,
+    "return": true, "typeof": true, "await": true, "with": true, "do": true, "else": true,
+    "super": true, "import": true, "yield": true, "void": true, "delete": true, "in": true, "of": true,
+}
+
+// maskJsSource blanks out comments and the text of string and template literals,
+// keeping newlines and the code inside ${...}, so braces and keywords can be found
+// by position without tripping over literals
+func maskJsSource(content string) string {
+    masked := []byte(content)
+    blank := func(i int) {
+        if masked[i] != '\n' {
+            masked[i] = ' '
+        }
+    }
+
+    // Brace depth at which each open ${...} inside a template literal started
+    var templates []int
+    depth := 0
+    scanTemplate := func(i int) int {
+        for ; i < len(content); i++ {
+            switch {
+            case content[i] == '\\' && i+1 < len(content):
+                blank(i)
+                blank(i + 1)
+                i++
+            case content[i] == '`':
+                return i
+            case content[i] == '$' && i+1 < len(content) && content[i+1] == '{':
+                templates = append(templates, depth)
+                depth++
+                return i + 1
+            default:
+                blank(i)
+            }
+        }
+        return i
+    }
+
+    for i := 0; i < len(content); i++ {
+        c := content[i]
+        switch {
+        case c == '/' && i+1 < len(content) && content[i+1] == '/':
+            for ; i < len(content) && content[i] != '\n'; i++ {
+                blank(i)
+            }
+        case c == '/' && i+1 < len(content) && content[i+1] == '*':
+            stop := len(content)
+            if end := strings.Index(content[i+2:], "*/"); end != -1 {
+                stop = i + 2 + end + 2
+            }
+            for ; i < stop; i++ {
+                blank(i)
+            }
+            i--
+        case c == '\'' || c == '"':
+            for i++; i < len(content) && content[i] != c && content[i] != '\n'; i++ {
+                if content[i] == '\\' && i+1 < len(content) {
+                    blank(i)
+                    i++
+                }
+                blank(i)
+            }
+        case c == '`':
+            i = scanTemplate(i + 1)
+        case c == '{':
+            depth++
+        case c == '}':
+            depth--
+            if len(templates) > 0 && templates[len(templates)-1] == depth {
+                templates = templates[:len(templates)-1]
+                i = scanTemplate(i + 1)
+            }
+        }
+    }
+    return string(masked)
+}
+
+// maskScriptSource blanks out the comments and string literals of PHP or Python source,
+// keeping newlines, so keywords can be counted without tripping over prose in them
+func maskScriptSource(content string, language string) string {
+    masked := []byte(content)
+    blank := func(from int, to int) {
+        for i := from; i < to && i < len(masked); i++ {
+            if masked[i] != '\n' {
+                masked[i] = ' '
+            }
+        }
+    }
+    lineEnd := func(i int) int {
+        if end := strings.IndexByte(content[i:], '\n'); end != -1 {
+            return i + end
+        }
+        return len(content)
+    }
+
+    for i := 0; i < len(content); i++ {
+        c := content[i]
+        switch {
+        case c == '#' && !(language == "php" && strings.HasPrefix(content[i:], "#[")):
+            end := lineEnd(i)
+            blank(i, end)
+            i = end
+        case language == "php" && strings.HasPrefix(content[i:], "//"):
+            end := lineEnd(i)
+            blank(i, end)
+            i = end
+        case language == "php" && strings.HasPrefix(content[i:], "/*"):
+            end := len(content)
+            if close := strings.Index(content[i+2:], "*/"); close != -1 {
+                end = i + 2 + close + 2
+            }
+            blank(i, end)
+            i = end - 1
+        case c == '\'' || c == '"':
+            // Python triple-quoted strings run across lines, other strings may too in PHP
+            quote := string(c)
+            if language == "python" && strings.HasPrefix(content[i:], strings.Repeat(quote, 3)) {
+                quote = strings.Repeat(quote, 3)
+            }
+            j := i + len(quote)
+            for j < len(content) && !strings.HasPrefix(content[j:], quote) {
+                if content[j] == '\\' {
+                    j++
+                } else if content[j] == '\n' && language == "python" && len(quote) == 1 {
+                    break
+                }
+                j++
+            }
+            blank(i+len(quote), j)
+            i = j + len(quote) - 1
+        }
+    }
+    return string(masked)
+}
+
+// Decision points counted towards the cyclomatic complexity of PHP, Python and
+// JavaScript functions, matched against source with comments and strings masked
+var (
+    phpDecisionRegex    = regexp.MustCompile(`\b(?:if|elseif|for|foreach|while|case|catch|and|or)\b|&&|\|\||\?\?`)
+    pythonDecisionRegex = regexp.MustCompile(`\b(?:if|elif|for|while|except|and|or)\b|(?m)^[ \t]*case\b`)
+    jsDecisionRegex     = regexp.MustCompile(`\b(?:if|for|while|case|catch)\b|&&|\|\||\?\?`)
+)
+
+// scriptComplexity returns the cyclomatic complexity of a masked PHP, Python or JavaScript
+// function body: one plus a branch for every conditional, loop, case, catch and short-circuit
+// operator, the same decisions goComplexity counts in Go
+func scriptComplexity(maskedBody string, decisions *regexp.Regexp) int {
+    return 1 + len(decisions.FindAllStringIndex(maskedBody, -1))
+}
+
+// jsBraceDepths returns the brace nesting depth in front of each byte of masked source
+func jsBraceDepths(masked string) []int {
+    depths := make([]int, len(masked)+1)
+    depth := 0
+    for i := 0; i < len(masked); i++ {
+        depths[i] = depth
+        switch masked[i] {
+        case '{':
+            depth++
+        case '}':
+            if depth > 0 {
+                depth--
+            }
+        }
+    }
+    depths[len(masked)] = depth
+    return depths
+}
+
+// jsBlockEnd returns the position of the brace closing the block opened at open
+func jsBlockEnd(masked string, open int) int {
+    depth := 0
+    for i := open; i < len(masked); i++ {
+        switch masked[i] {
+        case '{':
+            depth++
+        case '}':
+            depth--
+            if depth == 0 {
+                return i
+            }
+        }
+    }
+    return len(masked)
+}
+
+// jsParamsEnd returns the position of the parenthesis closing the parameter list opened
+// at open, so defaults such as "cb = () => {}" don't cut the list short
+func jsParamsEnd(masked string, open int) int {
+    depth := 0
+    for i := open; i < len(masked); i++ {
+        switch masked[i] {
+        case '(':
+            depth++
+        case ')':
+            depth--
+            if depth == 0 {
+                return i
+            }
+        }
+    }
+    return len(masked)
+}
+
+// jsFunctionBody returns the body of the function whose parameter list ends at pos: the
+// braced block, or the expression of an arrow function up to the end of its line.
+// A TypeScript return type annotation is skipped. Bodyless declarations yield "".
+func jsFunctionBody(masked string, pos int) string {
+    start, end := jsFunctionBodyRange(masked, pos)
+    return masked[start:end]
+}
+
+// jsFunctionBodyRange returns where the body jsFunctionBody reads starts and ends; a
+// braced body ends at its closing brace
+func jsFunctionBodyRange(masked string, pos int) (int, int) {
+    _, pos = jsReturnType(masked, masked, pos)
+    rest := strings.TrimLeft(masked[pos:], " \t\r\n")
+    if strings.HasPrefix(rest, ";") {
+        return pos, pos
+    }
+    rest = strings.TrimLeft(strings.TrimPrefix(rest, "=>"), " \t\r\n")
+    start := len(masked) - len(rest)
+    if strings.HasPrefix(rest, "{") {
+        return start + 1, jsBlockEnd(masked, start)
+    }
+    if end := strings.IndexAny(rest, ";\n"); end != -1 {
+        return start, start + end
+    }
+    return start, len(masked)
+}
+
+// jsFunctionMetrics returns the end line, statement count and nesting depth of the body
+// of the function whose parameter list ends at pos
+func jsFunctionMetrics(content string, masked string, pos int) (int, int, int) {
+    start, end := jsFunctionBodyRange(masked, pos)
+    statements, nesting := braceBodyMetrics(masked[start:end])
+    return countLines(content[:end]), statements, nesting
+}
+
+// jsReturnType reads the TypeScript return type annotation that follows the parameter
+// list ending at pos, returning it and the position after it. Without an annotation it
+// returns "" and pos. The annotation ends at the body's brace, "=>" or the end of the line.
+func jsReturnType(content string, masked string, pos int) (string, int) {
+    i := pos
+    for i < len(masked) && strings.ContainsRune(" \t\r\n", rune(masked[i])) {
+        i++
+    }
+    if i >= len(masked) || masked[i] != ':' {
+        return "", pos
+    }
+    start := i + 1
+    typ := func(end int) (string, int) {
+        return strings.Join(strings.Fields(content[start:end]), " "), end
+    }
+    depth := 0
+    for i = start; i < len(masked); i++ {
+        switch c := masked[i]; c {
+        case '=':
+            if i+1 < len(masked) && masked[i+1] == '>' {
+                if depth == 0 {
+                    return typ(i)
+                }
+                i++
+            }
+        case '(', '[', '<':
+            depth++
+        case ')', ']', '>':
+            depth--
+        case '{':
+            // An object type only when nothing precedes it, otherwise it opens the body
+            if depth == 0 && strings.TrimSpace(masked[start:i]) != "" {
+                return typ(i)
+            }
+            depth++
+        case '}':
+            depth--
+        case ';', '\n':
+            if depth == 0 {
+                return typ(i)
+            }
+        }
+        if depth < 0 {
+            return typ(i)
+        }
+    }
+    return typ(len(masked))
+}
+
+// jsParamModifiers are TypeScript constructor parameter modifiers, left off parameter names
+var jsParamModifiers = []string{"public ", "private ", "protected ", "readonly ", "override "}
+
+// tsDecorators lists the decorators stacked in front of the class declared at pos,
+// e.g. "Component" and "Injectable", top to bottom. Arguments are left out.
+func tsDecorators(masked string, pos int) []string {
+    var decorators []string
+    before := strings.TrimRight(masked[:pos], " \t\r\n")
+    for _, keyword := range []string{"default", "export", "declare"} {
+        before = strings.TrimRight(strings.TrimSuffix(before, keyword), " \t\r\n")
+    }
+    for {
+        // Skip the argument list, if any, back to the decorator name
+        end := len(before)
+        if strings.HasSuffix(before, ")") {
+            depth := 0
+            for end = len(before) - 1; end >= 0; end-- {
+                if before[end] == ')' {
+                    depth++
+                } else if before[end] == '(' {
+                    depth--
+                    if depth == 0 {
+                        break
+                    }
+                }
+            }
+            if end < 0 {
+                break
+            }
+        }
+        start := end
+        for start > 0 && (isJsIdentChar(before[start-1]) || before[start-1] == '.') {
+            start--
+        }
+        if start == end || start == 0 || before[start-1] != '@' {
+            break
+        }
+        decorators = append([]string{before[start:end]}, decorators...)
+        before = strings.TrimRight(before[:start-1], " \t\r\n")
+    }
+    return decorators
+}
+
+// tsConstructorDependencies lists the types injected through a constructor's parameters,
+// or the token of an @Inject(TOKEN) parameter decorator
+func tsConstructorDependencies(argsStr string) []string {
+    var dependencies []string
+    for _, span := range jsParamSpans(argsStr) {
+        param := argsStr[span[0]:span[1]]
+        if inject := tsInjectRegex.FindStringSubmatch(param); inject != nil {
+            dependencies = appendIfNotExists(dependencies, inject[1])
+            continue
+        }
+        if args := parseJsArgs(param, 0); len(args) == 1 && args[0].Type != "" {
+            dependencies = appendIfNotExists(dependencies, args[0].Type)
+        }
+    }
+    return dependencies
+}
+
+// isJsIdentChar reports whether c can appear in a JavaScript identifier
+func isJsIdentChar(c byte) bool {
+    return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// parseJsArgs splits a JavaScript parameter list, keeping destructured parameters whole
+// and leaving out default values. TypeScript annotations become the parameter types.
+// The list starts on the given line, from which each parameter's own line is counted.
+func parseJsArgs(argsStr string, line int) []Variable {
+    var args []Variable
+    add := func(param string, line int) {
+        // Parameter decorators, e.g. "@Inject(TOKEN) private token: string", are no part of the name
+        param = strings.TrimSpace(tsParamDecoratorRegex.ReplaceAllString(param, ""))
+        // Drop the default value and split off the type annotation, outside nested brackets
+        colon, level := -1, 0
+    scan:
+        for i := 0; i < len(param); i++ {
+            switch param[i] {
+            case '{', '[', '(', '<':
+                level++
+            case '}', ']', ')':
+                level--
+            case '>':
+                if i == 0 || param[i-1] != '=' {
+                    level--
+                }
+            case ':':
+                if level == 0 && colon == -1 {
+                    colon = i
+                }
+            case '=':
+                if level == 0 && (i+1 >= len(param) || param[i+1] != '>') {
+                    param = param[:i]
+                    break scan
+                }
+            }
+        }
+        name, typ := param, ""
+        if colon != -1 {
+            name, typ = param[:colon], param[colon+1:]
+        }
+        for _, modifier := range jsParamModifiers {
+            name = strings.TrimPrefix(strings.TrimSpace(name), modifier)
+        }
+        name = strings.TrimSuffix(strings.TrimSpace(name), "?")
+        if name != "" {
+            args = append(args, Variable{Name: name, Type: strings.Join(strings.Fields(typ), " "), Scope: "parameter", Line: line})
+        }
+    }
+    for _, span := range jsParamSpans(argsStr) {
+        param := argsStr[span[0]:span[1]]
+        start := span[0] + len(param) - len(strings.TrimLeft(param, " \t\r\n"))
+        add(param, line+strings.Count(argsStr[:start], "\n"))
+    }
+    return args
+}
+
+// jsMemberAccess returns "private" or "protected" when a TypeScript member declaration
+// carries that modifier, and "" for public members
+func jsMemberAccess(declaration string) string {
+    for _, word := range strings.Fields(declaration) {
+        if word == "private" || word == "protected" {
+            return word
+        }
+    }
+    return ""
+}
+
+// jsParamSpans returns the start and end offsets of each parameter in a parameter list,
+// splitting at the commas outside brackets
+func jsParamSpans(argsStr string) [][2]int {
+    var spans [][2]int
+    depth, start := 0, 0
+    for i, c := range argsStr {
+        switch c {
+        case '{', '[', '(', '<':
+            depth++
+        case '}', ']', ')':
+            depth--
+        case '>':
+            if i == 0 || argsStr[i-1] != '=' {
+                depth--
+            }
+        case ',':
+            if depth == 0 {
+                spans = append(spans, [2]int{start, i})
+                start = i + 1
+            }
+        }
+    }
+    return append(spans, [2]int{start, len(argsStr)})
+}
+
+// extractJsCalls lists the functions and methods called in a JavaScript function body,
+// with "this." left off method calls on the same object
+func extractJsCalls(body string) []string {
+    var calls []string
+    for _, match := range jsCallRegex.FindAllStringSubmatch(body, -1) {
+        name := strings.Join(strings.Fields(match[1]), "")
+        if jsKeywords[name] {
+            continue
+        }
+        calls = appendIfNotExists(calls, strings.TrimPrefix(name, "this."))
+    }
+    return calls
+}
+
+// jsValueType guesses the type of a constant from the start of its initializer
+func jsValueType(value string) string {
+    value = strings.TrimSpace(value)
+    switch {
+    case value == "":
+        return "inferred"
+    case strings.HasPrefix(value, "true") || strings.HasPrefix(value, "false"):
+        return "boolean"
+    case strings.ContainsRune("0123456789-.", rune(value[0])):
+        return "number"
+    case strings.ContainsRune("'\"`", rune(value[0])):
+        return "string"
+    case value[0] == '[':
+        return "array"
+    case value[0] == '{':
+        return "object"
+    case strings.HasPrefix(value, "require("):
+        return "module"
+    case strings.HasPrefix(value, "new "):
+        name := strings.TrimSpace(value[4:])
+        if end := strings.IndexAny(name, "( ;"); end != -1 {
+            name = name[:end]
+        }
+        if name != "" {
+            return name
+        }
+    }
+    return "inferred"
+}
+
+// analyzeJsContent analyzes JavaScript source code and returns a JsFileSummary. Module-level
+// constants and arrow functions are taken from the top level only; function declarations
+// are found at any depth, so code wrapped in an IIFE is still covered.
+func analyzeJsContent(filePath string, content string) JsFileSummary {
+    summary := JsFileSummary{
+        FilePath: filePath,
+    }
+    masked := maskJsSource(content)
+    depths := jsBraceDepths(masked)
+
+    // Extract ES module imports, require() calls and dynamic imports, skipping commented-out ones
+    for _, regex := range []*regexp.Regexp{jsImportRegex, jsRequireRegex} {
+        for _, match := range regex.FindAllStringSubmatchIndex(content, -1) {
+            if masked[match[1]-1] == ' ' {
+                continue
+            }
+            path := content[match[2]:match[3]]
+            exists := false
+            for _, imp := range summary.Imports {
+                exists = exists || imp.Path == path
+            }
+            if !exists {
+                summary.Imports = append(summary.Imports, Import{Path: path})
+            }
+        }
+    }
+
+    // Extract exported names
+    for _, regex := range jsExportRegexes {
+        for _, match := range regex.FindAllStringSubmatch(masked, -1) {
+            name := match[1]
+            if name == "" {
+                name = "default"
+            }
+            summary.Exports = appendIfNotExists(summary.Exports, name)
+        }
+    }
+    for _, match := range jsExportListRegex.FindAllStringSubmatch(masked, -1) {
+        // "export { a as b }" exports b, "module.exports = { a: fn }" exports a
+        for _, item := range strings.Split(match[1]+match[2], ",") {
+            fields := strings.Fields(strings.Replace(item, ":", " : ", 1))
+            if len(fields) == 0 || strings.HasPrefix(fields[0], "...") {
+                continue
+            }
+            name := fields[0]
+            if match[1] != "" && len(fields) == 3 && fields[1] == "as" {
+                name = fields[2]
+            }
+            summary.Exports = appendIfNotExists(summary.Exports, name)
+        }
+    }
+    sort.Strings(summary.Exports)
+
+    // Extract classes with their fields and methods
+    type span struct{ start, end int }
+    var classSpans []span
+    for _, match := range jsClassRegex.FindAllStringSubmatchIndex(masked, -1) {
+        open := match[1] - 1
+        end := jsBlockEnd(masked, open)
+        classSpans = append(classSpans, span{open, end})
+        className := masked[match[4]:match[5]]
+
+        class := Struct{
+            Name:       className,
+            Line:       countLines(content[:match[0]]),
+            IsAbstract: match[2] != -1,
+        }
+        class.Doc = phpDocComment(content, match[0])
+        class.IsDeprecated, class.Deprecation = phpDeprecation(content, match[0])
+        if match[6] != -1 {
+            class.Bases = []string{masked[match[6]:match[7]]}
+        }
+        if match[8] != -1 {
+            for _, s := range tsSplitMembers(masked, match[8], match[9]) {
+                class.Bases = append(class.Bases, masked[s[0]:s[1]])
+            }
+        }
+
+        // Only declarations directly in the class body, not inside its methods
+        body := masked[:end]
+        memberDepth := depths[open] + 1
+        var fieldNames []string
+        fieldTypes := make(map[string]string)
+        fieldScopes := make(map[string]string) // TypeScript private and protected fields
+        fieldLines := make(map[string]int)
+        addMethod := func(pos int, name string, modifiers string, argsStart int, argsStr string, argsEnd int) {
+            body := jsFunctionBody(masked, argsEnd)
+            method := Function{
+                Name:      name,
+                Receiver:  className,
+                Line:      countLines(content[:pos]),
+                Args:      parseJsArgs(argsStr, countLines(content[:argsStart])),
+                Calls:     extractJsCalls(body),
+                Modifiers: strings.Fields(modifiers),
+                Complexity: scriptComplexity(body, jsDecisionRegex),
+            }
+            method.EndLine, method.Statements, method.MaxNesting = jsFunctionMetrics(content, masked, argsEnd)
+            if returns, _ := jsReturnType(content, masked, argsEnd); returns != "" {
+                method.Returns = []string{returns}
+            }
+            method.IsStatic = strings.Contains(modifiers, "static")
+            method.IsAbstract = strings.Contains(modifiers, "abstract")
+            if method.IsAbstract {
+                method.Complexity, method.EndLine, method.Statements, method.MaxNesting = 0, 0, 0, 0
+            }
+            for _, call := range jsSuperCallRegex.FindAllStringSubmatch(body, -1) {
+                method.SuperCalls = appendIfNotExists(method.SuperCalls, call[1])
+            }
+            method.Doc = phpDocComment(content, pos)
+            method.IsDeprecated, method.Deprecation = phpDeprecation(content, pos)
+            class.Methods = append(class.Methods, method)
+        }
+        for _, m := range jsMethodRegex.FindAllStringSubmatchIndex(body[open+1:], -1) {
+            pos := open + 1 + m[4]
+            name := body[open+1+m[4] : open+1+m[5]]
+            if depths[pos] != memberDepth || jsKeywords[name] {
+                continue
+            }
+            // A method needs a body, unless it is abstract; bodyless overloads are skipped
+            modifiers, close := body[open+1+m[2]:open+1+m[3]], jsParamsEnd(masked, open+m[6])
+            if close >= end {
+                continue
+            }
+            argsEnd := close + 1
+            _, next := jsReturnType(masked, masked, argsEnd)
+            next += len(masked[next:]) - len(strings.TrimLeft(masked[next:], " \t\r\n"))
+            if next >= end || !(masked[next] == '{' || masked[next] == ';' && strings.Contains(modifiers, "abstract")) {
+                continue
+            }
+            argsStr := content[open+1+m[6] : close]
+            addMethod(pos, name, modifiers, open+1+m[6], argsStr, argsEnd)
+            if name == "constructor" {
+                // An assignment gives the line of a field nothing declares
+                bodyStart, bodyEnd := jsFunctionBodyRange(masked, argsEnd)
+                for _, field := range jsThisFieldRegex.FindAllStringSubmatchIndex(masked[bodyStart:bodyEnd], -1) {
+                    name := masked[bodyStart+field[2] : bodyStart+field[3]]
+                    fieldNames = appendIfNotExists(fieldNames, name)
+                    if fieldLines[name] == 0 {
+                        fieldLines[name] = countLines(content[:bodyStart+field[2]])
+                    }
+                }
+                // TypeScript parameter properties, e.g. "private readonly http: HttpClient",
+                // take their scope from their modifiers like fields declared in the body
+                for _, span := range jsParamSpans(argsStr) {
+                    param := argsStr[span[0]:span[1]]
+                    start := open + 1 + m[6] + span[0] + len(param)
+                    param = tsParamDecoratorRegex.ReplaceAllString(param, "")
+                    start -= len(param)
+                    field := jsParamPropertyRegex.FindStringSubmatchIndex(param)
+                    if field == nil {
+                        continue
+                    }
+                    name := param[field[4]:field[5]]
+                    fieldNames = appendIfNotExists(fieldNames, name)
+                    if field[6] != -1 {
+                        fieldTypes[name] = strings.Join(strings.Fields(param[field[6]:field[7]]), " ")
+                    }
+                    fieldScopes[name] = jsMemberAccess(param[field[2]:field[3]])
+                    fieldLines[name] = countLines(content[:start+field[4]])
+                }
+            }
+        }
+        arrowFields := make(map[string]bool)
+        for _, m := range jsArrowFieldRegex.FindAllStringSubmatchIndex(body[open+1:], -1) {
+            pos := open + 1 + m[4]
+            if depths[pos] != memberDepth {
+                continue
+            }
+            name := body[pos : open+1+m[5]]
+            modifiers := body[open+1+m[2] : open+1+m[3]]
+            // A lone parameter without parentheses, e.g. "x => x * 2", ends with the match
+            argsStart, argsStop, argsEnd := open+1+m[8], open+1+m[9], open+1+m[1]
+            if m[6] != -1 {
+                argsStart, argsStop, argsEnd = open+1+m[6], open+1+m[7], open+1+m[7]+1
+            }
+            addMethod(pos, name, modifiers, argsStart, content[argsStart:argsStop], argsEnd)
+            arrowFields[name] = true
+        }
+        for _, m := range jsFieldRegex.FindAllStringSubmatchIndex(body[open+1:], -1) {
+            pos := open + 1 + m[4]
+            name := body[pos : open+1+m[5]]
+            if depths[pos] != memberDepth || arrowFields[name] || jsKeywords[name] {
+                continue
+            }
+            // Skip lines of a parameter list or array literal that span several lines
+            between := masked[open+1 : pos]
+            if strings.Count(between, "(") != strings.Count(between, ")") || strings.Count(between, "[") != strings.Count(between, "]") {
+                continue
+            }
+            fieldNames = appendIfNotExists(fieldNames, name)
+            if m[6] != -1 {
+                fieldTypes[name] = strings.Join(strings.Fields(content[open+1+m[6]:open+1+m[7]]), " ")
+            }
+            fieldScopes[name] = jsMemberAccess(body[open+1+m[2] : open+1+m[3]])
+            fieldLines[name] = countLines(content[:pos])
+        }
+        for _, name := range fieldNames {
+            scope := fieldScopes[name]
+            if scope == "" {
+                scope = "class"
+            }
+            class.Fields = append(class.Fields, Variable{Name: name, Type: fieldTypes[name], Scope: scope, Line: fieldLines[name]})
+        }
+        sort.SliceStable(class.Methods, func(i, j int) bool {
+            return class.Methods[i].Line < class.Methods[j].Line
+        })
+
+        summary.Classes = append(summary.Classes, class)
+    }
+    inClass := func(pos int) bool {
+        for _, s := range classSpans {
+            if pos > s.start && pos < s.end {
+                return true
+            }
+        }
+        return false
+    }
+
+    // Extract function declarations and top-level function expressions
+    isFunction := make(map[string]bool)
+    implemented := make(map[string]bool)
+    var declarations []Function
+    for _, match := range jsFunctionRegex.FindAllStringSubmatchIndex(masked, -1) {
+        if inClass(match[0]) {
+            continue
+        }
+        close := jsParamsEnd(masked, match[4]-1)
+        if close == len(masked) {
+            continue
+        }
+        body := jsFunctionBody(masked, close+1)
+        function := Function{
+            Name:  masked[match[2]:match[3]],
+            Line:  countLines(content[:match[0]]),
+            Args:  parseJsArgs(content[match[4]:close], countLines(content[:match[4]])),
+            Calls: extractJsCalls(body),
+            Complexity: scriptComplexity(body, jsDecisionRegex),
+        }
+        function.EndLine, function.Statements, function.MaxNesting = jsFunctionMetrics(content, masked, close+1)
+        returns, next := jsReturnType(content, masked, close+1)
+        if returns != "" {
+            function.Returns = []string{returns}
+        }
+        if strings.HasPrefix(masked[match[0]:], "async") {
+            function.Modifiers = []string{"async"}
+        }
+        function.Doc = phpDocComment(content, match[0])
+        function.IsDeprecated, function.Deprecation = phpDeprecation(content, match[0])
+        isFunction[function.Name] = true
+        // TypeScript overload signatures and ambient declarations have no body
+        if !strings.HasPrefix(strings.TrimLeft(masked[next:], " \t\r\n"), "{") {
+            function.Complexity, function.EndLine, function.Statements, function.MaxNesting = 0, 0, 0, 0
+            declarations = append(declarations, function)
+            continue
+        }
+        implemented[function.Name] = true
+        summary.Functions = append(summary.Functions, function)
+    }
+    for _, function := range declarations {
+        if !implemented[function.Name] {
+            implemented[function.Name] = true
+            summary.Functions = append(summary.Functions, function)
+        }
+    }
+    for _, match := range jsArrowRegex.FindAllStringSubmatchIndex(masked, -1) {
+        if depths[match[2]] != 0 {
+            continue
+        }
+        argsStr, argsStart, argsEnd := "", match[1], match[1]
+        for group := 2; group <= 3; group++ {
+            if match[2*group] != -1 {
+                close := jsParamsEnd(masked, match[2*group]-1)
+                argsStr, argsStart, argsEnd = content[match[2*group]:close], match[2*group], close+1
+            }
+        }
+        if match[8] != -1 {
+            argsStr, argsStart = content[match[8]:match[9]], match[8]
+        }
+        body := jsFunctionBody(masked, argsEnd)
+        function := Function{
+            Name:  masked[match[2]:match[3]],
+            Line:  countLines(content[:match[2]]),
+            Args:  parseJsArgs(argsStr, countLines(content[:argsStart])),
+            Calls: extractJsCalls(body),
+            Complexity: scriptComplexity(body, jsDecisionRegex),
+        }
+        function.EndLine, function.Statements, function.MaxNesting = jsFunctionMetrics(content, masked, argsEnd)
+        if returns, _ := jsReturnType(content, masked, argsEnd); returns != "" {
+            function.Returns = []string{returns}
+        }
+        if strings.Contains(masked[match[3]:match[1]], "async") {
+            function.Modifiers = []string{"async"}
+        }
+        function.Doc = phpDocComment(content, match[2])
+        function.IsDeprecated, function.Deprecation = phpDeprecation(content, match[2])
+        isFunction[function.Name] = true
+        summary.Functions = append(summary.Functions, function)
+    }
+    sort.SliceStable(summary.Functions, func(i, j int) bool {
+        return summary.Functions[i].Line < summary.Functions[j].Line
+    })
+
+    // Extract top-level constants that aren't functions
+    for _, match := range jsConstRegex.FindAllStringSubmatchIndex(masked, -1) {
+        name := masked[match[2]:match[3]]
+        if depths[match[2]] != 0 || isFunction[name] {
+            continue
+        }
+        value := content[match[1]:]
+        if end := strings.IndexAny(value, ";\n"); end != -1 {
+            value = value[:end]
+        }
+        constant := Variable{
+            Name:  name,
+            Type:  jsValueType(value),
+            Scope: "global",
+            Line:  countLines(content[:match[2]]),
+        }
+        if match[4] != -1 {
+            constant.Type = strings.TrimSpace(content[match[4]:match[5]])
+        }
+        summary.Constants = append(summary.Constants, constant)
+    }
+
+    // JavaScript shares PHP's brace syntax for control flow
+    summary.ControlFlows = extractPhpControlFlow(masked)
+
+    summary.ExternalCalls = findExternalCalls(content, jsExternalCallPatterns)
+    summary.LikelyMinified, summary.ReadableSymbols = detectMinified(content)
+    summary.EventsEmitted, summary.EventsHandled = findEvents(content, jsEventPatterns)
+    summary.EnvVars = findEnvVars(content, jsEnvVarRegex)
+    summary.SQLConcatenations = findSQLConcatenations(content, "javascript")
+    summary.SQLTables = findSQLTables(content)
+    summary.RealtimeEndpoints = findRealtimeEndpoints(content, jsRealtimePatterns, withMethods(summary.Functions, summary.Classes), nil)
+
+    return summary
+}
+
+// analyzeTsFile analyzes a TypeScript file and returns a TsFileSummary
+func analyzeTsFile(filePath string) TsFileSummary {
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading TypeScript file %s: %v\n", filePath, err)
+        return TsFileSummary{FilePath: filePath}
+    }
+
+    return analyzeTsContent(filePath, string(data))
+}
+
+var (
+    tsInterfaceRegex       = regexp.MustCompile(`\binterface\s+([\w$]+)(?:\s*<[^{]*?>)?(?:\s+extends\s+([^{]+?))?\s*\{`)
+    tsTypeAliasRegex       = regexp.MustCompile(`(?m)^[ \t]*(?:export\s+)?(?:declare\s+)?type\s+([\w$]+)\s*(?:<[^;\n]*?>)?\s*=`)
+    tsEnumRegex            = regexp.MustCompile(`(?m)^[ \t]*(?:export\s+)?(?:declare\s+)?(const\s+)?enum\s+([\w$]+)\s*\{`)
+    tsMethodSignatureRegex = regexp.MustCompile(`^(?:readonly\s+)?([\w$]+)\s*\??\s*(?:<[^(]*>)?\s*\(`)
+)
+
+// tsSplitMembers returns the spans of the members between start and end, separated by
+// ";", "," or line breaks outside brackets, with surrounding whitespace trimmed
+func tsSplitMembers(masked string, start int, end int) [][2]int {
+    var spans [][2]int
+    add := func(s, e int) {
+        for s < e && strings.ContainsRune(" \t\r\n", rune(masked[s])) {
+            s++
+        }
+        for e > s && strings.ContainsRune(" \t\r\n", rune(masked[e-1])) {
+            e--
+        }
+        if s < e {
+            spans = append(spans, [2]int{s, e})
+        }
+    }
+    depth, from := 0, start
+    for i := start; i < end; i++ {
+        switch masked[i] {
+        case '{', '[', '(', '<':
+            depth++
+        case '}', ']', ')':
+            depth--
+        case '>':
+            if i == 0 || masked[i-1] != '=' {
+                depth--
+            }
+        case ';', ',', '\n':
+            if depth == 0 {
+                add(from, i)
+                from = i + 1
+            }
+        }
+    }
+    add(from, end)
+    return spans
+}
+
+// tsTypeExpression reads the type expression starting at start, as on the right of a type
+// alias. It ends at a semicolon or line break outside brackets, unless the next line
+// continues a union or intersection.
+func tsTypeExpression(content string, masked string, start int) string {
+    depth, i := 0, start
+    for ; i < len(masked); i++ {
+        c := masked[i]
+        switch c {
+        case '{', '[', '(', '<':
+            depth++
+        case '}', ']', ')':
+            depth--
+        case '>':
+            if masked[i-1] != '=' {
+                depth--
+            }
+        }
+        if depth < 0 || depth == 0 && c == ';' {
+            break
+        }
+        if depth == 0 && c == '\n' {
+            next := strings.TrimLeft(masked[i:], " \t\r\n")
+            if strings.TrimSpace(masked[start:i]) == "" || strings.HasPrefix(next, "|") || strings.HasPrefix(next, "&") {
+                continue
+            }
+            break
+        }
+    }
+    // A leading "|" before the first member of a union is allowed and says nothing
+    return strings.TrimPrefix(strings.Join(strings.Fields(content[start:i]), " "), "| ")
+}
+
+// analyzeTsContent analyzes TypeScript source code and returns a TsFileSummary. Functions,
+// classes and the rest come from the JavaScript analyzer, which reads type annotations;
+// interfaces, type aliases and enums are extracted here.
+func analyzeTsContent(filePath string, content string) TsFileSummary {
+    js := analyzeJsContent(filePath, content)
+    summary := TsFileSummary{
+        FilePath:          filePath,
+        Functions:         js.Functions,
+        Classes:           js.Classes,
+        Constants:         js.Constants,
+        ControlFlows:      js.ControlFlows,
+        Imports:           js.Imports,
+        Exports:           js.Exports,
+        ExternalCalls:     js.ExternalCalls,
+        LikelyMinified:    js.LikelyMinified,
+        ReadableSymbols:   js.ReadableSymbols,
+        EventsEmitted:     js.EventsEmitted,
+        EventsHandled:     js.EventsHandled,
+        EnvVars:           js.EnvVars,
+        RealtimeEndpoints: js.RealtimeEndpoints,
+        SQLConcatenations: js.SQLConcatenations,
+        SQLTables:         js.SQLTables,
+    }
+    masked := maskJsSource(content)
+
+    // Extract interfaces with their method signatures and properties
+    for _, match := range tsInterfaceRegex.FindAllStringSubmatchIndex(masked, -1) {
+        open := match[1] - 1
+        iface := Interface{
+            Name: masked[match[2]:match[3]],
+            Doc:  phpDocComment(content, match[0]),
+            Line: countLines(content[:match[0]]),
+        }
+        if match[4] != -1 {
+            for _, s := range tsSplitMembers(content, match[4], match[5]) {
+                iface.Embeds = append(iface.Embeds, content[s[0]:s[1]])
+            }
+        }
+        for _, s := range tsSplitMembers(masked, open+1, jsBlockEnd(masked, open)) {
+            text, code := content[s[0]:s[1]], masked[s[0]:s[1]]
+            line := countLines(content[:s[0]])
+            if m := tsMethodSignatureRegex.FindStringSubmatchIndex(code); m != nil {
+                if close := jsParamsEnd(code, m[1]-1); close < len(code) {
+                    method := Function{
+                        Name: code[m[2]:m[3]],
+                        Args: parseJsArgs(text[m[1]:close], line+strings.Count(text[:m[1]], "\n")),
+                        Line: line,
+                    }
+                    if returns, _ := jsReturnType(text, code, close+1); returns != "" {
+                        method.Returns = []string{returns}
+                    }
+                    iface.Methods = append(iface.Methods, method)
+                    continue
+                }
+            }
+            // Properties, index signatures and call signatures, split at the annotation
+            field := parseJsArgs(text, line)
+            if len(field) == 1 {
+                field[0].Scope = "property"
+                iface.Fields = append(iface.Fields, field[0])
+            }
+        }
+        summary.Interfaces = append(summary.Interfaces, iface)
+    }
+
+    for _, match := range tsTypeAliasRegex.FindAllStringSubmatchIndex(masked, -1) {
+        summary.TypeAliases = append(summary.TypeAliases, TypeAlias{
+            Name: masked[match[2]:match[3]],
+            Type: tsTypeExpression(content, masked, match[1]),
+            Line: countLines(content[:match[0]]),
+        })
+    }
+
+    for _, match := range tsEnumRegex.FindAllStringSubmatchIndex(masked, -1) {
+        open := match[1] - 1
+        enum := Enum{
+            Name:    masked[match[4]:match[5]],
+            IsConst: match[2] != -1,
+            Line:    countLines(content[:match[0]]),
+        }
+        for _, s := range tsSplitMembers(masked, open+1, jsBlockEnd(masked, open)) {
+            member := content[s[0]:s[1]]
+            if eq := strings.Index(masked[s[0]:s[1]], "="); eq != -1 {
+                member = member[:eq]
+            }
+            enum.Members = append(enum.Members, strings.TrimSpace(member))
+        }
+        summary.Enums = append(summary.Enums, enum)
+    }
+
+    // Angular and NestJS wire classes together through decorators and constructor injection.
+    // The JavaScript pass keeps one class per match, in order.
+    depths := jsBraceDepths(masked)
+    for i, match := range jsClassRegex.FindAllStringSubmatchIndex(masked, -1) {
+        if i >= len(summary.Classes) {
+            break
+        }
+        class := &summary.Classes[i]
+        class.Decorators = tsDecorators(masked, match[0])
+        open := match[1] - 1
+        end := jsBlockEnd(masked, open)
+        for _, m := range tsConstructorRegex.FindAllStringIndex(masked[open:end], -1) {
+            if depths[open+m[0]] != depths[open]+1 {
+                continue
+            }
+            paren := open + m[1] - 1
+            class.Dependencies = tsConstructorDependencies(content[paren+1 : jsParamsEnd(masked, paren)])
+            break
+        }
+    }
+
+    return summary
+}
+
+// analyzeHtmlFile analyzes an HTML file with enhanced features
+func analyzeHtmlFile(filePath string) HtmlFileSummary {
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+    fmt.Fprintf(os.Stderr, "Error reading HTML file %s: %v\n", filePath, err)
+    return HtmlFileSummary{FilePath: filePath}
+    }
+
+    return analyzeHtmlContent(filePath, string(data))
+}
+
+// analyzeHtmlContent analyzes HTML markup and returns an HtmlFileSummary
+func analyzeHtmlContent(filePath string, content string) HtmlFileSummary {
+    doc, err := html.Parse(strings.NewReader(content))
+    if err != nil {
+    fmt.Fprintf(os.Stderr, "Error parsing HTML file %s: %v\n", filePath, err)
+    return HtmlFileSummary{FilePath: filePath}
+    }
+
+    summary := HtmlFileSummary{
+    FilePath: filePath,
+    }
+
+    // Extract includes (PHP includes in HTML)
+    includeRegex := regexp.MustCompile(`(?i)<\?(?:php)?\s+(?:include|require)(?:_once)?\s*\(?\s*['"]([^'"]+)['"]\s*\)?\s*;?\s*\?>`)
+    includeMatches := includeRegex.FindAllStringSubmatch(content, -1)
+    
+    for _, match := range includeMatches {
+    if len(match) >= 2 {
+        summary.Includes = append(summary.Includes, match[1])
+    }
+    }
+
+    // Extract embedded JavaScript
+    scriptRegex := regexp.MustCompile(`(?s)<script[^>]*>(.*?)</script>`)
+    scriptMatches := scriptRegex.FindAllStringSubmatch(content, -1)
+    
+    for _, match := range scriptMatches {
+    if len(match) >= 2 {
+        jsContent := match[1]
+        if jsContent != "" {
+	// Simple function extraction from JS
+	funcRegex := regexp.MustCompile(`function\s+(\w+)\s*\((.*?)\)`)
+	funcMatches := funcRegex.FindAllStringSubmatch(jsContent, -1)
+	
+	for _, fMatch := range funcMatches {
+	    if len(fMatch) >= 3 {
+	    funcName := fMatch[1]
+	    lineNum := countLines(content[:strings.Index(content, match[0])]) + 
+		   countLines(jsContent[:strings.Index(jsContent, fMatch[0])])
+	    
+	    function := Function{
+	        Name: funcName,
+	        Line: lineNum,
+	    }
+	    
+	    summary.EmbeddedJS = append(summary.EmbeddedJS, function)
+	    }
+	}
+        }
+    }
+    }
+
+    // Extract outbound HTTP calls made from scripts and inline handlers
+    summary.ExternalCalls = findExternalCalls(content, jsExternalCallPatterns)
+
+    // Note minified markup or inline scripts, which yield little structure
+    summary.LikelyMinified, summary.ReadableSymbols = detectMinified(content)
+
+    // Extract events emitted and listened for by scripts
+    summary.EventsEmitted, summary.EventsHandled = findEvents(content, jsEventPatterns)
+    summary.RealtimeEndpoints = findRealtimeEndpoints(content, jsRealtimePatterns, summary.EmbeddedJS, nil)
+
+    // Extract embedded CSS
+    styleRegex := regexp.MustCompile(`(?s)<style[^>]*>(.*?)</style>`)
+    styleMatches := styleRegex.FindAllStringSubmatch(content, -1)
+    
+    for _, match := range styleMatches {
+    if len(match) >= 2 {
+        cssContent := match[1]
+        if cssContent != "" {
+	rules := parseCssContent(cssContent)
+	
+	// Adjust line numbers relative to the HTML file
+	baseLineNum := countLines(content[:strings.Index(content, match[0])])
+	for i := range rules {
+	    rules[i].Line += baseLineNum
+	}
+	
+	summary.EmbeddedCSS = append(summary.EmbeddedCSS, rules...)
+        }
+    }
+    }
+
+    // Process HTML elements
+    var processNode func(*html.Node, int) int
+    processNode = func(n *html.Node, currentLine int) int {
+    if n.Type == html.ElementNode {
+        element := HtmlElement{
+	Attributes: make(map[string]string),
+	Line:       currentLine,
+        }
+
+        for _, attr := range n.Attr {
+	if attr.Key == "id" {
+	    element.ID = attr.Val
+	} else if attr.Key == "class" {
+	    element.Classes = strings.Fields(attr.Val)
+	} else {
+	    element.Attributes[attr.Key] = attr.Val
+	}
+        }
+
+        summary.Elements = append(summary.Elements, element)
+    }
+
+    // Estimate line number based on position in the HTML
+    for c := n.FirstChild; c != nil; c = c.NextSibling {
+        currentLine++
+        currentLine = processNode(c, currentLine)
+    }
+
+    return currentLine
+    }
+
+    processNode(doc, 1)
+
+    return summary
+}
+
+// analyzeCssFile analyzes a CSS file
+func analyzeCssFile(filePath string) CSSFileSummary {
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+    fmt.Fprintf(os.Stderr, "Error reading CSS file %s: %v\n", filePath, err)
+    return CSSFileSummary{FilePath: filePath}
+    }
+
+    return analyzeCssContent(filePath, string(data))
+}
+
+// analyzeCssContent analyzes CSS source and returns a CSSFileSummary
+func analyzeCssContent(filePath string, content string) CSSFileSummary {
+    summary := CSSFileSummary{
+    FilePath: filePath,
+    }
+    
+    // Extract @import statements
+    importRegex := regexp.MustCompile(`@import\s+(?:url\()?['"]?([^'")]+)['"]?(?:\))?`)
+    importMatches := importRegex.FindAllStringSubmatch(content, -1)
+    
+    for _, match := range importMatches {
+    if len(match) >= 2 {
+        summary.Imports = append(summary.Imports, match[1])
+    }
+    }
+    
+    // Parse CSS rules
+    summary.Rules = parseCssContent(content)
+    summary.DuplicateSelectors, summary.Conflicts = findCssConflicts(summary.Rules)
+    summary.ZIndexValues = extractZIndexes(summary.Rules)
+    
+    // Note minified stylesheets
+    summary.LikelyMinified, summary.ReadableSymbols = detectMinified(content)
+    
+    return summary
+}
+
+// promoteEmbeddedCss turns the inline styles of an HTML page into a stylesheet with the
+// virtual path "page.html#style", so they take part in the selector index and the
+// duplicate, conflict and z-index checks
+func promoteEmbeddedCss(htmlFile HtmlFileSummary) CSSFileSummary {
+    styles := CSSFileSummary{
+        FilePath: htmlFile.FilePath + "#style",
+        Rules:    htmlFile.EmbeddedCSS,
+    }
+    styles.DuplicateSelectors, styles.Conflicts = findCssConflicts(styles.Rules)
+    styles.ZIndexValues = extractZIndexes(styles.Rules)
+    return styles
+}
+
+// extractZIndexes collects the z-index declarations of the rules, sorted by stacking order
+func extractZIndexes(rules []CSSRule) []ZIndexEntry {
+    var entries []ZIndexEntry
+    for _, rule := range rules {
+        if value, ok := rule.Properties["z-index"]; ok {
+            value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+            entries = append(entries, ZIndexEntry{Selector: rule.Selector, Value: value, Line: rule.Line, MediaQuery: rule.MediaQuery})
+        }
+    }
+    sortZIndexes(entries)
+    return entries
+}
+
+// sortZIndexes orders z-index entries by numeric value, with keywords and variables last
+func sortZIndexes(entries []ZIndexEntry) {
+    sort.SliceStable(entries, func(i, j int) bool {
+        a, errA := strconv.Atoi(entries[i].Value)
+        b, errB := strconv.Atoi(entries[j].Value)
+        if errA != nil || errB != nil {
+            return errA == nil && errB != nil
+        }
+        return a < b
+    })
+}
+
+// findCssConflicts returns the selectors defined by several rules under the same
+// media query, and the properties those rules set to different values
+func findCssConflicts(rules []CSSRule) ([]string, []Conflict) {
+    type ruleKey struct {
+        selector string
+        media    string
+    }
+
+    var order []ruleKey
+    byKey := make(map[ruleKey][]CSSRule)
+    for _, rule := range rules {
+        key := ruleKey{strings.Join(strings.Fields(rule.Selector), " "), rule.MediaQuery}
+        if _, seen := byKey[key]; !seen {
+            order = append(order, key)
+        }
+        byKey[key] = append(byKey[key], rule)
+    }
+
+    var duplicates []string
+    var conflicts []Conflict
+    for _, key := range order {
+        group := byKey[key]
+        if len(group) < 2 {
+            continue
+        }
+        name := key.selector
+        if key.media != "" {
+            name += " @media " + key.media
+        }
+        duplicates = append(duplicates, name)
+
+        // Compare each property across the rules, in source order
+        var properties []string
+        for _, rule := range group {
+            for _, property := range sortedKeys(rule.Properties) {
+                properties = appendIfNotExists(properties, property)
+            }
+        }
+        for _, property := range properties {
+            conflict := Conflict{Selector: key.selector, MediaQuery: key.media, Property: property}
+            distinct := make(map[string]bool)
+            for _, rule := range group {
+                if value, exists := rule.Properties[property]; exists {
+                    conflict.Values = append(conflict.Values, value)
+                    conflict.Lines = append(conflict.Lines, rule.Line)
+                    distinct[strings.ToLower(strings.TrimSpace(value))] = true
+                }
+            }
+            if len(distinct) > 1 {
+                conflicts = append(conflicts, conflict)
+            }
+        }
+    }
+
+    return duplicates, conflicts
+}
+
+// parseCssContent extracts CSS rules from content
+func parseCssContent(content string) []CSSRule {
+    var rules []CSSRule
+    
+    // This is a simplified CSS parser - a real implementation would use a proper CSS parser
+    // But for the purpose of this example, we'll use regex
+    
+    // Extract rules with their selectors and content
+    ruleRegex := regexp.MustCompile(`([^{]+)(?:{([^}]*)})`)
+    ruleMatches := ruleRegex.FindAllStringSubmatch(content, -1)
+    
+    currentMediaQuery := ""
+    
+    for _, match := range ruleMatches {
+    if len(match) >= 3 {
+        selector := strings.TrimSpace(match[1])
+        body := match[2]
+        
+        // Check if this is a media query
+        if strings.HasPrefix(selector, "@media") {
+	currentMediaQuery = selector
+	continue
+        }
+        
+        // Count line number
+        lineNum := countLines(content[:strings.Index(content, match[0])])
+        
+        rule := CSSRule{
+	Selector:   selector,
+	Properties: make(map[string]string),
+	Line:       lineNum,
+        }
+        
+        if currentMediaQuery != "" {
+	rule.MediaQuery = currentMediaQuery
+        }
+        
+        // Extract properties
+        propRegex := regexp.MustCompile(`([\w-]+)\s*:\s*([^;]+)`)
+        propMatches := propRegex.FindAllStringSubmatch(body, -1)
+        
+        for _, propMatch := range propMatches {
+	if len(propMatch) >= 3 {
+	    propName := strings.TrimSpace(propMatch[1])
+	    propValue := strings.TrimSpace(propMatch[2])
+	    rule.Properties[propName] = propValue
+	}
+        }
+        
+        rules = append(rules, rule)
+    }
+    }
+    
+    return rules
+}
+
+// analyzeSqlFile analyzes a SQL file
+func analyzeSqlFile(filePath string) SQLFileSummary {
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+    fmt.Fprintf(os.Stderr, "Error reading SQL file %s: %v\n", filePath, err)
+    return SQLFileSummary{FilePath: filePath}
+    }
+
+    return analyzeSqlContent(filePath, string(data))
+}
+
+// analyzeSqlContent analyzes SQL source and returns a SQLFileSummary
+func analyzeSqlContent(filePath string, content string) SQLFileSummary {
+    summary := SQLFileSummary{
+    FilePath: filePath,
+    }
+    
+    // Split into separate SQL statements
+    statements := splitSqlStatements(content)
+    
+    lineNum := 1
+    for _, stmt := range statements {
+    sqlStmt := parseSqlStatement(stmt, lineNum)
+    if sqlStmt.Type != "" {
+        summary.Statements = append(summary.Statements, sqlStmt)
+    }
+    
+    // Update line number
+    lineNum += countLines(stmt)
+    }
+    
+    return summary
+}
+
+// splitSqlStatements splits SQL content into separate statements
+func splitSqlStatements(content string) []string {
+    var statements []string
+    
+    // This is a simplified SQL splitter - a real implementation would handle more edge cases
+    // Like quoted semicolons, multi-line comments, etc.
+    
+    // Remove comments
+    content = removeSqlComments(content)
+    
+    // Split by semicolons
+    parts := strings.Split(content, ";")
+    
+    for _, part := range parts {
+    part = strings.TrimSpace(part)
+    if part != "" {
+        statements = append(statements, part+";")
+    }
+    }
+    
+    return statements
+}
+
+// removeSqlComments removes SQL comments from content
+func removeSqlComments(content string) string {
+    // Remove single-line comments
+    singleLineRegex := regexp.MustCompile(`--.*?(\r\n|\r|\n|$)`)
+    content = singleLineRegex.ReplaceAllString(content, "$1")
+    
+    // Remove multi-line comments
+    multiLineRegex := regexp.MustCompile(`(?s)/\*.*?\*/`)
+    content = multiLineRegex.ReplaceAllString(content, "")
+    
+    return content
+}
+
+var (
+    migrationFileRegex   = regexp.MustCompile(`^(\d+(?:_\d+)*)_(\w+?)(?:\.(up|down))?\.(sql|rb|py|php)$`)
+    flywayFileRegex      = regexp.MustCompile(`^[VU](\d+(?:[._]\d+)*)__(\w+)\.sql$`)
+    sqlSchemaChangeRegex = regexp.MustCompile("(?is)^(CREATE|ALTER|DROP)\\s+(?:OR\\s+REPLACE\\s+)?(?:UNIQUE\\s+)?(TABLE|INDEX|VIEW|SEQUENCE|TYPE|SCHEMA|TRIGGER|FUNCTION|EXTENSION)\\s+(?:IF\\s+(?:NOT\\s+)?EXISTS\\s+)?([\\w.\"`]+)")
+    railsMigrationRegex  = regexp.MustCompile(`\b(create_table|drop_table|rename_table|add_column|remove_column|rename_column|change_column|add_index|remove_index|add_reference|remove_reference|add_foreign_key|remove_foreign_key)\s*\(?\s*:(\w+)(?:\s*,\s*:(\w+))?`)
+    djangoOperationRegex = regexp.MustCompile(`\bmigrations\.(\w+)\(`)
+    djangoModelRegex     = regexp.MustCompile(`\bmodel_name\s*=\s*['"](\w+)['"]`)
+    djangoNameRegex      = regexp.MustCompile(`\bname\s*=\s*['"](\w+)['"]`)
+    laravelSchemaRegex   = regexp.MustCompile(`Schema::(create|table|drop|dropIfExists|rename)\(\s*['"](\w+)['"]`)
+)
+
+// analyzeMigration recognizes a migration file by its location in a migrations directory
+// and a versioned file name, and lists the schema changes it makes
+func analyzeMigration(path string, relPath string) (Migration, bool) {
+    inMigrationsDir := false
+    for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(relPath)), "/") {
+        switch strings.ToLower(dir) {
+        case "migrations", "migration", "migrate":
+            inMigrationsDir = true
+        }
+    }
+    if !inMigrationsDir {
+        return Migration{}, false
+    }
+
+    name := filepath.Base(path)
+    migration := Migration{FilePath: path}
+    if match := flywayFileRegex.FindStringSubmatch(name); match != nil {
+        migration.Version, migration.Name, migration.Framework = match[1], match[2], "flyway"
+    } else if match := migrationFileRegex.FindStringSubmatch(name); match != nil {
+        migration.Version, migration.Name, migration.Direction = match[1], match[2], match[3]
+        migration.Framework = map[string]string{"sql": "sql", "rb": "rails", "py": "django", "php": "laravel"}[match[4]]
+    } else {
+        return Migration{}, false
+    }
+
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return migration, true
+    }
+    content := string(data)
+
+    switch migration.Framework {
+    case "sql", "flyway":
+        for _, stmt := range splitSqlStatements(content) {
+            if match := sqlSchemaChangeRegex.FindStringSubmatch(stmt); match != nil {
+                change := strings.ToUpper(match[1]) + " " + strings.ToUpper(match[2]) + " " + strings.Trim(match[3], "\"`")
+                migration.Changes = appendIfNotExists(migration.Changes, change)
+            }
+        }
+    case "rails":
+        for _, match := range railsMigrationRegex.FindAllStringSubmatch(content, -1) {
+            target := match[2]
+            if match[3] != "" && strings.Contains(match[1], "column") {
+                target += "." + match[3]
+            }
+            migration.Changes = appendIfNotExists(migration.Changes, match[1]+" "+target)
+        }
+    case "django":
+        // Each operation's arguments run up to the next operation
+        operations := djangoOperationRegex.FindAllStringSubmatchIndex(content, -1)
+        for i, op := range operations {
+            end := len(content)
+            if i+1 < len(operations) {
+                end = operations[i+1][0]
+            }
+            args := content[op[1]:end]
+            change := content[op[2]:op[3]]
+            target := ""
+            if model := djangoModelRegex.FindStringSubmatch(args); model != nil {
+                target = model[1] + "."
+            }
+            if name := djangoNameRegex.FindStringSubmatch(args); name != nil {
+                target += name[1]
+            }
+            if target = strings.TrimSuffix(target, "."); target != "" {
+                change += " " + target
+            }
+            migration.Changes = appendIfNotExists(migration.Changes, change)
+        }
+    case "laravel":
+        for _, match := range laravelSchemaRegex.FindAllStringSubmatch(content, -1) {
+            migration.Changes = appendIfNotExists(migration.Changes, match[1]+" "+match[2])
+        }
+    }
+
+    return migration, true
+}
+
+// sortMigrations orders migrations by directory and then numerically by version,
+// applying the up half of a paired migration before its down half
+func sortMigrations(migrations []Migration) {
+    digits := func(version string) string {
+        version = strings.TrimLeft(strings.NewReplacer("_", "", ".", "").Replace(version), "0")
+        return fmt.Sprintf("%020s", version)
+    }
+    sort.SliceStable(migrations, func(i, j int) bool {
+        a, b := migrations[i], migrations[j]
+        if dirA, dirB := filepath.Dir(a.FilePath), filepath.Dir(b.FilePath); dirA != dirB {
+            return dirA < dirB
+        }
+        if versionA, versionB := digits(a.Version), digits(b.Version); versionA != versionB {
+            return versionA < versionB
+        }
+        return a.Direction == "up" && b.Direction != "up"
+    })
+}
+
+// parseSqlStatement analyzes a single SQL statement
+func parseSqlStatement(stmt string, lineNum int) SQLStatement {
+    sqlStmt := SQLStatement{
+    Line:     lineNum,
+    RawQuery: stmt,
+    }
+    
+    // Determine statement type
+    stmt = strings.TrimSpace(stmt)
+    lowerStmt := strings.ToLower(stmt)
+    
+    // Detect bind placeholders
+    sqlStmt.ParamStyle, sqlStmt.ParamCount, sqlStmt.Params = extractSqlParams(stmt)
+    
+    if strings.HasPrefix(lowerStmt, "select") {
+    sqlStmt.Type = "SELECT"
+    sqlStmt.Tables = extractSqlTables(stmt, "from")
+    sqlStmt.Columns = extractSqlColumns(stmt)
+    sqlStmt.Aggregates, sqlStmt.GroupBy = extractSqlAggregation(stmt)
+    sqlStmt.HasLimit, sqlStmt.Limit, sqlStmt.Offset = extractSqlLimit(stmt)
+    
+    // An aggregate without GROUP BY returns a single row however large the table
+    outer := sqlOuterQuery(stmt)
+    filtered := regexp.MustCompile(`(?i)\bwhere\b`).MatchString(outer)
+    singleRow := len(sqlStmt.Aggregates) > 0 && len(sqlStmt.GroupBy) == 0
+    sqlStmt.Unbounded = !sqlStmt.HasLimit && !filtered && !singleRow && len(sqlStmt.Tables) > 0
+    } else if strings.HasPrefix(lowerStmt, "insert") {
+    sqlStmt.Type = "INSERT"
+    sqlStmt.Tables = extractSqlTables(stmt, "into")
+    sqlStmt.Columns = extractSqlInsertColumns(stmt)
+    } else if strings.HasPrefix(lowerStmt, "update") {
+    sqlStmt.Type = "UPDATE"
+    sqlStmt.Tables = extractSqlTables(stmt, "update")
+    sqlStmt.Columns = extractSqlUpdateColumns(stmt)
+    } else if strings.HasPrefix(lowerStmt, "delete") {
+    sqlStmt.Type = "DELETE"
+    sqlStmt.Tables = extractSqlTables(stmt, "from")
+    } else if strings.HasPrefix(lowerStmt, "create table") {
+    sqlStmt.Type = "CREATE"
+    sqlStmt.Tables = extractSqlTables(stmt, "table")
+    sqlStmt.Columns = extractSqlCreateColumns(stmt)
+    sqlStmt.ForeignKeys = extractSqlForeignKeys(stmt)
+    } else if strings.HasPrefix(lowerStmt, "alter table") {
+    sqlStmt.Type = "ALTER"
+    sqlStmt.Tables = extractSqlTables(stmt, "table")
+    sqlStmt.ForeignKeys = extractSqlForeignKeys(stmt)
+    } else {
+    // Other statement types (DROP, TRUNCATE, etc.)
+    firstWord := strings.Fields(lowerStmt)[0]
+    sqlStmt.Type = strings.ToUpper(firstWord)
+    
+    // Try to find tables
+if strings.Contains(lowerStmt, "table") {
+        sqlStmt.Tables = extractSqlTables(stmt, "table")
+    }
+    }
+    
+    return sqlStmt
+}
+
+// sqlParamPatterns recognizes the placeholder styles used by common database drivers
+var sqlParamPatterns = []struct {
+    style string
+    regex *regexp.Regexp
+}{
+    {"$n", regexp.MustCompile(`\$(\d+)`)},
+    {"%(name)s", regexp.MustCompile(`%\((\w+)\)s`)},
+    {"%s", regexp.MustCompile(`%s`)},
+    {":name", regexp.MustCompile(`(?:^|[^:\w]):([A-Za-z_]\w*)`)},
+    {"@name", regexp.MustCompile(`(?:^|[^@\w])@([A-Za-z_]\w*)`)},
+    {"?", regexp.MustCompile(`\?`)},
+}
+
+// extractSqlParams detects the placeholder style of a statement, how many arguments
+// it binds, and the distinct numbered or named placeholders it uses
+func extractSqlParams(stmt string) (string, int, []string) {
+    // Ignore anything inside string literals
+    stripped := regexp.MustCompile(`'(?:[^']|'')*'`).ReplaceAllString(stmt, "''")
+
+    style := ""
+    count := 0
+    var params []string
+
+    for _, pattern := range sqlParamPatterns {
+        matches := pattern.regex.FindAllStringSubmatch(stripped, -1)
+        if len(matches) == 0 {
+            continue
+        }
+        if style != "" {
+            style = "mixed"
+        } else {
+            style = pattern.style
+        }
+
+        switch pattern.style {
+        case "?", "%s":
+            // Positional placeholders bind one argument each
+            count += len(matches)
+        case "$n":
+            // Numbered placeholders may repeat; the highest number is the argument count
+            highest := 0
+            for _, match := range matches {
+                params = appendIfNotExists(params, "$"+match[1])
+                var n int
+                fmt.Sscanf(match[1], "%d", &n)
+                if n > highest {
+                    highest = n
+                }
+            }
+            count += highest
+        default:
+            // Named placeholders may repeat; each distinct name binds one argument
+            for _, match := range matches {
+                before := len(params)
+                params = appendIfNotExists(params, match[1])
+                if len(params) > before {
+                    count++
+                }
+            }
+        }
+    }
+
+    return style, count, params
+}
+
+// Helper functions for SQL analysis
+func extractSqlTables(stmt string, keyword string) []string {
+    var tables []string
+    
+    lowerStmt := strings.ToLower(stmt)
+    keywordPos := strings.Index(lowerStmt, keyword)
+    
+    if keywordPos == -1 {
+    return tables
+    }
+    
+    // Extract text after the keyword
+    restStmt := stmt[keywordPos+len(keyword):]
+    
+    // Extract table names - this is a simplification
+    // A real implementation would handle JOIN clauses, aliases, etc.
+    tableRegex := regexp.MustCompile(`[a-zA-Z0-9_]+`)
+    tableMatches := tableRegex.FindAllString(restStmt, -1)
+    
+    // Filter out SQL keywords and keep only table names
+    sqlKeywords := map[string]bool{
+    "select": true, "where": true, "group": true, "order": true, "by": true,
+    "having": true, "limit": true, "offset": true, "join": true, "inner": true,
+    "outer": true, "left": true, "right": true, "full": true, "on": true,
+    "as": true, "union": true, "except": true, "intersect": true, "values": true,
+    "set": true, "into": true, "default": true, "if": true, "exists": true,
+    "primary": true, "key": true, "foreign": true, "references": true,
+    }
+    
+    for _, match := range tableMatches {
+    if !sqlKeywords[strings.ToLower(match)] {
+        tables = append(tables, match)
+    }
+    }
+    
+    return tables
+}
+
+// extractSqlColumns extracts column names from a SELECT statement
+func extractSqlColumns(stmt string) []string {
+    var columns []string
+    
+    lowerStmt := strings.ToLower(stmt)
+    selectPos := strings.Index(lowerStmt, "select")
+    fromPos := strings.Index(lowerStmt, "from")
+    
+    if selectPos == -1 || fromPos == -1 || selectPos >= fromPos {
+    return columns
+    }
+    
+    // Extract text between SELECT and FROM
+    columnsText := stmt[selectPos+6:fromPos]
+    
+    // Handle SELECT *
+    if strings.TrimSpace(columnsText) == "*" {
+    columns = append(columns, "*")
+    return columns
+    }
+    
+    // Split by commas
+    columnParts := strings.Split(columnsText, ",")
+    
+    for _, part := range columnParts {
+    part = strings.TrimSpace(part)
+    
+    // Handle column aliases
+    if strings.Contains(part, " as ") {
+        parts := strings.Split(strings.ToLower(part), " as ")
+        if len(parts) >= 2 {
+	part = strings.TrimSpace(parts[1])
+        }
+    }
+    
+    // Extract the column name
+    colRegex := regexp.MustCompile(`[a-zA-Z0-9_]+`)
+    colMatches := colRegex.FindAllString(part, -1)
+    
+    if len(colMatches) > 0 {
+        columns = append(columns, colMatches[len(colMatches)-1])
+    }
+    }
+    
+    return columns
+}
+
+// extractSqlInsertColumns extracts column names from an INSERT statement
+func extractSqlInsertColumns(stmt string) []string {
+    var columns []string
+    
+    // Look for column list in INSERT statement
+    colListRegex := regexp.MustCompile(`\(\s*([^)]+)\s*\)\s*VALUES`)
+    colListMatch := colListRegex.FindStringSubmatch(stmt)
+    
+    if len(colListMatch) >= 2 {
+    colList := colListMatch[1]
+    // Split by commas
+    colParts := strings.Split(colList, ",")
+    
+    for _, part := range colParts {
+        part = strings.TrimSpace(part)
+        // Extract identifier
+        colRegex := regexp.MustCompile(`[a-zA-Z0-9_]+`)
+        colMatches := colRegex.FindAllString(part, -1)
+        
+        if len(colMatches) > 0 {
+	columns = append(columns, colMatches[0])
+        }
+    }
+    }
+    
+    return columns
+}
+
+// extractSqlUpdateColumns extracts column names from an UPDATE statement
+func extractSqlUpdateColumns(stmt string) []string {
+    var columns []string
+    
+    // Look for SET clause in UPDATE statement
+    lowerStmt := strings.ToLower(stmt)
+    setPos := strings.Index(lowerStmt, "set ")
+    wherePos := strings.Index(lowerStmt, "where ")
+    
+    if setPos == -1 {
+    return columns
+    }
+    
+    // Extract SET clause
+    var setClause string
+    if wherePos == -1 {
+    setClause = stmt[setPos+4:]
+    } else {
+    setClause = stmt[setPos+4:wherePos]
+    }
+    
+    // Split by commas
+    colParts := strings.Split(setClause, ",")
+    
+    for _, part := range colParts {
+    // Look for column name before =
+    eqPos := strings.Index(part, "=")
+    if eqPos == -1 {
+        continue
+    }
+    
+    colName := strings.TrimSpace(part[:eqPos])
+    // Extract identifier
+    colRegex := regexp.MustCompile(`[a-zA-Z0-9_]+`)
+    colMatches := colRegex.FindAllString(colName, -1)
+    
+    if len(colMatches) > 0 {
+        columns = append(columns, colMatches[0])
+    }
+    }
+    
+    return columns
+}
+
+// extractSqlCreateColumns extracts column definitions from a CREATE TABLE statement
+func extractSqlCreateColumns(stmt string) []string {
+    var columns []string
+    
+    for _, part := range sqlCreateDefinitions(stmt) {
+        // Skip if this is a constraint or key definition
+        if isSqlConstraintDefinition(part) {
+	continue
+        }
+        
+        // Extract column name (should be the first identifier)
+        colRegex := regexp.MustCompile(`[a-zA-Z0-9_]+`)
+        colMatches := colRegex.FindAllString(part, -1)
+        
+        if len(colMatches) > 0 {
+	columns = append(columns, colMatches[0])
+        }
+    }
+    
+    return columns
+}
+
+// sqlCreateDefinitions splits the parenthesized body of a CREATE TABLE statement into
+// its column and constraint definitions, ignoring commas inside types like DECIMAL(10,2)
+func sqlCreateDefinitions(stmt string) []string {
+    var parts []string
+    
+    open := strings.Index(stmt, "(")
+    if open == -1 {
+    return parts
+    }
+    
+    depth := 0
+    start := open + 1
+    for i := open; i < len(stmt); i++ {
+    switch stmt[i] {
+    case '(':
+        depth++
+    case ')':
+        depth--
+        if depth == 0 {
+	if part := strings.TrimSpace(stmt[start:i]); part != "" {
+	    parts = append(parts, part)
+	}
+	return parts
+        }
+    case ',':
+        if depth == 1 {
+	if part := strings.TrimSpace(stmt[start:i]); part != "" {
+	    parts = append(parts, part)
+	}
+	start = i + 1
+        }
+    }
+    }
+    
+    return parts
+}
+
+// isSqlConstraintDefinition reports whether a CREATE TABLE definition is a table constraint rather than a column
+func isSqlConstraintDefinition(part string) bool {
+    return sqlConstraintRegex.MatchString(part)
+}
+
+var (
+    sqlConstraintRegex = regexp.MustCompile(`(?i)^(constraint|primary\s+key|foreign\s+key|unique|check)\b`)
+    sqlForeignKeyRegex = regexp.MustCompile("(?is)foreign\\s+key\\s*\\(([^)]*)\\)\\s*references\\s+([\\w.\"`\\[\\]]+)\\s*(?:\\(([^)]*)\\))?")
+    sqlInlineReferenceRegex = regexp.MustCompile("(?is)^([\\w\"`\\[\\]]+)\\s.*?\\breferences\\s+([\\w.\"`\\[\\]]+)\\s*(?:\\(([^)]*)\\))?")
+)
+
+// Aggregate calls and the GROUP BY list, which ends at the next clause of the query
+var (
+    sqlAggregateRegex = regexp.MustCompile(`(?i)\b(COUNT|SUM|AVG|MAX|MIN)\s*\(\s*((?:DISTINCT\s+)?[^()]*?)\s*\)`)
+    sqlGroupByRegex   = regexp.MustCompile(`(?is)\bgroup\s+by\s+(.+?)(?:\bhaving\b|\border\s+by\b|\blimit\b|\boffset\b|\bwindow\b|\bunion\b|;|$)`)
+)
+
+// extractSqlAggregation returns the aggregate calls and GROUP BY columns of a SELECT
+func extractSqlAggregation(stmt string) ([]string, []string) {
+    var aggregates, groupBy []string
+
+    for _, match := range sqlAggregateRegex.FindAllStringSubmatch(stmt, -1) {
+        argument := strings.Join(strings.Fields(match[2]), " ")
+        aggregates = appendIfNotExists(aggregates, strings.ToUpper(match[1])+"("+argument+")")
+    }
+
+    if match := sqlGroupByRegex.FindStringSubmatch(stmt); match != nil {
+        // Split on top-level commas, stopping at the parenthesis closing a subquery
+        depth, start := 0, 0
+        list := match[1]
+        for i := 0; i <= len(list); i++ {
+            if i < len(list) && list[i] == '(' {
+                depth++
+                continue
+            }
+            if i < len(list) && list[i] == ')' && depth > 0 {
+                depth--
+                continue
+            }
+            if i < len(list) && list[i] != ')' && (list[i] != ',' || depth > 0) {
+                continue
+            }
+            if column := strings.Join(strings.Fields(list[start:i]), " "); column != "" {
+                groupBy = append(groupBy, column)
+            }
+            if i < len(list) && list[i] == ')' {
+                break
+            }
+            start = i + 1
+        }
+    }
+
+    return aggregates, groupBy
+}
+
+var (
+    sqlLimitCommaRegex = regexp.MustCompile(`(?i)\blimit\s+([\w$:?@]+)\s*,\s*([\w$:?@]+)`)
+    sqlLimitRegex      = regexp.MustCompile(`(?i)\blimit\s+([\w$:?@]+)`)
+    sqlOffsetRegex     = regexp.MustCompile(`(?i)\boffset\s+([\w$:?@]+)`)
+    sqlFetchRegex      = regexp.MustCompile(`(?i)\bfetch\s+(?:first|next)\s+([\w$:?@]+)\s+rows?\s+only`)
+    sqlTopRegex        = regexp.MustCompile(`(?i)^\s*select\s+(?:distinct\s+)?top\s*\(?\s*([\w$:?@]+)`)
+)
+
+// sqlOuterQuery blanks out string literals and everything inside parentheses,
+// leaving only the clauses of the outermost query
+func sqlOuterQuery(stmt string) string {
+    stmt = regexp.MustCompile(`'(?:[^']|'')*'`).ReplaceAllString(stmt, "''")
+    var outer strings.Builder
+    depth := 0
+    for _, c := range stmt {
+        switch {
+        case c == '(':
+            depth++
+        case c == ')' && depth > 0:
+            depth--
+        case depth == 0:
+            outer.WriteRune(c)
+            continue
+        }
+        outer.WriteRune(' ')
+    }
+    return outer.String()
+}
+
+// extractSqlLimit finds the row limit and offset of the outer query, written as
+// LIMIT n [OFFSET m], MySQL's LIMIT m, n, FETCH FIRST n ROWS ONLY or SELECT TOP n.
+// Bound placeholders set only hasLimit, as their values are unknown.
+func extractSqlLimit(stmt string) (bool, int, int) {
+    outer := sqlOuterQuery(stmt)
+    literal := func(value string) int {
+        n, _ := strconv.Atoi(value)
+        return n
+    }
+
+    if match := sqlLimitCommaRegex.FindStringSubmatch(outer); match != nil {
+        return true, literal(match[2]), literal(match[1])
+    }
+    offset := 0
+    if match := sqlOffsetRegex.FindStringSubmatch(outer); match != nil {
+        offset = literal(match[1])
+    }
+    for _, regex := range []*regexp.Regexp{sqlLimitRegex, sqlFetchRegex, sqlTopRegex} {
+        if match := regex.FindStringSubmatch(outer); match != nil {
+            return true, literal(match[1]), offset
+        }
+    }
+    return false, 0, offset
+}
+
+// extractSqlForeignKeys finds FOREIGN KEY constraints and inline REFERENCES clauses
+func extractSqlForeignKeys(stmt string) []ForeignKey {
+    var keys []ForeignKey
+    
+    // Table constraints, possibly over several columns
+    for _, match := range sqlForeignKeyRegex.FindAllStringSubmatch(stmt, -1) {
+    columns := strings.Split(match[1], ",")
+    refColumns := strings.Split(match[3], ",")
+    for i, column := range columns {
+        key := ForeignKey{
+	Column:   trimSqlIdentifier(column),
+	RefTable: trimSqlIdentifier(match[2]),
+        }
+        if i < len(refColumns) {
+	key.RefColumn = trimSqlIdentifier(refColumns[i])
+        }
+        keys = append(keys, key)
+    }
+    }
+    
+    // Column definitions such as "user_id INT REFERENCES users(id)"
+    for _, part := range sqlCreateDefinitions(stmt) {
+    if isSqlConstraintDefinition(part) {
+        continue
+    }
+    if match := sqlInlineReferenceRegex.FindStringSubmatch(part); match != nil {
+        keys = append(keys, ForeignKey{
+	Column:    trimSqlIdentifier(match[1]),
+	RefTable:  trimSqlIdentifier(match[2]),
+	RefColumn: trimSqlIdentifier(match[3]),
+        })
+    }
+    }
+    
+    return keys
+}
+
+// trimSqlIdentifier removes whitespace and quoting from a SQL identifier
+func trimSqlIdentifier(name string) string {
+    return strings.Trim(strings.TrimSpace(name), "\"`[]")
+}
+
+// analyzeMarkdownFile analyzes the fenced code blocks of a Markdown file
+func analyzeMarkdownFile(filePath string) MarkdownFileSummary {
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading Markdown file %s: %v\n", filePath, err)
+        return MarkdownFileSummary{FilePath: filePath}
+    }
+
+    summary := MarkdownFileSummary{
+        FilePath: filePath,
+    }
+
+    lines := strings.Split(string(data), "\n")
+    fenceRegex := regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([^\\s`]*)")
+
+    for i := 0; i < len(lines); i++ {
+        match := fenceRegex.FindStringSubmatch(lines[i])
+        if match == nil {
+            continue
+        }
+
+        fence := match[1]
+        language := strings.ToLower(match[2])
+        startLine := i + 2 // Line numbers are 1-based and the code starts after the fence
+
+        // Collect the block body up to the matching closing fence
+        var body []string
+        i++
+        for ; i < len(lines); i++ {
+            trimmed := strings.TrimSpace(lines[i])
+            if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+                break
+            }
+            body = append(body, lines[i])
+        }
+
+        block := CodeBlock{
+            Language: language,
+            Line:     startLine,
+            Summary:  analyzeCodeBlock(filePath, language, strings.Join(body, "\n")),
+        }
+
+        summary.CodeBlocks = append(summary.CodeBlocks, block)
+    }
+
+    return summary
+}
+
+// analyzeConfigFile analyzes the section structure of an INI or TOML file. Values are
+// redacted unless showValues is set, since config files frequently hold credentials.
+func analyzeConfigFile(filePath string, showValues bool) ConfigFileSummary {
+    format := "ini"
+    if strings.ToLower(filepath.Ext(filePath)) == ".toml" {
+        format = "toml"
+    }
+
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading config file %s: %v\n", filePath, err)
+        return ConfigFileSummary{FilePath: filePath, Format: format}
+    }
+
+    summary := ConfigFileSummary{
+        FilePath: filePath,
+        Format:   format,
+    }
+
+    sectionRegex := regexp.MustCompile(`^\[\[?\s*([^\]]+?)\s*\]\]?\s*(?:[#;].*)?$`)
+    keyRegex := regexp.MustCompile(`^([\w.\-"' ]+?)\s*[=:]\s*(.*)$`)
+
+    // Keys that appear before any section header belong to an unnamed section
+    current := ConfigSection{Line: 1}
+
+    for i, line := range strings.Split(string(data), "\n") {
+        line = strings.TrimSpace(line)
+        if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+            continue
+        }
+
+        if match := sectionRegex.FindStringSubmatch(line); match != nil {
+            if current.Name != "" || len(current.Keys) > 0 {
+                summary.Sections = append(summary.Sections, current)
+            }
+            current = ConfigSection{
+                Name: match[1],
+                Line: i + 1,
+            }
+            continue
+        }
+
+        // TOML only allows "=" between keys and values
+        if format == "toml" && !strings.Contains(line, "=") {
+            continue
+        }
+
+        if match := keyRegex.FindStringSubmatch(line); match != nil {
+            key := strings.Trim(strings.TrimSpace(match[1]), `"'`)
+            current.Keys = appendIfNotExists(current.Keys, key)
+            if showValues {
+                if current.Values == nil {
+                    current.Values = make(map[string]string)
+                }
+                current.Values[key] = strings.TrimSpace(match[2])
+            }
+        }
+    }
+
+    if current.Name != "" || len(current.Keys) > 0 {
+        summary.Sections = append(summary.Sections, current)
+    }
+
+    return summary
+}
+
+// conventionSource returns "editorconfig", "eslint" or "prettier" for formatting config files
+func conventionSource(name string) string {
+    switch {
+    case name == ".editorconfig":
+        return "editorconfig"
+    case name == ".eslintrc" || strings.HasPrefix(name, ".eslintrc."):
+        return "eslint"
+    case name == ".prettierrc" || strings.HasPrefix(name, ".prettierrc."):
+        return "prettier"
+    }
+    return ""
+}
+
+var (
+    // editorconfigKeys are the .editorconfig properties that affect generated code
+    editorconfigKeys = map[string]bool{
+        "indent_style":         true,
+        "indent_size":          true,
+        "tab_width":            true,
+        "max_line_length":      true,
+        "end_of_line":          true,
+        "insert_final_newline": true,
+        "quote_type":           true,
+    }
+
+    // eslintRuleRegex matches formatting rules in JSON, YAML or JS ESLint configs
+    eslintRuleRegex = regexp.MustCompile(`["']?\b(indent|quotes|semi|max-len|comma-dangle|brace-style|eol-last)["']?\s*:\s*(\[[^\]]*\]|"[^"]*"|'[^']*'|\w+)`)
+
+    // prettierOptionRegex matches formatting options in JSON, YAML or JS Prettier configs
+    prettierOptionRegex = regexp.MustCompile(`["']?\b(tabWidth|useTabs|semi|singleQuote|printWidth|trailingComma|bracketSpacing|endOfLine)["']?\s*:\s*("[^"]*"|'[^']*'|\w+)`)
+)
+
+// extractConventions reads the code generation relevant settings of a formatting config file.
+// Keys are prefixed with the source, and .editorconfig keys carry their file glob section.
+func extractConventions(filePath string, source string) map[string]string {
+    conventions := make(map[string]string)
+
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading %s file %s: %v\n", source, filePath, err)
+        return conventions
+    }
+    content := string(data)
+
+    switch source {
+    case "editorconfig":
+        section := "*"
+        for _, line := range strings.Split(content, "\n") {
+            line = strings.TrimSpace(line)
+            if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+                continue
+            }
+            if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+                section = line[1 : len(line)-1]
+                continue
+            }
+            parts := strings.SplitN(line, "=", 2)
+            if len(parts) != 2 {
+                continue
+            }
+            key := strings.ToLower(strings.TrimSpace(parts[0]))
+            if editorconfigKeys[key] {
+                conventions["editorconfig["+section+"]."+key] = strings.TrimSpace(parts[1])
+            }
+        }
+
+    case "eslint":
+        for _, match := range eslintRuleRegex.FindAllStringSubmatch(content, -1) {
+            conventions["eslint."+match[1]] = cleanConventionValue(match[2])
+        }
+
+    case "prettier":
+        for _, match := range prettierOptionRegex.FindAllStringSubmatch(content, -1) {
+            conventions["prettier."+match[1]] = cleanConventionValue(match[2])
+        }
+    }
+
+    return conventions
+}
+
+// cleanConventionValue strips quotes and collapses whitespace in a config value
+func cleanConventionValue(value string) string {
+    value = strings.Join(strings.Fields(value), " ")
+    value = strings.ReplaceAll(value, `"`, "")
+    return strings.ReplaceAll(value, "'", "")
+}
+
+// isDockerfile reports whether a file name follows the Dockerfile naming conventions
+func isDockerfile(name string) bool {
+    lower := strings.ToLower(name)
+    return lower == "dockerfile" || strings.HasPrefix(lower, "dockerfile.") || strings.HasSuffix(lower, ".dockerfile")
+}
+
+// isMakefile reports whether a file name is a Makefile or a make include such as rules.mk
+func isMakefile(name string) bool {
+    return name == "Makefile" || name == "makefile" || name == "GNUmakefile" || strings.HasSuffix(strings.ToLower(name), ".mk")
+}
+
+// isCrontab reports whether a file is a crontab: named crontab, with a .cron or .crontab
+// extension, or in a cron.d directory
+func isCrontab(relPath string) bool {
+    name := strings.ToLower(filepath.Base(relPath))
+    dir := filepath.Base(filepath.Dir(relPath))
+    return name == "crontab" || strings.HasSuffix(name, ".cron") || strings.HasSuffix(name, ".crontab") ||
+        (dir == "cron.d" && filepath.Ext(name) == "")
+}
+
+// cronFieldRegex matches one of the five time fields of a crontab entry
+var cronFieldRegex = regexp.MustCompile(`^(?:[\d*/,\-]+|[A-Za-z]{3}(?:[,\-][A-Za-z]{3})*)$`)
+
+// analyzeCrontab lists the entries of a crontab file. Entries in cron.d name the user
+// to run as before the command, which is left out of the handler.
+func analyzeCrontab(path string, relPath string) []ScheduledJob {
+    content, err := ioutil.ReadFile(path)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading crontab %s: %v\n", path, err)
+        return nil
+    }
+    systemCrontab := filepath.Base(filepath.Dir(relPath)) == "cron.d"
+
+    var jobs []ScheduledJob
+    for i, line := range strings.Split(string(content), "\n") {
+        fields := strings.Fields(line)
+        if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || envKeyRegex.MatchString(line) {
+            continue
+        }
+
+        var schedule string
+        var command []string
+        if strings.HasPrefix(fields[0], "@") {
+            schedule, command = fields[0], fields[1:]
+        } else if len(fields) > 5 {
+            valid := true
+            for _, field := range fields[:5] {
+                if !cronFieldRegex.MatchString(field) {
+                    valid = false
+                    break
+                }
+            }
+            if !valid {
+                continue
+            }
+            schedule, command = strings.Join(fields[:5], " "), fields[5:]
+        } else {
+            continue
+        }
+        if systemCrontab && len(command) > 1 {
+            command = command[1:]
+        }
+
+        jobs = append(jobs, ScheduledJob{
+            Schedule: schedule,
+            Handler:  strings.Join(command, " "),
+            Line:     i + 1,
+            FilePath: path,
+        })
+    }
+    return jobs
+}
+
+// isEnvFile reports whether a file name is a dotenv file such as .env or .env.example
+func isEnvFile(name string) bool {
+    return name == ".env" || strings.HasPrefix(name, ".env.")
+}
+
+// envKeyRegex matches a KEY=value assignment, optionally prefixed with export
+var envKeyRegex = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*=`)
+
+// analyzeEnvFile extracts the keys defined in a dotenv file, leaving out their values
+func analyzeEnvFile(filePath string) EnvFileSummary {
+    summary := EnvFileSummary{
+        FilePath: filePath,
+    }
+
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading env file %s: %v\n", filePath, err)
+        return summary
+    }
+
+    for _, line := range strings.Split(string(data), "\n") {
+        line = strings.TrimSpace(line)
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        if match := envKeyRegex.FindStringSubmatch(line); match != nil {
+            summary.EnvKeys = appendIfNotExists(summary.EnvKeys, match[1])
+        }
+    }
+
+    return summary
+}
+
+// crossReferenceEnvKeys compares the keys documented in env files with the
+// environment variables read in code, returning the used-but-undocumented
+// and documented-but-unused names
+func crossReferenceEnvKeys(summary Summary) ([]string, []string) {
+    documented := make(map[string]bool)
+    for _, f := range summary.EnvFiles {
+        for _, key := range f.EnvKeys {
+            documented[key] = true
+        }
+    }
+
+    used := make(map[string]bool)
+    for _, f := range summary.GoFiles {
+        for _, name := range f.EnvVars {
+            used[name] = true
+        }
+    }
+    for _, f := range summary.PhpFiles {
+        for _, name := range f.EnvVars {
+            used[name] = true
+        }
+    }
+    for _, f := range summary.PythonFiles {
+        for _, name := range f.EnvVars {
+            used[name] = true
+        }
+    }
+    for _, f := range summary.JsFiles {
+        for _, name := range f.EnvVars {
+            used[name] = true
+        }
+    }
+    for _, f := range summary.TsFiles {
+        for _, name := range f.EnvVars {
+            used[name] = true
+        }
+    }
+    // Variables set by a Dockerfile are documented there
+    for _, f := range summary.Dockerfiles {
+        for _, name := range f.EnvVars {
+            documented[name] = true
+        }
+    }
+
+    var undocumented, unused []string
+    for name := range used {
+        if !documented[name] {
+            undocumented = append(undocumented, name)
+        }
+    }
+    for _, f := range summary.EnvFiles {
+        for _, key := range f.EnvKeys {
+            if !used[key] {
+                unused = appendIfNotExists(unused, key)
+            }
+        }
+    }
+    sort.Strings(undocumented)
+    sort.Strings(unused)
+
+    return undocumented, unused
+}
+
+// analyzeDockerfile extracts base images, ports, environment, copies and the
+// start command from a Dockerfile
+func analyzeDockerfile(filePath string) DockerfileSummary {
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading Dockerfile %s: %v\n", filePath, err)
+        return DockerfileSummary{FilePath: filePath}
+    }
+
+    summary := DockerfileSummary{
+        FilePath: filePath,
+    }
+
+    lines := strings.Split(string(data), "\n")
+    for i := 0; i < len(lines); i++ {
+        lineNumber := i + 1
+        line := strings.TrimSpace(lines[i])
+
+        // Join continuation lines
+        for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+            i++
+            line = strings.TrimSuffix(line, "\\") + " " + strings.TrimSpace(lines[i])
+        }
+
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+
+        fields := strings.Fields(line)
+        instruction := strings.ToUpper(fields[0])
+        args := fields[1:]
+        rest := strings.TrimSpace(line[len(fields[0]):])
+
+        // Separate --flag=value options from the instruction arguments
+        flags := make(map[string]string)
+        for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+            parts := strings.SplitN(strings.TrimPrefix(args[0], "--"), "=", 2)
+            if len(parts) == 2 {
+                flags[parts[0]] = parts[1]
+            }
+            args = args[1:]
+        }
+
+        switch instruction {
+        case "FROM":
+            if len(args) > 0 {
+                summary.BaseImages = appendIfNotExists(summary.BaseImages, args[0])
+            }
+            if len(args) >= 3 && strings.ToUpper(args[1]) == "AS" {
+                summary.Stages = append(summary.Stages, args[2])
+            }
+        case "EXPOSE":
+            for _, port := range args {
+                summary.ExposedPorts = appendIfNotExists(summary.ExposedPorts, port)
+            }
+        case "ENV":
+            // Either "ENV KEY value" or "ENV KEY=value KEY2=value2"
+            if len(args) > 0 && !strings.Contains(args[0], "=") {
+                summary.EnvVars = appendIfNotExists(summary.EnvVars, args[0])
+            } else {
+                for _, arg := range args {
+                    if eq := strings.Index(arg, "="); eq > 0 {
+                        summary.EnvVars = appendIfNotExists(summary.EnvVars, arg[:eq])
+                    }
+                }
+            }
+        case "ARG":
+            if len(args) > 0 {
+                summary.BuildArgs = appendIfNotExists(summary.BuildArgs, strings.SplitN(args[0], "=", 2)[0])
+            }
+        case "COPY", "ADD":
+            paths := args
+            var jsonPaths []string
+            if strings.HasPrefix(strings.Join(args, " "), "[") && json.Unmarshal([]byte(strings.Join(args, " ")), &jsonPaths) == nil {
+                paths = jsonPaths
+            }
+            if len(paths) >= 2 {
+                summary.Copies = append(summary.Copies, DockerCopy{
+                    Instruction: instruction,
+                    Sources:     paths[:len(paths)-1],
+                    Dest:        paths[len(paths)-1],
+                    FromStage:   flags["from"],
+                    Line:        lineNumber,
+                })
+            }
+        case "CMD":
+            summary.Cmd = rest
+        case "ENTRYPOINT":
+            summary.Entrypoint = rest
+        }
+    }
+
+    return summary
+}
+
+var (
+    makeVariableRegex = regexp.MustCompile(`^(?:(?:export|override)\s+)*([A-Za-z_.][\w.-]*)\s*(::=|:=|\?=|\+=|!=|=)\s*(.*)$`)
+    makeIncludeRegex  = regexp.MustCompile(`^(?:-|s)?include\s+(.+)$`)
+    makeConditionalRegex = regexp.MustCompile(`^(ifeq|ifneq|ifdef|ifndef|else|endif)\b`)
+)
+
+// makeWords splits a Makefile word list on whitespace, keeping $(...) and ${...}
+// references such as $(wildcard *.go) whole
+func makeWords(text string) []string {
+    var words []string
+    depth, start := 0, -1
+    for i, r := range text {
+        switch {
+        case r == '(' || r == '{':
+            depth++
+        case (r == ')' || r == '}') && depth > 0:
+            depth--
+        case (r == ' ' || r == '\t') && depth == 0:
+            if start >= 0 {
+                words = append(words, text[start:i])
+                start = -1
+            }
+            continue
+        }
+        if start < 0 {
+            start = i
+        }
+    }
+    if start >= 0 {
+        words = append(words, text[start:])
+    }
+    return words
+}
+
+// analyzeMakefile extracts the targets with their prerequisites and recipes, the
+// variables and the included makefiles of a Makefile
+func analyzeMakefile(filePath string) MakefileSummary {
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading Makefile %s: %v\n", filePath, err)
+        return MakefileSummary{FilePath: filePath}
+    }
+
+    summary := MakefileSummary{
+        FilePath: filePath,
+    }
+
+    var phony []string
+    var current []int // Targets of the rule whose recipe is being read
+    inDefine := false
+    lines := strings.Split(string(data), "\n")
+    for i := 0; i < len(lines); i++ {
+        lineNumber := i + 1
+        line := strings.TrimRight(lines[i], "\r")
+
+        // Join continuation lines
+        for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+            i++
+            line = strings.TrimSuffix(line, "\\") + " " + strings.TrimSpace(lines[i])
+        }
+
+        // Recipe lines start with a tab and belong to the rule above them
+        if strings.HasPrefix(line, "\t") && !inDefine {
+            command := strings.TrimSpace(line)
+            if len(current) > 0 && command != "" && !strings.HasPrefix(command, "#") {
+                for _, t := range current {
+                    summary.Targets[t].Commands = append(summary.Targets[t].Commands, command)
+                }
+            }
+            continue
+        }
+
+        line = strings.TrimSpace(line)
+        if inDefine {
+            inDefine = line != "endef"
+            continue
+        }
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+
+        // Multi-line variables are recorded by name only
+        if fields := strings.Fields(line); fields[0] == "define" && len(fields) > 1 {
+            summary.Variables = append(summary.Variables, MakeVariable{Name: fields[1], Operator: "define", Line: lineNumber})
+            inDefine = true
+            current = nil
+            continue
+        }
+        if match := makeIncludeRegex.FindStringSubmatch(line); match != nil {
+            summary.Includes = append(summary.Includes, strings.Fields(match[1])...)
+            current = nil
+            continue
+        }
+        if makeConditionalRegex.MatchString(line) {
+            continue
+        }
+        if match := makeVariableRegex.FindStringSubmatch(line); match != nil {
+            summary.Variables = append(summary.Variables, MakeVariable{Name: match[1], Operator: match[2], Value: match[3], Line: lineNumber})
+            if match[1] == ".DEFAULT_GOAL" {
+                summary.DefaultTarget = match[3]
+            }
+            current = nil
+            continue
+        }
+
+        colon := strings.Index(line, ":")
+        if colon <= 0 {
+            continue
+        }
+        rest := strings.TrimPrefix(line[colon+1:], ":")
+        // Target-specific variables, as in "debug: CFLAGS += -g", are not rules
+        if makeVariableRegex.MatchString(strings.TrimSpace(rest)) {
+            continue
+        }
+        recipe := ""
+        if semi := strings.Index(rest, ";"); semi >= 0 {
+            rest, recipe = rest[:semi], strings.TrimSpace(rest[semi+1:])
+        }
+        // Order-only prerequisites after "|" are still prerequisites
+        prerequisites := makeWords(strings.Replace(rest, "|", " ", 1))
+
+        names := makeWords(line[:colon])
+        if len(names) == 1 && names[0] == ".PHONY" {
+            phony = append(phony, prerequisites...)
+            current = nil
+            continue
+        }
+
+        current = nil
+        for _, name := range names {
+            // Other special targets such as .SUFFIXES configure make itself
+            if strings.HasPrefix(name, ".") && !strings.Contains(name, "/") {
+                continue
+            }
+            if summary.DefaultTarget == "" && !strings.Contains(name, "%") {
+                summary.DefaultTarget = name
+            }
+            target := MakeTarget{Name: name, Prerequisites: prerequisites, Line: lineNumber}
+            if recipe != "" {
+                target.Commands = []string{recipe}
+            }
+            current = append(current, len(summary.Targets))
+            summary.Targets = append(summary.Targets, target)
+        }
+    }
+
+    for i := range summary.Targets {
+        for _, name := range phony {
+            if summary.Targets[i].Name == name {
+                summary.Targets[i].Phony = true
+            }
+        }
+    }
+
+    return summary
+}
+
+// analyzeCodeBlock dispatches a Markdown code block to the analyzer for its language tag
+func analyzeCodeBlock(filePath string, language string, code string) interface{} {
+    switch language {
+    case "go", "golang":
+        // Snippets frequently omit the package clause, so supply one if needed
+        if !regexp.MustCompile(`(?m)^package\s+\w+`).MatchString(code) {
+            goFile := analyzeGoSource(filePath, []byte("package snippet\n"+code))
+            shiftGoLines(&goFile, -1)
+            return goFile
+        }
+        return analyzeGoSource(filePath, []byte(code))
+    case "php":
+        return analyzePhpContent(filePath, code)
+    case "python", "py", "python3":
+        return analyzePythonContent(filePath, normalizePythonIndentation(code, 0))
+    case "javascript", "js", "jsx", "mjs":
+        return analyzeJsContent(filePath, code)
+    case "typescript", "ts", "tsx":
+        return analyzeTsContent(filePath, code)
+    case "html", "htm":
+        return analyzeHtmlContent(filePath, code)
+    case "css":
+        return analyzeCssContent(filePath, code)
+    case "sql":
+        return analyzeSqlContent(filePath, code)
+    }
+
+    return nil
+}
+
+// shiftGoLines adjusts the line numbers of a Go summary by the given offset
+func shiftGoLines(goFile *GoFileSummary, offset int) {
+    if goFile.ParseErrorLine > 0 {
+        goFile.ParseErrorLine += offset
+    }
+    for i := range goFile.Variables {
+        goFile.Variables[i].Line += offset
+    }
+    for i := range goFile.Constants {
+        goFile.Constants[i].Line += offset
+        if goFile.Constants[i].Group > 0 {
+            goFile.Constants[i].Group += offset
+        }
+    }
+    for i := range goFile.Functions {
+        goFile.Functions[i].Line += offset
+        if goFile.Functions[i].EndLine > 0 {
+            goFile.Functions[i].EndLine += offset
+        }
+        for j := range goFile.Functions[i].Args {
+            goFile.Functions[i].Args[j].Line += offset
+        }
+    }
+    for i := range goFile.Structs {
+        goFile.Structs[i].Line += offset
+        for j := range goFile.Structs[i].Fields {
+            goFile.Structs[i].Fields[j].Line += offset
+        }
+    }
+    for i := range goFile.Interfaces {
+        goFile.Interfaces[i].Line += offset
+        for j := range goFile.Interfaces[i].Methods {
+            goFile.Interfaces[i].Methods[j].Line += offset
+        }
+    }
+    shiftControlFlowLines(goFile.ControlFlows, offset)
+}
+
+// shiftControlFlowLines adjusts the line numbers of a control flow tree by the given offset
+func shiftControlFlowLines(controls []ControlFlow, offset int) {
+    for i := range controls {
+        controls[i].Line += offset
+        if controls[i].EndLine > 0 {
+            controls[i].EndLine += offset
+        }
+        shiftControlFlowLines(controls[i].Children, offset)
+    }
+}
+
+// externalCallPattern describes how to recognize an outbound HTTP call in source text.
+// The regex may use "method" and "url" named groups to capture those details.
+type externalCallPattern struct {
+    client string
+    regex  *regexp.Regexp
+}
+
+var (
+    pythonExternalCallPatterns = []externalCallPattern{
+        {"requests", regexp.MustCompile(`requests\.(?P<method>get|post|put|patch|delete|head|options)\(\s*(?:[rbf]?['"](?P<url>[^'"]*)['"])?`)},
+        {"requests", regexp.MustCompile(`requests\.request\(\s*['"](?P<method>\w+)['"]\s*,\s*(?:[rbf]?['"](?P<url>[^'"]*)['"])?`)},
+        {"httpx", regexp.MustCompile(`httpx\.(?P<method>get|post|put|patch|delete|head|options)\(\s*(?:[rbf]?['"](?P<url>[^'"]*)['"])?`)},
+        {"urllib", regexp.MustCompile(`urlopen\(\s*(?:[rbf]?['"](?P<url>[^'"]*)['"])?`)},
+    }
+
+    phpExternalCallPatterns = []externalCallPattern{
+        {"curl", regexp.MustCompile(`curl_init\(\s*(?:['"](?P<url>[^'"]*)['"])?`)},
+        {"curl", regexp.MustCompile(`curl_setopt\([^,]+,\s*CURLOPT_URL\s*,\s*(?:['"](?P<url>[^'"]*)['"])?`)},
+        {"guzzle", regexp.MustCompile(`->request\(\s*['"](?P<method>\w+)['"]\s*,\s*(?:['"](?P<url>[^'"]*)['"])?`)},
+        {"guzzle", regexp.MustCompile(`(?i)\$\w*(?:client|http|guzzle)\w*->(?P<method>get|post|put|patch|delete|head)(?:Async)?\(\s*(?:['"](?P<url>[^'"]*)['"])?`)},
+        {"file_get_contents", regexp.MustCompile(`file_get_contents\(\s*['"](?P<url>https?://[^'"]*)['"]`)},
+    }
+
+    jsExternalCallPatterns = []externalCallPattern{
+        {"fetch", regexp.MustCompile("\\bfetch\\(\\s*(?:['\"`](?P<url>[^'\"`]*)['\"`])?")},
+        {"axios", regexp.MustCompile("axios\\.(?P<method>get|post|put|patch|delete|head|options)\\(\\s*(?:['\"`](?P<url>[^'\"`]*)['\"`])?")},
+        {"XMLHttpRequest", regexp.MustCompile(`\.open\(\s*['"](?P<method>GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)['"]\s*,\s*(?:['"](?P<url>[^'"]*)['"])?`)},
+    }
+)
+
+// findExternalCalls locates outbound HTTP calls in source text using the given patterns
+func findExternalCalls(content string, patterns []externalCallPattern) []ExternalCall {
+    var calls []ExternalCall
+
+    for _, pattern := range patterns {
+        methodIndex := pattern.regex.SubexpIndex("method")
+        urlIndex := pattern.regex.SubexpIndex("url")
+
+        for _, match := range pattern.regex.FindAllStringSubmatchIndex(content, -1) {
+            call := ExternalCall{
+                Client: pattern.client,
+                Line:   countLines(content[:match[0]]),
+            }
+            if methodIndex > 0 && match[2*methodIndex] != -1 {
+                call.Method = strings.ToUpper(content[match[2*methodIndex]:match[2*methodIndex+1]])
+            }
+            if urlIndex > 0 && match[2*urlIndex] != -1 {
+                call.URL = content[match[2*urlIndex]:match[2*urlIndex+1]]
+            }
+            calls = append(calls, call)
+        }
+    }
+
+    // Report calls in source order
+    sort.SliceStable(calls, func(i, j int) bool {
+        return calls[i].Line < calls[j].Line
+    })
+
+    return calls
+}
+
+// realtimePattern recognizes a real-time endpoint of one kind. The regex may capture
+// the path, event and handler in groups of those names.
+type realtimePattern struct {
+    kind  string
+    regex *regexp.Regexp
+}
+
+var (
+    goRealtimePatterns = []realtimePattern{
+        {"websocket", regexp.MustCompile(`\b\w*[uU]pgrader\w*\.Upgrade\(`)},
+        {"websocket", regexp.MustCompile(`\bwebsocket\.(?:Upgrade|Accept)\(`)},
+        {"websocket", regexp.MustCompile(`\bwebsocket\.Handler\(\s*(?P<handler>[\w.]+)`)},
+        {"sse", regexp.MustCompile(`["']text/event-stream["']`)},
+    }
+
+    pythonRealtimePatterns = []realtimePattern{
+        {"websocket", regexp.MustCompile(`@\w+\.websocket(?:_route)?\(\s*['"](?P<path>[^'"]+)['"][^\n]*\n(?:\s*@[^\n]*\n)*\s*(?:async\s+)?def\s+(?P<handler>\w+)`)},
+        {"websocket", regexp.MustCompile(`@\w*sock\w*\.route\(\s*['"](?P<path>[^'"]+)['"][^\n]*\n(?:\s*@[^\n]*\n)*\s*(?:async\s+)?def\s+(?P<handler>\w+)`)},
+        {"websocket", regexp.MustCompile(`\bwebsockets\.serve\(\s*(?P<handler>\w+)`)},
+        {"socketio", regexp.MustCompile(`@\w*(?:socketio|sio)\w*\.(?:on|event)\(\s*['"](?P<event>[^'"]+)['"][^\n]*\n(?:\s*@[^\n]*\n)*\s*(?:async\s+)?def\s+(?P<handler>\w+)`)},
+        {"sse", regexp.MustCompile(`["']text/event-stream["']|\bEventSourceResponse\(`)},
+    }
+
+    phpRealtimePatterns = []realtimePattern{
+        {"websocket", regexp.MustCompile(`class\s+(?P<handler>\w+)[^{]*\bMessageComponentInterface\b`)},
+        {"sse", regexp.MustCompile(`(?i)text/event-stream`)},
+    }
+
+    jsRealtimePatterns = []realtimePattern{
+        {"websocket", regexp.MustCompile("\\bnew\\s+WebSocket\\(\\s*(?:['\"`](?P<path>[^'\"`]*)['\"`])?")},
+        {"websocket", regexp.MustCompile(`\bnew\s+WebSocket(?:\.Server|Server)\(`)},
+        {"websocket", regexp.MustCompile(`\b(?:ws|socket|conn)\w*\.on\(\s*['"](?P<event>message)['"]`)},
+        {"socketio", regexp.MustCompile("\\bio\\(\\s*(?:['\"`](?P<path>[^'\"`]*)['\"`])?")},
+        {"sse", regexp.MustCompile("\\bnew\\s+EventSource\\(\\s*(?:['\"`](?P<path>[^'\"`]*)['\"`])?")},
+    }
+)
+
+// withMethods returns a file's functions followed by the methods of its classes
+func withMethods(functions []Function, classes []Struct) []Function {
+    all := append([]Function{}, functions...)
+    for _, class := range classes {
+        all = append(all, class.Methods...)
+    }
+    return all
+}
+
+// findRealtimeEndpoints locates WebSocket, Socket.IO and SSE endpoints. A handler the
+// pattern doesn't name is the function enclosing the match, and a path it doesn't name
+// comes from the route registered for that handler.
+func findRealtimeEndpoints(content string, patterns []realtimePattern, functions []Function, routes []Route) []RealtimeEndpoint {
+    var endpoints []RealtimeEndpoint
+
+    group := func(pattern realtimePattern, match []int, name string) string {
+        if i := pattern.regex.SubexpIndex(name); i > 0 && match[2*i] != -1 {
+            return content[match[2*i]:match[2*i+1]]
+        }
+        return ""
+    }
+
+    for _, pattern := range patterns {
+        for _, match := range pattern.regex.FindAllStringSubmatchIndex(content, -1) {
+            endpoint := RealtimeEndpoint{
+                Kind:    pattern.kind,
+                Path:    group(pattern, match, "path"),
+                Event:   group(pattern, match, "event"),
+                Handler: group(pattern, match, "handler"),
+                Line:    countLines(content[:match[0]]),
+            }
+
+            // The enclosing function is the one declared last before the match whose body
+            // hasn't ended yet
+            if endpoint.Handler == "" {
+                enclosing := 0
+                for _, fn := range functions {
+                    if fn.EndLine > 0 && fn.EndLine < endpoint.Line {
+                        continue
+                    }
+                    if fn.Line <= endpoint.Line && fn.Line > enclosing {
+                        enclosing = fn.Line
+                        endpoint.Handler = qualifiedFunctionName(fn)
+                    }
+                }
+            }
+            if endpoint.Path == "" && endpoint.Handler != "" {
+                name := endpoint.Handler[strings.LastIndex(endpoint.Handler, ".")+1:]
+                for _, route := range routes {
+                    if route.Handler != "" && route.Handler[strings.LastIndex(route.Handler, ".")+1:] == name {
+                        endpoint.Path = route.Path
+                        break
+                    }
+                }
+            }
+            endpoints = append(endpoints, endpoint)
+        }
+    }
+
+    // Report endpoints in source order
+    sort.SliceStable(endpoints, func(i, j int) bool {
+        return endpoints[i].Line < endpoints[j].Line
+    })
+    return endpoints
+}
+
+// Scheduler registrations. Each regex captures the schedule and the job it runs in
+// groups of those names; anonymous functions leave the handler empty.
+var (
+    goSchedulePatterns = []*regexp.Regexp{
+        regexp.MustCompile(`\.Add(?:Func|Job)\(\s*"(?P<schedule>[^"]+)"\s*,\s*(?P<handler>[\w.]+)`),
+        regexp.MustCompile(`\.(?P<schedule>Every\([^)]*\)(?:\.\w+\([^()]*\))*)\.Do\(\s*(?P<handler>[\w.]+)`),
+        regexp.MustCompile(`gocron\.(?P<schedule>\w+Job\([^()]*\))\s*,\s*gocron\.NewTask\(\s*(?P<handler>[\w.]+)`),
+    }
+
+    pythonSchedulePatterns = []*regexp.Regexp{
+        regexp.MustCompile(`@(?:\w+\.)*periodic_task\(\s*(?:run_every\s*=\s*)?(?P<schedule>[^\n]*?)\)\s*\n(?:\s*@[^\n]*\n)*\s*(?:async\s+)?def\s+(?P<handler>\w+)`),
+        regexp.MustCompile(`@\w+\.scheduled_job\(\s*(?P<schedule>[^\n]*?)\)\s*\n(?:\s*@[^\n]*\n)*\s*(?:async\s+)?def\s+(?P<handler>\w+)`),
+        regexp.MustCompile(`['"]task['"]\s*:\s*['"](?P<handler>[^'"]+)['"][^{}]*?['"]schedule['"]\s*:\s*(?P<schedule>[\w.]+(?:\([^()]*\))?)`),
+        regexp.MustCompile(`['"]schedule['"]\s*:\s*(?P<schedule>[\w.]+(?:\([^()]*\))?)[^{}]*?['"]task['"]\s*:\s*['"](?P<handler>[^'"]+)['"]`),
+        regexp.MustCompile(`\.add_periodic_task\(\s*(?P<schedule>[\w.]+(?:\([^()]*\))?)\s*,\s*(?P<handler>[\w.]+?)(?:\.si?)?\(`),
+        regexp.MustCompile(`\.add_job\(\s*(?P<handler>[\w.]+)\s*,\s*(?P<schedule>['"](?:cron|interval|date)['"][^)\n]*)`),
+        regexp.MustCompile(`\bschedule\.(?P<schedule>every\([^)]*\)(?:\.\w+(?:\([^()]*\))?)*?)\.do\(\s*(?P<handler>[\w.]+)`),
+    }
+
+    phpSchedulePatterns = []*regexp.Regexp{
+        regexp.MustCompile(`(?:\$schedule\s*->|Schedule::)\s*(?:command|job|exec)\(\s*(?P<handler>'[^']*'|"[^"]*"|new\s+[\w\\]+|[\w\\]+::class)[^;]*?\)(?P<schedule>(?:\s*->\s*\w+\([^()]*\))+)\s*;`),
+    }
+)
+
+// findScheduledJobs locates jobs registered with cron and scheduler libraries, in source order
+func findScheduledJobs(content string, patterns []*regexp.Regexp) []ScheduledJob {
+    var jobs []ScheduledJob
+    for _, regex := range patterns {
+        for _, match := range regex.FindAllStringSubmatchIndex(content, -1) {
+            group := func(name string) string {
+                if i := regex.SubexpIndex(name); i > 0 && match[2*i] != -1 {
+                    return strings.TrimSpace(content[match[2*i]:match[2*i+1]])
+                }
+                return ""
+            }
+
+            job := ScheduledJob{
+                Schedule: strings.Join(strings.Fields(group("schedule")), " "),
+                Handler:  strings.Trim(group("handler"), `'"`),
+                Line:     countLines(content[:match[0]]),
+            }
+            // Laravel chains read as "daily()->at('13:00')", whatever the line breaks
+            job.Schedule = strings.TrimPrefix(strings.NewReplacer(" ->", "->", "-> ", "->").Replace(job.Schedule), "->")
+            job.Handler = strings.TrimSpace(strings.TrimPrefix(strings.TrimSuffix(job.Handler, "::class"), "new "))
+            if job.Handler == "func" || job.Handler == "function" || job.Handler == "lambda" {
+                job.Handler = ""
+            }
+            jobs = append(jobs, job)
+        }
+    }
+
+    sort.SliceStable(jobs, func(i, j int) bool {
+        return jobs[i].Line < jobs[j].Line
+    })
+    return jobs
+}
+
+// eventPattern describes how to recognize an event being emitted or handled. The regex
+// captures the event (or event class/signal) name in its first group.
+type eventPattern struct {
+    handled bool
+    regex   *regexp.Regexp
+}
+
+var (
+    goEventPatterns = []eventPattern{
+        {false, regexp.MustCompile(`\.(?:Publish|Emit|Fire|Dispatch)\(\s*(?:\w+,\s*)?"([^"]+)"`)},
+        {true, regexp.MustCompile(`\.(?:Subscribe|SubscribeAsync|On|Listen|Handle)\(\s*(?:\w+,\s*)?"([^"]+)"`)},
+    }
+
+    phpEventPatterns = []eventPattern{
+        {false, regexp.MustCompile(`\b(?:event|dispatch|broadcast)\(\s*new\s+([\w\\]+)`)},
+        {false, regexp.MustCompile(`(?:Event::dispatch|->dispatch)\(\s*(?:new\s+([\w\\]+)|['"]([^'"]+)['"])`)},
+        {false, regexp.MustCompile(`\bdo_action\(\s*['"]([^'"]+)['"]`)},
+        {true, regexp.MustCompile(`(?:Event::listen|->listen|->addListener)\(\s*(?:['"]([^'"]+)['"]|([\w\\]+)::class)`)},
+        {true, regexp.MustCompile(`\badd_action\(\s*['"]([^'"]+)['"]`)},
+        {true, regexp.MustCompile(`function\s+handle\(\s*([\w\\]+)\s+\$event`)},
+    }
+
+    pythonEventPatterns = []eventPattern{
+        {false, regexp.MustCompile(`\.emit\(\s*['"]([^'"]+)['"]`)},
+        {false, regexp.MustCompile(`(\w+)\.send\(\s*sender\s*=`)},
+        {true, regexp.MustCompile(`@receiver\(\s*([\w.]+)`)},
+        {true, regexp.MustCompile(`(\w+)\.connect\(\s*\w+`)},
+        {true, regexp.MustCompile(`\.on\(\s*['"]([^'"]+)['"]`)},
+    }
+
+    jsEventPatterns = []eventPattern{
+        {false, regexp.MustCompile(`\.(?:emit|\$emit|trigger)\(\s*['"]([^'"]+)['"]`)},
+        {false, regexp.MustCompile(`dispatchEvent\(\s*new\s+(?:Custom)?Event\(\s*['"]([^'"]+)['"]`)},
+        {true, regexp.MustCompile(`\.(?:on|once|\$on|addListener)\(\s*['"]([^'"]+)['"]`)},
+        {true, regexp.MustCompile(`addEventListener\(\s*['"]([^'"]+)['"]`)},
+    }
+)
+
+// findEvents returns the names of events emitted and handled in the content
+func findEvents(content string, patterns []eventPattern) ([]string, []string) {
+    var emitted, handled []string
+
+    for _, pattern := range patterns {
+        for _, match := range pattern.regex.FindAllStringSubmatch(content, -1) {
+            // Patterns with alternatives capture the name in whichever group matched
+            name := ""
+            for _, group := range match[1:] {
+                if group != "" {
+                    name = group
+                    break
+                }
+            }
+            if name == "" {
+                continue
+            }
+            if pattern.handled {
+                handled = appendIfNotExists(handled, name)
+            } else {
+                emitted = appendIfNotExists(emitted, name)
+            }
+        }
+    }
+
+    return emitted, handled
+}
+
+// callArguments splits the arguments of the call whose opening parenthesis is at
+// open, respecting nesting and string literals, and returns them with the position
+// just past the closing parenthesis
+func callArguments(content string, open int) ([]string, int) {
+    var args []string
+    depth := 0
+    start := open + 1
+    var quote byte
+    for i := open; i < len(content); i++ {
+        c := content[i]
+        if quote != 0 {
+            if c == '\\' && quote != '`' {
+                i++
+            } else if c == quote {
+                quote = 0
+            }
+            continue
+        }
+        switch c {
+        case '"', '\'', '`':
+            quote = c
+        case '(', '[', '{':
+            depth++
+        case ')', ']', '}':
+            depth--
+            if depth == 0 {
+                if arg := strings.TrimSpace(content[start:i]); arg != "" {
+                    args = append(args, arg)
+                }
+                return args, i + 1
+            }
+        case ',':
+            if depth == 1 {
+                args = append(args, strings.TrimSpace(content[start:i]))
+                start = i + 1
+            }
+        }
+    }
+    return args, len(content)
+}
+
+// unquote strips the quotes around a string literal
+func unquote(s string) string {
+    s = strings.TrimSpace(s)
+    if len(s) >= 2 && strings.ContainsRune(`"'`+"`", rune(s[0])) && s[len(s)-1] == s[0] {
+        return s[1 : len(s)-1]
+    }
+    return s
+}
+
+// middlewareNames expands middleware arguments, including PHP/Python list literals,
+// into plain names
+func middlewareNames(args []string) []string {
+    var names []string
+    for _, arg := range args {
+        arg = strings.TrimSpace(arg)
+        if strings.HasPrefix(arg, "[") && strings.HasSuffix(arg, "]") {
+            items, _ := callArguments("("+arg[1:len(arg)-1]+")", 0)
+            names = append(names, middlewareNames(items)...)
+            continue
+        }
+        if arg != "" {
+            names = append(names, routeHandlerName(arg))
+        }
+    }
+    return names
+}
+
+// routeHandlerName shortens a handler expression: function literals and closures
+// become "closure", Laravel [Controller::class, 'method'] arrays "Controller@method"
+// and FastAPI Depends(fn) "fn"
+func routeHandlerName(expr string) string {
+    expr = strings.TrimSpace(expr)
+    switch {
+    case strings.HasPrefix(expr, "func"), strings.HasPrefix(expr, "function"), strings.HasPrefix(expr, "fn"), strings.HasPrefix(expr, "lambda"):
+        return "closure"
+    case strings.HasPrefix(expr, "[") && strings.HasSuffix(expr, "]"):
+        items, _ := callArguments("("+expr[1:len(expr)-1]+")", 0)
+        if len(items) == 2 {
+            return strings.TrimSuffix(items[0], "::class") + "@" + unquote(items[1])
+        }
+    case strings.HasPrefix(expr, "Depends("):
+        return strings.TrimSuffix(strings.TrimPrefix(expr, "Depends("), ")")
+    }
+    return unquote(expr)
+}
+
+// goRouteEvent is a router call found in Go source, in source order
+type goRouteEvent struct {
+    pos     int
+    kind    string // "use", "group" or "route"
+    router  string // Variable the call is made on
+    target  string // Group variable being assigned
+    method  string
+    args    []string
+}
+
+var (
+    goRouteRegex      = regexp.MustCompile(`\b(\w+)\.(GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|Any|Get|Post|Put|Patch|Delete|Head|Options|Handle|HandleFunc)\(\s*"`)
+    goRouterUseRegex  = regexp.MustCompile(`\b(\w+)\.Use\(`)
+    goRouterGroupRegex = regexp.MustCompile(`\b(\w+)\s*:?=\s*(\w+)\.Group\(`)
+)
+
+// extractGoRoutes finds Gin, Echo, chi and net/http route registrations. Middleware
+// added with Use applies to the routes registered afterwards on the same router
+// and on groups derived from it; handlers listed before the last one are
+// route-level middleware.
+func extractGoRoutes(content string) []Route {
+    var events []goRouteEvent
+    for _, match := range goRouteRegex.FindAllStringSubmatchIndex(content, -1) {
+        args, _ := callArguments(content, match[1]-2)
+        events = append(events, goRouteEvent{pos: match[0], kind: "route", router: content[match[2]:match[3]], method: content[match[4]:match[5]], args: args})
+    }
+    for _, match := range goRouterUseRegex.FindAllStringSubmatchIndex(content, -1) {
+        args, _ := callArguments(content, match[1]-1)
+        events = append(events, goRouteEvent{pos: match[0], kind: "use", router: content[match[2]:match[3]], args: args})
+    }
+    for _, match := range goRouterGroupRegex.FindAllStringSubmatchIndex(content, -1) {
+        args, _ := callArguments(content, match[1]-1)
+        events = append(events, goRouteEvent{pos: match[0], kind: "group", target: content[match[2]:match[3]], router: content[match[4]:match[5]], args: args})
+    }
+    sort.Slice(events, func(i, j int) bool { return events[i].pos < events[j].pos })
+
+    prefixes := make(map[string]string)
+    middleware := make(map[string][]string)
+    var routes []Route
+    for _, event := range events {
+        switch event.kind {
+        case "use":
+            middleware[event.router] = append(middleware[event.router], middlewareNames(event.args)...)
+        case "group":
+            var prefix string
+            var groupMiddleware []string
+            if len(event.args) > 0 {
+                prefix = unquote(event.args[0])
+                groupMiddleware = middlewareNames(event.args[1:])
+            }
+            prefixes[event.target] = prefixes[event.router] + prefix
+            middleware[event.target] = append(append([]string{}, middleware[event.router]...), groupMiddleware...)
+        case "route":
+            if len(event.args) < 2 {
+                continue
+            }
+            route := Route{
+                Method:  strings.ToUpper(event.method),
+                Path:    unquote(event.args[0]),
+                Handler: routeHandlerName(event.args[len(event.args)-1]),
+                Line:    countLines(content[:event.pos]),
+            }
+            if route.Method == "HANDLE" || route.Method == "HANDLEFUNC" {
+                // Go 1.22 patterns may carry the method, as in "GET /users"
+                route.Method = "ANY"
+                if fields := strings.Fields(route.Path); len(fields) == 2 {
+                    route.Method, route.Path = fields[0], fields[1]
+                }
+            }
+            route.Path = prefixes[event.router] + route.Path
+            route.Middleware = append(append([]string{}, middleware[event.router]...), middlewareNames(event.args[1:len(event.args)-1])...)
+            routes = append(routes, route)
+        }
+    }
+    return routes
+}
+
+var (
+    phpRouteStartRegex = regexp.MustCompile(`\bRoute::`)
+    phpRouteVerbs      = map[string]bool{"get": true, "post": true, "put": true, "patch": true, "delete": true, "options": true, "any": true, "match": true, "resource": true, "apiResource": true}
+)
+
+// phpRouteScope is a Laravel route group body with the middleware and prefix it adds
+type phpRouteScope struct {
+    start, end int
+    prefix     string
+    middleware []string
+}
+
+// extractPhpRoutes finds Laravel routes, with middleware given on the route itself
+// through ->middleware(...) and inherited from enclosing Route groups
+func extractPhpRoutes(content string) []Route {
+    type pendingRoute struct {
+        pos   int
+        route Route
+    }
+    var scopes []phpRouteScope
+    var pending []pendingRoute
+
+    for _, match := range phpRouteStartRegex.FindAllStringIndex(content, -1) {
+        // Walk the Route::a(...)->b(...)->c(...) chain
+        pos := match[1]
+        var verb, prefix string
+        var verbArgs, middleware []string
+        for {
+            nameEnd := pos
+            for nameEnd < len(content) && (isWordByte(content[nameEnd])) {
+                nameEnd++
+            }
+            name := content[pos:nameEnd]
+            open := nameEnd
+            for open < len(content) && (content[open] == ' ' || content[open] == '\t') {
+                open++
+            }
+            if name == "" || open >= len(content) || content[open] != '(' {
+                break
+            }
+            args, end := callArguments(content, open)
+            switch {
+            case phpRouteVerbs[name]:
+                verb, verbArgs = name, args
+            case name == "middleware":
+                middleware = append(middleware, middlewareNames(args)...)
+            case name == "prefix" && len(args) > 0:
+                prefix = unquote(args[0])
+            case name == "group":
+                scope := phpRouteScope{start: open, end: end, prefix: prefix, middleware: middleware}
+                // Route::group(['middleware' => ..., 'prefix' => ...], function () { ... })
+                if len(args) > 1 && strings.HasPrefix(args[0], "[") {
+                    options, _ := callArguments("("+strings.TrimSuffix(strings.TrimPrefix(args[0], "["), "]")+")", 0)
+                    for _, option := range options {
+                        parts := strings.SplitN(option, "=>", 2)
+                        if len(parts) != 2 {
+                            continue
+                        }
+                        switch unquote(parts[0]) {
+                        case "middleware":
+                            scope.middleware = append(scope.middleware, middlewareNames([]string{parts[1]})...)
+                        case "prefix":
+                            scope.prefix = unquote(parts[1])
+                        }
+                    }
+                }
+                scopes = append(scopes, scope)
+            }
+            pos = end
+            for pos < len(content) && strings.ContainsRune(" \t\r\n", rune(content[pos])) {
+                pos++
+            }
+            if !strings.HasPrefix(content[pos:], "->") {
+                break
+            }
+            pos += 2
+        }
+
+        if verb == "" || len(verbArgs) < 1 {
+            continue
+        }
+        route := Route{
+            Method:     strings.ToUpper(verb),
+            Path:       unquote(verbArgs[0]),
+            Middleware: middleware,
+            Line:       countLines(content[:match[0]]),
+        }
+        if len(verbArgs) > 1 {
+            route.Handler = routeHandlerName(verbArgs[len(verbArgs)-1])
+        }
+        if verb == "match" && len(verbArgs) > 2 {
+            route.Method = strings.ToUpper(strings.Join(middlewareNames(verbArgs[:1]), "|"))
+            route.Path = unquote(verbArgs[1])
+        }
+        pending = append(pending, pendingRoute{match[0], route})
+    }
+
+    var routes []Route
+    for _, p := range pending {
+        route := p.route
+        var inherited []string
+        prefix := ""
+        // Scopes are found outermost first
+        for _, scope := range scopes {
+            if p.pos > scope.start && p.pos < scope.end {
+                inherited = append(inherited, scope.middleware...)
+                if scope.prefix != "" {
+                    prefix += "/" + strings.Trim(scope.prefix, "/")
+                }
+            }
+        }
+        if prefix != "" {
+            route.Path = prefix + "/" + strings.TrimPrefix(route.Path, "/")
+        }
+        route.Middleware = append(inherited, route.Middleware...)
+        routes = append(routes, route)
+    }
+    return routes
+}
+
+// isWordByte reports whether c can be part of an identifier
+func isWordByte(c byte) bool {
+    return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+var (
+    pythonRouteRegex       = regexp.MustCompile(`(?m)^[ \t]*@(\w+)\.(route|get|post|put|patch|delete|api_route|websocket)\(`)
+    pythonDefRegex         = regexp.MustCompile(`(?m)^[ \t]*(?:async\s+)?def\s+(\w+)`)
+    pythonDecoratorRegex   = regexp.MustCompile(`(?m)^[ \t]*@([\w.]+)`)
+    pythonAppMiddlewareRegex = regexp.MustCompile(`\b(\w+)\.(?:add_middleware|before_request|after_request|middleware)\(\s*([\w.]+)?`)
+    pythonAppHookRegex     = regexp.MustCompile(`(?m)^[ \t]*@(\w+)\.(before_request|after_request|middleware)\b`)
+    pythonMountRegex       = regexp.MustCompile(`\b(\w+)\.(?:register_blueprint|include_router)\(\s*(\w+)`)
+)
+
+// extractPythonRoutes finds Flask and FastAPI routes. Decorators stacked on the
+// handler below the route decorator (login_required and the like) and FastAPI
+// dependencies are route middleware; before_request hooks and add_middleware calls
+// on an app apply to its routes and to the blueprints or routers it mounts.
+func extractPythonRoutes(content string) []Route {
+    appMiddleware := make(map[string][]string)
+    for _, match := range pythonAppMiddlewareRegex.FindAllStringSubmatch(content, -1) {
+        if match[2] != "" {
+            appMiddleware[match[1]] = appendIfNotExists(appMiddleware[match[1]], match[2])
+        }
+    }
+    for _, match := range pythonAppHookRegex.FindAllStringSubmatchIndex(content, -1) {
+        if def := pythonDefRegex.FindStringSubmatch(content[match[1]:]); def != nil {
+            app := content[match[2]:match[3]]
+            appMiddleware[app] = appendIfNotExists(appMiddleware[app], def[1])
+        }
+    }
+    mountedOn := make(map[string]string)
+    for _, match := range pythonMountRegex.FindAllStringSubmatch(content, -1) {
+        mountedOn[match[2]] = match[1]
+    }
+
+    var routes []Route
+    for _, match := range pythonRouteRegex.FindAllStringSubmatchIndex(content, -1) {
+        router := content[match[2]:match[3]]
+        verb := content[match[4]:match[5]]
+        args, end := callArguments(content, match[1]-1)
+        def := pythonDefRegex.FindStringSubmatchIndex(content[end:])
+        if def == nil || len(args) == 0 {
+            continue
+        }
+
+        route := Route{
+            Method:  strings.ToUpper(verb),
+            Path:    unquote(args[0]),
+            Handler: content[end+def[2] : end+def[3]],
+            Line:    countLines(content[:match[0]]),
+        }
+        if verb == "route" || verb == "api_route" {
+            route.Method = "GET"
+        }
+        for _, arg := range args[1:] {
+            parts := strings.SplitN(arg, "=", 2)
+            if len(parts) != 2 {
+                continue
+            }
+            switch strings.TrimSpace(parts[0]) {
+            case "methods":
+                route.Method = strings.ToUpper(strings.Join(middlewareNames([]string{parts[1]}), "|"))
+            case "dependencies":
+                route.Middleware = append(route.Middleware, middlewareNames([]string{parts[1]})...)
+            }
+        }
+
+        // App-wide middleware, following blueprints up to the app they are mounted on
+        var inherited []string
+        for app, seen := router, map[string]bool{}; app != "" && !seen[app]; app = mountedOn[app] {
+            seen[app] = true
+            inherited = append(append([]string{}, appMiddleware[app]...), inherited...)
+        }
+
+        // Decorators between the route decorator and the def wrap the handler
+        for _, decorator := range pythonDecoratorRegex.FindAllStringSubmatch(content[end:end+def[0]], -1) {
+            if !pythonRouteRegex.MatchString(decorator[0] + "(") {
+                route.Middleware = append(route.Middleware, decorator[1])
+            }
+        }
+        route.Middleware = append(append([]string{}, inherited...), route.Middleware...)
+        routes = append(routes, route)
+    }
+    return routes
+}
+
+// Environment variable reads, capturing the variable name
+var (
+    goEnvVarRegex     = regexp.MustCompile(`\bos\.(?:Getenv|LookupEnv)\(\s*"(\w+)"`)
+    pythonEnvVarRegex = regexp.MustCompile(`\bos\.(?:environ\.get\(|environ\[|getenv\()\s*['"](\w+)['"]`)
+    phpEnvVarRegex    = regexp.MustCompile(`(?:\bgetenv\(|\benv\(|\$_ENV\[|\$_SERVER\[)\s*['"]([A-Z][A-Z0-9_]*)['"]`)
+)
+
+// findEnvVars returns the sorted, unique environment variable names read in the content
+func findEnvVars(content string, regex *regexp.Regexp) []string {
+    var names []string
+    for _, match := range regex.FindAllStringSubmatch(content, -1) {
+        names = appendIfNotExists(names, match[1])
+    }
+    sort.Strings(names)
+    return names
+}
+
+var (
+    // sqlKeywordRegex spots text that reads like a SQL statement rather than prose
+    sqlKeywordRegex = regexp.MustCompile(`(?i)\b(?:SELECT\s+\S.*\sFROM\b|INSERT\s+INTO|UPDATE\s+\w+\s+SET|DELETE\s+FROM|WHERE\s+\w+\s*(?:=|<|>|LIKE\b|IN\b))`)
+    // stringLiteralRegex matches a quoted or backquoted string with its Python prefix, if any
+    stringLiteralRegex = regexp.MustCompile(`([fFrRbBuU]{0,2})("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|` + "`[^`]*`)")
+    // Values joined onto a string literal: "..." + name, name + "...", "..." . $name
+    sqlConcatAfterRegex     = regexp.MustCompile(`^\s*\+\s*[\w$(]`)
+    sqlConcatBeforeRegex    = regexp.MustCompile(`[\w)\]]\s*\+\s*$`)
+    sqlPhpConcatAfterRegex  = regexp.MustCompile(`^\s*\.\s*[\w$(]`)
+    sqlPhpConcatBeforeRegex = regexp.MustCompile(`[\w)\]]\s*\.\s*$`)
+    sqlPythonFormatRegex    = regexp.MustCompile(`^\s*(?:%\s*[\w(]|\.format\()`)
+    sqlSprintfRegex         = regexp.MustCompile(`Sprintf\(\s*$`)
+    sqlPhpInterpolationRegex = regexp.MustCompile(`\$\w|\{\$`)
+    sqlVerbRegex            = regexp.MustCompile(`%[sqv]`)
+    sqlIdentifierRegex      = regexp.MustCompile(`^[A-Za-z_][\w.]*$`)
+)
+
+// findSQLConcatenations returns the lines where a SQL string literal is built from values
+// by concatenation, interpolation or string formatting instead of bound parameters.
+// Language is "go", "php", "python" or "javascript"; only single-line expressions are seen.
+func findSQLConcatenations(content string, language string) []int {
+    var lines []int
+    for i, line := range strings.Split(content, "\n") {
+        if !sqlKeywordRegex.MatchString(line) {
+            continue
+        }
+        for _, loc := range stringLiteralRegex.FindAllStringSubmatchIndex(line, -1) {
+            literal := line[loc[4]:loc[5]]
+            if !sqlKeywordRegex.MatchString(literal) {
+                continue
+            }
+            prefix := strings.ToLower(line[loc[2]:loc[3]])
+            if loc[2] > 0 && isWordByte(line[loc[2]-1]) {
+                prefix = ""
+            }
+            before, after := line[:loc[2]], line[loc[1]:]
+
+            built := false
+            switch language {
+            case "go":
+                built = sqlConcatAfterRegex.MatchString(after) || sqlConcatBeforeRegex.MatchString(before) ||
+                    (sqlSprintfRegex.MatchString(before) && sqlVerbRegex.MatchString(literal))
+            case "php":
+                built = sqlPhpConcatAfterRegex.MatchString(after) || sqlPhpConcatBeforeRegex.MatchString(before) ||
+                    (literal[0] == '"' && sqlPhpInterpolationRegex.MatchString(literal))
+            case "python":
+                built = sqlConcatAfterRegex.MatchString(after) || sqlConcatBeforeRegex.MatchString(before) ||
+                    sqlPythonFormatRegex.MatchString(after) || (strings.Contains(prefix, "f") && strings.Contains(literal, "{"))
+            case "javascript":
+                built = sqlConcatAfterRegex.MatchString(after) || sqlConcatBeforeRegex.MatchString(before) ||
+                    (literal[0] == '`' && strings.Contains(literal, "${"))
+            }
+            if built {
+                lines = append(lines, i+1)
+                break
+            }
+        }
+    }
+    return lines
+}
+
+// sqlBacktickRegex matches a backquoted string, which in Go and JavaScript may span lines
+var sqlBacktickRegex = regexp.MustCompile("`[^`]*`")
+
+// findSQLTables returns the sorted tables named by SQL statements held in string literals
+func findSQLTables(content string) []string {
+    var tables []string
+    add := func(literal string) {
+        query := strings.Join(strings.Fields(literal[1:len(literal)-1]), " ")
+        if !sqlKeywordRegex.MatchString(query) {
+            return
+        }
+        for _, table := range parseSqlStatement(query, 0).Tables {
+            if sqlIdentifierRegex.MatchString(table) {
+                tables = appendIfNotExists(tables, table)
+            }
+        }
+    }
+    for _, literal := range sqlBacktickRegex.FindAllString(content, -1) {
+        add(literal)
+    }
+    // Quoted strings end on their line, which keeps apostrophes in comments contained
+    for _, line := range strings.Split(content, "\n") {
+        if !sqlKeywordRegex.MatchString(line) {
+            continue
+        }
+        for _, loc := range stringLiteralRegex.FindAllStringSubmatchIndex(line, -1) {
+            if line[loc[4]] != '`' {
+                add(line[loc[4]:loc[5]])
+            }
+        }
+    }
+    sort.Strings(tables)
+    return tables
+}
+
+// minifiedIgnoredWords are keywords that say nothing about whether identifiers were mangled
+var minifiedIgnoredWords = map[string]bool{
+    "function": true, "return": true, "var": true, "let": true, "const": true,
+    "this": true, "true": true, "false": true, "null": true, "undefined": true,
+    "typeof": true, "new": true, "else": true, "while": true, "for": true,
+    "if": true, "in": true, "of": true, "do": true, "void": true, "case": true,
+    "break": true, "switch": true, "throw": true, "try": true, "catch": true,
+    "class": true, "extends": true, "public": true, "private": true, "static": true,
+    "protected": true, "echo": true, "array": true, "instanceof": true, "delete": true,
+}
+
+// detectMinified reports whether content looks minified or machine-generated, either
+// because its lines are extremely long or because nearly all identifiers are one or two
+// characters. For minified content it also returns the readable names that survived.
+func detectMinified(content string) (bool, []string) {
+    lines := 0
+    longestLine := 0
+    totalLength := 0
+    for _, line := range strings.Split(content, "\n") {
+        length := len(strings.TrimSpace(line))
+        if length == 0 {
+            continue
+        }
+        lines++
+        totalLength += length
+        if length > longestLine {
+            longestLine = length
+        }
+    }
+    if lines == 0 {
+        return false, nil
+    }
+
+    identifiers := make(map[string]bool)
+    for _, ident := range regexp.MustCompile(`[A-Za-z_$][\w$]*`).FindAllString(content, -1) {
+        if !minifiedIgnoredWords[ident] {
+            identifiers[ident] = true
+        }
+    }
+    short := 0
+    for ident := range identifiers {
+        if len(ident) <= 2 {
+            short++
+        }
+    }
+
+    longLines := totalLength/lines > 300 || longestLine > 2000
+    mangledNames := len(identifiers) >= 20 && short*10 >= len(identifiers)*6
+    if !longLines && !mangledNames {
+        return false, nil
+    }
+
+    // Longer names (exports, properties, strings) usually survive minification
+    var readable []string
+    for ident := range identifiers {
+        if len(ident) >= 4 {
+            readable = append(readable, ident)
+        }
+    }
+    sort.Strings(readable)
+    if len(readable) > 50 {
+        readable = readable[:50]
+    }
+
+    return true, readable
+}
+
+// Utility functions
+func countLines(text string) int {
+    return 1 + strings.Count(text, "\n")
+}
+
+func isWithinString(content string, pos int) bool {
+    // Check if the position is within a string literal
+    // This is a simplification - a real implementation would track string states
+    
+    // Count quotes before this position
+    singleQuotes := strings.Count(content[:pos], "'") - strings.Count(content[:pos], "\\'")
+    doubleQuotes := strings.Count(content[:pos], "\"") - strings.Count(content[:pos], "\\\"")
+    
+    return singleQuotes%2 == 1 || doubleQuotes%2 == 1
+}
+
+func isWithinFunction(content string, pos int) bool {
+    // Check if position is inside a function
+    // Find last opening and closing braces before this position
+    openCount := strings.Count(content[:pos], "{")
+    closeCount := strings.Count(content[:pos], "}")
+    
+    return openCount > closeCount
+}
+
+func isWithinClass(content string, pos int) bool {
+    // Find last "class" before this position
+    lastClassPos := strings.LastIndex(content[:pos], "class ")
+    
+    if lastClassPos == -1 {
+    return false
+    }
+    
+    // Find opening brace after "class"
+    openBracePos := strings.Index(content[lastClassPos:pos], "{")
+    
+    if openBracePos == -1 {
+    return false
+    }
+    
+    // Count opening and closing braces between class opening and current position
+    classPart := content[lastClassPos+openBracePos+1:pos]
+    openCount := strings.Count(classPart, "{")
+    closeCount := strings.Count(classPart, "}")
+    
+    return openCount >= closeCount
+}
+
+func isWithinMethod(content string, pos int) bool {
+    // Check if position is inside a method
+    // Find last "function" before this position
+    lastFuncPos := strings.LastIndex(content[:pos], "function ")
+    
+    if lastFuncPos == -1 {
+    return false
+    }
+    
+    // Find opening brace after "function"
+    openBracePos := strings.Index(content[lastFuncPos:pos], "{")
+    
+    if openBracePos == -1 {
+    return false
+    }
+    
+    // Count opening and closing braces between function opening and current position
+    funcPart := content[lastFuncPos+openBracePos+1:pos]
+    openCount := strings.Count(funcPart, "{")
+    closeCount := strings.Count(funcPart, "}")
+    
+    return openCount >= closeCount
+}
+
+// sortedKeys returns the keys of a map in sorted order so iteration is repeatable
+func sortedKeys[V any](m map[string]V) []string {
+    keys := make([]string, 0, len(m))
+    for key := range m {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// findLinkedFunctions finds functions linked to an HTML element
+func findLinkedFunctions(element HtmlElement, allFunctions map[string]Function, allClasses map[string]Struct) []string {
+    var linkedFunctions []string
+
+    // Check for event handlers in attributes
+    for _, key := range sortedKeys(element.Attributes) {
+    value := element.Attributes[key]
+    if strings.HasPrefix(key, "on") && strings.Contains(value, "(") {
+        // Extract function name from event handler like onClick="myFunction()"
+        re := regexp.MustCompile(`([a-zA-Z0-9_]+)\(`)
+        matches := re.FindStringSubmatch(value)
+        if len(matches) > 1 {
+	funcName := matches[1]
+	if _, exists := allFunctions[funcName]; exists {
+	    linkedFunctions = appendIfNotExists(linkedFunctions, funcName)
+	}
+        }
+    }
+    }
+
+    // Check for data-* attributes that might reference functions
+    if funcName, exists := element.Attributes["data-function"]; exists {
+    if _, exists := allFunctions[funcName]; exists {
+        linkedFunctions = appendIfNotExists(linkedFunctions, funcName)
+    }
+    }
+
+    // Check for PHP/JS event handlers
+    if handlerName, exists := element.Attributes["onclick"]; exists {
+    if _, exists := allFunctions[handlerName]; exists {
+        linkedFunctions = appendIfNotExists(linkedFunctions, handlerName)
+    }
+    }
+
+    // Check for form actions that point to PHP scripts
+    if action, exists := element.Attributes["action"]; exists && 
+       (strings.HasSuffix(action, ".php") || strings.Contains(action, ".php?")) {
+    // Extract the PHP script name
+    scriptName := filepath.Base(strings.Split(action, "?")[0])
+    scriptName = strings.TrimSuffix(scriptName, ".php")
+    
+    // Look for functions with matching name
+    for _, funcName := range sortedKeys(allFunctions) {
+        if strings.ToLower(funcName) == strings.ToLower(scriptName) ||
+           strings.HasPrefix(strings.ToLower(funcName), strings.ToLower(scriptName)+"_") {
+	linkedFunctions = appendIfNotExists(linkedFunctions, funcName)
+        }
+    }
+    }
+
+    // Check for hx-get, hx-post, hx-put, etc. attributes (HTMX)
+    for _, key := range sortedKeys(element.Attributes) {
+    value := element.Attributes[key]
+    if strings.HasPrefix(key, "hx-") && strings.Contains(value, "/") {
+        // Extract endpoint path which might map to a handler
+        parts := strings.Split(value, "/")
+        if len(parts) > 0 {
+	lastPart := parts[len(parts)-1]
+	// Check if there's a function with a similar name
+	for _, funcName := range sortedKeys(allFunctions) {
+	    // Convert camelCase or snake_case to lowercase for comparison
+	    funcNameLower := strings.ToLower(funcName)
+	    lastPartLower := strings.ToLower(lastPart)
+	    
+	    // Remove common suffixes for comparison
+	    funcNameLower = strings.TrimSuffix(funcNameLower, "handler")
+	    funcNameLower = strings.TrimSuffix(funcNameLower, "endpoint")
+	    funcNameLower = strings.TrimSuffix(funcNameLower, "controller")
+	    lastPartLower = strings.TrimSuffix(lastPartLower, ".html")
+	    lastPartLower = strings.TrimSuffix(lastPartLower, ".php")
+	    
+	    if strings.Contains(funcNameLower, lastPartLower) || 
+	       strings.Contains(lastPartLower, funcNameLower) {
+	    linkedFunctions = appendIfNotExists(linkedFunctions, funcName)
+	    }
+	}
+        }
+    }
+    }
+
+    return linkedFunctions
+}
+
+// pageStaticExtensions are link targets that are assets or other pages rather than endpoints
+var pageStaticExtensions = map[string]bool{
+    ".html": true, ".htm": true, ".css": true, ".js": true, ".png": true, ".jpg": true,
+    ".jpeg": true, ".gif": true, ".svg": true, ".ico": true, ".webp": true, ".pdf": true,
+    ".woff": true, ".woff2": true, ".ttf": true, ".txt": true, ".xml": true, ".json": true,
+}
+
+// pageEndpoints returns the "METHOD target" requests an HTML element can make
+// through a form action, link or HTMX attribute
+func pageEndpoints(element HtmlElement) []string {
+    var endpoints []string
+
+    add := func(method string, target string) {
+        target = strings.TrimSpace(target)
+        lower := strings.ToLower(target)
+        if target == "" || strings.HasPrefix(target, "#") || strings.Contains(lower, "://") ||
+           strings.HasPrefix(lower, "//") || strings.HasPrefix(lower, "mailto:") ||
+           strings.HasPrefix(lower, "tel:") || strings.HasPrefix(lower, "javascript:") ||
+           strings.HasPrefix(lower, "data:") || strings.Contains(target, "{{") {
+            return
+        }
+        path := strings.SplitN(strings.SplitN(target, "#", 2)[0], "?", 2)[0]
+        if pageStaticExtensions[strings.ToLower(filepath.Ext(path))] {
+            return
+        }
+        endpoints = appendIfNotExists(endpoints, method+" "+path)
+    }
+
+    if action, exists := element.Attributes["action"]; exists {
+        method := strings.ToUpper(element.Attributes["method"])
+        if method == "" {
+            method = "GET"
+        }
+        add(method, action)
+    }
+    if action, exists := element.Attributes["formaction"]; exists {
+        method := strings.ToUpper(element.Attributes["formmethod"])
+        if method == "" {
+            method = "POST"
+        }
+        add(method, action)
+    }
+    // Stylesheets, icons and other <link> targets are filtered out by extension
+    if href, exists := element.Attributes["href"]; exists {
+        add("GET", href)
+    }
+    for _, method := range []string{"get", "post", "put", "patch", "delete"} {
+        if target, exists := element.Attributes["hx-"+method]; exists {
+            add(strings.ToUpper(method), target)
+        }
+    }
+
+    return endpoints
+}
+
+// handlerKey reduces a function name or URL segment to a comparable form, so
+// "save_user", "saveUser", "SaveUserHandler" and "save-user" all match
+func handlerKey(name string) string {
+    key := strings.ToLower(name)
+    for _, r := range []string{"_", "-", "."} {
+        key = strings.ReplaceAll(key, r, "")
+    }
+    for _, suffix := range []string{"handler", "endpoint", "controller"} {
+        if trimmed := strings.TrimSuffix(key, suffix); trimmed != "" {
+            key = trimmed
+        }
+    }
+    if trimmed := strings.TrimPrefix(key, "handle"); trimmed != "" {
+        key = trimmed
+    }
+    return key
+}
+
+// routePathMatches reports whether a request path fits a route pattern, where
+// ":id", "{id}", "<id>" and "*" segments match anything
+func routePathMatches(pattern string, path string) bool {
+    patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+    pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+    if len(patternSegments) != len(pathSegments) {
+        return false
+    }
+    for i, segment := range patternSegments {
+        if segment == "*" || strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "{") || strings.HasPrefix(segment, "<") {
+            continue
+        }
+        if segment != pathSegments[i] {
+            return false
+        }
+    }
+    return true
+}
+
+// buildPageMap links every HTML page to the endpoints it requests, resolving each
+// endpoint against the detected routes and then the analyzed PHP, Python and Go
+// functions. A target naming a PHP script resolves to that file, otherwise the last
+// two path segments, joined either way, and then the last one alone are matched
+// against function names. Unresolved endpoints are kept as-is.
+func buildPageMap(summary Summary) map[string][]string {
+    handlers := make(map[string][]string)
+    addHandler := func(name string, file string) {
+        key := handlerKey(name)
+        if key == "" {
+            return
+        }
+        handlers[key] = appendIfNotExists(handlers[key], name+" ("+file+")")
+    }
+    addMethods := func(file string, classes []Struct) {
+        for _, class := range classes {
+            for _, method := range class.Methods {
+                addHandler(class.Name+"."+method.Name, file)
+                addHandler(method.Name, file)
+            }
+        }
+    }
+
+    type fileRoute struct {
+        route Route
+        file  string
+    }
+    var routes []fileRoute
+    for _, f := range summary.GoFiles {
+        for _, route := range f.Routes {
+            routes = append(routes, fileRoute{route, f.FilePath})
+        }
+    }
+    for _, f := range summary.PhpFiles {
+        for _, route := range f.Routes {
+            routes = append(routes, fileRoute{route, f.FilePath})
+        }
+    }
+    for _, f := range summary.PythonFiles {
+        for _, route := range f.Routes {
+            routes = append(routes, fileRoute{route, f.FilePath})
+        }
+    }
+
+    phpScripts := make(map[string]string)
+    for _, f := range summary.PhpFiles {
+        phpScripts[strings.ToLower(filepath.Base(f.FilePath))] = f.FilePath
+        for _, fn := range f.Functions {
+            addHandler(fn.Name, f.FilePath)
+        }
+        addMethods(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.PythonFiles {
+        for _, fn := range f.Functions {
+            addHandler(fn.Name, f.FilePath)
+        }
+        addMethods(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.GoFiles {
+        for _, fn := range f.Functions {
+            addHandler(fn.Name, f.FilePath)
+        }
+    }
+
+    pageMap := make(map[string][]string)
+    for _, page := range summary.HtmlFiles {
+        var entries []string
+        for _, element := range page.Elements {
+            for _, endpoint := range pageEndpoints(element) {
+                method := endpoint[:strings.Index(endpoint, " ")]
+                path := endpoint[strings.Index(endpoint, " ")+1:]
+
+                var resolved []string
+                for _, r := range routes {
+                    methods := "|" + r.route.Method + "|"
+                    if (r.route.Method == "ANY" || strings.Contains(methods, "|"+method+"|")) && routePathMatches(r.route.Path, path) {
+                        resolved = appendIfNotExists(resolved, r.route.Handler+" ("+r.file+")")
+                    }
+                }
+                // Fall back to file and function names when no route matches
+                if script, exists := phpScripts[strings.ToLower(filepath.Base(path))]; len(resolved) == 0 && exists && strings.HasSuffix(strings.ToLower(path), ".php") {
+                    resolved = append(resolved, script)
+                } else if len(resolved) == 0 {
+                    var segments []string
+                    for _, segment := range strings.Split(path, "/") {
+                        // Skip empty and numeric or placeholder segments such as ids
+                        segment = strings.TrimSuffix(segment, filepath.Ext(segment))
+                        if segment != "" && !strings.ContainsAny(segment, ":{<") && strings.Trim(segment, "0123456789") != "" {
+                            segments = append(segments, segment)
+                        }
+                    }
+                    // Prefer "/users/save" -> save_user or users_save over a bare save
+                    var candidates []string
+                    if n := len(segments); n > 1 {
+                        resource := segments[n-2]
+                        singular := strings.TrimSuffix(resource, "s")
+                        candidates = append(candidates, segments[n-1]+singular, segments[n-1]+resource, resource+segments[n-1], singular+segments[n-1])
+                    }
+                    if len(segments) > 0 {
+                        candidates = append(candidates, segments[len(segments)-1])
+                    }
+                    for _, candidate := range candidates {
+                        if resolved = handlers[handlerKey(candidate)]; len(resolved) > 0 {
+                            break
+                        }
+                    }
+                }
+
+                if len(resolved) == 0 {
+                    entries = appendIfNotExists(entries, endpoint)
+                }
+                for _, handler := range resolved {
+                    entries = appendIfNotExists(entries, endpoint+": "+handler)
+                }
+            }
+        }
+        if len(entries) > 0 {
+            pageMap[page.FilePath] = entries
+        }
+    }
+
+    return pageMap
+}
+
+// groupByPackage reorganizes a summary into groups keyed by package directory, merging
+// files of different languages that live in the same directory into one logical module
+func groupByPackage(summary Summary) PackageGroupedSummary {
+    grouped := PackageGroupedSummary{
+        Packages: make(map[string]*PackageGroup),
+    }
+
+    // group returns the package group for a file, creating it if needed
+    group := func(filePath string, language string, declaredName string) *PackageGroup {
+        dir := filepath.Dir(filePath)
+        pkg, exists := grouped.Packages[dir]
+        if !exists {
+            pkg = &PackageGroup{
+                Name:      filepath.Base(dir),
+                Directory: dir,
+            }
+            grouped.Packages[dir] = pkg
+        }
+        if declaredName != "" && pkg.Name == filepath.Base(dir) {
+            // The first declared package or namespace name wins
+            pkg.Name = declaredName
+        }
+        pkg.Languages = appendIfNotExists(pkg.Languages, language)
+        return pkg
+    }
+
+    for _, f := range summary.GoFiles {
+        pkg := group(f.FilePath, "go", f.Package)
+        pkg.Files.GoFiles = append(pkg.Files.GoFiles, f)
+    }
+    for _, f := range summary.PhpFiles {
+        pkg := group(f.FilePath, "php", f.Namespace)
+        pkg.Files.PhpFiles = append(pkg.Files.PhpFiles, f)
+    }
+    for _, f := range summary.PythonFiles {
+        pkg := group(f.FilePath, "python", "")
+        pkg.Files.PythonFiles = append(pkg.Files.PythonFiles, f)
+    }
+    for _, f := range summary.JsFiles {
+        pkg := group(f.FilePath, "javascript", "")
+        pkg.Files.JsFiles = append(pkg.Files.JsFiles, f)
+    }
+    for _, f := range summary.TsFiles {
+        pkg := group(f.FilePath, "typescript", "")
+        pkg.Files.TsFiles = append(pkg.Files.TsFiles, f)
+    }
+    for _, f := range summary.HtmlFiles {
+        pkg := group(f.FilePath, "html", "")
+        pkg.Files.HtmlFiles = append(pkg.Files.HtmlFiles, f)
+    }
+    for _, f := range summary.CssFiles {
+        pkg := group(f.FilePath, "css", "")
+        pkg.Files.CssFiles = append(pkg.Files.CssFiles, f)
+    }
+    for _, f := range summary.SqlFiles {
+        pkg := group(f.FilePath, "sql", "")
+        pkg.Files.SqlFiles = append(pkg.Files.SqlFiles, f)
+    }
+    for _, f := range summary.MarkdownFiles {
+        pkg := group(f.FilePath, "markdown", "")
+        pkg.Files.MarkdownFiles = append(pkg.Files.MarkdownFiles, f)
+    }
+    for _, f := range summary.ConfigFiles {
+        pkg := group(f.FilePath, f.Format, "")
+        pkg.Files.ConfigFiles = append(pkg.Files.ConfigFiles, f)
+    }
+    for _, f := range summary.Dockerfiles {
+        pkg := group(f.FilePath, "dockerfile", "")
+        pkg.Files.Dockerfiles = append(pkg.Files.Dockerfiles, f)
+    }
+    for _, f := range summary.Makefiles {
+        pkg := group(f.FilePath, "makefile", "")
+        pkg.Files.Makefiles = append(pkg.Files.Makefiles, f)
+    }
+    for _, f := range summary.EnvFiles {
+        pkg := group(f.FilePath, "env", "")
+        pkg.Files.EnvFiles = append(pkg.Files.EnvFiles, f)
+    }
+
+    return grouped
+}
+
+// groupGoPackages gathers the Go files of each package, a package being the files of one
+// directory declaring the same package name, and attaches every method to its struct
+// whichever file of the package declares it
+func groupGoPackages(summary Summary) []GoPackage {
+    var packages []GoPackage
+    index := make(map[string]int)
+    methods := make(map[int][]Function)
+    for _, f := range summary.GoFiles {
+        if f.Package == "" {
+            continue
+        }
+        key := filepath.Dir(f.FilePath) + "\x00" + f.Package
+        i, ok := index[key]
+        if !ok {
+            i = len(packages)
+            index[key] = i
+            packages = append(packages, GoPackage{Name: f.Package, Dir: filepath.Dir(f.FilePath)})
+        }
+        pkg := &packages[i]
+        pkg.Files = append(pkg.Files, f.FilePath)
+        for _, imp := range f.Imports {
+            pkg.Imports = appendIfNotExists(pkg.Imports, imp.Path)
+        }
+        for _, s := range f.Structs {
+            s.Methods = nil
+            pkg.Structs = append(pkg.Structs, s)
+        }
+        pkg.Interfaces = append(pkg.Interfaces, f.Interfaces...)
+        pkg.Constants = append(pkg.Constants, f.Constants...)
+        pkg.Variables = append(pkg.Variables, f.Variables...)
+        for _, fn := range f.Functions {
+            if fn.Receiver != "" {
+                methods[i] = append(methods[i], fn)
+            } else {
+                pkg.Functions = append(pkg.Functions, fn)
+            }
+        }
+    }
+
+    // Methods on types other than structs, such as "type State int", stay with the functions
+    for i := range packages {
+        pkg := &packages[i]
+        sort.Strings(pkg.Imports)
+        structs := make(map[string]int)
+        for j, s := range pkg.Structs {
+            structs[s.Name] = j
+        }
+        for _, fn := range methods[i] {
+            if j, ok := structs[fn.Receiver]; ok {
+                pkg.Structs[j].Methods = append(pkg.Structs[j].Methods, fn)
+            } else {
+                pkg.Functions = append(pkg.Functions, fn)
+            }
+        }
+    }
+    return packages
+}
+
+// schemaColumn returns the database column named by a db or gorm struct tag
+func schemaColumn(tag reflect.StructTag) string {
+    if db := strings.Split(tag.Get("db"), ",")[0]; db != "" && db != "-" {
+        return db
+    }
+    for _, option := range strings.Split(tag.Get("gorm"), ";") {
+        if strings.HasPrefix(option, "column:") {
+            return strings.TrimPrefix(option, "column:")
+        }
+    }
+    return ""
+}
+
+// schemaFields flattens the fields of a struct, following untagged embedded
+// structs declared in the analyzed code the way encoding/json does
+func schemaFields(s Struct, structs map[string]Struct, embeddedFrom string, seen map[string]bool) []SchemaField {
+    var fields []SchemaField
+    seen[s.Name] = true
+    defer delete(seen, s.Name)
+
+    for _, field := range s.Fields {
+        tag := reflect.StructTag(field.Tag)
+        jsonParts := strings.Split(tag.Get("json"), ",")
+
+        // Embedded structs without a JSON name are promoted into the parent
+        if field.Name == field.Type && jsonParts[0] == "" {
+            embeddedName := strings.TrimPrefix(field.Type, "*")
+            if embedded, ok := structs[embeddedName]; ok && !seen[embeddedName] {
+                fields = append(fields, schemaFields(embedded, structs, embeddedName, seen)...)
+                continue
+            }
+        }
+
+        // Unexported fields are never serialized
+        name := strings.TrimPrefix(field.Name, "*")
+        if idx := strings.LastIndex(name, "."); idx >= 0 {
+            name = name[idx+1:]
+        }
+        if name == "" || !ast.IsExported(name) {
+            continue
+        }
+
+        schemaField := SchemaField{
+            Field:        name,
+            DBColumn:     schemaColumn(tag),
+            Type:         field.Type,
+            EmbeddedFrom: embeddedFrom,
+        }
+        switch {
+        case jsonParts[0] == "-" && len(jsonParts) == 1:
+            // Excluded from JSON
+        case jsonParts[0] != "":
+            schemaField.JSONName = jsonParts[0]
+        default:
+            schemaField.JSONName = name
+        }
+        for _, option := range jsonParts[1:] {
+            if option == "omitempty" {
+                schemaField.OmitEmpty = true
+            }
+        }
+
+        fields = append(fields, schemaField)
+    }
+
+    return fields
+}
+
+// buildSchemaView maps every Go struct with json or db tags to its serialized field names
+func buildSchemaView(summary Summary) []SchemaStruct {
+    structs := make(map[string]Struct)
+    for _, goFile := range summary.GoFiles {
+        for _, s := range goFile.Structs {
+            structs[s.Name] = s
+        }
+    }
+
+    var schema []SchemaStruct
+    for _, goFile := range summary.GoFiles {
+        for _, s := range goFile.Structs {
+            tagged := false
+            for _, field := range s.Fields {
+                tag := reflect.StructTag(field.Tag)
+                if tag.Get("json") != "" || schemaColumn(tag) != "" {
+                    tagged = true
+                    break
+                }
+            }
+            if !tagged {
+                continue
+            }
+
+            schema = append(schema, SchemaStruct{
+                Struct:   s.Name,
+                FilePath: goFile.FilePath,
+                Fields:   schemaFields(s, structs, "", make(map[string]bool)),
+            })
+        }
+    }
+
+    return schema
+}
+
+// addSymbolToPattern records a symbol in the pattern file map, keyed by its stable ID when it has one
+func addSymbolToPattern(pattern *PatternSummary, id string, qualifiedName string, fileIndex int) {
+    key := qualifiedName
+    if id != "" {
+        key = id
+        pattern.Symbols[id] = qualifiedName
+    }
+    pattern.FileMap[key] = append(pattern.FileMap[key], fileIndex)
+}
+
+// processPythonFileForPattern extracts pattern information from a Python file
+func processPythonFileForPattern(pyFile PythonFileSummary, fileIndex int, pattern *PatternSummary) {
+    // Add classes to types
+    for _, c := range pyFile.Classes {
+        pattern.Types = append(pattern.Types, c.Name)
+        addSymbolToPattern(pattern, c.ID, c.Name, fileIndex)
+    }
+    
+    // Add functions
+    for _, f := range pyFile.Functions {
+        pattern.Functions = append(pattern.Functions, f.Name)
+        addSymbolToPattern(pattern, f.ID, qualifiedFunctionName(f), fileIndex)
+    }
+    
+    // Add decorators as special "types"
+    for _, decorator := range pyFile.Decorators {
+        decoratorName := "@" + decorator
+        pattern.Types = append(pattern.Types, decoratorName)
+        pattern.FileMap[decoratorName] = append(pattern.FileMap[decoratorName], fileIndex)
+    }
+}
+
+// convertToPatternFormat converts to the AI-friendly pattern format
+func convertToPatternFormat(summary Summary, config Config) PatternSummary {
+    patternSummary := PatternSummary{
+    AnalyzedDir: config.Directory,
+    FileMap:     make(map[string][]int),
+    Symbols:     make(map[string]string),
+    Files:       make([]string, 0),
+    }
+    
+    // A timestamp would make otherwise identical runs differ
+    if !config.Deterministic {
+        patternSummary.Timestamp = time.Now().Format(time.RFC3339)
+    }
+    
+    // Collect all file paths
+    fileIndex := 0
+    
+    // Go files
+    for _, goFile := range summary.GoFiles {
+    patternSummary.Files = append(patternSummary.Files, goFile.FilePath)
+    processGoFileForPattern(goFile, fileIndex, &patternSummary)
+    fileIndex++
+    }
+    
+    // PHP files
+    for _, phpFile := range summary.PhpFiles {
+    patternSummary.Files = append(patternSummary.Files, phpFile.FilePath)
+    processPhpFileForPattern(phpFile, fileIndex, &patternSummary)
+    fileIndex++
+    }
+    
+    // Python files
+    for _, pyFile := range summary.PythonFiles {
+        patternSummary.Files = append(patternSummary.Files, pyFile.FilePath)
+        processPythonFileForPattern(pyFile, fileIndex, &patternSummary)
+        fileIndex++
+    }
+    
+    // JavaScript files
+    for _, jsFile := range summary.JsFiles {
+        patternSummary.Files = append(patternSummary.Files, jsFile.FilePath)
+        processJsFileForPattern(jsFile, fileIndex, &patternSummary)
+        fileIndex++
+    }
+
+    // TypeScript files
+    for _, tsFile := range summary.TsFiles {
+        patternSummary.Files = append(patternSummary.Files, tsFile.FilePath)
+        processTsFileForPattern(tsFile, fileIndex, &patternSummary)
+        fileIndex++
+    }
+    
+    // HTML files
+    for _, htmlFile := range summary.HtmlFiles {
+    patternSummary.Files = append(patternSummary.Files, htmlFile.FilePath)
+    processHtmlFileForPattern(htmlFile, fileIndex, &patternSummary)
+    fileIndex++
+    }
+    
+    // CSS files
+    for _, cssFile := range summary.CssFiles {
+    patternSummary.Files = append(patternSummary.Files, cssFile.FilePath)
+    processCssFileForPattern(cssFile, fileIndex, &patternSummary)
+    fileIndex++
+    }
+    
+    // SQL files
+    for _, sqlFile := range summary.SqlFiles {
+    patternSummary.Files = append(patternSummary.Files, sqlFile.FilePath)
+    processSqlFileForPattern(sqlFile, fileIndex, &patternSummary)
+    fileIndex++
+    }
+    
+    // Markdown files
+    for _, mdFile := range summary.MarkdownFiles {
+        patternSummary.Files = append(patternSummary.Files, mdFile.FilePath)
+        fileIndex++
+    }
+    
+    // Config files
+    for _, configFile := range summary.ConfigFiles {
+        patternSummary.Files = append(patternSummary.Files, configFile.FilePath)
+        processConfigFileForPattern(configFile, fileIndex, &patternSummary)
+        fileIndex++
+    }
+    
+    // Dockerfiles
+    for _, dockerfile := range summary.Dockerfiles {
+        patternSummary.Files = append(patternSummary.Files, dockerfile.FilePath)
+        fileIndex++
+    }
+    
+    // Makefiles
+    for _, makefile := range summary.Makefiles {
+        patternSummary.Files = append(patternSummary.Files, makefile.FilePath)
+        fileIndex++
+    }
+    
+    // Env files
+    for _, envFile := range summary.EnvFiles {
+        patternSummary.Files = append(patternSummary.Files, envFile.FilePath)
+        fileIndex++
+    }
+    
+    // Remove duplicates and sort
+    patternSummary.Types = removeDuplicatesAndSort(patternSummary.Types)
+    patternSummary.Functions = removeDuplicatesAndSort(patternSummary.Functions)
+    patternSummary.CSSSelectors = removeDuplicatesAndSort(patternSummary.CSSSelectors)
+    patternSummary.SQLTables = removeDuplicatesAndSort(patternSummary.SQLTables)
+    sortZIndexes(patternSummary.ZIndexValues)
+    patternSummary.ConfigSections = removeDuplicatesAndSort(patternSummary.ConfigSections)
+    patternSummary.ThirdPartyDependencies = summary.ThirdPartyDependencies
+    patternSummary.Deprecated = deprecatedSymbols(summary)
+    patternSummary.ImportIndex = summary.ImportIndex
+    
+    // Keep the full details
+    if !config.SummaryOnly {
+        patternSummary.Details = &summary
+    }
+    
+    return patternSummary
+}
+
+// functionSignature formats a function's arguments and returns, leaving out types the
+// language doesn't declare
+func functionSignature(fn Function) string {
+    var args []string
+    for _, arg := range fn.Args {
+        args = append(args, strings.TrimSpace(arg.Name+" "+arg.Type))
+    }
+    signature := "(" + strings.Join(args, ", ") + ")"
+    if len(fn.TypeParams) > 0 {
+        var params []string
+        for _, param := range fn.TypeParams {
+            params = append(params, param.Name+" "+param.Constraint)
+        }
+        signature = "[" + strings.Join(params, ", ") + "]" + signature
+    }
+    switch len(fn.Returns) {
+    case 0:
+    case 1:
+        signature += " " + fn.Returns[0]
+    default:
+        signature += " (" + strings.Join(fn.Returns, ", ") + ")"
+    }
+    return signature
+}
+
+// convertToPattern2Format converts to the symbol-keyed pattern format. Files are numbered
+// as in the pattern format, and callers come from the resolved call graph. A function or
+// type whose name another language already defines is keyed "name (language)".
+func convertToPattern2Format(summary Summary, config Config) Pattern2Summary {
+    pattern := convertToPatternFormat(summary, config)
+    result := Pattern2Summary{
+        Timestamp:   pattern.Timestamp,
+        AnalyzedDir: pattern.AnalyzedDir,
+        Files:       pattern.Files,
+        Symbols:     make(map[string]*SymbolEntry),
+        SchemaRelationships:    pattern.SchemaRelationships,
+        ThirdPartyDependencies: pattern.ThirdPartyDependencies,
+    }
+    fileIndices := make(map[string]int)
+    for i, file := range pattern.Files {
+        fileIndices[file] = i
+    }
+
+    // A name defined in several files keeps its first definition's kind and line
+    add := func(name string, kind string, filePath string, line int) *SymbolEntry {
+        entry, ok := result.Symbols[name]
+        if !ok {
+            entry = &SymbolEntry{Kind: kind, Line: line}
+            result.Symbols[name] = entry
+        }
+        index := fileIndices[filePath]
+        if len(entry.Files) == 0 || entry.Files[len(entry.Files)-1] != index {
+            entry.Files = append(entry.Files, index)
+        }
+        return entry
+    }
+    languages := make(map[string]string)
+    inLanguage := func(name string, language string) string {
+        if first, ok := languages[name]; ok && first != language {
+            return name + " (" + language + ")"
+        }
+        languages[name] = language
+        return name
+    }
+    addFunctions := func(language string, filePath string, functions []Function) {
+        for _, fn := range functions {
+            entry := add(inLanguage(qualifiedFunctionName(fn), language), functionKind(fn), filePath, fn.Line)
+            if entry.Signature == "" {
+                entry.Signature = functionSignature(fn)
+            }
+            entry.Calls = append(entry.Calls, fn.Calls...)
+            entry.CalledBy = append(entry.CalledBy, fn.CalledBy...)
+        }
+    }
+    addTypes := func(language string, filePath string, types []Struct, kind string) {
+        for _, t := range types {
+            add(inLanguage(t.Name, language), kind, filePath, t.Line)
+            addFunctions(language, filePath, t.Methods)
+        }
+    }
+    addInterfaces := func(language string, filePath string, interfaces []Interface) {
+        for _, i := range interfaces {
+            add(inLanguage(i.Name, language), "interface", filePath, i.Line)
+        }
+    }
+
+    for _, f := range summary.GoFiles {
+        addTypes("go", f.FilePath, f.Structs, "struct")
+        addInterfaces("go", f.FilePath, f.Interfaces)
+        addFunctions("go", f.FilePath, f.Functions)
+    }
+    for _, f := range summary.PhpFiles {
+        addTypes("php", f.FilePath, f.Classes, "class")
+        addInterfaces("php", f.FilePath, f.Interfaces)
+        addFunctions("php", f.FilePath, f.Functions)
+    }
+    for _, f := range summary.PythonFiles {
+        addTypes("python", f.FilePath, f.Classes, "class")
+        addFunctions("python", f.FilePath, f.Functions)
+        for _, decorator := range f.Decorators {
+            add("@"+decorator, "decorator", f.FilePath, 0)
+        }
+    }
+    for _, f := range summary.JsFiles {
+        addTypes("js", f.FilePath, f.Classes, "class")
+        addFunctions("js", f.FilePath, f.Functions)
+    }
+    for _, f := range summary.TsFiles {
+        addTypes("js", f.FilePath, f.Classes, "class")
+        addInterfaces("js", f.FilePath, f.Interfaces)
+        for _, t := range f.TypeAliases {
+            add(t.Name, "type", f.FilePath, t.Line)
+        }
+        for _, e := range f.Enums {
+            add(e.Name, "enum", f.FilePath, e.Line)
+        }
+        addFunctions("js", f.FilePath, f.Functions)
+    }
+    for _, f := range summary.HtmlFiles {
+        addFunctions("js", f.FilePath, f.EmbeddedJS)
+        for _, elem := range f.Elements {
+            if elem.ID != "" {
+                add("#"+elem.ID, "element", f.FilePath, elem.Line)
+            }
+        }
+    }
+    for _, f := range summary.CssFiles {
+        for _, rule := range f.Rules {
+            add(rule.Selector, "selector", f.FilePath, rule.Line)
+        }
+    }
+    for _, f := range summary.SqlFiles {
+        for _, stmt := range f.Statements {
+            for _, table := range stmt.Tables {
+                add(table, "table", f.FilePath, 0)
+            }
+        }
+    }
+    for _, f := range summary.ConfigFiles {
+        for _, section := range f.Sections {
+            if section.Name != "" {
+                add("["+section.Name+"]", "section", f.FilePath, 0)
+            }
+        }
+    }
+
+    // Go methods are listed both on their own and on their struct
+    for _, entry := range result.Symbols {
+        entry.Calls = removeDuplicatesAndSort(entry.Calls)
+        entry.CalledBy = removeDuplicatesAndSort(entry.CalledBy)
+    }
+    return result
+}
+
+var (
+    deprecationRegex      = regexp.MustCompile(`(?im)^[\s*/#]*(?:@deprecated\b|\.\.\s+deprecated::|deprecated:)[ \t]*(.*)$`)
+    deprecationQuoteRegex = regexp.MustCompile(`["']([^"']+)["']`)
+)
+
+// deprecationNote looks for a Go "Deprecated:" paragraph, a PHPDoc/JSDoc @deprecated
+// tag or a Sphinx ".. deprecated::" directive in doc text and returns its message
+func deprecationNote(doc string) (bool, string) {
+    match := deprecationRegex.FindStringSubmatch(doc)
+    if match == nil {
+        return false, ""
+    }
+    return true, strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(match[1]), "*/"))
+}
+
+// deprecatedSymbols lists the deprecated functions, methods and types in the summary
+func deprecatedSymbols(summary Summary) []string {
+    var deprecated []string
+    add := func(name string, message string) {
+        if message != "" {
+            name += ": " + message
+        }
+        deprecated = append(deprecated, name)
+    }
+    addFunctions := func(functions []Function) {
+        for _, fn := range functions {
+            if fn.IsDeprecated {
+                add(qualifiedFunctionName(fn), fn.Deprecation)
+            }
+        }
+    }
+    addTypes := func(types []Struct) {
+        for _, t := range types {
+            if t.IsDeprecated {
+                add(t.Name, t.Deprecation)
+            }
+            addFunctions(t.Methods)
+        }
+    }
+    for _, f := range summary.GoFiles {
+        addFunctions(f.Functions)
+        addTypes(f.Structs)
+    }
+    for _, f := range summary.PhpFiles {
+        addFunctions(f.Functions)
+        addTypes(f.Classes)
+    }
+    for _, f := range summary.PythonFiles {
+        addFunctions(f.Functions)
+        addTypes(f.Classes)
+    }
+    for _, f := range summary.JsFiles {
+        addFunctions(f.Functions)
+        addTypes(f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        addFunctions(f.Functions)
+        addTypes(f.Classes)
+    }
+    // Go methods are listed both on their own and on their struct
+    return removeDuplicatesAndSort(deprecated)
+}
+
+// processGoFileForPattern extracts pattern information from a Go file
+func processGoFileForPattern(goFile GoFileSummary, fileIndex int, pattern *PatternSummary) {
+    // Add structs to types
+    for _, s := range goFile.Structs {
+    pattern.Types = append(pattern.Types, s.Name)
+    addSymbolToPattern(pattern, s.ID, s.Name, fileIndex)
+    }
+    
+    // Add interfaces to types
+    for _, i := range goFile.Interfaces {
+    pattern.Types = append(pattern.Types, i.Name)
+    pattern.FileMap[i.Name] = append(pattern.FileMap[i.Name], fileIndex)
+    }
+    
+    // Add functions
+    for _, f := range goFile.Functions {
+    pattern.Functions = append(pattern.Functions, f.Name)
+    addSymbolToPattern(pattern, f.ID, qualifiedFunctionName(f), fileIndex)
+    }
+}
+
+// processPhpFileForPattern extracts pattern information from a PHP file
+func processPhpFileForPattern(phpFile PhpFileSummary, fileIndex int, pattern *PatternSummary) {
+    // Add classes to types
+    for _, c := range phpFile.Classes {
+    pattern.Types = append(pattern.Types, c.Name)
+    addSymbolToPattern(pattern, c.ID, c.Name, fileIndex)
+    }
+    
+    // Add interfaces to types
+    for _, i := range phpFile.Interfaces {
+    pattern.Types = append(pattern.Types, i.Name)
+    pattern.FileMap[i.Name] = append(pattern.FileMap[i.Name], fileIndex)
+    }
+    
+    // Add functions
+    for _, f := range phpFile.Functions {
+    pattern.Functions = append(pattern.Functions, f.Name)
+    addSymbolToPattern(pattern, f.ID, qualifiedFunctionName(f), fileIndex)
+    }
+}
+
+// processJsFileForPattern extracts pattern information from a JavaScript file
+func processJsFileForPattern(jsFile JsFileSummary, fileIndex int, pattern *PatternSummary) {
+    for _, c := range jsFile.Classes {
+        pattern.Types = append(pattern.Types, c.Name)
+        addSymbolToPattern(pattern, c.ID, c.Name, fileIndex)
+    }
+    for _, f := range jsFile.Functions {
+        pattern.Functions = append(pattern.Functions, f.Name)
+        addSymbolToPattern(pattern, f.ID, qualifiedFunctionName(f), fileIndex)
+    }
+}
+
+// processTsFileForPattern extracts pattern information from a TypeScript file
+func processTsFileForPattern(tsFile TsFileSummary, fileIndex int, pattern *PatternSummary) {
+    for _, c := range tsFile.Classes {
+        pattern.Types = append(pattern.Types, c.Name)
+        addSymbolToPattern(pattern, c.ID, c.Name, fileIndex)
+    }
+    // Interfaces, type aliases and enums are types too
+    for _, i := range tsFile.Interfaces {
+        pattern.Types = append(pattern.Types, i.Name)
+        pattern.FileMap[i.Name] = append(pattern.FileMap[i.Name], fileIndex)
+    }
+    for _, t := range tsFile.TypeAliases {
+        pattern.Types = append(pattern.Types, t.Name)
+        pattern.FileMap[t.Name] = append(pattern.FileMap[t.Name], fileIndex)
+    }
+    for _, e := range tsFile.Enums {
+        pattern.Types = append(pattern.Types, e.Name)
+        pattern.FileMap[e.Name] = append(pattern.FileMap[e.Name], fileIndex)
+    }
+    for _, f := range tsFile.Functions {
+        pattern.Functions = append(pattern.Functions, f.Name)
+        addSymbolToPattern(pattern, f.ID, qualifiedFunctionName(f), fileIndex)
+    }
+}
+
+// processHtmlFileForPattern extracts pattern information from an HTML file
+func processHtmlFileForPattern(htmlFile HtmlFileSummary, fileIndex int, pattern *PatternSummary) {
+    // Add embedded JS functions
+    for _, f := range htmlFile.EmbeddedJS {
+    pattern.Functions = append(pattern.Functions, f.Name)
+    addSymbolToPattern(pattern, f.ID, f.Name, fileIndex)
+    }
+    
+    // Add element IDs for reference
+    for _, elem := range htmlFile.Elements {
+    if elem.ID != "" {
+        elemId := "#" + elem.ID
+        pattern.FileMap[elemId] = append(pattern.FileMap[elemId], fileIndex)
+    }
+    }
+}
+
+// processCssFileForPattern extracts pattern information from a CSS file
+func processCssFileForPattern(cssFile CSSFileSummary, fileIndex int, pattern *PatternSummary) {
+    // Add CSS selectors
+    for _, rule := range cssFile.Rules {
+    pattern.CSSSelectors = append(pattern.CSSSelectors, rule.Selector)
+    pattern.FileMap[rule.Selector] = append(pattern.FileMap[rule.Selector], fileIndex)
+    }
+
+    for _, entry := range cssFile.ZIndexValues {
+        entry.FilePath = cssFile.FilePath
+        pattern.ZIndexValues = append(pattern.ZIndexValues, entry)
+    }
+}
+
+// processSqlFileForPattern extracts pattern information from a SQL file
+func processSqlFileForPattern(sqlFile SQLFileSummary, fileIndex int, pattern *PatternSummary) {
+    // Add SQL tables
+    for _, stmt := range sqlFile.Statements {
+    for _, table := range stmt.Tables {
+        pattern.SQLTables = append(pattern.SQLTables, table)
+        pattern.FileMap[table] = append(pattern.FileMap[table], fileIndex)
+    }
+    
+    // Collect foreign keys into the schema graph
+    if len(stmt.Tables) > 0 {
+        for _, key := range stmt.ForeignKeys {
+	pattern.SchemaRelationships = append(pattern.SchemaRelationships, Relationship{
+	    FromTable:  stmt.Tables[0],
+	    FromColumn: key.Column,
+	    ToTable:    key.RefTable,
+	    ToColumn:   key.RefColumn,
+	})
+        }
+    }
+    }
+}
+
+// pythonStdlibModules are the top-level modules of the Python 3 standard library
+var pythonStdlibModules = map[string]bool{
+    "abc": true, "aifc": true, "argparse": true, "array": true, "ast": true, "asynchat": true,
+    "asyncio": true, "asyncore": true, "atexit": true, "audioop": true, "base64": true, "bdb": true,
+    "binascii": true, "bisect": true, "builtins": true, "bz2": true, "cProfile": true,
+    "calendar": true, "cgi": true, "cgitb": true, "chunk": true, "cmath": true, "cmd": true,
+    "code": true, "codecs": true, "codeop": true, "collections": true, "colorsys": true,
+    "compileall": true, "concurrent": true, "configparser": true, "contextlib": true,
+    "contextvars": true, "copy": true, "copyreg": true, "crypt": true, "csv": true, "ctypes": true,
+    "curses": true, "dataclasses": true, "datetime": true, "dbm": true, "decimal": true,
+    "difflib": true, "dis": true, "distutils": true, "doctest": true, "email": true,
+    "encodings": true, "ensurepip": true, "enum": true, "errno": true, "faulthandler": true,
+    "fcntl": true, "filecmp": true, "fileinput": true, "fnmatch": true, "fractions": true,
+    "ftplib": true, "functools": true, "gc": true, "genericpath": true, "getopt": true,
+    "getpass": true, "gettext": true, "glob": true, "graphlib": true, "grp": true, "gzip": true,
+    "hashlib": true, "heapq": true, "hmac": true, "html": true, "http": true, "imaplib": true,
+    "imghdr": true, "imp": true, "importlib": true, "inspect": true, "io": true, "ipaddress": true,
+    "itertools": true, "json": true, "keyword": true, "linecache": true, "locale": true,
+    "logging": true, "lzma": true, "mailbox": true, "mailcap": true, "marshal": true, "math": true,
+    "mimetypes": true, "mmap": true, "modulefinder": true, "msilib": true, "msvcrt": true,
+    "multiprocessing": true, "netrc": true, "nis": true, "nntplib": true, "nt": true,
+    "ntpath": true, "nturl2path": true, "numbers": true, "opcode": true, "operator": true,
+    "optparse": true, "os": true, "ossaudiodev": true, "pathlib": true, "pdb": true, "pickle": true,
+    "pickletools": true, "pipes": true, "pkgutil": true, "platform": true, "plistlib": true,
+    "poplib": true, "posix": true, "posixpath": true, "pprint": true, "profile": true,
+    "pstats": true, "pty": true, "pwd": true, "py_compile": true, "pyclbr": true, "pydoc": true,
+    "pyexpat": true, "queue": true, "quopri": true, "random": true, "re": true, "readline": true,
+    "reprlib": true, "resource": true, "rlcompleter": true, "runpy": true, "sched": true,
+    "secrets": true, "select": true, "selectors": true, "shelve": true, "shlex": true,
+    "shutil": true, "signal": true, "site": true, "smtpd": true, "smtplib": true, "sndhdr": true,
+    "socket": true, "socketserver": true, "spwd": true, "sqlite3": true, "sre_compile": true,
+    "sre_constants": true, "sre_parse": true, "ssl": true, "stat": true, "statistics": true,
+    "string": true, "stringprep": true, "struct": true, "subprocess": true, "sunau": true,
+    "symtable": true, "sys": true, "sysconfig": true, "syslog": true, "tabnanny": true,
+    "tarfile": true, "telnetlib": true, "tempfile": true, "termios": true, "textwrap": true,
+    "threading": true, "time": true, "timeit": true, "tkinter": true, "token": true,
+    "tokenize": true, "tomllib": true, "trace": true, "traceback": true, "tracemalloc": true,
+    "tty": true, "turtle": true, "types": true, "typing": true, "unicodedata": true,
+    "unittest": true, "urllib": true, "uu": true, "uuid": true, "venv": true, "warnings": true,
+    "wave": true, "weakref": true, "webbrowser": true, "winreg": true, "winsound": true,
+    "wsgiref": true, "xdrlib": true, "xml": true, "xmlrpc": true, "zipapp": true, "zipfile": true,
+    "zipimport": true, "zlib": true, "zoneinfo": true,
+}
+
+// analyzeGoModFile reads the module path, Go version and requirements of a go.mod
+// file, or the Go version and used module directories of a go.work file
+func analyzeGoModFile(filePath string) GoModule {
+    module := GoModule{FilePath: filePath}
+    data, err := ioutil.ReadFile(filePath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error reading Go module file %s: %v\n", filePath, err)
+        return module
+    }
+
+    block := ""
+    for _, line := range strings.Split(string(data), "\n") {
+        parts := strings.SplitN(line, "//", 2)
+        fields := strings.Fields(parts[0])
+        if len(fields) == 0 {
+            continue
+        }
+        if block != "" && fields[0] == ")" {
+            block = ""
+            continue
+        }
+        verb := block
+        if verb == "" {
+            verb, fields = fields[0], fields[1:]
+            if len(fields) == 1 && fields[0] == "(" {
+                block = verb
+                continue
+            }
+        }
+        if len(fields) == 0 {
+            continue
+        }
+        switch verb {
+        case "module":
+            module.Module = strings.Trim(fields[0], `"`)
+        case "go":
+            module.GoVersion = fields[0]
+        case "toolchain":
+            module.Toolchain = fields[0]
+        case "require":
+            requirement := GoRequirement{Path: strings.Trim(fields[0], `"`)}
+            if len(fields) > 1 {
+                requirement.Version = fields[1]
+            }
+            requirement.Indirect = len(parts) > 1 && strings.TrimSpace(parts[1]) == "indirect"
+            module.Requires = append(module.Requires, requirement)
+        case "replace":
+            module.Replaces = append(module.Replaces, strings.Join(fields, " "))
+        case "use":
+            module.Uses = append(module.Uses, strings.Trim(fields[0], `"`))
+        }
+    }
+    return module
+}
+
+// goLocalModules lists the module paths that are part of the analyzed code: those of
+// its go.mod files, and those replaced by a directory of the tree
+func goLocalModules(modules []GoModule) []string {
+    var local []string
+    for _, module := range modules {
+        if module.Module != "" {
+            local = appendIfNotExists(local, module.Module)
+        }
+        for _, replace := range module.Replaces {
+            sides := strings.SplitN(replace, "=>", 2)
+            if len(sides) < 2 {
+                continue
+            }
+            target := strings.TrimSpace(sides[1])
+            if strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") || filepath.IsAbs(target) {
+                local = appendIfNotExists(local, strings.Fields(sides[0])[0])
+            }
+        }
+    }
+    return local
+}
+
+// goModuleOf returns the longest of the module paths that the import path lies under,
+// or "" when it is under none of them
+func goModuleOf(path string, modules []string) string {
+    best := ""
+    for _, module := range modules {
+        if (path == module || strings.HasPrefix(path, module+"/")) && len(module) > len(best) {
+            best = module
+        }
+    }
+    return best
+}
+
+// goDependency returns the module an external Go import belongs to, using the
+// go.mod requirements when available and the repository root otherwise
+func goDependency(path string, requires []string) string {
+    if module := goModuleOf(path, requires); module != "" {
+        return module
+    }
+    segments := strings.Split(path, "/")
+    switch segments[0] {
+    case "github.com", "gitlab.com", "bitbucket.org", "golang.org":
+        if len(segments) > 3 {
+            return strings.Join(segments[:3], "/")
+        }
+    }
+    return path
+}
+
+// classifyImportOrigins sets the Origin of every Go, PHP, Python and JavaScript import
+// and returns the third-party dependencies of each language. Go imports without a dot
+// in the first path element are stdlib and those under a module of the tree are local.
+// Python imports are local when relative or named after an analyzed module or
+// package directory. PHP includes are third-party only when loaded from vendor/.
+// JavaScript imports are local when given as a path and stdlib when a Node.js builtin.
+func classifyImportOrigins(summary *Summary, dir string) map[string][]string {
+    dependencies := make(map[string][]string)
+
+    // The walk may have left go.mod out, e.g. with -include=*.go
+    modules := summary.Modules
+    if len(modules) == 0 {
+        if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+            modules = []GoModule{analyzeGoModFile(filepath.Join(dir, "go.mod"))}
+        }
+    }
+    local := goLocalModules(modules)
+    var requires []string
+    for _, module := range modules {
+        for _, requirement := range module.Requires {
+            requires = append(requires, requirement.Path)
+        }
+    }
+    for i := range summary.GoFiles {
+        imports := summary.GoFiles[i].Imports
+        for j := range imports {
+            path := imports[j].Path
+            switch {
+            case goModuleOf(path, local) != "":
+                imports[j].Origin = "local"
+            case !strings.Contains(strings.Split(path, "/")[0], "."):
+                imports[j].Origin = "stdlib"
+            default:
+                imports[j].Origin = "thirdparty"
+                dependencies["go"] = appendIfNotExists(dependencies["go"], goDependency(path, requires))
+            }
+        }
+    }
+
+    for i := range summary.PhpFiles {
+        imports := summary.PhpFiles[i].Imports
+        for j := range imports {
+            path := filepath.ToSlash(imports[j].Path)
+            if vendor := strings.Index(path, "vendor/"); vendor >= 0 {
+                imports[j].Origin = "thirdparty"
+                // vendor/<vendor>/<package>/..., or the Composer autoloader itself
+                parts := strings.Split(path[vendor+len("vendor/"):], "/")
+                name := "composer"
+                if len(parts) > 2 {
+                    name = parts[0] + "/" + parts[1]
+                }
+                dependencies["php"] = appendIfNotExists(dependencies["php"], name)
+            } else {
+                imports[j].Origin = "local"
+            }
+        }
+    }
+
+    // Any directory or file name under the analyzed tree can be a local module
+    localModules := make(map[string]bool)
+    for _, f := range summary.PythonFiles {
+        rel, err := filepath.Rel(dir, f.FilePath)
+        if err != nil {
+            rel = f.FilePath
+        }
+        for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+            localModules[strings.TrimSuffix(part, ".py")] = true
+        }
+    }
+    for i := range summary.PythonFiles {
+        imports := summary.PythonFiles[i].Imports
+        for j := range imports {
+            path := strings.TrimSpace(strings.SplitN(imports[j].Path, " as ", 2)[0])
+            top := strings.SplitN(path, ".", 2)[0]
+            switch {
+            case strings.HasPrefix(path, "."):
+                imports[j].Origin = "local"
+            case localModules[top]:
+                imports[j].Origin = "local"
+            case pythonStdlibModules[top]:
+                imports[j].Origin = "stdlib"
+            default:
+                imports[j].Origin = "thirdparty"
+                dependencies["python"] = appendIfNotExists(dependencies["python"], top)
+            }
+        }
+    }
+
+    for i := range summary.JsFiles {
+        imports := summary.JsFiles[i].Imports
+        for j := range imports {
+            path := imports[j].Path
+            switch {
+            case strings.HasPrefix(path, ".") || strings.HasPrefix(path, "/"):
+                imports[j].Origin = "local"
+            case strings.HasPrefix(path, "node:") || nodeBuiltinModules[strings.SplitN(path, "/", 2)[0]]:
+                imports[j].Origin = "stdlib"
+            default:
+                imports[j].Origin = "thirdparty"
+                dependencies["javascript"] = appendIfNotExists(dependencies["javascript"], jsPackageName(path))
+            }
+        }
+    }
+
+    // TypeScript resolves modules the way Node does
+    for i := range summary.TsFiles {
+        imports := summary.TsFiles[i].Imports
+        for j := range imports {
+            path := imports[j].Path
+            switch {
+            case strings.HasPrefix(path, ".") || strings.HasPrefix(path, "/"):
+                imports[j].Origin = "local"
+            case strings.HasPrefix(path, "node:") || nodeBuiltinModules[strings.SplitN(path, "/", 2)[0]]:
+                imports[j].Origin = "stdlib"
+            default:
+                imports[j].Origin = "thirdparty"
+                dependencies["typescript"] = appendIfNotExists(dependencies["typescript"], jsPackageName(path))
+            }
+        }
+    }
+
+    for language := range dependencies {
+        sort.Strings(dependencies[language])
+    }
+    return dependencies
+}
+
+// nodeBuiltinModules are the modules shipped with Node.js, importable without the node: prefix
+var nodeBuiltinModules = map[string]bool{
+    "assert": true, "async_hooks": true, "buffer": true, "child_process": true, "cluster": true,
+    "console": true, "constants": true, "crypto": true, "dgram": true, "diagnostics_channel": true,
+    "dns": true, "domain": true, "events": true, "fs": true, "http": true, "http2": true,
+    "https": true, "inspector": true, "module": true, "net": true, "os": true, "path": true,
+    "perf_hooks": true, "process": true, "punycode": true, "querystring": true, "readline": true,
+    "repl": true, "stream": true, "string_decoder": true, "timers": true, "tls": true,
+    "trace_events": true, "tty": true, "url": true, "util": true, "v8": true, "vm": true,
+    "wasi": true, "worker_threads": true, "zlib": true,
+}
+
+// jsPackageName returns the npm package an import belongs to, keeping the scope of
+// scoped packages such as "@babel/core"
+func jsPackageName(path string) string {
+    parts := strings.Split(path, "/")
+    if strings.HasPrefix(path, "@") && len(parts) > 1 {
+        return parts[0] + "/" + parts[1]
+    }
+    return parts[0]
+}
+
+// clearImportUsage removes the import usage details from Go and Python files
+func clearImportUsage(summary *Summary) {
+    clear := func(imports []Import) {
+        for i := range imports {
+            imports[i].UsedSymbols = nil
+            imports[i].Unused = false
+        }
+    }
+    for i := range summary.GoFiles {
+        clear(summary.GoFiles[i].Imports)
+    }
+    for i := range summary.PythonFiles {
+        clear(summary.PythonFiles[i].Imports)
+    }
+    for i := range summary.MarkdownFiles {
+        for _, block := range summary.MarkdownFiles[i].CodeBlocks {
+            switch blockSummary := block.Summary.(type) {
+            case GoFileSummary:
+                clear(blockSummary.Imports)
+            case PythonFileSummary:
+                clear(blockSummary.Imports)
+            }
+        }
+    }
+}
+
+// normalizePaths converts file paths and HTML includes to forward slashes
+func normalizePaths(summary *Summary) {
+    for i := range summary.GoFiles {
+        summary.GoFiles[i].FilePath = filepath.ToSlash(summary.GoFiles[i].FilePath)
+    }
+    for i := range summary.PhpFiles {
+        summary.PhpFiles[i].FilePath = filepath.ToSlash(summary.PhpFiles[i].FilePath)
+    }
+    for i := range summary.PythonFiles {
+        summary.PythonFiles[i].FilePath = filepath.ToSlash(summary.PythonFiles[i].FilePath)
+    }
+    for i := range summary.JsFiles {
+        summary.JsFiles[i].FilePath = filepath.ToSlash(summary.JsFiles[i].FilePath)
+    }
+    for i := range summary.TsFiles {
+        summary.TsFiles[i].FilePath = filepath.ToSlash(summary.TsFiles[i].FilePath)
+    }
+    for i := range summary.HtmlFiles {
+        normalizeHtmlPaths(&summary.HtmlFiles[i])
+    }
+    for i := range summary.CssFiles {
+        summary.CssFiles[i].FilePath = filepath.ToSlash(summary.CssFiles[i].FilePath)
+    }
+    for i := range summary.SqlFiles {
+        summary.SqlFiles[i].FilePath = filepath.ToSlash(summary.SqlFiles[i].FilePath)
+    }
+    for i := range summary.ConfigFiles {
+        summary.ConfigFiles[i].FilePath = filepath.ToSlash(summary.ConfigFiles[i].FilePath)
+    }
+    for i := range summary.Dockerfiles {
+        summary.Dockerfiles[i].FilePath = filepath.ToSlash(summary.Dockerfiles[i].FilePath)
+    }
+    for i := range summary.Makefiles {
+        summary.Makefiles[i].FilePath = filepath.ToSlash(summary.Makefiles[i].FilePath)
+    }
+    for i := range summary.EnvFiles {
+        summary.EnvFiles[i].FilePath = filepath.ToSlash(summary.EnvFiles[i].FilePath)
+    }
+    for i := range summary.Migrations {
+        summary.Migrations[i].FilePath = filepath.ToSlash(summary.Migrations[i].FilePath)
+    }
+    for i := range summary.ScheduledJobs {
+        summary.ScheduledJobs[i].FilePath = filepath.ToSlash(summary.ScheduledJobs[i].FilePath)
+    }
+
+    // Code blocks hold their summaries by value, so normalize a copy and store it back
+    for i := range summary.MarkdownFiles {
+        summary.MarkdownFiles[i].FilePath = filepath.ToSlash(summary.MarkdownFiles[i].FilePath)
+        for j := range summary.MarkdownFiles[i].CodeBlocks {
+            block := &summary.MarkdownFiles[i].CodeBlocks[j]
+            switch blockSummary := block.Summary.(type) {
+            case GoFileSummary:
+                blockSummary.FilePath = filepath.ToSlash(blockSummary.FilePath)
+                block.Summary = blockSummary
+            case PhpFileSummary:
+                blockSummary.FilePath = filepath.ToSlash(blockSummary.FilePath)
+                block.Summary = blockSummary
+            case PythonFileSummary:
+                blockSummary.FilePath = filepath.ToSlash(blockSummary.FilePath)
+                block.Summary = blockSummary
+            case JsFileSummary:
+                blockSummary.FilePath = filepath.ToSlash(blockSummary.FilePath)
+                block.Summary = blockSummary
+            case TsFileSummary:
+                blockSummary.FilePath = filepath.ToSlash(blockSummary.FilePath)
+                block.Summary = blockSummary
+            case HtmlFileSummary:
+                normalizeHtmlPaths(&blockSummary)
+                block.Summary = blockSummary
+            case CSSFileSummary:
+                blockSummary.FilePath = filepath.ToSlash(blockSummary.FilePath)
+                block.Summary = blockSummary
+            case SQLFileSummary:
+                blockSummary.FilePath = filepath.ToSlash(blockSummary.FilePath)
+                block.Summary = blockSummary
+            }
+        }
+    }
+}
+
+// normalizeHtmlPaths converts the path and includes of an HTML file to forward slashes
+func normalizeHtmlPaths(htmlFile *HtmlFileSummary) {
+    htmlFile.FilePath = filepath.ToSlash(htmlFile.FilePath)
+    for i, include := range htmlFile.Includes {
+        // Includes are written by hand, so backslashes may appear on any OS
+        htmlFile.Includes[i] = strings.ReplaceAll(include, "\\", "/")
+    }
+}
+
+// rewriteFunctions replaces every function and method list in the summary with the result of fn
+func rewriteFunctions(summary *Summary, fn func([]Function) []Function) {
+    for i := range summary.GoFiles {
+        rewriteGoFunctions(&summary.GoFiles[i], fn)
+    }
+    for i := range summary.PhpFiles {
+        rewritePhpFunctions(&summary.PhpFiles[i], fn)
+    }
+    for i := range summary.PythonFiles {
+        rewritePythonFunctions(&summary.PythonFiles[i], fn)
+    }
+    for i := range summary.JsFiles {
+        rewriteJsFunctions(&summary.JsFiles[i], fn)
+    }
+    for i := range summary.TsFiles {
+        rewriteTsFunctions(&summary.TsFiles[i], fn)
+    }
+    for i := range summary.HtmlFiles {
+        summary.HtmlFiles[i].EmbeddedJS = fn(summary.HtmlFiles[i].EmbeddedJS)
+    }
+
+    // Code blocks hold their summaries by value, so rewrite a copy and store it back
+    for i := range summary.MarkdownFiles {
+        for j := range summary.MarkdownFiles[i].CodeBlocks {
+            block := &summary.MarkdownFiles[i].CodeBlocks[j]
+            switch blockSummary := block.Summary.(type) {
+            case GoFileSummary:
+                rewriteGoFunctions(&blockSummary, fn)
+                block.Summary = blockSummary
+            case PhpFileSummary:
+                rewritePhpFunctions(&blockSummary, fn)
+                block.Summary = blockSummary
+            case PythonFileSummary:
+                rewritePythonFunctions(&blockSummary, fn)
+                block.Summary = blockSummary
+            case JsFileSummary:
+                rewriteJsFunctions(&blockSummary, fn)
+                block.Summary = blockSummary
+            case TsFileSummary:
+                rewriteTsFunctions(&blockSummary, fn)
+                block.Summary = blockSummary
+            case HtmlFileSummary:
+                blockSummary.EmbeddedJS = fn(blockSummary.EmbeddedJS)
+                block.Summary = blockSummary
+            }
+        }
+    }
+}
+
+// rewriteGoFunctions applies fn to the functions and methods of a Go file
+func rewriteGoFunctions(goFile *GoFileSummary, fn func([]Function) []Function) {
+    goFile.Functions = fn(goFile.Functions)
+    for i := range goFile.Structs {
+        goFile.Structs[i].Methods = fn(goFile.Structs[i].Methods)
+    }
+    for i := range goFile.Interfaces {
+        goFile.Interfaces[i].Methods = fn(goFile.Interfaces[i].Methods)
+    }
+}
+
+// rewritePhpFunctions applies fn to the functions and methods of a PHP file
+func rewritePhpFunctions(phpFile *PhpFileSummary, fn func([]Function) []Function) {
+    phpFile.Functions = fn(phpFile.Functions)
+    for i := range phpFile.Classes {
+        phpFile.Classes[i].Methods = fn(phpFile.Classes[i].Methods)
+    }
+    for i := range phpFile.Interfaces {
+        phpFile.Interfaces[i].Methods = fn(phpFile.Interfaces[i].Methods)
+    }
+}
+
+// rewritePythonFunctions applies fn to the functions and methods of a Python file
+func rewritePythonFunctions(pyFile *PythonFileSummary, fn func([]Function) []Function) {
+    pyFile.Functions = fn(pyFile.Functions)
+    for i := range pyFile.Classes {
+        pyFile.Classes[i].Methods = fn(pyFile.Classes[i].Methods)
+    }
+}
+
+// rewriteJsFunctions applies fn to the functions and methods of a JavaScript file
+func rewriteJsFunctions(jsFile *JsFileSummary, fn func([]Function) []Function) {
+    jsFile.Functions = fn(jsFile.Functions)
+    for i := range jsFile.Classes {
+        jsFile.Classes[i].Methods = fn(jsFile.Classes[i].Methods)
+    }
+}
+
+// rewriteTsFunctions applies fn to the functions, methods and interface methods of a TypeScript file
+func rewriteTsFunctions(tsFile *TsFileSummary, fn func([]Function) []Function) {
+    tsFile.Functions = fn(tsFile.Functions)
+    for i := range tsFile.Classes {
+        tsFile.Classes[i].Methods = fn(tsFile.Classes[i].Methods)
+    }
+    for i := range tsFile.Interfaces {
+        tsFile.Interfaces[i].Methods = fn(tsFile.Interfaces[i].Methods)
+    }
+}
+
+// omitFunctions removes functions whose name or qualified name is listed or matches the regex
+func omitFunctions(summary *Summary, names []string, pattern *regexp.Regexp) {
+    omitted := make(map[string]bool)
+    for _, name := range names {
+        omitted[strings.TrimSpace(name)] = true
+    }
+
+    rewriteFunctions(summary, func(functions []Function) []Function {
+        var kept []Function
+        for _, fn := range functions {
+            qualified := qualifiedFunctionName(fn)
+            if omitted[fn.Name] || omitted[qualified] {
+                continue
+            }
+            if pattern != nil && (pattern.MatchString(fn.Name) || pattern.MatchString(qualified)) {
+                continue
+            }
+            kept = append(kept, fn)
+        }
+        return kept
+    })
+}
+
+// excludeSymbols removes the functions, types, fields, variables, CSS rules and SQL
+// tables whose names match pattern from every file, Markdown code blocks included
+func excludeSymbols(summary *Summary, pattern *regexp.Regexp) {
+    omitFunctions(summary, nil, pattern)
+
+    for i := range summary.GoFiles {
+        excludeGoSymbols(&summary.GoFiles[i], pattern)
+    }
+    for i := range summary.PhpFiles {
+        excludePhpSymbols(&summary.PhpFiles[i], pattern)
+    }
+    for i := range summary.PythonFiles {
+        excludePythonSymbols(&summary.PythonFiles[i], pattern)
+    }
+    for i := range summary.JsFiles {
+        excludeJsSymbols(&summary.JsFiles[i], pattern)
+    }
+    for i := range summary.TsFiles {
+        excludeTsSymbols(&summary.TsFiles[i], pattern)
+    }
+    for i := range summary.HtmlFiles {
+        summary.HtmlFiles[i].EmbeddedCSS = excludeCssRules(summary.HtmlFiles[i].EmbeddedCSS, pattern)
+    }
+    for i := range summary.CssFiles {
+        excludeCssSymbols(&summary.CssFiles[i], pattern)
+    }
+    for i := range summary.SqlFiles {
+        summary.SqlFiles[i].Statements = excludeSqlTables(summary.SqlFiles[i].Statements, pattern)
+    }
+
+    // Code blocks hold their summaries by value, so rewrite a copy and store it back
+    for i := range summary.MarkdownFiles {
+        for j := range summary.MarkdownFiles[i].CodeBlocks {
+            block := &summary.MarkdownFiles[i].CodeBlocks[j]
+            switch blockSummary := block.Summary.(type) {
+            case GoFileSummary:
+                excludeGoSymbols(&blockSummary, pattern)
+                block.Summary = blockSummary
+            case PhpFileSummary:
+                excludePhpSymbols(&blockSummary, pattern)
+                block.Summary = blockSummary
+            case PythonFileSummary:
+                excludePythonSymbols(&blockSummary, pattern)
+                block.Summary = blockSummary
+            case JsFileSummary:
+                excludeJsSymbols(&blockSummary, pattern)
+                block.Summary = blockSummary
+            case TsFileSummary:
+                excludeTsSymbols(&blockSummary, pattern)
+                block.Summary = blockSummary
+            case HtmlFileSummary:
+                blockSummary.EmbeddedCSS = excludeCssRules(blockSummary.EmbeddedCSS, pattern)
+                block.Summary = blockSummary
+            case CSSFileSummary:
+                excludeCssSymbols(&blockSummary, pattern)
+                block.Summary = blockSummary
+            case SQLFileSummary:
+                blockSummary.Statements = excludeSqlTables(blockSummary.Statements, pattern)
+                block.Summary = blockSummary
+            }
+        }
+    }
+}
+
+// excludeGoSymbols drops the matching types, fields, variables and constants of a Go file
+func excludeGoSymbols(goFile *GoFileSummary, pattern *regexp.Regexp) {
+    goFile.Structs = excludeTypes(goFile.Structs, pattern)
+    goFile.Interfaces = excludeInterfaces(goFile.Interfaces, pattern)
+    goFile.Variables = excludeVariables(goFile.Variables, pattern)
+    var constants []Constant
+    for _, c := range goFile.Constants {
+        if !pattern.MatchString(c.Name) {
+            constants = append(constants, c)
+        }
+    }
+    goFile.Constants = constants
+}
+
+// excludePhpSymbols drops the matching classes, properties and variables of a PHP file
+func excludePhpSymbols(phpFile *PhpFileSummary, pattern *regexp.Regexp) {
+    phpFile.Classes = excludeTypes(phpFile.Classes, pattern)
+    phpFile.Interfaces = excludeInterfaces(phpFile.Interfaces, pattern)
+    phpFile.Variables = excludeVariables(phpFile.Variables, pattern)
+}
+
+// excludePythonSymbols drops the matching classes, fields and variables of a Python file
+func excludePythonSymbols(pyFile *PythonFileSummary, pattern *regexp.Regexp) {
+    pyFile.Classes = excludeTypes(pyFile.Classes, pattern)
+    pyFile.Variables = excludeVariables(pyFile.Variables, pattern)
+}
+
+// excludeJsSymbols drops matching classes, class fields and constants from a JavaScript file
+func excludeJsSymbols(jsFile *JsFileSummary, pattern *regexp.Regexp) {
+    jsFile.Classes = excludeTypes(jsFile.Classes, pattern)
+    jsFile.Constants = excludeVariables(jsFile.Constants, pattern)
+}
+
+// excludeTsSymbols drops matching classes, interfaces, type aliases, enums and constants from a TypeScript file
+func excludeTsSymbols(tsFile *TsFileSummary, pattern *regexp.Regexp) {
+    tsFile.Classes = excludeTypes(tsFile.Classes, pattern)
+    tsFile.Interfaces = excludeInterfaces(tsFile.Interfaces, pattern)
+    tsFile.Constants = excludeVariables(tsFile.Constants, pattern)
+    var aliases []TypeAlias
+    for _, alias := range tsFile.TypeAliases {
+        if !pattern.MatchString(alias.Name) {
+            aliases = append(aliases, alias)
+        }
+    }
+    tsFile.TypeAliases = aliases
+    var enums []Enum
+    for _, enum := range tsFile.Enums {
+        if !pattern.MatchString(enum.Name) {
+            enums = append(enums, enum)
+        }
+    }
+    tsFile.Enums = enums
+}
+
+// excludeTypes drops matching types, nested ones included, and their matching fields
+func excludeTypes(types []Struct, pattern *regexp.Regexp) []Struct {
+    var kept []Struct
+    for _, t := range types {
+        if pattern.MatchString(t.Name) {
+            continue
+        }
+        t.Fields = excludeVariables(t.Fields, pattern)
+        t.Nested = excludeTypes(t.Nested, pattern)
+        kept = append(kept, t)
+    }
+    return kept
+}
+
+// excludeInterfaces drops matching interfaces, and the matching fields of TypeScript interfaces
+func excludeInterfaces(interfaces []Interface, pattern *regexp.Regexp) []Interface {
+    var kept []Interface
+    for _, intf := range interfaces {
+        if !pattern.MatchString(intf.Name) {
+            intf.Fields = excludeVariables(intf.Fields, pattern)
+            kept = append(kept, intf)
+        }
+    }
+    return kept
+}
+
+// excludeVariables drops matching variables and fields
+func excludeVariables(variables []Variable, pattern *regexp.Regexp) []Variable {
+    var kept []Variable
+    for _, v := range variables {
+        if !pattern.MatchString(v.Name) {
+            kept = append(kept, v)
+        }
+    }
+    return kept
+}
+
+// excludeCssRules drops rules whose selector matches
+func excludeCssRules(rules []CSSRule, pattern *regexp.Regexp) []CSSRule {
+    var kept []CSSRule
+    for _, rule := range rules {
+        if !pattern.MatchString(rule.Selector) {
+            kept = append(kept, rule)
+        }
+    }
+    return kept
+}
+
+// excludeCssSymbols drops the matching selectors of a CSS file from its rules and findings
+func excludeCssSymbols(cssFile *CSSFileSummary, pattern *regexp.Regexp) {
+    cssFile.Rules = excludeCssRules(cssFile.Rules, pattern)
+
+    var duplicates []string
+    for _, selector := range cssFile.DuplicateSelectors {
+        if !pattern.MatchString(selector) {
+            duplicates = append(duplicates, selector)
+        }
+    }
+    cssFile.DuplicateSelectors = duplicates
+
+    var conflicts []Conflict
+    for _, conflict := range cssFile.Conflicts {
+        if !pattern.MatchString(conflict.Selector) {
+            conflicts = append(conflicts, conflict)
+        }
+    }
+    cssFile.Conflicts = conflicts
+
+    var zIndexes []ZIndexEntry
+    for _, entry := range cssFile.ZIndexValues {
+        if !pattern.MatchString(entry.Selector) {
+            zIndexes = append(zIndexes, entry)
+        }
+    }
+    cssFile.ZIndexValues = zIndexes
+}
+
+// excludeSqlTables removes matching tables from statements, dropping the CREATE and
+// ALTER statements of matching tables and statements left with no table at all
+func excludeSqlTables(statements []SQLStatement, pattern *regexp.Regexp) []SQLStatement {
+    var kept []SQLStatement
+    for _, stmt := range statements {
+        if len(stmt.Tables) == 0 {
+            kept = append(kept, stmt)
+            continue
+        }
+        if (stmt.Type == "CREATE" || stmt.Type == "ALTER") && pattern.MatchString(stmt.Tables[0]) {
+            continue
+        }
+        var tables []string
+        for _, table := range stmt.Tables {
+            if !pattern.MatchString(table) {
+                tables = append(tables, table)
+            }
+        }
+        if len(tables) == 0 {
+            continue
+        }
+        stmt.Tables = tables
+        kept = append(kept, stmt)
+    }
+    return kept
+}
+
+// processConfigFileForPattern extracts pattern information from an INI or TOML file
+func processConfigFileForPattern(configFile ConfigFileSummary, fileIndex int, pattern *PatternSummary) {
+    // Add section names, bracketed so they don't collide with other symbols
+    for _, section := range configFile.Sections {
+        if section.Name == "" {
+            continue
+        }
+        sectionName := "[" + section.Name + "]"
+        pattern.ConfigSections = append(pattern.ConfigSections, section.Name)
+        pattern.FileMap[sectionName] = append(pattern.FileMap[sectionName], fileIndex)
+    }
+}
+
+// symbolImportance scores functions and types by how often the rest of the codebase refers
+// to them. Type uses only count within a language, so scores are read through in.
+type symbolImportance struct {
+    language string         // Language of the symbols being scored
+    typeUses map[string]int // Language and type name -> fields, arguments, variables and returns using it
+}
+
+// computeSymbolImportance gathers type reference counts across the summary, leaving out
+// Go type parameters the same way -cluster does. Functions are scored by the callers the
+// call graph resolved to them.
+func computeSymbolImportance(summary *Summary) symbolImportance {
+    return symbolImportance{typeUses: countTypeUsage(*summary)}
+}
+
+// in returns the scores for symbols declared in the given language
+func (importance symbolImportance) in(language string) symbolImportance {
+    importance.language = language
+    return importance
+}
+
+// functionScore ranks a function by its number of callers
+func (importance symbolImportance) functionScore(fn Function) int {
+    return len(fn.CalledBy)
+}
+
+// typeScore ranks a type by how often it is used plus how often its methods are called
+func (importance symbolImportance) typeScore(t Struct) int {
+    score := importance.typeUses[importance.language+"\x00"+t.Name[strings.LastIndex(t.Name, ".")+1:]]
+    for _, method := range t.Methods {
+        score += importance.functionScore(method)
+    }
+    return score
+}
+
+// interfaceScore ranks an interface by how often it is used
+func (importance symbolImportance) interfaceScore(intf Interface) int {
+    return importance.typeUses[importance.language+"\x00"+intf.Name]
+}
+
+// functionsScore sums the scores of a list of functions and counts each one
+func (importance symbolImportance) functionsScore(functions []Function) int {
+    score := len(functions)
+    for _, fn := range functions {
+        score += importance.functionScore(fn)
+    }
+    return score
+}
+
+// typesScore sums the scores of a list of types and counts each one
+func (importance symbolImportance) typesScore(types []Struct) int {
+    score := len(types)
+    for _, t := range types {
+        score += importance.typeScore(t)
+    }
+    return score
+}
+
+// rankTopSymbols scores every function and type by resolved callers, cyclomatic complexity
+// and export status and returns the n highest scoring
+func rankTopSymbols(summary *Summary, n int) []RankedSymbol {
+    importance := computeSymbolImportance(summary)
+    var ranked []RankedSymbol
+
+    addFunction := func(language string, fn Function, filePath string, exported bool) {
+        ranked = append(ranked, RankedSymbol{
+            ID:         fn.ID,
+            Name:       qualifiedFunctionName(fn),
+            Kind:       functionKind(fn),
+            FilePath:   filePath,
+            Line:       fn.Line,
+            Callers:    importance.in(language).functionScore(fn),
+            Complexity: fn.Complexity,
+            Exported:   exported,
+        })
+    }
+    addType := func(language string, t Struct, filePath string, exported bool) {
+        ranked = append(ranked, RankedSymbol{
+            ID:         t.ID,
+            Name:       t.Name,
+            Kind:       "type",
+            FilePath:   filePath,
+            Line:       t.Line,
+            Callers:    importance.in(language).typeScore(t),
+            Complexity: len(t.Methods),
+            Exported:   exported,
+        })
+    }
+
+    // Go exports by capitalization, PHP and Python mark private names with an underscore
+    for _, f := range summary.GoFiles {
+        for _, fn := range f.Functions {
+            addFunction("go", fn, f.FilePath, ast.IsExported(fn.Name))
+        }
+        for _, t := range f.Structs {
+            addType("go", t, f.FilePath, ast.IsExported(t.Name))
+        }
+    }
+    for _, f := range summary.PhpFiles {
+        for _, fn := range f.Functions {
+            addFunction("php", fn, f.FilePath, !strings.HasPrefix(fn.Name, "_"))
+        }
+        for _, t := range f.Classes {
+            addType("php", t, f.FilePath, !strings.HasPrefix(t.Name, "_"))
+            for _, method := range t.Methods {
+                addFunction("php", method, f.FilePath, !strings.HasPrefix(method.Name, "_"))
+            }
+        }
+    }
+    for _, f := range summary.PythonFiles {
+        for _, fn := range f.Functions {
+            addFunction("python", fn, f.FilePath, !strings.HasPrefix(fn.Name, "_"))
+        }
+        for _, t := range f.Classes {
+            addType("python", t, f.FilePath, !strings.HasPrefix(t.Name, "_"))
+            for _, method := range t.Methods {
+                addFunction("python", method, f.FilePath, !strings.HasPrefix(method.Name, "_"))
+            }
+        }
+    }
+    // JavaScript exports explicitly, and private class members start with #
+    for _, f := range summary.JsFiles {
+        exported := make(map[string]bool)
+        for _, name := range f.Exports {
+            exported[name] = true
+        }
+        for _, fn := range f.Functions {
+            addFunction("js", fn, f.FilePath, exported[fn.Name])
+        }
+        for _, t := range f.Classes {
+            addType("js", t, f.FilePath, exported[t.Name])
+            for _, method := range t.Methods {
+                addFunction("js", method, f.FilePath, exported[t.Name] && !strings.HasPrefix(method.Name, "#"))
+            }
+        }
+    }
+    // TypeScript also hides members with the private and protected modifiers
+    for _, f := range summary.TsFiles {
+        exported := make(map[string]bool)
+        for _, name := range f.Exports {
+            exported[name] = true
+        }
+        for _, fn := range f.Functions {
+            addFunction("js", fn, f.FilePath, exported[fn.Name])
+        }
+        for _, t := range f.Classes {
+            addType("js", t, f.FilePath, exported[t.Name])
+            for _, method := range t.Methods {
+                public := exported[t.Name] && !strings.HasPrefix(method.Name, "#")
+                for _, modifier := range method.Modifiers {
+                    public = public && modifier != "private" && modifier != "protected"
+                }
+                addFunction("js", method, f.FilePath, public)
+            }
+        }
+    }
+
+    // Being depended on weighs most, then doing a lot, then being public API
+    for i := range ranked {
+        ranked[i].Score = ranked[i].Callers*3 + ranked[i].Complexity
+        if ranked[i].Exported {
+            ranked[i].Score += 2
+        }
+    }
+
+    sort.SliceStable(ranked, func(i, j int) bool {
+        if ranked[i].Score != ranked[j].Score {
+            return ranked[i].Score > ranked[j].Score
+        }
+        return ranked[i].Name < ranked[j].Name
+    })
+    if len(ranked) > n {
+        ranked = ranked[:n]
+    }
+    return ranked
+}
+
+// fileReferences returns, for each code file, the other files of the same language it
+// calls a function or uses a type from. Calls follow the resolved call graph. Type names
+// defined in several files are ambiguous and ignored, as are type parameters of generic
+// Go code.
+func fileReferences(summary Summary) map[string][]string {
+    type codeFile struct {
+        path      string
+        language  string // JavaScript, TypeScript and inline scripts share "js"
+        pkg       string // Go package name, to resolve qualified type references
+        functions []Function
+        types     []Struct
+    }
+
+    var files []codeFile
+    for _, f := range summary.GoFiles {
+        files = append(files, codeFile{f.FilePath, "go", f.Package, f.Functions, f.Structs})
+    }
+    for _, f := range summary.PhpFiles {
+        functions := f.Functions
+        for _, class := range f.Classes {
+            functions = append(functions, class.Methods...)
+        }
+        files = append(files, codeFile{path: f.FilePath, language: "php", functions: functions, types: f.Classes})
+    }
+    for _, f := range summary.PythonFiles {
+        functions := f.Functions
+        for _, class := range f.Classes {
+            functions = append(functions, class.Methods...)
+        }
+        files = append(files, codeFile{path: f.FilePath, language: "python", functions: functions, types: f.Classes})
+    }
+    for _, f := range summary.JsFiles {
+        files = append(files, codeFile{path: f.FilePath, language: "js", functions: withMethods(f.Functions, f.Classes), types: f.Classes})
+    }
+    for _, f := range summary.TsFiles {
+        files = append(files, codeFile{path: f.FilePath, language: "js", functions: withMethods(f.Functions, f.Classes), types: f.Classes})
+    }
+    for _, f := range summary.HtmlFiles {
+        files = append(files, codeFile{path: f.FilePath, language: "js", functions: f.EmbeddedJS})
+    }
+
+    // Map every symbol name to the files of each language defining it
+    definedIn := make(map[string]map[int]bool)
+    define := func(language, name string, file int) {
+        key := language + "\x00" + name
+        if definedIn[key] == nil {
+            definedIn[key] = make(map[int]bool)
+        }
+        definedIn[key][file] = true
+    }
+    for i, f := range files {
+        for _, fn := range f.functions {
+            define(f.language, fn.Name, i)
+        }
+        for _, t := range f.types {
+            define(f.language, t.Name, i)
+        }
+    }
+
+    references := make(map[string][]string)
+    link := func(file int, name string, typeParams map[string]bool) {
+        // A "pkg.Type" reference can only point into a file of that package
+        qualifier := ""
+        if idx := strings.LastIndex(name, "."); idx >= 0 {
+            qualifier, name = name[:idx], name[idx+1:]
+        }
+        if qualifier == "" && typeParams[name] {
+            return
+        }
+        candidates := definedIn[files[file].language+"\x00"+name]
+        if len(candidates) != 1 {
+            return
+        }
+        for other := range candidates {
+            if qualifier != "" && files[other].pkg != qualifier {
+                continue
+            }
+            if other != file {
+                references[files[file].path] = appendIfNotExists(references[files[file].path], files[other].path)
+            }
+        }
+    }
+
+    genericTypes := goGenericTypes(summary)
+    wordRegex := regexp.MustCompile(`[\w.]+`)
+    for i, f := range files {
+        for _, fn := range f.functions {
+            var typeParams map[string]bool
+            if f.language == "go" {
+                typeParams = goTypeParamsInScope(fn, filepath.Dir(f.path), genericTypes)
+            }
+            for _, arg := range fn.Args {
+                for _, name := range wordRegex.FindAllString(arg.Type, -1) {
+                    link(i, name, typeParams)
+                }
+            }
+            for _, ret := range fn.Returns {
+                for _, name := range wordRegex.FindAllString(ret, -1) {
+                    link(i, name, typeParams)
+                }
+            }
+        }
+        for _, t := range f.types {
+            for _, field := range t.Fields {
+                for _, name := range wordRegex.FindAllString(field.Type, -1) {
+                    link(i, name, typeParamNames(t.TypeParams))
+                }
+            }
+        }
+    }
+
+    graph := buildCallGraph(summary)
+    for _, edge := range graph.edges {
+        caller, callee := graph.files[graph.nodes[edge[0]].file], graph.files[graph.nodes[edge[1]].file]
+        if caller != callee {
+            references[caller] = appendIfNotExists(references[caller], callee)
+        }
+    }
+
+    for file := range references {
+        sort.Strings(references[file])
+    }
+    return references
+}
+
+// goGenericTypes maps each generic Go type, keyed by directory and name, to its type
+// parameters, which its methods can use without declaring them
+func goGenericTypes(summary Summary) map[string][]TypeParam {
+    generic := make(map[string][]TypeParam)
+    for _, f := range summary.GoFiles {
+        for _, st := range f.Structs {
+            if len(st.TypeParams) > 0 {
+                generic[filepath.Dir(f.FilePath)+"\x00"+st.Name] = st.TypeParams
+            }
+        }
+    }
+    return generic
+}
+
+// goTypeParamsInScope returns the names of the type parameters a Go function can refer
+// to: its own and, for a method, those of its receiver type. A method that renames its
+// receiver's parameters, as in func (l *List[E]) for List[T], is not recognised.
+func goTypeParamsInScope(fn Function, dir string, genericTypes map[string][]TypeParam) map[string]bool {
+    names := typeParamNames(fn.TypeParams)
+    if fn.Receiver != "" {
+        for _, param := range genericTypes[dir+"\x00"+fn.Receiver] {
+            names[param.Name] = true
+        }
+    }
+    return names
+}
+
+// typeParamNames returns the names of a list of type parameters as a set
+func typeParamNames(params []TypeParam) map[string]bool {
+    names := make(map[string]bool)
+    for _, param := range params {
+        names[param.Name] = true
+    }
+    return names
+}
+
+// clusterFiles groups files into connected components of the cross-file reference
+// graph, where an edge means one file calls a function or uses a type defined in the
+// other. Names defined in several files are ambiguous and ignored, and files sharing
+// nothing with others are left out.
+func clusterFiles(summary Summary) [][]string {
+    references := fileReferences(summary)
+
+    // Union-find over file paths
+    parent := make(map[string]string)
+    var find func(string) string
+    find = func(path string) string {
+        if parent[path] == "" {
+            parent[path] = path
+        }
+        if parent[path] != path {
+            parent[path] = find(parent[path])
+        }
+        return parent[path]
+    }
+    for file, others := range references {
+        for _, other := range others {
+            parent[find(file)] = find(other)
+        }
+    }
+
+    // Collect the components, which all have more than one file
+    components := make(map[string][]string)
+    for path := range parent {
+        root := find(path)
+        components[root] = append(components[root], path)
+    }
+
+    var clusters [][]string
+    for _, paths := range components {
+        sort.Strings(paths)
+        clusters = append(clusters, paths)
+    }
+    sort.Slice(clusters, func(i, j int) bool {
+        if len(clusters[i]) != len(clusters[j]) {
+            return len(clusters[i]) > len(clusters[j])
+        }
+        return clusters[i][0] < clusters[j][0]
+    })
+    return clusters
+}
+
+// keepMostImportant returns at most n items, choosing the highest-scoring ones but
+// preserving their original order so the output still follows the source
+func keepMostImportant[T any](items []T, n int, score func(T) int) []T {
+    if n <= 0 || len(items) <= n {
+        return items
+    }
+
+    indices := make([]int, len(items))
+    for i := range indices {
+        indices[i] = i
+    }
+    sort.SliceStable(indices, func(a, b int) bool {
+        return score(items[indices[a]]) > score(items[indices[b]])
+    })
+
+    kept := indices[:n]
+    sort.Ints(kept)
+
+    result := make([]T, 0, n)
+    for _, i := range kept {
+        result = append(result, items[i])
+    }
+    return result
+}
+
+// applyImportanceCaps applies the -max-functions, -max-types and -max-files-per-lang caps
+func applyImportanceCaps(summary *Summary, config Config) {
+    importance := computeSymbolImportance(summary)
+
+    for i := range summary.GoFiles {
+        summary.GoFiles[i].Functions = keepMostImportant(summary.GoFiles[i].Functions, config.MaxFunctions, importance.in("go").functionScore)
+        summary.GoFiles[i].Structs = keepMostImportant(summary.GoFiles[i].Structs, config.MaxTypes, importance.in("go").typeScore)
+        summary.GoFiles[i].Interfaces = keepMostImportant(summary.GoFiles[i].Interfaces, config.MaxTypes, func(intf Interface) int {
+            return importance.in("go").interfaceScore(intf)
+        })
+    }
+    for i := range summary.PhpFiles {
+        summary.PhpFiles[i].Functions = keepMostImportant(summary.PhpFiles[i].Functions, config.MaxFunctions, importance.in("php").functionScore)
+        summary.PhpFiles[i].Classes = keepMostImportant(summary.PhpFiles[i].Classes, config.MaxTypes, importance.in("php").typeScore)
+    }
+    for i := range summary.PythonFiles {
+        summary.PythonFiles[i].Functions = keepMostImportant(summary.PythonFiles[i].Functions, config.MaxFunctions, importance.in("python").functionScore)
+        summary.PythonFiles[i].Classes = keepMostImportant(summary.PythonFiles[i].Classes, config.MaxTypes, importance.in("python").typeScore)
+    }
+    for i := range summary.JsFiles {
+        summary.JsFiles[i].Functions = keepMostImportant(summary.JsFiles[i].Functions, config.MaxFunctions, importance.in("js").functionScore)
+        summary.JsFiles[i].Classes = keepMostImportant(summary.JsFiles[i].Classes, config.MaxTypes, importance.in("js").typeScore)
+    }
+    for i := range summary.TsFiles {
+        summary.TsFiles[i].Functions = keepMostImportant(summary.TsFiles[i].Functions, config.MaxFunctions, importance.in("js").functionScore)
+        summary.TsFiles[i].Classes = keepMostImportant(summary.TsFiles[i].Classes, config.MaxTypes, importance.in("js").typeScore)
+    }
+    for i := range summary.HtmlFiles {
+        summary.HtmlFiles[i].EmbeddedJS = keepMostImportant(summary.HtmlFiles[i].EmbeddedJS, config.MaxFunctions, importance.in("js").functionScore)
+    }
+
+    // Files are ranked by the combined importance of the symbols they define
+    n := config.MaxFilesPerLang
+    summary.GoFiles = keepMostImportant(summary.GoFiles, n, func(f GoFileSummary) int {
+        return importance.in("go").functionsScore(f.Functions) + importance.in("go").typesScore(f.Structs) + len(f.Interfaces)
+    })
+    summary.PhpFiles = keepMostImportant(summary.PhpFiles, n, func(f PhpFileSummary) int {
+        return importance.in("php").functionsScore(f.Functions) + importance.in("php").typesScore(f.Classes)
+    })
+    summary.PythonFiles = keepMostImportant(summary.PythonFiles, n, func(f PythonFileSummary) int {
+        return importance.in("python").functionsScore(f.Functions) + importance.in("python").typesScore(f.Classes)
+    })
+    summary.JsFiles = keepMostImportant(summary.JsFiles, n, func(f JsFileSummary) int {
+        return importance.in("js").functionsScore(f.Functions) + importance.in("js").typesScore(f.Classes)
+    })
+    summary.TsFiles = keepMostImportant(summary.TsFiles, n, func(f TsFileSummary) int {
+        return importance.in("js").functionsScore(f.Functions) + importance.in("js").typesScore(f.Classes)
+    })
+    summary.HtmlFiles = keepMostImportant(summary.HtmlFiles, n, func(f HtmlFileSummary) int {
+        return importance.in("js").functionsScore(f.EmbeddedJS) + len(f.Elements)
+    })
+    summary.CssFiles = keepMostImportant(summary.CssFiles, n, func(f CSSFileSummary) int {
+        return len(f.Rules)
+    })
+    summary.SqlFiles = keepMostImportant(summary.SqlFiles, n, func(f SQLFileSummary) int {
+        return len(f.Statements)
+    })
+    summary.MarkdownFiles = keepMostImportant(summary.MarkdownFiles, n, func(f MarkdownFileSummary) int {
+        return len(f.CodeBlocks)
+    })
+    summary.ConfigFiles = keepMostImportant(summary.ConfigFiles, n, func(f ConfigFileSummary) int {
+        return len(f.Sections)
+    })
+    summary.Dockerfiles = keepMostImportant(summary.Dockerfiles, n, func(f DockerfileSummary) int {
+        return len(f.BaseImages) + len(f.Copies)
+    })
+    summary.Makefiles = keepMostImportant(summary.Makefiles, n, func(f MakefileSummary) int {
+        return len(f.Targets)
+    })
+    summary.EnvFiles = keepMostImportant(summary.EnvFiles, n, func(f EnvFileSummary) int {
+        return len(f.EnvKeys)
+    })
+}
+
+// languageFunction is a function tagged with the language and file it was found in
+type languageFunction struct {
+    language string
+    file     string
+    function Function
+}
+
+// duplicateIgnoredNames are normalized names too generic to indicate duplicated logic
+var duplicateIgnoredNames = map[string]bool{
+    "main": true, "init": true, "construct": true, "constructor": true,
+    "setup": true, "run": true, "get": true, "set": true, "handle": true,
+}
+
+// isTestFunction reports whether a function is a test: a Go TestXxx(*testing.T) in a
+// _test.go file, or a test, test_xxx or testXxx function in a test file of another
+// language, the way pytest and PHPUnit find them
+func isTestFunction(language string, filePath string, fn Function) bool {
+    if language == "go" {
+        return fn.Kind == "test"
+    }
+    if !isTestFile(filepath.Base(filePath)) || !strings.HasPrefix(fn.Name, "test") {
+        return false
+    }
+    rest := fn.Name[len("test"):]
+    return rest == "" || rest[0] == '_' || rest[0] >= 'A' && rest[0] <= 'Z' || rest[0] >= '0' && rest[0] <= '9'
+}
+
+// isAssertionCall reports whether a call looks like a test assertion, e.g. "assert.Equal",
+// "self.assertEqual", "$this->assertSame", "expect" or "t.Fatalf"
+func isAssertionCall(call string) bool {
+    name := call[strings.LastIndex(call, ".")+1:]
+    switch {
+    case strings.HasPrefix(call, "assert.") || strings.HasPrefix(call, "require."):
+        return true
+    case strings.HasPrefix(name, "assert") || name == "expect" || strings.HasPrefix(name, "toBe") || strings.HasPrefix(name, "toEqual"):
+        return true
+    case strings.HasPrefix(call, "t.") && (strings.HasPrefix(name, "Error") || strings.HasPrefix(name, "Fatal") || name == "Fail" || name == "FailNow"):
+        return true
+    }
+    return false
+}
+
+// containsSuffix reports whether any item ends with suffix
+func containsSuffix(items []string, suffix string) bool {
+    for _, item := range items {
+        if strings.HasSuffix(item, suffix) {
+            return true
+        }
+    }
+    return false
+}
+
+// annotateTests records the assertions of test functions and infers the functions
+// under test from the test name (TestParseConfig, test_parse_config) and its calls
+func annotateTests(summary *Summary) {
+    // Visit every file's functions knowing the language and path isTestFunction needs
+    eachFile := func(visit func(language string, filePath string) func([]Function) []Function) {
+        for i := range summary.GoFiles {
+            rewriteGoFunctions(&summary.GoFiles[i], visit("go", summary.GoFiles[i].FilePath))
+        }
+        for i := range summary.PhpFiles {
+            rewritePhpFunctions(&summary.PhpFiles[i], visit("php", summary.PhpFiles[i].FilePath))
+        }
+        for i := range summary.PythonFiles {
+            rewritePythonFunctions(&summary.PythonFiles[i], visit("python", summary.PythonFiles[i].FilePath))
+        }
+        for i := range summary.JsFiles {
+            rewriteJsFunctions(&summary.JsFiles[i], visit("js", summary.JsFiles[i].FilePath))
+        }
+        for i := range summary.TsFiles {
+            rewriteTsFunctions(&summary.TsFiles[i], visit("js", summary.TsFiles[i].FilePath))
+        }
+        for i := range summary.HtmlFiles {
+            summary.HtmlFiles[i].EmbeddedJS = visit("js", summary.HtmlFiles[i].FilePath)(summary.HtmlFiles[i].EmbeddedJS)
+        }
+    }
+
+    known := make(map[string]string)
+    eachFile(func(language string, filePath string) func([]Function) []Function {
+        return func(functions []Function) []Function {
+            for _, fn := range functions {
+                if !isTestFunction(language, filePath, fn) {
+                    known[normalizeFunctionName(fn.Name)] = fn.Name
+                }
+            }
+            return functions
+        }
+    })
+
+    eachFile(func(language string, filePath string) func([]Function) []Function {
+        return func(functions []Function) []Function {
+            annotateTestFunctions(language, filePath, functions, known)
+            return functions
+        }
+    })
+}
+
+// annotateTestFunctions fills in the assertions and tested functions of the tests among
+// the functions of one file, given the other functions of the codebase by normalized name
+func annotateTestFunctions(language string, filePath string, functions []Function, known map[string]string) {
+    for i, fn := range functions {
+        if !isTestFunction(language, filePath, fn) {
+            continue
+        }
+
+        // The name minus the prefix, and minus a Go "_case" suffix
+        subject := strings.TrimPrefix(strings.TrimPrefix(fn.Name, "Test"), "test")
+        if idx := strings.Index(subject, "_"); idx > 0 && strings.HasPrefix(fn.Name, "Test") {
+            subject = subject[:idx]
+        }
+        if name, ok := known[normalizeFunctionName(subject)]; ok {
+            functions[i].TestedFunctions = appendIfNotExists(functions[i].TestedFunctions, name)
+        }
+
+        for _, call := range fn.Calls {
+            if isAssertionCall(call) {
+                // Python calls are recorded both bare and qualified, keep the qualified one
+                if !strings.Contains(call, ".") && containsSuffix(fn.Calls, "."+call) {
+                    continue
+                }
+                functions[i].Assertions = appendIfNotExists(functions[i].Assertions, call)
+                continue
+            }
+            if name, ok := known[normalizeFunctionName(call[strings.LastIndex(call, ".")+1:])]; ok {
+                functions[i].TestedFunctions = appendIfNotExists(functions[i].TestedFunctions, name)
+            }
+        }
+    }
+}
+
+// normalizeFunctionName lowercases a name and strips separators so that
+// validate_email, validateEmail and ValidateEmail compare equal
+func normalizeFunctionName(name string) string {
+    return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+// findCrossLanguageDuplicates clusters functions from different languages that share
+// a normalized name and have a similar number of arguments
+func findCrossLanguageDuplicates(summary Summary) [][]string {
+    byName := make(map[string][]languageFunction)
+
+    add := func(language string, file string, functions []Function) {
+        for _, fn := range functions {
+            name := normalizeFunctionName(fn.Name)
+            if len(name) < 3 || duplicateIgnoredNames[name] {
+                continue
+            }
+            byName[name] = append(byName[name], languageFunction{language, file, fn})
+        }
+    }
+    addTypes := func(language string, file string, types []Struct) {
+        for _, t := range types {
+            add(language, file, t.Methods)
+        }
+    }
+
+    for _, f := range summary.GoFiles {
+        add("go", f.FilePath, f.Functions)
+    }
+    for _, f := range summary.PhpFiles {
+        add("php", f.FilePath, f.Functions)
+        addTypes("php", f.FilePath, f.Classes)
+    }
+    for _, f := range summary.PythonFiles {
+        add("python", f.FilePath, f.Functions)
+        addTypes("python", f.FilePath, f.Classes)
+    }
+    for _, f := range summary.JsFiles {
+        add("javascript", f.FilePath, f.Functions)
+        addTypes("javascript", f.FilePath, f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        add("typescript", f.FilePath, f.Functions)
+        addTypes("typescript", f.FilePath, f.Classes)
+    }
+    for _, f := range summary.HtmlFiles {
+        add("javascript", f.FilePath, f.EmbeddedJS)
+    }
+
+    names := make([]string, 0, len(byName))
+    for name := range byName {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    var clusters [][]string
+    for _, name := range names {
+        candidates := byName[name]
+
+        // Keep functions that have a counterpart in another language with a similar arity
+        var cluster []string
+        for i, a := range candidates {
+            for j, b := range candidates {
+                if i == j || a.language == b.language {
+                    continue
+                }
+                diff := len(a.function.Args) - len(b.function.Args)
+                if diff >= -1 && diff <= 1 {
+                    cluster = appendIfNotExists(cluster, a.language+":"+a.file+":"+qualifiedFunctionName(a.function))
+                    break
+                }
+            }
+        }
+
+        if len(cluster) > 1 {
+            clusters = append(clusters, cluster)
+        }
+    }
+
+    return clusters
+}
+
+// SARIF 2.1.0 document types, limited to what distiller reports
+type sarifLog struct {
+    Version string     `json:"version"`
+    Schema  string     `json:"$schema"`
+    Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+    Tool    sarifTool     `json:"tool"`
+    Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+    Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+    Name    string      `json:"name"`
+    Version string      `json:"version"`
+    Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+    ID               string       `json:"id"`
+    ShortDescription sarifMessage `json:"shortDescription"`
+    DefaultConfiguration struct {
+        Level string `json:"level"`
+    } `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+    Text string `json:"text"`
+}
+
+type sarifResult struct {
+    RuleID    string          `json:"ruleId"`
+    Level     string          `json:"level"`
+    Message   sarifMessage    `json:"message"`
+    Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+    PhysicalLocation struct {
+        ArtifactLocation struct {
+            URI       string `json:"uri"`
+            URIBaseID string `json:"uriBaseId,omitempty"`
+        } `json:"artifactLocation"`
+        Region *sarifRegion `json:"region,omitempty"`
+    } `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+    StartLine   int `json:"startLine"`
+    StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifRules describes every finding distiller can report, with its default severity
+var sarifRules = []struct {
+    id          string
+    level       string
+    description string
+}{
+    {"go/context-not-propagated", "warning", "Function takes a context but calls a context-taking function without passing it"},
+    {"imports/unused", "note", "Import is never referred to in the file"},
+    {"env/undocumented-variable", "warning", "Environment variable is read but missing from every env file"},
+    {"env/unused-key", "note", "Env file key is never read by the code"},
+    {"css/duplicate-selector", "note", "Selector is defined by more than one rule"},
+    {"css/conflicting-declaration", "warning", "Property is given different values for the same selector"},
+    {"sql/unbounded-select", "note", "SELECT has neither LIMIT nor WHERE and may scan the whole table"},
+    {"duplicates/cross-language", "note", "Function looks reimplemented in another language"},
+    {"complexity/regression", "warning", "Function grew more complex than -complexity-delta allows since the baseline"},
+    {"go/parse-error", "error", "Go file could not be parsed and was left out of the summary"},
+    {"functions/unreachable", "note", "Private function or method is never called from the analyzed code"},
+    {"classes/empty", "note", "Class declares no fields, methods, nested classes or base classes"},
+    {"sql/concatenated-query", "warning", "SQL is built by concatenating or interpolating values instead of binding parameters"},
+}
+
+// sarifLocationAt builds a location for a file and a 1-based line, 0 meaning no line.
+// The URI is relative to the analyzed directory, which SARIF consumers know as %SRCROOT%.
+func sarifLocationAt(root, filePath string, line int) sarifLocation {
+    var location sarifLocation
+    location.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(filePath)
+    if rel, err := filepath.Rel(root, filePath); err == nil {
+        location.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(rel)
+        location.PhysicalLocation.ArtifactLocation.URIBaseID = "%SRCROOT%"
+    }
+    if line > 0 {
+        location.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+    }
+    return location
+}
+
+// callGraphNode is a function or method in the call graph
+type callGraphNode struct {
+    file     int    // Index into callGraph.files
+    language string // "go", "php", "python", "javascript" or "typescript"
+    pkg      string // Go package, to resolve "pkg.Func" calls
+    fn       Function
+}
+
+// callGraph links functions to the functions they call, resolved by name
+type callGraph struct {
+    files []string
+    nodes []callGraphNode
+    edges [][2]int // Caller and callee node indices, in caller order
+    byName map[string][]int // Nodes by language and function name
+    classes map[string]map[string]Struct // Classes by language and name, to follow super calls to a base class
+}
+
+// buildCallGraph resolves the calls of every Go, PHP, Python, JavaScript and TypeScript
+// function to definitions in the same language. A call goes to a method of the caller's
+// own class for this/self receivers, to the nearest base class defining the method for
+// super calls, then to a definition in the caller's file, then to the only definition
+// anywhere; calls that stay ambiguous or reach outside the codebase are left out.
+func buildCallGraph(summary Summary) callGraph {
+    graph := callGraph{classes: make(map[string]map[string]Struct)}
+    addFile := func(language string, filePath string, pkg string, functions []Function) {
+        graph.files = append(graph.files, filePath)
+        for _, fn := range functions {
+            graph.nodes = append(graph.nodes, callGraphNode{len(graph.files) - 1, language, pkg, fn})
+        }
+    }
+    addClasses := func(language string, classes []Struct) {
+        if graph.classes[language] == nil {
+            graph.classes[language] = make(map[string]Struct)
+        }
+        for _, class := range classes {
+            graph.classes[language][class.Name] = class
+        }
+    }
+    for _, f := range summary.GoFiles {
+        addFile("go", f.FilePath, f.Package, f.Functions)
+    }
+    for _, f := range summary.PhpFiles {
+        addFile("php", f.FilePath, "", withMethods(f.Functions, f.Classes))
+        addClasses("php", f.Classes)
+    }
+    for _, f := range summary.PythonFiles {
+        addFile("python", f.FilePath, "", withMethods(f.Functions, f.Classes))
+        addClasses("python", f.Classes)
+    }
+    for _, f := range summary.JsFiles {
+        addFile("javascript", f.FilePath, "", withMethods(f.Functions, f.Classes))
+        addClasses("javascript", f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        addFile("typescript", f.FilePath, "", withMethods(f.Functions, f.Classes))
+        addClasses("typescript", f.Classes)
+    }
+
+    graph.byName = make(map[string][]int)
+    for i, node := range graph.nodes {
+        key := node.language + "\x00" + node.fn.Name
+        graph.byName[key] = append(graph.byName[key], i)
+    }
+    for i, caller := range graph.nodes {
+        seen := make(map[int]bool)
+        for _, call := range caller.fn.Calls {
+            if callee, ok := graph.resolve(i, call); ok && !seen[callee] {
+                seen[callee] = true
+                graph.edges = append(graph.edges, [2]int{i, callee})
+            }
+        }
+    }
+    return graph
+}
+
+// resolveCallGraph lists every call between analyzed functions that resolves to a single
+// definition, in file order, so a reader can follow behavior from one file to the next
+func resolveCallGraph(summary Summary) []CallEdge {
+    graph := buildCallGraph(summary)
+    symbol := func(i int) CallSymbol {
+        node := graph.nodes[i]
+        return CallSymbol{
+            ID:       node.fn.ID,
+            Name:     qualifiedFunctionName(node.fn),
+            Language: node.language,
+            FilePath: graph.files[node.file],
+            Line:     node.fn.Line,
+        }
+    }
+    var edges []CallEdge
+    for _, edge := range graph.edges {
+        edges = append(edges, CallEdge{Caller: symbol(edge[0]), Callee: symbol(edge[1])})
+    }
+    return edges
+}
+
+// resolve finds the node a call made by the caller node refers to
+func (graph *callGraph) resolve(caller int, call string) (int, bool) {
+    from := graph.nodes[caller]
+    qualifier, name := "", call
+    if idx := strings.LastIndexAny(call, ".>:"); idx >= 0 {
+        qualifier, name = strings.TrimRight(call[:idx], "-:"), call[idx+1:]
+    }
+    candidates := graph.byName[from.language+"\x00"+name]
+    keep := func(match func(node callGraphNode) bool) {
+        var narrowed []int
+        for _, c := range candidates {
+            if match(graph.nodes[c]) {
+                narrowed = append(narrowed, c)
+            }
+        }
+        if len(narrowed) > 0 {
+            candidates = narrowed
+        }
+    }
+    // PHP parent:: and Python super() calls are recorded by bare name, and SuperCalls
+    // tells them apart from calls to a function of the same name
+    superCall := qualifier == "super"
+    for _, call := range from.fn.SuperCalls {
+        superCall = superCall || qualifier == "" && call == name
+    }
+    if superCall && from.fn.Receiver != "" {
+        // The base implementation, never the overriding method making the call
+        class := graph.classes[from.language][from.fn.Receiver]
+        base := findOverridden(graph.classes[from.language], class.Bases, name, map[string]bool{from.fn.Receiver: true})
+        var narrowed []int
+        for _, c := range candidates {
+            if base != "" && graph.nodes[c].fn.Receiver == base {
+                narrowed = append(narrowed, c)
+            }
+        }
+        candidates = narrowed
+    } else if callGraphSelfReceivers[qualifier] && from.fn.Receiver != "" {
+        keep(func(node callGraphNode) bool { return node.fn.Receiver == from.fn.Receiver })
+    } else if qualifier != "" && from.language == "go" {
+        keep(func(node callGraphNode) bool { return node.pkg == qualifier && node.fn.Receiver == "" })
+    }
+    if len(candidates) > 1 {
+        keep(func(node callGraphNode) bool { return node.file == from.file })
+    }
+    if len(candidates) != 1 {
+        return 0, false
+    }
+    return candidates[0], true
+}
+
+// referenced reports for each node whether another function calls it. A name called
+// anywhere in the same language counts, even when the call could not be resolved to
+// one definition.
+func (graph *callGraph) referenced() []bool {
+    referenced := make([]bool, len(graph.nodes))
+    for _, edge := range graph.edges {
+        if edge[0] != edge[1] {
+            referenced[edge[1]] = true
+        }
+    }
+    called := make(map[string]bool)
+    for _, node := range graph.nodes {
+        for _, call := range node.fn.Calls {
+            if idx := strings.LastIndexAny(call, ".>:"); idx >= 0 {
+                call = call[idx+1:]
+            }
+            called[node.language+"\x00"+call] = true
+        }
+    }
+    for i, node := range graph.nodes {
+        if called[node.language+"\x00"+node.fn.Name] {
+            referenced[i] = true
+        }
+    }
+    return referenced
+}
+
+var (
+    // cssSimpleSelectorRegex matches the class and ID parts of a selector
+    cssSimpleSelectorRegex = regexp.MustCompile(`[.#][\w-]+`)
+    // cssSelectorArgsRegex matches attribute selectors and pseudo-class arguments, e.g. :not(.x)
+    cssSelectorArgsRegex = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+)
+
+// findUnreferenced lists the functions, classes, CSS selectors and SQL tables nothing else
+// in the analyzed tree refers to, as "file:name". Entry points are left out, see
+// entryPoint, and so are abstract and Protocol methods, which have no body. A CSS selector counts as unreferenced when a class or ID it needs appears
+// on no element of the analyzed HTML, and a table when it is created but no other
+// statement, foreign key or query in the code names it.
+func findUnreferenced(summary Summary) *Unreferenced {
+    unreferenced := &Unreferenced{}
+
+    graph := buildCallGraph(summary)
+    referenced := graph.referenced()
+    entryPoints := entryPointNames(summary)
+    for i, node := range graph.nodes {
+        if referenced[i] || graph.entryPoint(i, entryPoints) || graph.bodyless(i) {
+            continue
+        }
+        unreferenced.Functions = append(unreferenced.Functions, graph.files[node.file]+":"+qualifiedFunctionName(node.fn))
+    }
+
+    // Classes are referenced by type annotations, inheritance, instantiation and static calls
+    typeUsage := profileTypeUsage(summary)
+    usedClasses := make(map[string]bool)
+    for _, node := range graph.nodes {
+        for _, call := range node.fn.Calls {
+            call = strings.TrimPrefix(call, "new ")
+            usedClasses[baseClassName(call)] = true
+            if idx := strings.LastIndexAny(call, ".>:"); idx >= 0 {
+                usedClasses[baseClassName(strings.TrimRight(call[:idx], "-:"))] = true
+            }
+        }
+    }
+    for i := range graph.nodes {
+        if referenced[i] && graph.nodes[i].fn.Receiver != "" {
+            usedClasses[baseClassName(graph.nodes[i].fn.Receiver)] = true
+        }
+    }
+    var markBases func(classes []Struct)
+    markBases = func(classes []Struct) {
+        for _, class := range classes {
+            for _, base := range class.Bases {
+                usedClasses[baseClassName(base)] = true
+            }
+            markBases(class.Nested)
+        }
+    }
+    var checkClasses func(filePath string, classes []Struct)
+    checkClasses = func(filePath string, classes []Struct) {
+        for _, class := range classes {
+            name := baseClassName(class.Name)
+            if !usedClasses[name] && typeUsage[name] == 0 {
+                unreferenced.Classes = append(unreferenced.Classes, filePath+":"+class.Name)
+            }
+            checkClasses(filePath, class.Nested)
+        }
+    }
+    for _, f := range summary.PhpFiles {
+        markBases(f.Classes)
+    }
+    for _, f := range summary.PythonFiles {
+        markBases(f.Classes)
+    }
+    for _, f := range summary.JsFiles {
+        markBases(f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        markBases(f.Classes)
+    }
+    for _, f := range summary.PhpFiles {
+        checkClasses(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.PythonFiles {
+        checkClasses(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.JsFiles {
+        checkClasses(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        checkClasses(f.FilePath, f.Classes)
+    }
+
+    // Selectors can only be judged against markup, so they need analyzed HTML
+    htmlClasses := make(map[string]bool)
+    htmlIDs := make(map[string]bool)
+    for _, f := range summary.HtmlFiles {
+        for _, element := range f.Elements {
+            for _, class := range element.Classes {
+                htmlClasses[class] = true
+            }
+            if element.ID != "" {
+                htmlIDs[element.ID] = true
+            }
+        }
+    }
+    if len(htmlClasses) > 0 || len(htmlIDs) > 0 {
+        matchable := func(selector string) bool {
+            for _, alternative := range strings.Split(cssSelectorArgsRegex.ReplaceAllString(selector, ""), ",") {
+                ok := true
+                for _, token := range cssSimpleSelectorRegex.FindAllString(alternative, -1) {
+                    if (token[0] == '.' && !htmlClasses[token[1:]]) || (token[0] == '#' && !htmlIDs[token[1:]]) {
+                        ok = false
+                        break
+                    }
+                }
+                if ok {
+                    return true
+                }
+            }
+            return false
+        }
+        checkRules := func(filePath string, rules []CSSRule) {
+            seen := make(map[string]bool)
+            for _, rule := range rules {
+                if !seen[rule.Selector] && !matchable(rule.Selector) {
+                    unreferenced.CSSSelectors = append(unreferenced.CSSSelectors, filePath+":"+rule.Selector)
+                }
+                seen[rule.Selector] = true
+            }
+        }
+        for _, f := range summary.CssFiles {
+            checkRules(f.FilePath, f.Rules)
+        }
+        for _, f := range summary.HtmlFiles {
+            checkRules(f.FilePath, f.EmbeddedCSS)
+        }
+    }
+
+    // Tables are used by any statement other than the one creating them
+    usedTables := make(map[string]bool)
+    useTables := func(tables []string) {
+        for _, table := range tables {
+            usedTables[strings.ToLower(table)] = true
+        }
+    }
+    for _, f := range summary.SqlFiles {
+        for _, stmt := range f.Statements {
+            if stmt.Type != "CREATE" && stmt.Type != "DROP" {
+                useTables(stmt.Tables)
+            }
+            for _, fk := range stmt.ForeignKeys {
+                useTables([]string{fk.RefTable})
+            }
+        }
+    }
+    for _, f := range summary.GoFiles {
+        useTables(f.SQLTables)
+    }
+    for _, f := range summary.PhpFiles {
+        useTables(f.SQLTables)
+    }
+    for _, f := range summary.PythonFiles {
+        useTables(f.SQLTables)
+    }
+    for _, f := range summary.JsFiles {
+        useTables(f.SQLTables)
+    }
+    for _, f := range summary.TsFiles {
+        useTables(f.SQLTables)
+    }
+    for _, f := range summary.SqlFiles {
+        for _, stmt := range f.Statements {
+            if stmt.Type == "CREATE" && len(stmt.Tables) > 0 && !usedTables[strings.ToLower(stmt.Tables[0])] {
+                unreferenced.SQLTables = append(unreferenced.SQLTables, f.FilePath+":"+stmt.Tables[0])
+            }
+        }
+    }
+
+    if len(unreferenced.Functions) == 0 && len(unreferenced.Classes) == 0 && len(unreferenced.CSSSelectors) == 0 && len(unreferenced.SQLTables) == 0 {
+        return nil
+    }
+    return unreferenced
+}
+
+// entryPointNames collects the names reached from outside the call graph: route, job and
+// registered handlers, and the functions HTML elements and inline scripts call
+func entryPointNames(summary Summary) map[string]bool {
+    entryPoints := make(map[string]bool)
+    addEntryPoint := func(name string) {
+        if name != "" {
+            entryPoints[name[strings.LastIndexAny(name, ".:@>")+1:]] = true
+        }
+    }
+    addRoutes := func(routes []Route, jobs []ScheduledJob, handlers []string) {
+        for _, route := range routes {
+            addEntryPoint(route.Handler)
+        }
+        for _, job := range jobs {
+            addEntryPoint(job.Handler)
+        }
+        for _, handler := range handlers {
+            addEntryPoint(handler)
+        }
+    }
+    for _, f := range summary.GoFiles {
+        addRoutes(f.Routes, f.ScheduledJobs, f.Handlers)
+    }
+    for _, f := range summary.PhpFiles {
+        addRoutes(f.Routes, f.ScheduledJobs, nil)
+    }
+    for _, f := range summary.PythonFiles {
+        addRoutes(f.Routes, f.ScheduledJobs, nil)
+    }
+    for _, job := range summary.ScheduledJobs {
+        addEntryPoint(job.Handler)
+    }
+    for _, f := range summary.HtmlFiles {
+        for _, element := range f.Elements {
+            for _, name := range element.LinkedFunctions {
+                addEntryPoint(name)
+            }
+        }
+        for _, fn := range f.EmbeddedJS {
+            for _, call := range fn.Calls {
+                addEntryPoint(call)
+            }
+        }
+    }
+
+    return entryPoints
+}
+
+// entryPoint reports whether a node is called from outside the analyzed code: main and
+// init, tests, route, job and HTTP handlers, constructors, magic methods, overrides and
+// exported Go methods, which may satisfy an interface
+func (graph *callGraph) entryPoint(i int, names map[string]bool) bool {
+    node := graph.nodes[i]
+    fn := node.fn
+    if names[fn.Name] || fn.IsHTTPHandler || fn.Overrides != "" || isTestFunction(node.language, graph.files[node.file], fn) {
+        return true
+    }
+    switch node.language {
+    case "go":
+        // Benchmarks, examples and fuzz targets are run by go test
+        return fn.Name == "main" || fn.Name == "init" || fn.Name == "_" || (fn.Receiver != "" && ast.IsExported(fn.Name)) ||
+            (strings.HasSuffix(graph.files[node.file], "_test.go") && ast.IsExported(fn.Name))
+    case "php", "python":
+        return strings.HasPrefix(fn.Name, "__")
+    }
+    return fn.Name == "constructor"
+}
+
+// bodyless reports whether a node only declares a method for subclasses or implementers
+// to define: an abstract method, or a method of a Python Protocol
+func (graph *callGraph) bodyless(i int) bool {
+    node := graph.nodes[i]
+    return node.fn.IsAbstract || node.fn.Receiver != "" && graph.classes[node.language][node.fn.Receiver].IsProtocol
+}
+
+// unreachable lists the nodes of the private functions and methods nothing in the analyzed
+// code calls: unexported Go functions other than main and init, Python names with a
+// single leading underscore, and PHP, JavaScript and TypeScript methods marked private.
+// Entry points, given their names from entryPointNames, are never unreachable.
+func (graph *callGraph) unreachable(entryPoints map[string]bool) []int {
+    referenced := graph.referenced()
+
+    private := func(node callGraphNode) bool {
+        name := node.fn.Name
+        switch node.language {
+        case "go":
+            return node.fn.Receiver == "" && !ast.IsExported(name) && name != "main" && name != "init" && name != "_"
+        case "python":
+            return strings.HasPrefix(name, "_") && !strings.HasPrefix(name, "__")
+        }
+        for _, modifier := range node.fn.Modifiers {
+            if modifier == "private" {
+                return true
+            }
+        }
+        return strings.HasPrefix(name, "#")
+    }
+
+    var unreachable []int
+    for i, node := range graph.nodes {
+        if private(node) && !referenced[i] && !graph.entryPoint(i, entryPoints) && !graph.bodyless(i) {
+            unreachable = append(unreachable, i)
+        }
+    }
+    return unreachable
+}
+
+// callGraphSelfReceivers are the receivers through which a method calls its own class
+var callGraphSelfReceivers = map[string]bool{"this": true, "self": true, "$this": true, "static": true, "cls": true}
+
+// callGraphColors fills the call graph nodes of each language
+var callGraphColors = map[string]string{
+    "go":         "#cdeefa",
+    "php":        "#e1e2f2",
+    "python":     "#fff1bf",
+    "javascript": "#fbf5c8",
+    "typescript": "#d5e3f7",
+}
+
+// renderDOT draws the call graph in Graphviz DOT, one cluster per file and nodes
+// colored by language, for rendering with dot -Tsvg
+func renderDOT(summary Summary) []byte {
+    graph := buildCallGraph(summary)
+    quote := func(s string) string {
+        return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+    }
+
+    var out strings.Builder
+    out.WriteString("digraph calls {\n")
+    out.WriteString("    rankdir=LR;\n")
+    out.WriteString("    node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+    out.WriteString("    edge [color=\"#555555\"];\n")
+    for file, filePath := range graph.files {
+        var nodes []int
+        for i, node := range graph.nodes {
+            if node.file == file {
+                nodes = append(nodes, i)
+            }
+        }
+        if len(nodes) == 0 {
+            continue
+        }
+        fmt.Fprintf(&out, "    subgraph cluster_%d {\n", file)
+        fmt.Fprintf(&out, "        label=%s;\n", quote(filePath))
+        out.WriteString("        style=rounded; color=\"#999999\";\n")
+        for _, i := range nodes {
+            node := graph.nodes[i]
+            fmt.Fprintf(&out, "        n%d [label=%s, fillcolor=%s];\n", i, quote(qualifiedFunctionName(node.fn)), quote(callGraphColors[node.language]))
+        }
+        out.WriteString("    }\n")
+    }
+    for _, edge := range graph.edges {
+        fmt.Fprintf(&out, "    n%d -> n%d;\n", edge[0], edge[1])
+    }
+    out.WriteString("}\n")
+    return []byte(out.String())
+}
+
+// renderNDJSON writes one line per file, each a summary holding just that file as Stream
+// produces them, then a line with the codebase-wide sections if there are any
+func renderNDJSON(summary Summary) ([]byte, error) {
+    var lines []Summary
+    for _, f := range summary.GoFiles {
+        lines = append(lines, Summary{GoFiles: []GoFileSummary{f}})
+    }
+    for _, f := range summary.PhpFiles {
+        lines = append(lines, Summary{PhpFiles: []PhpFileSummary{f}})
+    }
+    for _, f := range summary.PythonFiles {
+        lines = append(lines, Summary{PythonFiles: []PythonFileSummary{f}})
+    }
+    for _, f := range summary.JsFiles {
+        lines = append(lines, Summary{JsFiles: []JsFileSummary{f}})
+    }
+    for _, f := range summary.TsFiles {
+        lines = append(lines, Summary{TsFiles: []TsFileSummary{f}})
+    }
+    for _, f := range summary.HtmlFiles {
+        lines = append(lines, Summary{HtmlFiles: []HtmlFileSummary{f}})
+    }
+    for _, f := range summary.CssFiles {
+        lines = append(lines, Summary{CssFiles: []CSSFileSummary{f}})
+    }
+    for _, f := range summary.SqlFiles {
+        lines = append(lines, Summary{SqlFiles: []SQLFileSummary{f}})
+    }
+    for _, f := range summary.MarkdownFiles {
+        lines = append(lines, Summary{MarkdownFiles: []MarkdownFileSummary{f}})
+    }
+    for _, f := range summary.ConfigFiles {
+        lines = append(lines, Summary{ConfigFiles: []ConfigFileSummary{f}})
+    }
+    for _, f := range summary.Dockerfiles {
+        lines = append(lines, Summary{Dockerfiles: []DockerfileSummary{f}})
+    }
+    for _, f := range summary.Makefiles {
+        lines = append(lines, Summary{Makefiles: []MakefileSummary{f}})
+    }
+    for _, f := range summary.EnvFiles {
+        lines = append(lines, Summary{EnvFiles: []EnvFileSummary{f}})
+    }
+    rest := keepSummaryFiles(summary, nil)
+    if !reflect.DeepEqual(rest, Summary{}) {
+        lines = append(lines, rest)
+    }
+
+    var out []byte
+    for _, line := range lines {
+        data, err := json.Marshal(line)
+        if err != nil {
+            return nil, err
+        }
+        out = append(append(out, data...), '\n')
+    }
+    return out, nil
+}
+
+// renderMermaid draws the types as a Mermaid classDiagram and the call graph as a
+// flowchart, each in a fenced block so the output renders as Markdown on GitHub
+func renderMermaid(summary Summary) []byte {
+    var out strings.Builder
+    if classes := mermaidClassDiagram(summary); classes != "" {
+        out.WriteString("```mermaid\n" + classes + "```\n")
+    }
+    if calls := mermaidFlowchart(summary); calls != "" {
+        if out.Len() > 0 {
+            out.WriteString("\n")
+        }
+        out.WriteString("```mermaid\n" + calls + "```\n")
+    }
+    return []byte(out.String())
+}
+
+// mermaidText makes a type or signature safe inside a class body, where braces end
+// the class and angle brackets are written as tildes
+var mermaidText = strings.NewReplacer("<", "~", ">", "~", "{", "(", "}", ")", `"`, "'").Replace
+
+// mermaidID turns a type name into a Mermaid class identifier
+func mermaidID(name string) string {
+    return regexp.MustCompile(`\W`).ReplaceAllString(name, "_")
+}
+
+// mermaidVisibility returns the UML visibility marker for a member: TypeScript and PHP
+// modifiers or property scope when present, otherwise a leading underscore or #
+func mermaidVisibility(name string, modifiers []string) string {
+    for _, modifier := range modifiers {
+        switch modifier {
+        case "private":
+            return "-"
+        case "protected":
+            return "#"
+        }
+    }
+    if strings.HasPrefix(name, "_") || strings.HasPrefix(name, "#") {
+        return "-"
+    }
+    return "+"
+}
+
+// mermaidClassDiagram lists the structs, classes and interfaces with their fields and
+// methods, and the inheritance between the ones declared in the codebase. A name declared
+// in several Go packages or files gets an ID qualified by its package directory or file.
+func mermaidClassDiagram(summary Summary) string {
+    var out strings.Builder
+    declared := make(map[string]bool)
+    interfaces := make(map[string]bool)
+    type relation struct{ base, derived, scope string }
+    var relations []relation
+
+    // Find the packages or files declaring each name first, to know which to qualify
+    scopes := make(map[string]map[string]bool)
+    declare := func(scope, name string) {
+        if scopes[name] == nil {
+            scopes[name] = make(map[string]bool)
+        }
+        scopes[name][scope] = true
+    }
+    var declareClasses func(scope string, classes []Struct)
+    declareClasses = func(scope string, classes []Struct) {
+        for _, class := range classes {
+            declare(scope, class.Name)
+            declareClasses(scope, class.Nested)
+        }
+    }
+    declareInterfaces := func(scope string, interfaces []Interface) {
+        for _, intf := range interfaces {
+            declare(scope, intf.Name)
+        }
+    }
+    for _, f := range summary.GoFiles {
+        declareClasses(filepath.Dir(f.FilePath), f.Structs)
+        declareInterfaces(filepath.Dir(f.FilePath), f.Interfaces)
+    }
+    for _, f := range summary.PhpFiles {
+        declareClasses(f.FilePath, f.Classes)
+        declareInterfaces(f.FilePath, f.Interfaces)
+    }
+    for _, f := range summary.PythonFiles {
+        declareClasses(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.JsFiles {
+        declareClasses(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        declareClasses(f.FilePath, f.Classes)
+        declareInterfaces(f.FilePath, f.Interfaces)
+    }
+    classID := func(scope, name string) string {
+        if len(scopes[name]) > 1 {
+            return mermaidID(filepath.ToSlash(scope) + "/" + name)
+        }
+        return mermaidID(name)
+    }
+    // A base resolves to the declaration in the same package or file, else to the only one
+    baseID := func(scope, base string) (string, bool) {
+        name := baseClassName(strings.SplitN(base, "<", 2)[0])
+        if scopes[name][scope] {
+            return classID(scope, name), true
+        }
+        if len(scopes[name]) == 1 {
+            for only := range scopes[name] {
+                return classID(only, name), true
+            }
+        }
+        return "", false
+    }
+    writeHeader := func(id, name string) {
+        if id == mermaidID(name) {
+            fmt.Fprintf(&out, "    class %s {\n", id)
+        } else {
+            fmt.Fprintf(&out, "    class %s[\"%s\"] {\n", id, name)
+        }
+    }
+
+    writeMember := func(visibility string, text string) {
+        fmt.Fprintf(&out, "        %s%s\n", visibility, mermaidText(text))
+    }
+    writeMethod := func(visibility string, fn Function) {
+        var args []string
+        for _, arg := range fn.Args {
+            args = append(args, strings.TrimSpace(arg.Name+" "+arg.Type))
+        }
+        signature := fn.Name + "(" + strings.Join(args, ", ") + ")"
+        if len(fn.Returns) > 0 {
+            signature += " " + strings.Join(fn.Returns, ", ")
+        }
+        if fn.IsAbstract {
+            signature += "*"
+        }
+        writeMember(visibility, signature)
+    }
+    var writeClass func(scope string, class Struct, methods []Function, goStyle bool)
+    writeClass = func(scope string, class Struct, methods []Function, goStyle bool) {
+        id := classID(scope, class.Name)
+        declared[id] = true
+        writeHeader(id, class.Name)
+        if class.IsAbstract {
+            out.WriteString("        <<abstract>>\n")
+        }
+        visibility := func(name string, modifiers []string) string {
+            if goStyle {
+                if ast.IsExported(name) {
+                    return "+"
+                }
+                return "-"
+            }
+            return mermaidVisibility(name, modifiers)
+        }
+        for _, field := range class.Fields {
+            writeMember(visibility(field.Name, []string{field.Scope}), strings.TrimSpace(field.Name+" "+field.Type))
+        }
+        for _, method := range methods {
+            writeMethod(visibility(method.Name, method.Modifiers), method)
+        }
+        out.WriteString("    }\n")
+        for _, base := range class.Bases {
+            relations = append(relations, relation{base, id, scope})
+        }
+        for _, nested := range class.Nested {
+            writeClass(scope, nested, nested.Methods, goStyle)
+        }
+    }
+    writeInterface := func(scope string, intf Interface) {
+        id := classID(scope, intf.Name)
+        declared[id] = true
+        interfaces[id] = true
+        writeHeader(id, intf.Name)
+        out.WriteString("        <<interface>>\n")
+        for _, field := range intf.Fields {
+            writeMember("+", strings.TrimSpace(field.Name+" "+field.Type))
+        }
+        for _, method := range intf.Methods {
+            writeMethod("+", method)
+        }
+        out.WriteString("    }\n")
+        for _, embed := range intf.Embeds {
+            relations = append(relations, relation{embed, id, scope})
+        }
+    }
+
+    for _, f := range summary.GoFiles {
+        methods := make(map[string][]Function)
+        for _, fn := range f.Functions {
+            if fn.Receiver != "" {
+                methods[fn.Receiver] = append(methods[fn.Receiver], fn)
+            }
+        }
+        dir := filepath.Dir(f.FilePath)
+        for _, str := range f.Structs {
+            if len(str.Methods) > 0 {
+                writeClass(dir, str, str.Methods, true)
+            } else {
+                writeClass(dir, str, methods[str.Name], true)
+            }
+        }
+        for _, intf := range f.Interfaces {
+            writeInterface(dir, intf)
+        }
+    }
+    for _, f := range summary.PhpFiles {
+        for _, class := range f.Classes {
+            writeClass(f.FilePath, class, class.Methods, false)
+        }
+        for _, intf := range f.Interfaces {
+            writeInterface(f.FilePath, intf)
+        }
+    }
+    for _, f := range summary.PythonFiles {
+        for _, class := range f.Classes {
+            writeClass(f.FilePath, class, class.Methods, false)
+        }
+    }
+    for _, f := range summary.JsFiles {
+        for _, class := range f.Classes {
+            writeClass(f.FilePath, class, class.Methods, false)
+        }
+    }
+    for _, f := range summary.TsFiles {
+        for _, class := range f.Classes {
+            writeClass(f.FilePath, class, class.Methods, false)
+        }
+        for _, intf := range f.Interfaces {
+            writeInterface(f.FilePath, intf)
+        }
+    }
+    if out.Len() == 0 {
+        return ""
+    }
+
+    // Bases from outside the codebase would show up as empty classes, so they are left out.
+    // A class implementing an interface gets the dotted realization arrow
+    seen := make(map[[2]string]bool)
+    for _, r := range relations {
+        base, ok := baseID(r.scope, r.base)
+        edge := [2]string{base, r.derived}
+        if !ok || !declared[base] || seen[edge] {
+            continue
+        }
+        seen[edge] = true
+        if interfaces[base] && !interfaces[r.derived] {
+            fmt.Fprintf(&out, "    %s <|.. %s\n", base, r.derived)
+        } else {
+            fmt.Fprintf(&out, "    %s <|-- %s\n", base, r.derived)
+        }
+    }
+    return "classDiagram\n" + out.String()
+}
+
+// mermaidFlowchart draws the call graph as a flowchart, one subgraph per file and
+// nodes colored by language
+func mermaidFlowchart(summary Summary) string {
+    graph := buildCallGraph(summary)
+    if len(graph.edges) == 0 {
+        return ""
+    }
+    label := strings.NewReplacer(`"`, "#quot;").Replace
+
+    var out strings.Builder
+    out.WriteString("flowchart LR\n")
+    byLanguage := make(map[string][]string)
+    for file, filePath := range graph.files {
+        var nodes []int
+        for i, node := range graph.nodes {
+            if node.file == file {
+                nodes = append(nodes, i)
+            }
+        }
+        if len(nodes) == 0 {
+            continue
+        }
+        fmt.Fprintf(&out, "    subgraph f%d[\"%s\"]\n", file, label(filePath))
+        for _, i := range nodes {
+            node := graph.nodes[i]
+            fmt.Fprintf(&out, "        n%d[\"%s\"]\n", i, label(qualifiedFunctionName(node.fn)))
+            byLanguage[node.language] = append(byLanguage[node.language], fmt.Sprintf("n%d", i))
+        }
+        out.WriteString("    end\n")
+    }
+    for _, edge := range graph.edges {
+        fmt.Fprintf(&out, "    n%d --> n%d\n", edge[0], edge[1])
+    }
+    for _, language := range sortedKeys(byLanguage) {
+        fmt.Fprintf(&out, "    classDef %s fill:%s\n", language, callGraphColors[language])
+        fmt.Fprintf(&out, "    class %s %s\n", strings.Join(byLanguage[language], ","), language)
+    }
+    return out.String()
+}
+
+// renderSARIF wraps the findings collected in the summary into a SARIF 2.1.0 log,
+// so they surface as code scanning annotations in CI
+func renderSARIF(summary Summary, root string) []byte {
+    levels := make(map[string]string)
+    driver := sarifDriver{Name: "distiller", Version: VERSION}
+    for _, r := range sarifRules {
+        rule := sarifRule{ID: r.id, ShortDescription: sarifMessage{r.description}}
+        rule.DefaultConfiguration.Level = r.level
+        driver.Rules = append(driver.Rules, rule)
+        levels[r.id] = r.level
+    }
+
+    results := []sarifResult{}
+    report := func(ruleID string, message string, locations ...sarifLocation) {
+        results = append(results, sarifResult{
+            RuleID:    ruleID,
+            Level:     levels[ruleID],
+            Message:   sarifMessage{message},
+            Locations: locations,
+        })
+    }
+
+    undocumented := make(map[string]bool)
+    for _, name := range summary.UndocumentedEnvVars {
+        undocumented[name] = true
+    }
+    reportEnvVars := func(filePath string, envVars []string) {
+        for _, name := range envVars {
+            if undocumented[name] {
+                report("env/undocumented-variable", name+" is read here but not listed in any env file", sarifLocationAt(root, filePath, 0))
+            }
+        }
+    }
+    reportSQLConcatenations := func(filePath string, lines []int) {
+        for _, line := range lines {
+            report("sql/concatenated-query", "SQL query is assembled from values; pass them as bound parameters", sarifLocationAt(root, filePath, line))
+        }
+    }
+    var reportEmptyClasses func(filePath string, classes []Struct)
+    reportEmptyClasses = func(filePath string, classes []Struct) {
+        for _, class := range classes {
+            if len(class.Fields) == 0 && len(class.Methods) == 0 && len(class.Nested) == 0 && len(class.Bases) == 0 {
+                report("classes/empty", class.Name+" declares nothing", sarifLocationAt(root, filePath, class.Line))
+            }
+            reportEmptyClasses(filePath, class.Nested)
+        }
+    }
+    reportUnusedImports := func(filePath string, imports []Import) {
+        for _, imp := range imports {
+            if imp.Unused {
+                report("imports/unused", imp.Path+" is imported but never used", sarifLocationAt(root, filePath, 0))
+            }
+        }
+    }
+
+    for _, f := range summary.GoFiles {
+        if f.ParseError != "" {
+            location := sarifLocationAt(root, f.FilePath, f.ParseErrorLine)
+            if location.PhysicalLocation.Region != nil {
+                location.PhysicalLocation.Region.StartColumn = f.ParseErrorColumn
+            }
+            report("go/parse-error", f.ParseError, location)
+        }
+        lines := make(map[string]int)
+        for _, fn := range f.Functions {
+            lines[qualifiedFunctionName(fn)] = fn.Line
+        }
+        for _, entry := range f.ContextNotPropagated {
+            parts := strings.SplitN(entry, ": ", 2)
+            if len(parts) == 2 {
+                report("go/context-not-propagated", parts[0]+" calls "+parts[1]+" without passing its context", sarifLocationAt(root, f.FilePath, lines[parts[0]]))
+            }
+        }
+        reportUnusedImports(f.FilePath, f.Imports)
+        reportEnvVars(f.FilePath, f.EnvVars)
+        reportSQLConcatenations(f.FilePath, f.SQLConcatenations)
+    }
+    for _, f := range summary.PhpFiles {
+        reportEnvVars(f.FilePath, f.EnvVars)
+        reportSQLConcatenations(f.FilePath, f.SQLConcatenations)
+        reportEmptyClasses(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.PythonFiles {
+        reportUnusedImports(f.FilePath, f.Imports)
+        reportEnvVars(f.FilePath, f.EnvVars)
+        reportSQLConcatenations(f.FilePath, f.SQLConcatenations)
+        reportEmptyClasses(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.JsFiles {
+        reportEnvVars(f.FilePath, f.EnvVars)
+        reportSQLConcatenations(f.FilePath, f.SQLConcatenations)
+        reportEmptyClasses(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        reportEnvVars(f.FilePath, f.EnvVars)
+        reportSQLConcatenations(f.FilePath, f.SQLConcatenations)
+        reportEmptyClasses(f.FilePath, f.Classes)
+    }
+
+    graph := buildCallGraph(summary)
+    for _, i := range graph.unreachable(entryPointNames(summary)) {
+        node := graph.nodes[i]
+        report("functions/unreachable", qualifiedFunctionName(node.fn)+" is never called", sarifLocationAt(root, graph.files[node.file], node.fn.Line))
+    }
+
+    unused := make(map[string]bool)
+    for _, key := range summary.UnusedEnvKeys {
+        unused[key] = true
+    }
+    for _, f := range summary.EnvFiles {
+        for _, key := range f.EnvKeys {
+            if unused[key] {
+                report("env/unused-key", key+" is documented but never read", sarifLocationAt(root, f.FilePath, 0))
+            }
+        }
+    }
+
+    for _, f := range summary.CssFiles {
+        // Point duplicates at the second definition
+        seen := make(map[string]bool)
+        reported := make(map[string]bool)
+        for _, rule := range f.Rules {
+            key := strings.Join(strings.Fields(rule.Selector), " ")
+            if rule.MediaQuery != "" {
+                key += " @media " + rule.MediaQuery
+            }
+            if seen[key] && !reported[key] {
+                report("css/duplicate-selector", key+" is already defined earlier in the file", sarifLocationAt(root, f.FilePath, rule.Line))
+                reported[key] = true
+            }
+            seen[key] = true
+        }
+        for _, conflict := range f.Conflicts {
+            report("css/conflicting-declaration",
+                fmt.Sprintf("%s sets %s to %s", conflict.Selector, conflict.Property, strings.Join(conflict.Values, ", then ")),
+                sarifLocationAt(root, f.FilePath, conflict.Lines[len(conflict.Lines)-1]))
+        }
+    }
+
+    for _, f := range summary.SqlFiles {
+        for _, stmt := range f.Statements {
+            if stmt.Unbounded {
+                report("sql/unbounded-select", "SELECT from "+stmt.Tables[0]+" reads every row", sarifLocationAt(root, f.FilePath, stmt.Line))
+            }
+        }
+    }
+
+    for _, cluster := range summary.CrossLangDuplicates {
+        var locations []sarifLocation
+        for _, member := range cluster {
+            // Members are "language:file:function", and the file may itself contain colons
+            first, last := strings.Index(member, ":"), strings.LastIndex(member, ":")
+            if first >= 0 && last > first {
+                locations = append(locations, sarifLocationAt(root, member[first+1:last], 0))
+            }
+        }
+        if len(locations) > 0 {
+            report("duplicates/cross-language", "Likely reimplemented across languages: "+strings.Join(cluster, ", "), locations...)
+        }
+    }
+
+    if summary.Diff != nil {
+        for _, change := range summary.Diff.ComplexityRegressions {
+            sep := strings.LastIndex(change.Function, ":")
+            report("complexity/regression",
+                fmt.Sprintf("%s went from complexity %d to %d", change.Function[sep+1:], change.Before, change.After),
+                sarifLocationAt(root, change.Function[:sep], change.Line))
+        }
+    }
+
+    log := sarifLog{
+        Version: "2.1.0",
+        Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+        Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+    }
+    data, _ := json.MarshalIndent(log, "", "  ")
+    return data
+}
+
+// walkOrderLess reports whether filepath.Walk visits path a before path b. Walk descends
+// depth-first through lexically sorted entries, so paths compare element by element.
+func walkOrderLess(a string, b string) bool {
+    aParts := strings.Split(filepath.ToSlash(a), "/")
+    bParts := strings.Split(filepath.ToSlash(b), "/")
+    for i := 0; i < len(aParts) && i < len(bParts); i++ {
+        if aParts[i] != bParts[i] {
+            return aParts[i] < bParts[i]
+        }
+    }
+    return len(aParts) < len(bParts)
+}
+
+// keepFiles returns the file summaries whose path is in keep, preserving their order
+func keepFiles[T any](files []T, filePath func(T) string, keep map[string]bool) []T {
+    var kept []T
+    for _, f := range files {
+        if keep[filePath(f)] {
+            kept = append(kept, f)
+        }
+    }
+    return kept
+}
+
+// countFileLines returns the number of lines in a file, or 0 if it cannot be read
+func countFileLines(path string) int {
+    data, err := ioutil.ReadFile(path)
+    if err != nil || len(data) == 0 {
+        return 0
+    }
+    lines := strings.Count(string(data), "\n")
+    if data[len(data)-1] != '\n' {
+        lines++
+    }
+    return lines
+}
+
+// computeLanguageStats counts the files, lines and functions of each language,
+// methods included, and the share of all lines each language accounts for
+func computeLanguageStats(summary Summary) []LanguageStat {
+    var stats []LanguageStat
+    add := func(language string, paths []string, functions int) {
+        if len(paths) == 0 {
+            return
+        }
+        stat := LanguageStat{Language: language, Files: len(paths), Functions: functions}
+        for _, path := range paths {
+            stat.Lines += countFileLines(path)
+        }
+        stats = append(stats, stat)
+    }
+    countMethods := func(types []Struct) int {
+        n := 0
+        for _, t := range types {
+            n += len(t.Methods)
+        }
+        return n
+    }
+
+    var paths []string
+    functions := 0
+    for _, f := range summary.GoFiles {
+        paths = append(paths, f.FilePath)
+        functions += len(f.Functions) + countMethods(f.Structs)
+    }
+    add("go", paths, functions)
+
+    paths, functions = nil, 0
+    for _, f := range summary.PhpFiles {
+        paths = append(paths, f.FilePath)
+        functions += len(f.Functions) + countMethods(f.Classes)
+    }
+    add("php", paths, functions)
+
+    paths, functions = nil, 0
+    for _, f := range summary.PythonFiles {
+        paths = append(paths, f.FilePath)
+        functions += len(f.Functions) + countMethods(f.Classes)
+    }
+    add("python", paths, functions)
+
+    paths, functions = nil, 0
+    for _, f := range summary.JsFiles {
+        paths = append(paths, f.FilePath)
+        functions += len(f.Functions) + countMethods(f.Classes)
+    }
+    add("javascript", paths, functions)
+
+    paths, functions = nil, 0
+    for _, f := range summary.TsFiles {
+        paths = append(paths, f.FilePath)
+        functions += len(f.Functions) + countMethods(f.Classes)
+    }
+    add("typescript", paths, functions)
+
+    paths, functions = nil, 0
+    for _, f := range summary.HtmlFiles {
+        paths = append(paths, f.FilePath)
+        functions += len(f.EmbeddedJS)
+    }
+    add("html", paths, functions)
+
+    paths = nil
+    for _, f := range summary.CssFiles {
+        paths = append(paths, f.FilePath)
+    }
+    add("css", paths, 0)
+
+    paths = nil
+    for _, f := range summary.SqlFiles {
+        paths = append(paths, f.FilePath)
+    }
+    add("sql", paths, 0)
+
+    paths = nil
+    for _, f := range summary.MarkdownFiles {
+        paths = append(paths, f.FilePath)
+    }
+    add("markdown", paths, 0)
+
+    paths = nil
+    for _, f := range summary.ConfigFiles {
+        paths = append(paths, f.FilePath)
+    }
+    add("config", paths, 0)
+
+    paths = nil
+    for _, f := range summary.Dockerfiles {
+        paths = append(paths, f.FilePath)
+    }
+    add("dockerfile", paths, 0)
+
+    paths = nil
+    for _, f := range summary.Makefiles {
+        paths = append(paths, f.FilePath)
+    }
+    add("makefile", paths, 0)
+
+    paths = nil
+    for _, f := range summary.EnvFiles {
+        paths = append(paths, f.FilePath)
+    }
+    add("env", paths, 0)
+
+    total := 0
+    for _, stat := range stats {
+        total += stat.Lines
+    }
+    for i := range stats {
+        if total > 0 {
+            // One decimal place is enough for an overview
+            stats[i].Percent = float64(stats[i].Lines*1000/total) / 10
+        }
+    }
+    sort.SliceStable(stats, func(i, j int) bool {
+        return stats[i].Lines > stats[j].Lines
+    })
+    return stats
+}
+
+var typeNameRegex = regexp.MustCompile(`\w+`)
+
+// profileTypeUsage counts, for every type declared in the codebase, the fields,
+// parameters, variables and returns whose type mentions it
+func profileTypeUsage(summary Summary) map[string]int {
+    usage := make(map[string]int)
+    for key, n := range countTypeUsage(summary) {
+        usage[key[strings.Index(key, "\x00")+1:]] += n
+    }
+    return usage
+}
+
+// countTypeUsage counts the uses of every declared type, keyed by language and type
+// name. A type string naming the same type twice, like map[ID]ID, counts once. Only
+// types declared in the same language count, and Go type parameters shadow declared
+// types of the same name.
+func countTypeUsage(summary Summary) map[string]int {
+    declared := make(map[string]bool)
+    declare := func(language, name string) {
+        declared[language+"\x00"+name] = true
+    }
+    var declareTypes func(language string, types []Struct)
+    declareTypes = func(language string, types []Struct) {
+        for _, t := range types {
+            declare(language, t.Name[strings.LastIndex(t.Name, ".")+1:])
+            declareTypes(language, t.Nested)
+        }
+    }
+    declareInterfaces := func(language string, interfaces []Interface) {
+        for _, intf := range interfaces {
+            declare(language, intf.Name)
+        }
+    }
+    for _, f := range summary.GoFiles {
+        declareTypes("go", f.Structs)
+        declareInterfaces("go", f.Interfaces)
+    }
+    for _, f := range summary.PhpFiles {
+        declareTypes("php", f.Classes)
+        declareInterfaces("php", f.Interfaces)
+    }
+    for _, f := range summary.PythonFiles {
+        declareTypes("python", f.Classes)
+    }
+    for _, f := range summary.JsFiles {
+        declareTypes("js", f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        declareTypes("js", f.Classes)
+        declareInterfaces("js", f.Interfaces)
+        for _, alias := range f.TypeAliases {
+            declare("js", alias.Name)
+        }
+        for _, enum := range f.Enums {
+            declare("js", enum.Name)
+        }
+    }
+
+    usage := make(map[string]int)
+    count := func(language, typeStr string, typeParams map[string]bool) {
+        seen := make(map[string]bool)
+        for _, name := range typeNameRegex.FindAllString(typeStr, -1) {
+            if declared[language+"\x00"+name] && !typeParams[name] && !seen[name] {
+                seen[name] = true
+                usage[language+"\x00"+name]++
+            }
+        }
+    }
+    countVariables := func(language string, variables []Variable, typeParams map[string]bool) {
+        for _, v := range variables {
+            count(language, v.Type, typeParams)
+        }
+    }
+    countFunctions := func(language string, functions []Function, typeParams func(Function) map[string]bool) {
+        for _, fn := range functions {
+            params := typeParams(fn)
+            countVariables(language, fn.Args, params)
+            for _, ret := range fn.Returns {
+                count(language, ret, params)
+            }
+        }
+    }
+    noTypeParams := func(Function) map[string]bool { return nil }
+    var countTypes func(language string, types []Struct, withMethods bool)
+    countTypes = func(language string, types []Struct, withMethods bool) {
+        for _, t := range types {
+            countVariables(language, t.Fields, typeParamNames(t.TypeParams))
+            if withMethods {
+                countFunctions(language, t.Methods, noTypeParams)
+            }
+            countTypes(language, t.Nested, withMethods)
+        }
+    }
+    countInterfaces := func(language string, interfaces []Interface) {
+        for _, intf := range interfaces {
+            params := typeParamNames(intf.TypeParams)
+            countVariables(language, intf.Fields, params)
+            countFunctions(language, intf.Methods, func(Function) map[string]bool { return params })
+        }
+    }
+
+    // Go methods are already among the file's functions
+    genericTypes := goGenericTypes(summary)
+    for _, f := range summary.GoFiles {
+        dir := filepath.Dir(f.FilePath)
+        countVariables("go", f.Variables, nil)
+        countFunctions("go", f.Functions, func(fn Function) map[string]bool {
+            return goTypeParamsInScope(fn, dir, genericTypes)
+        })
+        countTypes("go", f.Structs, false)
+        countInterfaces("go", f.Interfaces)
+    }
+    for _, f := range summary.PhpFiles {
+        countVariables("php", f.Variables, nil)
+        countFunctions("php", f.Functions, noTypeParams)
+        countTypes("php", f.Classes, true)
+        countInterfaces("php", f.Interfaces)
+    }
+    for _, f := range summary.PythonFiles {
+        countVariables("python", f.Variables, nil)
+        countFunctions("python", f.Functions, noTypeParams)
+        countTypes("python", f.Classes, true)
+    }
+    for _, f := range summary.JsFiles {
+        countVariables("js", f.Constants, nil)
+        countFunctions("js", f.Functions, noTypeParams)
+        countTypes("js", f.Classes, true)
+    }
+    for _, f := range summary.TsFiles {
+        countVariables("js", f.Constants, nil)
+        countFunctions("js", f.Functions, noTypeParams)
+        countTypes("js", f.Classes, true)
+        countInterfaces("js", f.Interfaces)
+        for _, alias := range f.TypeAliases {
+            count("js", alias.Type, nil)
+        }
+    }
+    return usage
+}
+
+// collapseImports replaces the import list of every Go, PHP, Python, JavaScript and TypeScript file with
+// indices into a project-wide list of distinct paths per language. Per-file usage
+// details from -explain-imports are dropped along with the lists.
+func collapseImports(summary *Summary) {
+    summary.ImportIndex = make(map[string][]string)
+    positions := make(map[string]int)
+    collapse := func(language string, imports []Import) []int {
+        var refs []int
+        for _, imp := range imports {
+            key := language + "\x00" + imp.Path
+            position, ok := positions[key]
+            if !ok {
+                position = len(summary.ImportIndex[language])
+                positions[key] = position
+                summary.ImportIndex[language] = append(summary.ImportIndex[language], imp.Path)
+            }
+            refs = append(refs, position)
+        }
+        return refs
+    }
+
+    for i := range summary.GoFiles {
+        summary.GoFiles[i].ImportRefs = collapse("go", summary.GoFiles[i].Imports)
+        summary.GoFiles[i].Imports = nil
+    }
+    for i := range summary.PhpFiles {
+        summary.PhpFiles[i].ImportRefs = collapse("php", summary.PhpFiles[i].Imports)
+        summary.PhpFiles[i].Imports = nil
+    }
+    for i := range summary.PythonFiles {
+        summary.PythonFiles[i].ImportRefs = collapse("python", summary.PythonFiles[i].Imports)
+        summary.PythonFiles[i].Imports = nil
+    }
+    for i := range summary.JsFiles {
+        summary.JsFiles[i].ImportRefs = collapse("javascript", summary.JsFiles[i].Imports)
+        summary.JsFiles[i].Imports = nil
+    }
+    for i := range summary.TsFiles {
+        summary.TsFiles[i].ImportRefs = collapse("typescript", summary.TsFiles[i].Imports)
+        summary.TsFiles[i].Imports = nil
+    }
+    if len(summary.ImportIndex) == 0 {
+        summary.ImportIndex = nil
+    }
+}
+
+// querySegmentRegex matches one step of a query path: a member name, optionally
+// followed by an index or [*], or a bare index
+var querySegmentRegex = regexp.MustCompile(`^(\*|[\w$-]*)((?:\[(?:\*|\d+)\])*)$`)
+
+// Query evaluates a JSONPath-like expression against the marshaled output and
+// returns the matched values one per line, strings unquoted. Paths are member names
+// separated by dots, each optionally indexed with [n] or [*]; "*" matches every member.
+func Query(output []byte, query string) ([]byte, error) {
+    var root interface{}
+    if err := json.Unmarshal(output, &root); err != nil {
+        return nil, fmt.Errorf("query needs JSON output: %v", err)
+    }
+
+    query = strings.TrimPrefix(strings.TrimPrefix(query, "$"), ".")
+    current := []interface{}{root}
+    for _, segment := range strings.Split(query, ".") {
+        if segment == "" {
+            continue
+        }
+        parts := querySegmentRegex.FindStringSubmatch(segment)
+        if parts == nil {
+            return nil, fmt.Errorf("invalid query segment %q", segment)
+        }
+
+        var next []interface{}
+        for _, value := range current {
+            object, isObject := value.(map[string]interface{})
+            switch {
+            case parts[1] == "":
+                next = append(next, value)
+            case parts[1] == "*" && isObject:
+                for _, key := range sortedKeys(object) {
+                    next = append(next, object[key])
+                }
+            case isObject:
+                if member, ok := object[parts[1]]; ok {
+                    next = append(next, member)
+                }
+            }
+        }
+
+        // Apply the indexes in turn, e.g. [*][0] on nested arrays
+        for _, index := range regexp.MustCompile(`\[(\*|\d+)\]`).FindAllStringSubmatch(parts[2], -1) {
+            var indexed []interface{}
+            for _, value := range next {
+                array, ok := value.([]interface{})
+                if !ok {
+                    continue
+                }
+                if index[1] == "*" {
+                    indexed = append(indexed, array...)
+                } else if n, _ := strconv.Atoi(index[1]); n < len(array) {
+                    indexed = append(indexed, array[n])
+                }
+            }
+            next = indexed
+        }
+        current = next
+    }
+
+    var lines []string
+    for _, value := range current {
+        if text, ok := value.(string); ok {
+            lines = append(lines, text)
+            continue
+        }
+        data, err := json.Marshal(value)
+        if err != nil {
+            return nil, err
+        }
+        lines = append(lines, string(data))
+    }
+    return []byte(strings.Join(lines, "\n")), nil
+}
+
+// estimateTokens approximates the tokens a model needs for text, at about four bytes a token
+func estimateTokens(size int) int {
+    return (size + 3) / 4
+}
+
+// BudgetReport breaks the estimated token cost of the output down by section, as a
+// table of tokens and share of the whole; whatever is in no section counts as "other"
+func BudgetReport(summary Summary, output []byte) string {
+    var functions, types, controlFlows []interface{}
+    rawQueries := 0
+    for _, f := range summary.GoFiles {
+        functions = append(functions, f.Functions)
+        types = append(types, f.Structs, f.Interfaces)
+        controlFlows = append(controlFlows, f.ControlFlows)
+    }
+    for _, f := range summary.PhpFiles {
+        functions = append(functions, f.Functions)
+        types = append(types, f.Classes, f.Interfaces)
+        controlFlows = append(controlFlows, f.ControlFlows)
+    }
+    for _, f := range summary.PythonFiles {
+        functions = append(functions, f.Functions)
+        types = append(types, f.Classes)
+        controlFlows = append(controlFlows, f.ControlFlows)
+    }
+    for _, f := range summary.JsFiles {
+        functions = append(functions, f.Functions)
+        types = append(types, f.Classes)
+        controlFlows = append(controlFlows, f.ControlFlows)
+    }
+    for _, f := range summary.TsFiles {
+        functions = append(functions, f.Functions)
+        types = append(types, f.Classes, f.Interfaces, f.TypeAliases, f.Enums)
+        controlFlows = append(controlFlows, f.ControlFlows)
+    }
+    for _, f := range summary.HtmlFiles {
+        functions = append(functions, f.EmbeddedJS)
+    }
+    for _, f := range summary.SqlFiles {
+        for _, stmt := range f.Statements {
+            rawQueries += len(stmt.RawQuery)
+        }
+    }
+
+    size := func(sections []interface{}) int {
+        total := 0
+        for _, section := range sections {
+            if data, _ := json.Marshal(section); string(data) != "null" {
+                total += len(data)
+            }
+        }
+        return total
+    }
+    rows := []struct {
+        name  string
+        bytes int
+    }{
+        {"functions", size(functions)},
+        {"types", size(types)},
+        {"control flow", size(controlFlows)},
+        {"SQL raw queries", rawQueries},
+    }
+
+    total := estimateTokens(len(output))
+    var report strings.Builder
+    fmt.Fprintf(&report, "%-16s %10s %7s\n", "Section", "Tokens", "Share")
+    other := total
+    for _, row := range rows {
+        tokens := estimateTokens(row.bytes)
+        if tokens > other {
+            tokens = other
+        }
+        other -= tokens
+        fmt.Fprintf(&report, "%-16s %10d %6.1f%%\n", row.name, tokens, percentOf(tokens, total))
+    }
+    fmt.Fprintf(&report, "%-16s %10d %6.1f%%\n", "other", other, percentOf(other, total))
+    fmt.Fprintf(&report, "%-16s %10d %6.1f%%\n", "total", total, percentOf(total, total))
+    return report.String()
+}
+
+// percentOf returns part as a percentage of whole, or 0 for an empty whole
+func percentOf(part int, whole int) float64 {
+    if whole == 0 {
+        return 0
+    }
+    return float64(part) * 100 / float64(whole)
+}
+
+// readBaseline loads an earlier run's output, either the plain JSON summary or the
+// pattern format with its details, and returns the directory it analyzed if known
+func readBaseline(path string) (Summary, string, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return Summary{}, "", err
+    }
+    var pattern PatternSummary
+    if err := json.Unmarshal(data, &pattern); err == nil && pattern.Details != nil {
+        return *pattern.Details, pattern.AnalyzedDir, nil
+    }
+    var summary Summary
+    if err := json.Unmarshal(data, &summary); err != nil {
+        return Summary{}, "", err
+    }
+    return summary, "", nil
+}
+
+// diffFunctions indexes the functions and methods of a summary by "file:qualified name",
+// with file paths relative to the analyzed directory
+func diffFunctions(summary Summary, dir string) map[string]Function {
+    functions := make(map[string]Function)
+    add := func(filePath string, fns []Function) {
+        file := filepath.ToSlash(filePath)
+        if rel, err := filepath.Rel(dir, filePath); err == nil {
+            file = filepath.ToSlash(rel)
+        }
+        for _, fn := range fns {
+            functions[file+":"+qualifiedFunctionName(fn)] = fn
+        }
+    }
+    addTypes := func(filePath string, types []Struct) {
+        for _, t := range types {
+            add(filePath, t.Methods)
+        }
+    }
+    for _, f := range summary.GoFiles {
+        add(f.FilePath, f.Functions)
+        addTypes(f.FilePath, f.Structs)
+    }
+    for _, f := range summary.PhpFiles {
+        add(f.FilePath, f.Functions)
+        addTypes(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.PythonFiles {
+        add(f.FilePath, f.Functions)
+        addTypes(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.JsFiles {
+        add(f.FilePath, f.Functions)
+        addTypes(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        add(f.FilePath, f.Functions)
+        addTypes(f.FilePath, f.Classes)
+    }
+    return functions
+}
+
+// functionShape describes a function without its name or location: its kind,
+// arguments, returns and the number of distinct calls it makes
+func functionShape(fn Function) string {
+    var args []string
+    for _, arg := range fn.Args {
+        args = append(args, arg.Name+" "+arg.Type)
+    }
+    return fmt.Sprintf("%s(%s)(%s)%d", functionKind(fn), strings.Join(args, ","), strings.Join(fn.Returns, ","), len(fn.Calls))
+}
+
+// diffSummaries compares the functions of a baseline run with the current one. A removed
+// function whose shape matches exactly one added function, preferring one with the same
+// name, is reported as renamed or moved instead of as a delete and an add.
+func diffSummaries(baseline Summary, baselineDir string, current Summary, currentDir string) *Diff {
+    before := diffFunctions(baseline, baselineDir)
+    after := diffFunctions(current, currentDir)
+    diff := &Diff{}
+
+    var removed, added []string
+    for _, key := range sortedKeys(before) {
+        fn, ok := after[key]
+        if !ok {
+            removed = append(removed, key)
+        } else if functionShape(fn) != functionShape(before[key]) {
+            diff.Changed = append(diff.Changed, key)
+        }
+    }
+    for _, key := range sortedKeys(after) {
+        if _, ok := before[key]; !ok {
+            added = append(added, key)
+        }
+    }
+
+    // splitKey separates "file:Receiver.Name" into the file and the bare name
+    splitKey := func(key string) (string, string) {
+        sep := strings.LastIndex(key, ":")
+        name := key[sep+1:]
+        if dot := strings.LastIndex(name, "."); dot >= 0 {
+            name = name[dot+1:]
+        }
+        return key[:sep], name
+    }
+
+    matched := make(map[string]bool)
+    for _, from := range removed {
+        shape := functionShape(before[from])
+        fromFile, fromName := splitKey(from)
+
+        var candidates, sameName []string
+        for _, to := range added {
+            if matched[to] || functionShape(after[to]) != shape {
+                continue
+            }
+            candidates = append(candidates, to)
+            if _, name := splitKey(to); name == fromName {
+                sameName = append(sameName, to)
+            }
+        }
+        if len(sameName) == 1 {
+            candidates = sameName
+        }
+        if len(candidates) != 1 {
+            // No match, or too many to tell which one it became
+            diff.Removed = append(diff.Removed, from)
+            continue
+        }
+
+        to := candidates[0]
+        matched[to] = true
+        toFile, toName := splitKey(to)
+        kind := "rename"
+        if toFile != fromFile {
+            kind = "move"
+            if toName != fromName {
+                kind = "move+rename"
+            }
+        }
+        diff.Renamed = append(diff.Renamed, RenamePair{From: from, To: to, Kind: kind})
+    }
+    for _, to := range added {
+        if !matched[to] {
+            diff.Added = append(diff.Added, to)
+        }
+    }
+    return diff
+}
+
+// complexityRegressions lists the functions, including renamed and moved ones, whose
+// complexity rose by more than delta since the baseline. Functions without a baseline
+// complexity, such as those from an older baseline, are not compared.
+func complexityRegressions(diff *Diff, baseline Summary, baselineDir string, current Summary, currentDir string, delta int) []ComplexityChange {
+    before := diffFunctions(baseline, baselineDir)
+    after := diffFunctions(current, currentDir)
+
+    previous := make(map[string]string)
+    for _, key := range sortedKeys(after) {
+        if _, ok := before[key]; ok {
+            previous[key] = key
+        }
+    }
+    for _, pair := range diff.Renamed {
+        previous[pair.To] = pair.From
+    }
+
+    var regressions []ComplexityChange
+    for _, key := range sortedKeys(previous) {
+        old, fn := before[previous[key]], after[key]
+        if old.Complexity > 0 && fn.Complexity-old.Complexity > delta {
+            regressions = append(regressions, ComplexityChange{Function: key, Line: fn.Line, Before: old.Complexity, After: fn.Complexity})
+        }
+    }
+    return regressions
+}
+
+// summaryFilePaths returns the paths of every file in the summary
+func summaryFilePaths(summary Summary) []string {
+    var paths []string
+    for _, f := range summary.GoFiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.PhpFiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.PythonFiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.JsFiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.TsFiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.HtmlFiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.CssFiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.SqlFiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.MarkdownFiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.ConfigFiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.Dockerfiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.Makefiles {
+        paths = append(paths, f.FilePath)
+    }
+    for _, f := range summary.EnvFiles {
+        paths = append(paths, f.FilePath)
+    }
+    return paths
+}
+
+// keepSummaryFiles drops every file whose path is not in keep
+func keepSummaryFiles(summary Summary, keep map[string]bool) Summary {
+    summary.GoFiles = keepFiles(summary.GoFiles, func(f GoFileSummary) string { return f.FilePath }, keep)
+    summary.PhpFiles = keepFiles(summary.PhpFiles, func(f PhpFileSummary) string { return f.FilePath }, keep)
+    summary.PythonFiles = keepFiles(summary.PythonFiles, func(f PythonFileSummary) string { return f.FilePath }, keep)
+    summary.JsFiles = keepFiles(summary.JsFiles, func(f JsFileSummary) string { return f.FilePath }, keep)
+    summary.TsFiles = keepFiles(summary.TsFiles, func(f TsFileSummary) string { return f.FilePath }, keep)
+    summary.HtmlFiles = keepFiles(summary.HtmlFiles, func(f HtmlFileSummary) string { return f.FilePath }, keep)
+    summary.CssFiles = keepFiles(summary.CssFiles, func(f CSSFileSummary) string { return f.FilePath }, keep)
+    summary.SqlFiles = keepFiles(summary.SqlFiles, func(f SQLFileSummary) string { return f.FilePath }, keep)
+    summary.MarkdownFiles = keepFiles(summary.MarkdownFiles, func(f MarkdownFileSummary) string { return f.FilePath }, keep)
+    summary.ConfigFiles = keepFiles(summary.ConfigFiles, func(f ConfigFileSummary) string { return f.FilePath }, keep)
+    summary.Dockerfiles = keepFiles(summary.Dockerfiles, func(f DockerfileSummary) string { return f.FilePath }, keep)
+    summary.Makefiles = keepFiles(summary.Makefiles, func(f MakefileSummary) string { return f.FilePath }, keep)
+    summary.EnvFiles = keepFiles(summary.EnvFiles, func(f EnvFileSummary) string { return f.FilePath }, keep)
+    return summary
+}
+
+// previewSummary keeps only the first n files in walk order and returns how many were dropped
+func previewSummary(summary Summary, n int) (Summary, int) {
+    paths := summaryFilePaths(summary)
+    if len(paths) <= n {
+        return summary, 0
+    }
+
+    sort.SliceStable(paths, func(i, j int) bool {
+        return walkOrderLess(paths[i], paths[j])
+    })
+    keep := make(map[string]bool)
+    for _, path := range paths[:n] {
+        keep[path] = true
+    }
+
+    return keepSummaryFiles(summary, keep), len(paths) - n
+}
+
+// BundledSource is the full text of a target file in a bundle
+type BundledSource struct {
+    FilePath string `json:"filePath"`
+    Source   string `json:"source"`
+}
+
+// Bundle combines the full source of the target files with the structure of the
+// files they reference, as self-contained context for changing a feature
+type Bundle struct {
+    Sources   []BundledSource `json:"sources"`
+    Structure Summary         `json:"structure"` // Files the targets reference, one hop out
+}
+
+// buildBundle reads the -files targets in full and keeps the structural summary of
+// the files they call into, use types from, or include
+func buildBundle(summary Summary, config Config) Bundle {
+    targets := make(map[string]bool)
+    for _, name := range config.TargetFiles {
+        targets[filepath.ToSlash(strings.TrimSpace(name))] = true
+    }
+    isTarget := func(path string) bool {
+        if targets[filepath.Base(path)] {
+            return true
+        }
+        rel, err := filepath.Rel(config.Directory, path)
+        return err == nil && targets[filepath.ToSlash(rel)]
+    }
+
+    paths := summaryFilePaths(summary)
+    includes, _ := fileIncludes(summary, config.Directory)
+
+    var bundle Bundle
+    references := fileReferences(summary)
+    keep := make(map[string]bool)
+    for _, path := range paths {
+        if !isTarget(path) {
+            continue
+        }
+        source, err := ioutil.ReadFile(path)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Error reading %s for the bundle: %v\n", path, err)
+            continue
+        }
+        bundle.Sources = append(bundle.Sources, BundledSource{FilePath: path, Source: string(source)})
+        for _, other := range append(references[path], includes[path]...) {
+            keep[other] = true
+        }
+    }
+
+    // Targets are already present in full
+    for _, source := range bundle.Sources {
+        delete(keep, source.FilePath)
+    }
+    bundle.Structure = keepSummaryFiles(summary, keep)
+    return bundle
+}
+
+// fileIncludes resolves the include and require statements of PHP and HTML files to
+// analyzed files, trying the including file's directory first and then the analyzed
+// directory. Includes that match no analyzed file are returned as "file: include".
+func fileIncludes(summary Summary, dir string) (map[string][]string, []string) {
+    known := make(map[string]bool)
+    for _, path := range summaryFilePaths(summary) {
+        known[path] = true
+    }
+
+    includes := make(map[string][]string)
+    var unresolved []string
+    addIncludes := func(file string, names []string) {
+        for _, name := range names {
+            // PHP namespace imports are not files
+            if !strings.Contains(name, ".") || strings.Contains(name, "\\") {
+                continue
+            }
+            resolved := ""
+            for _, base := range []string{filepath.Dir(file), dir} {
+                if path := filepath.ToSlash(filepath.Join(base, name)); known[path] {
+                    resolved = path
+                    break
+                }
+            }
+            if resolved == "" {
+                unresolved = append(unresolved, file+": "+name)
+                continue
+            }
+            includes[file] = appendIfNotExists(includes[file], resolved)
+        }
+    }
+    for _, f := range summary.PhpFiles {
+        var names []string
+        for _, imp := range f.Imports {
+            names = append(names, imp.Path)
+        }
+        addIncludes(f.FilePath, names)
+    }
+    for _, f := range summary.HtmlFiles {
+        addIncludes(f.FilePath, f.Includes)
+    }
+    return includes, unresolved
+}
+
+// Trace lists the files that compose a page, following its includes transitively
+type Trace struct {
+    Entry      string   `json:"entry"`
+    Files      []string `json:"files"`                // Dependency order: every file comes after the files it includes
+    Unresolved []string `json:"unresolved,omitempty"` // Includes along the chain that match no analyzed file
+    Structure  Summary  `json:"structure"`
+}
+
+// buildTrace follows the include chain from the -trace entry file depth first, so
+// each file is listed after everything it includes; include cycles are cut
+func buildTrace(summary Summary, config Config) (Trace, error) {
+    entryName := filepath.ToSlash(strings.TrimSpace(config.Trace))
+    entry := ""
+    for _, path := range summaryFilePaths(summary) {
+        rel, err := filepath.Rel(config.Directory, path)
+        if path == entryName || err == nil && filepath.ToSlash(rel) == entryName {
+            entry = path
+            break
+        }
+    }
+    if entry == "" {
+        return Trace{}, fmt.Errorf("trace entry %s is not among the analyzed files", config.Trace)
+    }
+
+    includes, unresolved := fileIncludes(summary, config.Directory)
+    trace := Trace{Entry: entry}
+    visited := make(map[string]bool)
+    var visit func(file string)
+    visit = func(file string) {
+        if visited[file] {
+            return
+        }
+        visited[file] = true
+        for _, included := range includes[file] {
+            visit(included)
+        }
+        trace.Files = append(trace.Files, file)
+    }
+    visit(entry)
+
+    for _, missing := range unresolved {
+        if visited[missing[:strings.Index(missing, ": ")]] {
+            trace.Unresolved = append(trace.Unresolved, missing)
+        }
+    }
+    trace.Structure = keepSummaryFiles(summary, visited)
+    return trace, nil
+}
+
+// filterEmptySlices removes empty slices from the summary
+func filterEmptySlices(summary Summary) Summary {
+    // Filter Go files
+    for i := range summary.GoFiles {
+    if len(summary.GoFiles[i].Variables) == 0 {
+        summary.GoFiles[i].Variables = nil
+    }
+    if len(summary.GoFiles[i].Constants) == 0 {
+        summary.GoFiles[i].Constants = nil
+    }
+    if len(summary.GoFiles[i].Functions) == 0 {
+        summary.GoFiles[i].Functions = nil
+    }
+    if len(summary.GoFiles[i].ControlFlows) == 0 {
+        summary.GoFiles[i].ControlFlows = nil
+    }
+    if len(summary.GoFiles[i].Structs) == 0 {
+        summary.GoFiles[i].Structs = nil
+    }
+    if len(summary.GoFiles[i].Interfaces) == 0 {
+        summary.GoFiles[i].Interfaces = nil
+    }
+    if len(summary.GoFiles[i].Imports) == 0 {
+        summary.GoFiles[i].Imports = nil
+    }
+    if len(summary.GoFiles[i].ExternalCalls) == 0 {
+        summary.GoFiles[i].ExternalCalls = nil
+    }
+    if len(summary.GoFiles[i].BuildInfoVars) == 0 {
+        summary.GoFiles[i].BuildInfoVars = nil
+    }
+    if len(summary.GoFiles[i].EventsEmitted) == 0 {
+        summary.GoFiles[i].EventsEmitted = nil
+    }
+    if len(summary.GoFiles[i].EventsHandled) == 0 {
+        summary.GoFiles[i].EventsHandled = nil
+    }
+    if len(summary.GoFiles[i].PanicSites) == 0 {
+        summary.GoFiles[i].PanicSites = nil
+    }
+    }
+
+    // Filter PHP files
+    for i := range summary.PhpFiles {
+    if len(summary.PhpFiles[i].Variables) == 0 {
+        summary.PhpFiles[i].Variables = nil
+    }
+    if len(summary.PhpFiles[i].Functions) == 0 {
+        summary.PhpFiles[i].Functions = nil
+    }
+    if len(summary.PhpFiles[i].ControlFlows) == 0 {
+        summary.PhpFiles[i].ControlFlows = nil
+    }
+    if len(summary.PhpFiles[i].Classes) == 0 {
+        summary.PhpFiles[i].Classes = nil
+    }
+    if len(summary.PhpFiles[i].Interfaces) == 0 {
+        summary.PhpFiles[i].Interfaces = nil
+    }
+    if len(summary.PhpFiles[i].Imports) == 0 {
+        summary.PhpFiles[i].Imports = nil
+    }
+    if len(summary.PhpFiles[i].ExternalCalls) == 0 {
+        summary.PhpFiles[i].ExternalCalls = nil
+    }
+    if len(summary.PhpFiles[i].EventsEmitted) == 0 {
+        summary.PhpFiles[i].EventsEmitted = nil
+    }
+    if len(summary.PhpFiles[i].EventsHandled) == 0 {
+        summary.PhpFiles[i].EventsHandled = nil
+    }
+    }
+    
+    // Filter Python files
+    for i := range summary.PythonFiles {
+        if len(summary.PythonFiles[i].Variables) == 0 {
+            summary.PythonFiles[i].Variables = nil
+        }
+        if len(summary.PythonFiles[i].Functions) == 0 {
+            summary.PythonFiles[i].Functions = nil
+        }
+        if len(summary.PythonFiles[i].ControlFlows) == 0 {
+            summary.PythonFiles[i].ControlFlows = nil
+        }
+        if len(summary.PythonFiles[i].Classes) == 0 {
+            summary.PythonFiles[i].Classes = nil
+        }
+        if len(summary.PythonFiles[i].Imports) == 0 {
+            summary.PythonFiles[i].Imports = nil
+        }
+        if len(summary.PythonFiles[i].Decorators) == 0 {
+            summary.PythonFiles[i].Decorators = nil
+        }
+        if len(summary.PythonFiles[i].ExternalCalls) == 0 {
+            summary.PythonFiles[i].ExternalCalls = nil
+        }
+        if len(summary.PythonFiles[i].ContextManagers) == 0 {
+            summary.PythonFiles[i].ContextManagers = nil
+        }
+        if len(summary.PythonFiles[i].EventsEmitted) == 0 {
+            summary.PythonFiles[i].EventsEmitted = nil
+        }
+        if len(summary.PythonFiles[i].EventsHandled) == 0 {
+            summary.PythonFiles[i].EventsHandled = nil
+        }
+    }
+
+    // Filter JavaScript files
+    for i := range summary.JsFiles {
+        if len(summary.JsFiles[i].Functions) == 0 {
+            summary.JsFiles[i].Functions = nil
+        }
+        if len(summary.JsFiles[i].Classes) == 0 {
+            summary.JsFiles[i].Classes = nil
+        }
+        if len(summary.JsFiles[i].Constants) == 0 {
+            summary.JsFiles[i].Constants = nil
+        }
+        if len(summary.JsFiles[i].ControlFlows) == 0 {
+            summary.JsFiles[i].ControlFlows = nil
+        }
+        if len(summary.JsFiles[i].Imports) == 0 {
+            summary.JsFiles[i].Imports = nil
+        }
+        if len(summary.JsFiles[i].Exports) == 0 {
+            summary.JsFiles[i].Exports = nil
+        }
+    }
+
+    // Filter TypeScript files
+    for i := range summary.TsFiles {
+        if len(summary.TsFiles[i].Functions) == 0 {
+            summary.TsFiles[i].Functions = nil
+        }
+        if len(summary.TsFiles[i].Classes) == 0 {
+            summary.TsFiles[i].Classes = nil
+        }
+        if len(summary.TsFiles[i].Interfaces) == 0 {
+            summary.TsFiles[i].Interfaces = nil
+        }
+        if len(summary.TsFiles[i].TypeAliases) == 0 {
+            summary.TsFiles[i].TypeAliases = nil
+        }
+        if len(summary.TsFiles[i].Enums) == 0 {
+            summary.TsFiles[i].Enums = nil
+        }
+        if len(summary.TsFiles[i].Constants) == 0 {
+            summary.TsFiles[i].Constants = nil
+        }
+        if len(summary.TsFiles[i].ControlFlows) == 0 {
+            summary.TsFiles[i].ControlFlows = nil
+        }
+        if len(summary.TsFiles[i].Imports) == 0 {
+            summary.TsFiles[i].Imports = nil
+        }
+        if len(summary.TsFiles[i].Exports) == 0 {
+            summary.TsFiles[i].Exports = nil
+        }
+    }
+    
+    // Filter HTML files
+    for i := range summary.HtmlFiles {
+    if len(summary.HtmlFiles[i].Elements) == 0 {
+        summary.HtmlFiles[i].Elements = nil
+    }
+    if len(summary.HtmlFiles[i].EmbeddedJS) == 0 {
+        summary.HtmlFiles[i].EmbeddedJS = nil
+    }
+    if len(summary.HtmlFiles[i].EmbeddedCSS) == 0 {
+        summary.HtmlFiles[i].EmbeddedCSS = nil
+    }
+    if len(summary.HtmlFiles[i].Includes) == 0 {
+        summary.HtmlFiles[i].Includes = nil
+    }
+    if len(summary.HtmlFiles[i].ExternalCalls) == 0 {
+        summary.HtmlFiles[i].ExternalCalls = nil
+    }
+    if len(summary.HtmlFiles[i].EventsEmitted) == 0 {
+        summary.HtmlFiles[i].EventsEmitted = nil
+    }
+    if len(summary.HtmlFiles[i].EventsHandled) == 0 {
+        summary.HtmlFiles[i].EventsHandled = nil
+    }
+    }
+    
+    // Filter CSS files
+    for i := range summary.CssFiles {
+    if len(summary.CssFiles[i].Rules) == 0 {
+        summary.CssFiles[i].Rules = nil
+    }
+    if len(summary.CssFiles[i].Imports) == 0 {
+        summary.CssFiles[i].Imports = nil
+    }
+    }
+    
+    // Filter SQL files
+    for i := range summary.SqlFiles {
+    if len(summary.SqlFiles[i].Statements) == 0 {
+        summary.SqlFiles[i].Statements = nil
+    }
+    }
+    
+    // Filter Markdown files
+    for i := range summary.MarkdownFiles {
+        if len(summary.MarkdownFiles[i].CodeBlocks) == 0 {
+            summary.MarkdownFiles[i].CodeBlocks = nil
+        }
+    }
+    
+    // Filter config files
+    for i := range summary.ConfigFiles {
+        if len(summary.ConfigFiles[i].Sections) == 0 {
+            summary.ConfigFiles[i].Sections = nil
+        }
+    }
+
+    return summary
+}
+
+// inventoryFunctions strips functions down to their identity
+func inventoryFunctions(functions []Function) []Function {
+    var stripped []Function
+    for _, fn := range functions {
+        stripped = append(stripped, Function{ID: fn.ID, Name: fn.Name, Receiver: fn.Receiver, Line: fn.Line, IsDeprecated: fn.IsDeprecated, Deprecation: fn.Deprecation})
+    }
+    return stripped
+}
+
+// inventoryTypes strips types down to their identity and method names
+func inventoryTypes(types []Struct) []Struct {
+    var stripped []Struct
+    for _, t := range types {
+        stripped = append(stripped, Struct{ID: t.ID, Name: t.Name, Line: t.Line, Methods: inventoryFunctions(t.Methods), IsDeprecated: t.IsDeprecated, Deprecation: t.Deprecation})
+    }
+    return stripped
+}
+
+// inventoryInterfaces strips interfaces down to their names and method names
+func inventoryInterfaces(interfaces []Interface) []Interface {
+    var stripped []Interface
+    for _, intf := range interfaces {
+        stripped = append(stripped, Interface{Name: intf.Name, Methods: inventoryFunctions(intf.Methods)})
+    }
+    return stripped
+}
+
+// summaryOnly reduces a summary to an inventory of files and symbol names with counts,
+// dropping arguments, calls, control flow and other per-symbol details
+func summaryOnly(summary Summary) Summary {
+    inventory := Summary{
+        CrossLangDuplicates: summary.CrossLangDuplicates,
+        Conventions:         summary.Conventions,
+        TopSymbols:          summary.TopSymbols,
+        Clusters:            summary.Clusters,
+        PageHandlerMap:      summary.PageHandlerMap,
+        ThirdPartyDependencies: summary.ThirdPartyDependencies,
+        LanguageStats:       summary.LanguageStats,
+        Diff:                summary.Diff,
+        TypeUsage:           summary.TypeUsage,
+        Migrations:          summary.Migrations,
+        ScheduledJobs:       summary.ScheduledJobs,
+        Modules:             summary.Modules,
+        CallGraph:           summary.CallGraph,
+        Unreferenced:        summary.Unreferenced,
+        UndocumentedEnvVars: summary.UndocumentedEnvVars,
+        UnusedEnvKeys:       summary.UnusedEnvKeys,
+        Counts:              make(map[string]int),
+    }
+
+    for _, f := range summary.GoFiles {
+        inventory.GoFiles = append(inventory.GoFiles, GoFileSummary{
+            FilePath:   f.FilePath,
+            Package:    f.Package,
+            Functions:  inventoryFunctions(f.Functions),
+            Structs:    inventoryTypes(f.Structs),
+            Interfaces: inventoryInterfaces(f.Interfaces),
+        })
+        inventory.Counts["functions"] += len(f.Functions)
+        inventory.Counts["types"] += len(f.Structs) + len(f.Interfaces)
+    }
+    for _, pkg := range summary.GoPackages {
+        inventory.GoPackages = append(inventory.GoPackages, GoPackage{
+            Name:       pkg.Name,
+            Dir:        pkg.Dir,
+            Files:      pkg.Files,
+            Structs:    inventoryTypes(pkg.Structs),
+            Interfaces: inventoryInterfaces(pkg.Interfaces),
+            Functions:  inventoryFunctions(pkg.Functions),
+        })
+    }
+    for _, f := range summary.PhpFiles {
+        inventory.PhpFiles = append(inventory.PhpFiles, PhpFileSummary{
+            FilePath:   f.FilePath,
+            Namespace:  f.Namespace,
+            Functions:  inventoryFunctions(f.Functions),
+            Classes:    inventoryTypes(f.Classes),
+            Interfaces: inventoryInterfaces(f.Interfaces),
+        })
+        inventory.Counts["functions"] += len(f.Functions)
+        inventory.Counts["types"] += len(f.Classes) + len(f.Interfaces)
+        for _, class := range f.Classes {
+            inventory.Counts["functions"] += len(class.Methods)
+        }
+    }
+    for _, f := range summary.PythonFiles {
+        inventory.PythonFiles = append(inventory.PythonFiles, PythonFileSummary{
+            FilePath:  f.FilePath,
+            Functions: inventoryFunctions(f.Functions),
+            Classes:   inventoryTypes(f.Classes),
+        })
+        inventory.Counts["functions"] += len(f.Functions)
+        inventory.Counts["types"] += len(f.Classes)
+        for _, class := range f.Classes {
+            inventory.Counts["functions"] += len(class.Methods)
+        }
+    }
+    for _, f := range summary.JsFiles {
+        inventory.JsFiles = append(inventory.JsFiles, JsFileSummary{
+            FilePath:  f.FilePath,
+            Functions: inventoryFunctions(f.Functions),
+            Classes:   inventoryTypes(f.Classes),
+            Exports:   f.Exports,
+        })
+        inventory.Counts["functions"] += len(f.Functions)
+        inventory.Counts["types"] += len(f.Classes)
+        for _, class := range f.Classes {
+            inventory.Counts["functions"] += len(class.Methods)
+        }
+    }
+    for _, f := range summary.TsFiles {
+        file := TsFileSummary{
+            FilePath:   f.FilePath,
+            Functions:  inventoryFunctions(f.Functions),
+            Classes:    inventoryTypes(f.Classes),
+            Interfaces: inventoryInterfaces(f.Interfaces),
+            Exports:    f.Exports,
+        }
+        for _, alias := range f.TypeAliases {
+            file.TypeAliases = append(file.TypeAliases, TypeAlias{Name: alias.Name, Line: alias.Line})
+        }
+        for _, enum := range f.Enums {
+            file.Enums = append(file.Enums, Enum{Name: enum.Name, Line: enum.Line})
+        }
+        inventory.TsFiles = append(inventory.TsFiles, file)
+        inventory.Counts["functions"] += len(f.Functions)
+        inventory.Counts["types"] += len(f.Classes) + len(f.Interfaces) + len(f.TypeAliases) + len(f.Enums)
+        for _, class := range f.Classes {
+            inventory.Counts["functions"] += len(class.Methods)
+        }
+    }
+    for _, f := range summary.HtmlFiles {
+        inventory.HtmlFiles = append(inventory.HtmlFiles, HtmlFileSummary{
+            FilePath:   f.FilePath,
+            EmbeddedJS: inventoryFunctions(f.EmbeddedJS),
+        })
+        inventory.Counts["functions"] += len(f.EmbeddedJS)
+    }
+    for _, f := range summary.CssFiles {
+        var rules []CSSRule
+        for _, rule := range f.Rules {
+            rules = append(rules, CSSRule{Selector: rule.Selector, Line: rule.Line})
+        }
+        inventory.CssFiles = append(inventory.CssFiles, CSSFileSummary{FilePath: f.FilePath, Rules: rules})
+        inventory.Counts["cssRules"] += len(f.Rules)
+    }
+    for _, f := range summary.SqlFiles {
+        var statements []SQLStatement
+        for _, stmt := range f.Statements {
+            statements = append(statements, SQLStatement{Type: stmt.Type, Tables: stmt.Tables, Line: stmt.Line})
+        }
+        inventory.SqlFiles = append(inventory.SqlFiles, SQLFileSummary{FilePath: f.FilePath, Statements: statements})
+        inventory.Counts["sqlStatements"] += len(f.Statements)
+    }
+    for _, f := range summary.MarkdownFiles {
+        var blocks []CodeBlock
+        for _, block := range f.CodeBlocks {
+            blocks = append(blocks, CodeBlock{Language: block.Language, Line: block.Line})
+        }
+        inventory.MarkdownFiles = append(inventory.MarkdownFiles, MarkdownFileSummary{FilePath: f.FilePath, CodeBlocks: blocks})
+    }
+    for _, f := range summary.ConfigFiles {
+        var sections []ConfigSection
+        for _, section := range f.Sections {
+            sections = append(sections, ConfigSection{Name: section.Name, Line: section.Line})
+        }
+        inventory.ConfigFiles = append(inventory.ConfigFiles, ConfigFileSummary{FilePath: f.FilePath, Format: f.Format, Sections: sections})
+    }
+    for _, f := range summary.Dockerfiles {
+        inventory.Dockerfiles = append(inventory.Dockerfiles, DockerfileSummary{FilePath: f.FilePath, BaseImages: f.BaseImages})
+    }
+    for _, f := range summary.Makefiles {
+        var targets []MakeTarget
+        for _, target := range f.Targets {
+            targets = append(targets, MakeTarget{Name: target.Name, Line: target.Line})
+        }
+        inventory.Makefiles = append(inventory.Makefiles, MakefileSummary{FilePath: f.FilePath, DefaultTarget: f.DefaultTarget, Targets: targets})
+    }
+    inventory.EnvFiles = summary.EnvFiles
+
+    fileCounts := map[string]int{
+        "goFiles":       len(inventory.GoFiles),
+        "phpFiles":      len(inventory.PhpFiles),
+        "pythonFiles":   len(inventory.PythonFiles),
+        "jsFiles":       len(inventory.JsFiles),
+        "tsFiles":       len(inventory.TsFiles),
+        "htmlFiles":     len(inventory.HtmlFiles),
+        "cssFiles":      len(inventory.CssFiles),
+        "sqlFiles":      len(inventory.SqlFiles),
+        "markdownFiles": len(inventory.MarkdownFiles),
+        "configFiles":   len(inventory.ConfigFiles),
+        "dockerfiles":   len(inventory.Dockerfiles),
+        "makefiles":     len(inventory.Makefiles),
+        "envFiles":      len(inventory.EnvFiles),
+    }
+    for name, count := range fileCounts {
+        if count > 0 {
+            inventory.Counts[name] = count
+        }
+    }
+
+    return inventory
+}
+
+// fileIdentityFields are set for every file and do not count as extracted content
+var fileIdentityFields = map[string]bool{
+    "FilePath":  true,
+    "Package":   true,
+    "Namespace": true,
+    "Format":    true,
+}
+
+// isEmptyFileSummary reports whether a file summary holds nothing beyond its identity fields
+func isEmptyFileSummary(file interface{}) bool {
+    v := reflect.ValueOf(file)
+    for i := 0; i < v.NumField(); i++ {
+        if fileIdentityFields[v.Type().Field(i).Name] {
+            continue
+        }
+        field := v.Field(i)
+        switch field.Kind() {
+        case reflect.Slice, reflect.Map:
+            if field.Len() > 0 {
+                return false
+            }
+        default:
+            if !field.IsZero() {
+                return false
+            }
+        }
+    }
+    return true
+}
+
+// dropEmptyFiles removes empty file summaries and returns how many were removed
+func dropEmptyFiles[T any](files []T) ([]T, int) {
+    var kept []T
+    for _, f := range files {
+        if !isEmptyFileSummary(f) {
+            kept = append(kept, f)
+        }
+    }
+    return kept, len(files) - len(kept)
+}
+
+// excludeEmptyFiles removes every file summary with no extracted content and returns the count removed
+func excludeEmptyFiles(summary *Summary) int {
+    var dropped, n int
+    summary.GoFiles, n = dropEmptyFiles(summary.GoFiles)
+    dropped += n
+    summary.PhpFiles, n = dropEmptyFiles(summary.PhpFiles)
+    dropped += n
+    summary.PythonFiles, n = dropEmptyFiles(summary.PythonFiles)
+    dropped += n
+    summary.JsFiles, n = dropEmptyFiles(summary.JsFiles)
+    dropped += n
+    summary.TsFiles, n = dropEmptyFiles(summary.TsFiles)
+    dropped += n
+    summary.HtmlFiles, n = dropEmptyFiles(summary.HtmlFiles)
+    dropped += n
+    summary.CssFiles, n = dropEmptyFiles(summary.CssFiles)
+    dropped += n
+    summary.SqlFiles, n = dropEmptyFiles(summary.SqlFiles)
+    dropped += n
+    summary.MarkdownFiles, n = dropEmptyFiles(summary.MarkdownFiles)
+    dropped += n
+    summary.ConfigFiles, n = dropEmptyFiles(summary.ConfigFiles)
+    dropped += n
+    summary.Dockerfiles, n = dropEmptyFiles(summary.Dockerfiles)
+    dropped += n
+    summary.Makefiles, n = dropEmptyFiles(summary.Makefiles)
+    dropped += n
+    summary.EnvFiles, n = dropEmptyFiles(summary.EnvFiles)
+    dropped += n
+    return dropped
+}
+
+// removeDuplicatesAndSort removes duplicates from a slice and sorts it
+func removeDuplicatesAndSort(slice []string) []string {
+    // Use a map to remove duplicates
+    uniqueMap := make(map[string]bool)
+    for _, item := range slice {
+    uniqueMap[item] = true
+    }
+    
+    // Convert back to slice
+    result := make([]string, 0, len(uniqueMap))
+    for item := range uniqueMap {
+    result = append(result, item)
+    }
+    
+    // Sort the slice
+    sort.Strings(result)
+    
+    return result
+}
+
+// appendIfNotExists appends a string to a slice if it doesn't already exist
+func appendIfNotExists(slice []string, item string) []string {
+    for _, s := range slice {
+    if s == item {
+        return slice
+    }
+    }
+    return append(slice, item)
+}
@@ -0,0 +1,86 @@
+package distiller
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+)
+
+// Interface methods keep variadic and unnamed parameters and named results, so a mock
+// can be written back out from them
+func TestGoInterfaceMethodSignatures(t *testing.T) {
+    source := `package store
+
+type Store interface {
+    Get(key string) (value []byte, ok bool)
+    Put(string, []byte) error
+    Log(format string, args ...any)
+    Read(p []byte) (n int, err error)
+    Bounds() (lo, hi int)
+}
+
+type file struct{}
+
+func (f *file) Read(p []byte) (n int, err error) { return 0, nil }
+`
+    summary := analyzeGoSource("store.go", []byte(source))
+    if len(summary.Interfaces) != 1 {
+        t.Fatalf("expected one interface, got %d", len(summary.Interfaces))
+    }
+    methods := map[string]Function{}
+    for _, method := range summary.Interfaces[0].Methods {
+        methods[method.Name] = method
+    }
+
+    tests := []struct {
+        name    string
+        args    []string
+        returns []string
+    }{
+        {"Get", []string{"key string"}, []string{"value []byte", "ok bool"}},
+        {"Put", []string{" string", " []byte"}, []string{"error"}},
+        {"Log", []string{"format string", "args ...any"}, nil},
+        {"Read", []string{"p []byte"}, []string{"n int", "err error"}},
+        {"Bounds", nil, []string{"lo int", "hi int"}},
+    }
+    for _, tt := range tests {
+        method, ok := methods[tt.name]
+        if !ok {
+            t.Errorf("%s: method not found", tt.name)
+            continue
+        }
+        var args []string
+        for _, arg := range method.Args {
+            args = append(args, arg.Name+" "+arg.Type)
+        }
+        if !reflect.DeepEqual(args, tt.args) {
+            t.Errorf("%s: args = %q, want %q", tt.name, args, tt.args)
+        }
+        if !reflect.DeepEqual(method.Returns, tt.returns) {
+            t.Errorf("%s: returns = %q, want %q", tt.name, method.Returns, tt.returns)
+        }
+    }
+
+    if len(summary.Functions) != 1 {
+        t.Fatalf("expected one method, got %d", len(summary.Functions))
+    }
+    if got, want := summary.Functions[0].Returns, []string{"int", "error"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("implementation returns %q, want %q", got, want)
+    }
+}
+
+// Named results reach the rendered output as "name type"
+func TestGoInterfaceNamedResultsRendered(t *testing.T) {
+    dir := t.TempDir()
+    source := "package store\n\ntype Reader interface {\n    Read(p []byte) (n int, err error)\n}\n"
+    if err := os.WriteFile(filepath.Join(dir, "store.go"), []byte(source), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    output := analyzeAndRender(t, Config{Directory: dir, OutputFormat: "json", Compact: true, ComplexityDelta: -1})
+    if !bytes.Contains(output, []byte(`"returns":["n int","err error"]`)) {
+        t.Errorf("named results missing from output: %s", output)
+    }
+}
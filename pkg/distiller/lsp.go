@@ -0,0 +1,417 @@
+package distiller
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "net/url"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "unicode/utf16"
+)
+
+// lspRequest is an incoming JSON-RPC request or notification of the Language Server Protocol
+type lspRequest struct {
+    ID     *json.RawMessage `json:"id,omitempty"` // Absent for notifications
+    Method string           `json:"method"`
+    Params json.RawMessage  `json:"params,omitempty"`
+}
+
+type lspError struct {
+    Code    int    `json:"code"`
+    Message string `json:"message"`
+}
+
+type lspPosition struct {
+    Line      int `json:"line"`
+    Character int `json:"character"`
+}
+
+type lspRange struct {
+    Start lspPosition `json:"start"`
+    End   lspPosition `json:"end"`
+}
+
+type lspLocation struct {
+    URI   string   `json:"uri"`
+    Range lspRange `json:"range"`
+}
+
+// lspSymbol is a SymbolInformation, as returned for document and workspace symbols
+type lspSymbol struct {
+    Name          string      `json:"name"`
+    Kind          int         `json:"kind"`
+    Location      lspLocation `json:"location"`
+    ContainerName string      `json:"containerName,omitempty"`
+}
+
+// LSP SymbolKind values for the symbols distiller extracts
+const (
+    lspKindClass     = 5
+    lspKindMethod    = 6
+    lspKindEnum      = 10
+    lspKindInterface = 11
+    lspKindFunction  = 12
+    lspKindStruct    = 23
+)
+
+// languageServer answers LSP requests from the summaries the analyzers produce
+type languageServer struct {
+    config    Config
+    out       io.Writer
+    workspace []lspSymbol // Symbols of the whole workspace, rebuilt when a file is saved
+    graph     callGraph   // Calls of the whole workspace resolved to their definitions
+}
+
+// RunLanguageServer serves LSP, reading requests from in and writing responses to out,
+// until the client sends exit. Verbose progress is turned off, as it is printed on stdout.
+func RunLanguageServer(config Config, in io.Reader, out io.Writer) {
+    server := &languageServer{config: config, out: out}
+    server.config.Verbose = false
+
+    reader := bufio.NewReader(in)
+    for {
+        body, err := readLSPMessage(reader)
+        if err != nil {
+            return
+        }
+        var request lspRequest
+        if err := json.Unmarshal(body, &request); err != nil {
+            continue
+        }
+        if request.Method == "exit" {
+            return
+        }
+        result, rpcErr := server.handle(request)
+        if request.ID != nil {
+            server.reply(request.ID, result, rpcErr)
+        }
+    }
+}
+
+// readLSPMessage reads one message body framed by a Content-Length header
+func readLSPMessage(reader *bufio.Reader) ([]byte, error) {
+    length := -1
+    for {
+        line, err := reader.ReadString('\n')
+        if err != nil {
+            return nil, err
+        }
+        line = strings.TrimSpace(line)
+        if line == "" {
+            break
+        }
+        if value, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+            length, _ = strconv.Atoi(strings.TrimSpace(value))
+        }
+    }
+    if length < 0 {
+        return nil, fmt.Errorf("message without Content-Length")
+    }
+    body := make([]byte, length)
+    _, err := io.ReadFull(reader, body)
+    return body, err
+}
+
+// reply writes the response to a request framed with its Content-Length header. A
+// response carries either an error or a result, null when there is nothing to return
+func (server *languageServer) reply(id *json.RawMessage, result interface{}, rpcErr *lspError) {
+    response := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+    if rpcErr != nil {
+        response["error"] = rpcErr
+    } else {
+        response["result"] = result
+    }
+    data, _ := json.Marshal(response)
+    fmt.Fprintf(server.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+// handle dispatches a request or notification and returns its result
+func (server *languageServer) handle(request lspRequest) (interface{}, *lspError) {
+    switch request.Method {
+    case "initialize":
+        var params struct {
+            RootURI  string `json:"rootUri"`
+            RootPath string `json:"rootPath"`
+        }
+        json.Unmarshal(request.Params, &params)
+        if root := uriToPath(params.RootURI); root != "" {
+            server.config.Directory = root
+        } else if params.RootPath != "" {
+            server.config.Directory = params.RootPath
+        }
+        server.index()
+        return map[string]interface{}{
+            "capabilities": map[string]interface{}{
+                "textDocumentSync":        map[string]interface{}{"openClose": true, "save": true},
+                "documentSymbolProvider":  true,
+                "workspaceSymbolProvider": true,
+                "definitionProvider":      true,
+            },
+            "serverInfo": map[string]string{"name": "distiller", "version": VERSION},
+        }, nil
+
+    case "textDocument/didSave":
+        server.index()
+        return nil, nil
+
+    case "shutdown":
+        return nil, nil
+
+    case "textDocument/documentSymbol":
+        var params struct {
+            TextDocument struct {
+                URI string `json:"uri"`
+            } `json:"textDocument"`
+        }
+        json.Unmarshal(request.Params, &params)
+        var summary Summary
+        analyzeFile(uriToPath(params.TextDocument.URI), server.config, &summary, newAnalysisContext())
+        return lspSymbols(summary), nil
+
+    case "workspace/symbol":
+        var params struct {
+            Query string `json:"query"`
+        }
+        json.Unmarshal(request.Params, &params)
+        query := strings.ToLower(params.Query)
+        symbols := []lspSymbol{}
+        for _, symbol := range server.workspace {
+            if strings.Contains(strings.ToLower(symbol.Name), query) {
+                symbols = append(symbols, symbol)
+            }
+        }
+        return symbols, nil
+
+    case "textDocument/definition":
+        var params struct {
+            TextDocument struct {
+                URI string `json:"uri"`
+            } `json:"textDocument"`
+            Position lspPosition `json:"position"`
+        }
+        json.Unmarshal(request.Params, &params)
+        return server.definition(uriToPath(params.TextDocument.URI), params.Position), nil
+    }
+
+    // Notifications such as initialized or didOpen need no answer
+    if request.ID == nil {
+        return nil, nil
+    }
+    return nil, &lspError{Code: -32601, Message: "method not supported: " + request.Method}
+}
+
+// index analyzes the workspace directory and rebuilds the workspace symbol table
+func (server *languageServer) index() {
+    if server.config.Directory == "" {
+        return
+    }
+    summary, _ := analyzeDirRecursive(context.Background(), server.config)
+    server.workspace = lspSymbols(summary)
+    server.graph = buildCallGraph(summary)
+}
+
+// definition finds where the identifier at a position is defined. A call made by the
+// enclosing function goes where the call graph resolves it, and nowhere when it reaches
+// outside the codebase or stays ambiguous; any other name goes to the symbols declaring it.
+func (server *languageServer) definition(path string, position lspPosition) []lspLocation {
+    locations := []lspLocation{}
+    name := identifierAt(path, position)
+    if name == "" {
+        return locations
+    }
+
+    if caller, ok := server.enclosingFunction(path, position.Line+1); ok {
+        isCall := false
+        for _, call := range server.graph.nodes[caller].fn.Calls {
+            if call[strings.LastIndexAny(call, ".>:")+1:] != name {
+                continue
+            }
+            isCall = true
+            if callee, ok := server.graph.resolve(caller, call); ok {
+                node := server.graph.nodes[callee]
+                position := lspPosition{Line: max(node.fn.Line-1, 0)}
+                location := lspLocation{URI: pathToURI(server.graph.files[node.file]), Range: lspRange{Start: position, End: position}}
+                if !containsLocation(locations, location) {
+                    locations = append(locations, location)
+                }
+            }
+        }
+        if isCall {
+            return locations
+        }
+    }
+
+    for _, symbol := range server.workspace {
+        if symbol.Name == name {
+            locations = append(locations, symbol.Location)
+        }
+    }
+    return locations
+}
+
+// enclosingFunction returns the call graph node of the innermost function of a file
+// whose body spans a 1-based line
+func (server *languageServer) enclosingFunction(path string, line int) (int, bool) {
+    found, start := -1, 0
+    for i, node := range server.graph.nodes {
+        fn := node.fn
+        if fn.Line > line || max(fn.EndLine, fn.Line) < line || fn.Line < start {
+            continue
+        }
+        if samePath(server.graph.files[node.file], path) {
+            found, start = i, fn.Line
+        }
+    }
+    return found, found >= 0
+}
+
+// samePath reports whether two paths name the same file once made absolute
+func samePath(a, b string) bool {
+    absA, errA := filepath.Abs(a)
+    absB, errB := filepath.Abs(b)
+    return errA == nil && errB == nil && absA == absB
+}
+
+// containsLocation reports whether a location is already in a list
+func containsLocation(locations []lspLocation, location lspLocation) bool {
+    for _, l := range locations {
+        if l == location {
+            return true
+        }
+    }
+    return false
+}
+
+// lspSymbols lists the functions, methods, types and interfaces of a summary as LSP symbols
+func lspSymbols(summary Summary) []lspSymbol {
+    symbols := []lspSymbol{}
+    add := func(filePath string, name string, kind int, line int, container string) {
+        position := lspPosition{Line: max(line-1, 0)}
+        symbols = append(symbols, lspSymbol{
+            Name:          name,
+            Kind:          kind,
+            Location:      lspLocation{URI: pathToURI(filePath), Range: lspRange{Start: position, End: position}},
+            ContainerName: container,
+        })
+    }
+    addFunctions := func(filePath string, functions []Function, container string) {
+        for _, fn := range functions {
+            owner := container
+            if owner == "" {
+                owner = fn.Receiver
+            }
+            if owner != "" {
+                add(filePath, fn.Name, lspKindMethod, fn.Line, owner)
+            } else {
+                add(filePath, fn.Name, lspKindFunction, fn.Line, "")
+            }
+        }
+    }
+    var addClasses func(filePath string, classes []Struct)
+    addClasses = func(filePath string, classes []Struct) {
+        for _, class := range classes {
+            add(filePath, class.Name, lspKindClass, class.Line, "")
+            addFunctions(filePath, class.Methods, class.Name)
+            addClasses(filePath, class.Nested)
+        }
+    }
+    addInterfaces := func(filePath string, interfaces []Interface) {
+        for _, intf := range interfaces {
+            add(filePath, intf.Name, lspKindInterface, intf.Line, "")
+        }
+    }
+
+    for _, f := range summary.GoFiles {
+        // Go methods are listed with the functions as well as on their struct
+        addFunctions(f.FilePath, f.Functions, "")
+        for _, structure := range f.Structs {
+            add(f.FilePath, structure.Name, lspKindStruct, structure.Line, "")
+        }
+        addInterfaces(f.FilePath, f.Interfaces)
+    }
+    for _, f := range summary.PhpFiles {
+        addFunctions(f.FilePath, f.Functions, "")
+        addClasses(f.FilePath, f.Classes)
+        addInterfaces(f.FilePath, f.Interfaces)
+    }
+    for _, f := range summary.PythonFiles {
+        addFunctions(f.FilePath, f.Functions, "")
+        addClasses(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.JsFiles {
+        addFunctions(f.FilePath, f.Functions, "")
+        addClasses(f.FilePath, f.Classes)
+    }
+    for _, f := range summary.TsFiles {
+        addFunctions(f.FilePath, f.Functions, "")
+        addClasses(f.FilePath, f.Classes)
+        addInterfaces(f.FilePath, f.Interfaces)
+        for _, enum := range f.Enums {
+            add(f.FilePath, enum.Name, lspKindEnum, enum.Line, "")
+        }
+    }
+    for _, f := range summary.HtmlFiles {
+        addFunctions(f.FilePath, f.EmbeddedJS, "")
+    }
+    return symbols
+}
+
+// identifierAt returns the identifier under a position of a file, taking the last part
+// of a dotted or -> chain such as obj.method. The character offset counts UTF-16 code
+// units, as LSP positions do.
+func identifierAt(path string, position lspPosition) string {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return ""
+    }
+    lines := strings.Split(string(data), "\n")
+    if position.Line < 0 || position.Line >= len(lines) {
+        return ""
+    }
+    line := lines[position.Line]
+    isIdent := func(c byte) bool {
+        return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+    }
+    offset := utf16Offset(line, position.Character)
+    start, end := offset, offset
+    for start > 0 && isIdent(line[start-1]) {
+        start--
+    }
+    for end < len(line) && isIdent(line[end]) {
+        end++
+    }
+    return line[start:end]
+}
+
+// utf16Offset converts a character offset in UTF-16 code units into a byte offset of a line
+func utf16Offset(line string, units int) int {
+    for i, r := range line {
+        if units <= 0 {
+            return i
+        }
+        units -= utf16.RuneLen(r)
+    }
+    return len(line)
+}
+
+// uriToPath converts a file:// URI to a local path, returning "" for other schemes
+func uriToPath(uri string) string {
+    parsed, err := url.Parse(uri)
+    if err != nil || parsed.Scheme != "file" {
+        return ""
+    }
+    return filepath.FromSlash(parsed.Path)
+}
+
+// pathToURI converts a local path to a file:// URI
+func pathToURI(path string) string {
+    if abs, err := filepath.Abs(path); err == nil {
+        path = abs
+    }
+    return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
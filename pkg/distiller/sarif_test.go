@@ -0,0 +1,36 @@
+package distiller
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+// A handler registered with http.HandleFunc is an entry point, not an unreachable function
+func TestSARIFSkipsRegisteredHandlers(t *testing.T) {
+    dir := t.TempDir()
+    source := `package main
+
+import "net/http"
+
+func handle(w http.ResponseWriter, r *http.Request) {}
+
+func unused() {}
+
+func main() {
+    http.HandleFunc("/", handle)
+}
+`
+    if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    output := analyzeAndRender(t, Config{Directory: dir, OutputFormat: "sarif", ComplexityDelta: -1})
+    if bytes.Contains(output, []byte("handle is never called")) {
+        t.Errorf("registered handler reported as unreachable: %s", output)
+    }
+    if !bytes.Contains(output, []byte("unused is never called")) {
+        t.Errorf("unused function not reported: %s", output)
+    }
+}
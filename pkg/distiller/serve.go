@@ -0,0 +1,239 @@
+package distiller
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+)
+
+// apiSymbol is a symbol as the HTTP API returns it, with file paths spelled out
+type apiSymbol struct {
+    Name      string   `json:"name"` // Qualified name, e.g. "Server.Start"
+    Kind      string   `json:"kind"`
+    Files     []string `json:"files"`
+    Line      int      `json:"line,omitempty"`
+    Signature string   `json:"signature,omitempty"`
+    Calls     []string `json:"calls,omitempty"`
+    CalledBy  []string `json:"calledBy,omitempty"`
+}
+
+// apiServer answers HTTP queries from the latest summary of the analyzed directory
+type apiServer struct {
+    config  Config
+    mu      sync.RWMutex
+    summary Summary
+    symbols Pattern2Summary // Symbol index of summary
+}
+
+// Serve analyzes the directory and answers HTTP requests about it on config.Listen
+// until ctx is cancelled. With config.Watch the answers follow changes to the files.
+//
+//    GET /summary             the whole summary in the configured output format
+//    GET /file?path=a/b.go    the summary of one file, path relative to the directory
+//    GET /symbol?name=Start   the symbols of that name, qualified or not
+//    GET /search?q=user       symbols and files whose name contains q, ignoring case
+func (analyzer *Analyzer) Serve(ctx context.Context) error {
+    server := &apiServer{config: analyzer.config}
+    ready := make(chan error, 1)
+    if analyzer.config.Watch {
+        go func() {
+            first := true
+            err := analyzer.Watch(ctx, func(summary Summary, changed []string) error {
+                server.update(summary)
+                if first {
+                    first = false
+                    ready <- nil
+                }
+                return nil
+            })
+            if first {
+                ready <- err
+            }
+        }()
+    } else {
+        summary, err := analyzer.Analyze(ctx)
+        server.update(summary)
+        ready <- err
+    }
+    if err := <-ready; err != nil {
+        return err
+    }
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("/summary", server.handleSummary)
+    mux.HandleFunc("/file", server.handleFile)
+    mux.HandleFunc("/symbol", server.handleSymbol)
+    mux.HandleFunc("/search", server.handleSearch)
+    httpServer := &http.Server{Addr: analyzer.config.Listen, Handler: mux}
+    go func() {
+        <-ctx.Done()
+        shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        httpServer.Shutdown(shutdownCtx)
+    }()
+    if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+        return err
+    }
+    return ctx.Err()
+}
+
+// update swaps in a new summary and rebuilds the symbol index
+func (server *apiServer) update(summary Summary) {
+    symbols := convertToPattern2Format(summary, server.config)
+    server.mu.Lock()
+    defer server.mu.Unlock()
+    server.summary = summary
+    server.symbols = symbols
+}
+
+// handleSummary returns the summary rendered as the command line would print it
+func (server *apiServer) handleSummary(w http.ResponseWriter, r *http.Request) {
+    server.mu.RLock()
+    defer server.mu.RUnlock()
+    data, err := Render(server.summary, server.config)
+    if err != nil {
+        writeAPIError(w, http.StatusInternalServerError, err.Error())
+        return
+    }
+    if json.Valid(data) {
+        w.Header().Set("Content-Type", "application/json")
+    } else {
+        w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+    }
+    w.Write(data)
+}
+
+// handleFile returns the summary narrowed down to the file given by the path parameter
+func (server *apiServer) handleFile(w http.ResponseWriter, r *http.Request) {
+    path := r.URL.Query().Get("path")
+    if path == "" {
+        writeAPIError(w, http.StatusBadRequest, "missing path parameter")
+        return
+    }
+    server.mu.RLock()
+    defer server.mu.RUnlock()
+
+    // Files are recorded under the directory they were found in, so accept either form
+    wanted := filepath.ToSlash(filepath.Join(server.config.Directory, path))
+    keep := make(map[string]bool)
+    for _, filePath := range summaryFilePaths(server.summary) {
+        if filePath == path || filepath.ToSlash(filepath.Clean(filePath)) == wanted {
+            keep[filePath] = true
+        }
+    }
+    if len(keep) == 0 {
+        writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no file %s in the summary", path))
+        return
+    }
+    kept := keepSummaryFiles(server.summary, keep)
+    writeAPIResult(w, Summary{
+        GoFiles:       kept.GoFiles,
+        PhpFiles:      kept.PhpFiles,
+        PythonFiles:   kept.PythonFiles,
+        JsFiles:       kept.JsFiles,
+        TsFiles:       kept.TsFiles,
+        HtmlFiles:     kept.HtmlFiles,
+        CssFiles:      kept.CssFiles,
+        SqlFiles:      kept.SqlFiles,
+        MarkdownFiles: kept.MarkdownFiles,
+        ConfigFiles:   kept.ConfigFiles,
+        Dockerfiles:   kept.Dockerfiles,
+        Makefiles:     kept.Makefiles,
+        EnvFiles:      kept.EnvFiles,
+    })
+}
+
+// handleSymbol returns every symbol whose qualified name is the name parameter or ends
+// with it, so "Start" finds both "Start" and "Server.Start"
+func (server *apiServer) handleSymbol(w http.ResponseWriter, r *http.Request) {
+    name := r.URL.Query().Get("name")
+    if name == "" {
+        writeAPIError(w, http.StatusBadRequest, "missing name parameter")
+        return
+    }
+    server.mu.RLock()
+    defer server.mu.RUnlock()
+    symbols := server.findSymbols(func(qualified string) bool {
+        return qualified == name || strings.HasSuffix(qualified, "."+name)
+    })
+    if len(symbols) == 0 {
+        writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no symbol %s in the summary", name))
+        return
+    }
+    writeAPIResult(w, symbols)
+}
+
+// handleSearch returns the symbols and files whose name contains the q parameter
+func (server *apiServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+    query := strings.ToLower(r.URL.Query().Get("q"))
+    if query == "" {
+        writeAPIError(w, http.StatusBadRequest, "missing q parameter")
+        return
+    }
+    server.mu.RLock()
+    defer server.mu.RUnlock()
+    result := struct {
+        Symbols []apiSymbol `json:"symbols"`
+        Files   []string    `json:"files"`
+    }{
+        Symbols: server.findSymbols(func(qualified string) bool {
+            return strings.Contains(strings.ToLower(qualified), query)
+        }),
+        Files: []string{},
+    }
+    for _, file := range server.symbols.Files {
+        if strings.Contains(strings.ToLower(file), query) {
+            result.Files = append(result.Files, file)
+        }
+    }
+    writeAPIResult(w, result)
+}
+
+// findSymbols lists the indexed symbols whose qualified name matches, sorted by name
+func (server *apiServer) findSymbols(match func(qualified string) bool) []apiSymbol {
+    symbols := []apiSymbol{}
+    for _, name := range sortedKeys(server.symbols.Symbols) {
+        if !match(name) {
+            continue
+        }
+        entry := server.symbols.Symbols[name]
+        symbol := apiSymbol{
+            Name:      name,
+            Kind:      entry.Kind,
+            Line:      entry.Line,
+            Signature: entry.Signature,
+            Calls:     entry.Calls,
+            CalledBy:  entry.CalledBy,
+        }
+        for _, index := range entry.Files {
+            symbol.Files = append(symbol.Files, server.symbols.Files[index])
+        }
+        symbols = append(symbols, symbol)
+    }
+    return symbols
+}
+
+// writeAPIResult writes value as the JSON body of a successful response
+func writeAPIResult(w http.ResponseWriter, value interface{}) {
+    data, err := json.Marshal(value)
+    if err != nil {
+        writeAPIError(w, http.StatusInternalServerError, err.Error())
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.Write(data)
+}
+
+// writeAPIError writes a JSON error body with the given status
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    data, _ := json.Marshal(map[string]string{"error": message})
+    w.Write(data)
+}
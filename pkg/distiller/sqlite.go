@@ -0,0 +1,258 @@
+//go:build sqlite
+
+package distiller
+
+import (
+    "database/sql"
+    "os"
+
+    _ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+    databaseWriter = writeSQLite
+}
+
+// sqliteSchema lays the summary out in normalized tables. Calls keep the name as written
+// in the source, and the callee's symbol when the call graph could resolve it.
+const sqliteSchema = `
+CREATE TABLE files (
+    id       INTEGER PRIMARY KEY,
+    path     TEXT NOT NULL UNIQUE,
+    language TEXT NOT NULL
+);
+CREATE TABLE symbols (
+    id             INTEGER PRIMARY KEY,
+    file_id        INTEGER NOT NULL REFERENCES files(id),
+    name           TEXT NOT NULL,
+    qualified_name TEXT NOT NULL,
+    kind           TEXT NOT NULL,
+    line           INTEGER,
+    signature      TEXT
+);
+CREATE TABLE calls (
+    caller_id INTEGER NOT NULL REFERENCES symbols(id),
+    callee    TEXT NOT NULL,
+    callee_id INTEGER REFERENCES symbols(id)
+);
+CREATE TABLE css_selectors (
+    file_id     INTEGER NOT NULL REFERENCES files(id),
+    selector    TEXT NOT NULL,
+    media_query TEXT,
+    line        INTEGER
+);
+CREATE TABLE sql_tables (
+    file_id   INTEGER NOT NULL REFERENCES files(id),
+    name      TEXT NOT NULL,
+    statement TEXT NOT NULL,
+    line      INTEGER
+);
+CREATE INDEX symbols_name ON symbols(name);
+CREATE INDEX symbols_qualified_name ON symbols(qualified_name);
+CREATE INDEX symbols_file ON symbols(file_id);
+CREATE INDEX calls_caller ON calls(caller_id);
+CREATE INDEX calls_callee ON calls(callee);
+CREATE INDEX calls_callee_id ON calls(callee_id);
+CREATE INDEX css_selectors_selector ON css_selectors(selector);
+CREATE INDEX sql_tables_name ON sql_tables(name);
+`
+
+// writeSQLite replaces the database at path with the files, symbols, calls, CSS
+// selectors and SQL table references of a summary
+func writeSQLite(summary Summary, path string) error {
+    if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+        return err
+    }
+    db, err := sql.Open("sqlite3", path)
+    if err != nil {
+        return err
+    }
+    defer db.Close()
+    if _, err := db.Exec(sqliteSchema); err != nil {
+        return err
+    }
+
+    tx, err := db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    insert := func(query string, args ...interface{}) (int64, error) {
+        result, err := tx.Exec(query, args...)
+        if err != nil {
+            return 0, err
+        }
+        return result.LastInsertId()
+    }
+
+    fileIDs := make(map[string]int64)
+    addFile := func(filePath string, language string) error {
+        id, err := insert(`INSERT INTO files (path, language) VALUES (?, ?)`, filePath, language)
+        fileIDs[filePath] = id
+        return err
+    }
+    addType := func(filePath string, name string, kind string, line int) error {
+        _, err := insert(`INSERT INTO symbols (file_id, name, qualified_name, kind, line) VALUES (?, ?, ?, ?, ?)`,
+            fileIDs[filePath], name, name, kind, line)
+        return err
+    }
+    var addClasses func(filePath string, classes []Struct) error
+    addClasses = func(filePath string, classes []Struct) error {
+        for _, class := range classes {
+            if err := addType(filePath, class.Name, "class", class.Line); err != nil {
+                return err
+            }
+            if err := addClasses(filePath, class.Nested); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+    addInterfaces := func(filePath string, interfaces []Interface) error {
+        for _, intf := range interfaces {
+            if err := addType(filePath, intf.Name, "interface", intf.Line); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+
+    for _, f := range summary.GoFiles {
+        if err := addFile(f.FilePath, "go"); err != nil {
+            return err
+        }
+        for _, str := range f.Structs {
+            if err := addType(f.FilePath, str.Name, "struct", str.Line); err != nil {
+                return err
+            }
+        }
+        if err := addInterfaces(f.FilePath, f.Interfaces); err != nil {
+            return err
+        }
+    }
+    for _, f := range summary.PhpFiles {
+        if err := addFile(f.FilePath, "php"); err != nil {
+            return err
+        }
+        if err := addClasses(f.FilePath, f.Classes); err != nil {
+            return err
+        }
+        if err := addInterfaces(f.FilePath, f.Interfaces); err != nil {
+            return err
+        }
+    }
+    for _, f := range summary.PythonFiles {
+        if err := addFile(f.FilePath, "python"); err != nil {
+            return err
+        }
+        if err := addClasses(f.FilePath, f.Classes); err != nil {
+            return err
+        }
+    }
+    for _, f := range summary.JsFiles {
+        if err := addFile(f.FilePath, "javascript"); err != nil {
+            return err
+        }
+        if err := addClasses(f.FilePath, f.Classes); err != nil {
+            return err
+        }
+    }
+    for _, f := range summary.TsFiles {
+        if err := addFile(f.FilePath, "typescript"); err != nil {
+            return err
+        }
+        if err := addClasses(f.FilePath, f.Classes); err != nil {
+            return err
+        }
+        if err := addInterfaces(f.FilePath, f.Interfaces); err != nil {
+            return err
+        }
+        for _, alias := range f.TypeAliases {
+            if err := addType(f.FilePath, alias.Name, "type", alias.Line); err != nil {
+                return err
+            }
+        }
+        for _, enum := range f.Enums {
+            if err := addType(f.FilePath, enum.Name, "enum", enum.Line); err != nil {
+                return err
+            }
+        }
+    }
+    for _, f := range summary.CssFiles {
+        if err := addFile(f.FilePath, "css"); err != nil {
+            return err
+        }
+        for _, rule := range f.Rules {
+            if _, err := insert(`INSERT INTO css_selectors (file_id, selector, media_query, line) VALUES (?, ?, ?, ?)`,
+                fileIDs[f.FilePath], rule.Selector, rule.MediaQuery, rule.Line); err != nil {
+                return err
+            }
+        }
+    }
+    for _, f := range summary.SqlFiles {
+        if err := addFile(f.FilePath, "sql"); err != nil {
+            return err
+        }
+        for _, stmt := range f.Statements {
+            for _, table := range stmt.Tables {
+                if _, err := insert(`INSERT INTO sql_tables (file_id, name, statement, line) VALUES (?, ?, ?, ?)`,
+                    fileIDs[f.FilePath], table, stmt.Type, stmt.Line); err != nil {
+                    return err
+                }
+            }
+        }
+    }
+
+    // The remaining files are listed without symbols of their own
+    languages := []struct {
+        language string
+        paths    []string
+    }{
+        {"html", filePathsOf(summary.HtmlFiles, func(f HtmlFileSummary) string { return f.FilePath })},
+        {"markdown", filePathsOf(summary.MarkdownFiles, func(f MarkdownFileSummary) string { return f.FilePath })},
+        {"config", filePathsOf(summary.ConfigFiles, func(f ConfigFileSummary) string { return f.FilePath })},
+        {"dockerfile", filePathsOf(summary.Dockerfiles, func(f DockerfileSummary) string { return f.FilePath })},
+        {"makefile", filePathsOf(summary.Makefiles, func(f MakefileSummary) string { return f.FilePath })},
+        {"env", filePathsOf(summary.EnvFiles, func(f EnvFileSummary) string { return f.FilePath })},
+    }
+    for _, l := range languages {
+        for _, filePath := range l.paths {
+            if err := addFile(filePath, l.language); err != nil {
+                return err
+            }
+        }
+    }
+
+    // Functions and methods come from the call graph, so calls can point at their callees
+    graph := buildCallGraph(summary)
+    symbolIDs := make([]int64, len(graph.nodes))
+    for i, node := range graph.nodes {
+        id, err := insert(`INSERT INTO symbols (file_id, name, qualified_name, kind, line, signature) VALUES (?, ?, ?, ?, ?, ?)`,
+            fileIDs[graph.files[node.file]], node.fn.Name, qualifiedFunctionName(node.fn), functionKind(node.fn), node.fn.Line, functionSignature(node.fn))
+        if err != nil {
+            return err
+        }
+        symbolIDs[i] = id
+    }
+    for i, node := range graph.nodes {
+        for _, call := range node.fn.Calls {
+            var callee interface{}
+            if target, ok := graph.resolve(i, call); ok {
+                callee = symbolIDs[target]
+            }
+            if _, err := insert(`INSERT INTO calls (caller_id, callee, callee_id) VALUES (?, ?, ?)`, symbolIDs[i], call, callee); err != nil {
+                return err
+            }
+        }
+    }
+    return tx.Commit()
+}
+
+// filePathsOf returns the paths of a list of file summaries
+func filePathsOf[T any](files []T, path func(T) string) []string {
+    paths := make([]string, 0, len(files))
+    for _, f := range files {
+        paths = append(paths, path(f))
+    }
+    return paths
+}
@@ -0,0 +1,488 @@
+//go:build treesitter
+
+package distiller
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    sitter "github.com/smacker/go-tree-sitter"
+    "github.com/smacker/go-tree-sitter/css"
+    "github.com/smacker/go-tree-sitter/php"
+    "github.com/smacker/go-tree-sitter/python"
+    "github.com/smacker/go-tree-sitter/sql"
+)
+
+func init() {
+    treeSitter = treeSitterParser{}
+}
+
+// treeSitterParser implements treeSitterBackend with the grammars bundled in go-tree-sitter.
+// Declarations, signatures and line numbers come from the syntax tree; what the regex
+// analyzers derived from bodies (calls, decorators, deprecations, ...) is kept for the
+// symbols both agree on
+type treeSitterParser struct{}
+
+// parseTree parses content with the given grammar and returns the root node
+func parseTree(language *sitter.Language, content []byte) (*sitter.Node, error) {
+    parser := sitter.NewParser()
+    parser.SetLanguage(language)
+    tree, err := parser.ParseCtx(context.Background(), nil, content)
+    if err != nil {
+        return nil, err
+    }
+    return tree.RootNode(), nil
+}
+
+// namedChildren returns the named children of a node, or nothing for a nil node
+func namedChildren(node *sitter.Node) []*sitter.Node {
+    if node == nil {
+        return nil
+    }
+    var children []*sitter.Node
+    for i := 0; i < int(node.NamedChildCount()); i++ {
+        children = append(children, node.NamedChild(i))
+    }
+    return children
+}
+
+// childOfType returns the first named child of the given type
+func childOfType(node *sitter.Node, nodeType string) *sitter.Node {
+    for _, child := range namedChildren(node) {
+        if child.Type() == nodeType {
+            return child
+        }
+    }
+    return nil
+}
+
+// nodeText returns the source of a node, or "" for a nil node
+func nodeText(node *sitter.Node, content []byte) string {
+    if node == nil {
+        return ""
+    }
+    return node.Content(content)
+}
+
+// nodeLine returns the 1-based line a node starts on
+func nodeLine(node *sitter.Node) int {
+    return int(node.StartPoint().Row) + 1
+}
+
+// collectNodes returns the descendants of node matching keep, without descending into
+// nodes for which stop is true
+func collectNodes(node *sitter.Node, keep func(*sitter.Node) bool, stop func(*sitter.Node) bool) []*sitter.Node {
+    var found []*sitter.Node
+    for _, child := range namedChildren(node) {
+        if keep(child) {
+            found = append(found, child)
+        }
+        if !stop(child) {
+            found = append(found, collectNodes(child, keep, stop)...)
+        }
+    }
+    return found
+}
+
+// mergeFunction fills a function read from the syntax tree with what the regex analyzer
+// found for the function of the same name, preferring the closest line
+func mergeFunction(fn Function, regexFunctions []Function) Function {
+    match := -1
+    for i, candidate := range regexFunctions {
+        if candidate.Name != fn.Name {
+            continue
+        }
+        if match == -1 || abs(candidate.Line-fn.Line) < abs(regexFunctions[match].Line-fn.Line) {
+            match = i
+        }
+    }
+    if match == -1 {
+        return fn
+    }
+    merged := regexFunctions[match]
+    merged.Args, merged.Returns, merged.Line = fn.Args, fn.Returns, fn.Line
+    if len(fn.Modifiers) > 0 {
+        merged.Modifiers, merged.IsStatic, merged.IsAbstract = fn.Modifiers, fn.IsStatic, fn.IsAbstract
+    }
+    return merged
+}
+
+// mergeStruct fills a class read from the syntax tree with the fields and flags the regex
+// analyzer found for the class of the same name
+func mergeStruct(class Struct, regexClasses []Struct) Struct {
+    for _, candidate := range regexClasses {
+        if candidate.Name != class.Name {
+            continue
+        }
+        class.Fields = candidate.Fields
+        class.IsAbstract = class.IsAbstract || candidate.IsAbstract
+        class.IsProtocol = candidate.IsProtocol
+        class.IsDeprecated, class.Deprecation = candidate.IsDeprecated, candidate.Deprecation
+        break
+    }
+    return class
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+    if n < 0 {
+        return -n
+    }
+    return n
+}
+
+// refinePython rebuilds the functions and classes of a Python file from its syntax tree
+func (treeSitterParser) refinePython(summary *PythonFileSummary, content []byte) error {
+    root, err := parseTree(python.GetLanguage(), content)
+    if err != nil {
+        return err
+    }
+
+    var functions []Function
+    var classes []Struct
+    for _, node := range pythonDefinitions(root) {
+        if node.Type() == "class_definition" {
+            classes = append(classes, pythonTreeClass(node, content, summary.Classes))
+        } else {
+            functions = append(functions, mergeFunction(pythonTreeFunction(node, content, false), summary.Functions))
+        }
+    }
+    summary.Functions, summary.Classes = functions, classes
+    return nil
+}
+
+// pythonDefinitions returns the function and class definitions directly in a module or
+// block, looking through if/try/with statements but not into other definitions
+func pythonDefinitions(node *sitter.Node) []*sitter.Node {
+    isDefinition := func(n *sitter.Node) bool {
+        return n.Type() == "function_definition" || n.Type() == "class_definition"
+    }
+    return collectNodes(node, isDefinition, func(n *sitter.Node) bool {
+        return isDefinition(n) || n.Type() == "lambda"
+    })
+}
+
+// pythonTreeFunction converts a function_definition node, dropping self or cls from methods
+func pythonTreeFunction(node *sitter.Node, content []byte, isMethod bool) Function {
+    fn := Function{
+        Name:    nodeText(node.ChildByFieldName("name"), content),
+        Line:    nodeLine(node),
+        Args:    []Variable{},
+        Returns: []string{},
+    }
+
+    for _, param := range namedChildren(node.ChildByFieldName("parameters")) {
+        name, paramType := "", "Any"
+        switch param.Type() {
+        case "identifier":
+            name = nodeText(param, content)
+        case "typed_parameter":
+            name = nodeText(childOfType(param, "identifier"), content)
+            paramType = nodeText(param.ChildByFieldName("type"), content)
+        case "default_parameter":
+            name = nodeText(param.ChildByFieldName("name"), content)
+        case "typed_default_parameter":
+            name = nodeText(param.ChildByFieldName("name"), content)
+            paramType = nodeText(param.ChildByFieldName("type"), content)
+        }
+        // *args, **kwargs and the bare * and / separators are skipped, as the regex parser does
+        if name == "" {
+            continue
+        }
+        fn.Args = append(fn.Args, Variable{Name: name, Type: paramType, Scope: "parameter", Line: fn.Line})
+    }
+    if isMethod && len(fn.Args) > 0 && (fn.Args[0].Name == "self" || fn.Args[0].Name == "cls") {
+        fn.Args = fn.Args[1:]
+    }
+
+    if returnType := nodeText(node.ChildByFieldName("return_type"), content); returnType != "" {
+        fn.Returns = append(fn.Returns, returnType)
+    }
+    fn.Calls = pythonTreeCalls(node.ChildByFieldName("body"), content)
+    return fn
+}
+
+// pythonTreeCalls lists the calls made in a body, as "name" or "object.method"
+func pythonTreeCalls(body *sitter.Node, content []byte) []string {
+    var calls []string
+    isCall := func(n *sitter.Node) bool { return n.Type() == "call" }
+    isNestedScope := func(n *sitter.Node) bool {
+        return n.Type() == "function_definition" || n.Type() == "class_definition"
+    }
+    for _, call := range collectNodes(body, isCall, isNestedScope) {
+        callee := call.ChildByFieldName("function")
+        if callee == nil {
+            continue
+        }
+        switch callee.Type() {
+        case "identifier":
+            name := nodeText(callee, content)
+            if !isPythonKeywordOrBuiltin(name) {
+                calls = appendIfNotExists(calls, name)
+            }
+        case "attribute":
+            method := nodeText(callee.ChildByFieldName("attribute"), content)
+            object := nodeText(callee.ChildByFieldName("object"), content)
+            object = object[strings.LastIndex(object, ".")+1:]
+            if !isPythonKeywordOrBuiltin(method) {
+                calls = appendIfNotExists(calls, object+"."+method)
+            }
+        }
+    }
+    return calls
+}
+
+// pythonTreeClass converts a class_definition node with its methods and nested classes
+func pythonTreeClass(node *sitter.Node, content []byte, regexClasses []Struct) Struct {
+    class := Struct{
+        Name:   nodeText(node.ChildByFieldName("name"), content),
+        Line:   nodeLine(node),
+        Fields: []Variable{},
+    }
+    for _, base := range namedChildren(node.ChildByFieldName("superclasses")) {
+        if base.Type() != "keyword_argument" {
+            class.Bases = append(class.Bases, nodeText(base, content))
+        }
+    }
+
+    var regexClass Struct
+    for _, candidate := range regexClasses {
+        if candidate.Name == class.Name {
+            regexClass = candidate
+            break
+        }
+    }
+
+    for _, child := range pythonDefinitions(node.ChildByFieldName("body")) {
+        if child.Type() == "class_definition" {
+            class.Nested = append(class.Nested, pythonTreeClass(child, content, regexClass.Nested))
+            continue
+        }
+        method := mergeFunction(pythonTreeFunction(child, content, true), regexClass.Methods)
+        method.Receiver = class.Name
+        class.Methods = append(class.Methods, method)
+
+        // Classes declared inside methods are nested in the class, as with the regex parser
+        for _, inner := range pythonDefinitions(child.ChildByFieldName("body")) {
+            if inner.Type() == "class_definition" {
+                class.Nested = append(class.Nested, pythonTreeClass(inner, content, regexClass.Nested))
+            }
+        }
+    }
+    return mergeStruct(class, regexClasses)
+}
+
+// refinePhp rebuilds the functions and named classes of a PHP file from its syntax tree;
+// anonymous classes are kept from the regex analyzer
+func (treeSitterParser) refinePhp(summary *PhpFileSummary, content []byte) error {
+    root, err := parseTree(php.GetLanguage(), content)
+    if err != nil {
+        return err
+    }
+
+    isDeclaration := func(n *sitter.Node) bool {
+        return n.Type() == "function_definition" || n.Type() == "class_declaration"
+    }
+    isScope := func(n *sitter.Node) bool {
+        return isDeclaration(n) || n.Type() == "interface_declaration" || n.Type() == "trait_declaration" ||
+            n.Type() == "anonymous_function_creation_expression" || n.Type() == "arrow_function"
+    }
+
+    var functions []Function
+    var classes []Struct
+    for _, node := range collectNodes(root, isDeclaration, isScope) {
+        if node.Type() == "class_declaration" {
+            classes = append(classes, mergeStruct(phpTreeClass(node, content, summary.Classes), summary.Classes))
+        } else {
+            functions = append(functions, mergeFunction(phpTreeFunction(node, content), summary.Functions))
+        }
+    }
+    for _, class := range summary.Classes {
+        if strings.HasSuffix(class.Name, "@anonymous") {
+            classes = append(classes, class)
+        }
+    }
+    summary.Functions, summary.Classes = functions, classes
+    return nil
+}
+
+// phpTreeFunction converts a function_definition or method_declaration node
+func phpTreeFunction(node *sitter.Node, content []byte) Function {
+    fn := Function{
+        Name:    nodeText(node.ChildByFieldName("name"), content),
+        Line:    nodeLine(node),
+        Args:    []Variable{},
+        Returns: []string{},
+    }
+
+    for _, param := range namedChildren(node.ChildByFieldName("parameters")) {
+        name := nodeText(param.ChildByFieldName("name"), content)
+        if name == "" {
+            continue
+        }
+        paramType := nodeText(param.ChildByFieldName("type"), content)
+        if paramType == "" {
+            paramType = "mixed"
+        }
+        fn.Args = append(fn.Args, Variable{Name: name, Type: paramType, Scope: "parameter", Line: fn.Line})
+    }
+
+    if returnType := strings.TrimLeft(nodeText(node.ChildByFieldName("return_type"), content), ": "); returnType != "" {
+        fn.Returns = append(fn.Returns, returnType)
+    }
+
+    for _, child := range namedChildren(node) {
+        switch child.Type() {
+        case "visibility_modifier", "static_modifier", "abstract_modifier", "final_modifier", "readonly_modifier":
+            modifier := strings.ToLower(nodeText(child, content))
+            fn.Modifiers = append(fn.Modifiers, modifier)
+            fn.IsStatic = fn.IsStatic || modifier == "static"
+            fn.IsAbstract = fn.IsAbstract || modifier == "abstract"
+        }
+    }
+
+    fn.Calls = phpTreeCalls(node.ChildByFieldName("body"), content)
+    return fn
+}
+
+// phpTreeCalls lists the functions and methods called in a body by name
+func phpTreeCalls(body *sitter.Node, content []byte) []string {
+    var calls []string
+    isCall := func(n *sitter.Node) bool {
+        switch n.Type() {
+        case "function_call_expression", "member_call_expression", "nullsafe_member_call_expression", "scoped_call_expression":
+            return true
+        }
+        return false
+    }
+    isNestedScope := func(n *sitter.Node) bool {
+        return n.Type() == "function_definition" || n.Type() == "class_declaration"
+    }
+    for _, call := range collectNodes(body, isCall, isNestedScope) {
+        callee := call.ChildByFieldName("name")
+        if call.Type() == "function_call_expression" {
+            callee = call.ChildByFieldName("function")
+        }
+        name := nodeText(callee, content)
+        name = name[strings.LastIndex(name, "\\")+1:]
+        if name != "" && !strings.HasPrefix(name, "$") {
+            calls = appendIfNotExists(calls, name)
+        }
+    }
+    return calls
+}
+
+// phpTreeClass converts a class_declaration node with its parent, interfaces and methods
+func phpTreeClass(node *sitter.Node, content []byte, regexClasses []Struct) Struct {
+    class := Struct{
+        Name:   nodeText(node.ChildByFieldName("name"), content),
+        Line:   nodeLine(node),
+        Fields: []Variable{},
+    }
+    for _, clause := range []string{"base_clause", "class_interface_clause"} {
+        for _, name := range namedChildren(childOfType(node, clause)) {
+            class.Bases = append(class.Bases, strings.TrimPrefix(nodeText(name, content), "\\"))
+        }
+    }
+    for _, child := range namedChildren(node) {
+        if child.Type() == "abstract_modifier" {
+            class.IsAbstract = true
+        }
+    }
+
+    var regexMethods []Function
+    for _, candidate := range regexClasses {
+        if candidate.Name == class.Name {
+            regexMethods = candidate.Methods
+            break
+        }
+    }
+    for _, member := range namedChildren(node.ChildByFieldName("body")) {
+        if member.Type() != "method_declaration" {
+            continue
+        }
+        method := mergeFunction(phpTreeFunction(member, content), regexMethods)
+        method.Receiver = class.Name
+        class.Methods = append(class.Methods, method)
+    }
+    return class
+}
+
+// refineCss rebuilds the rules of a stylesheet from its syntax tree, including rules
+// nested in @media blocks, and recomputes duplicates and conflicts
+func (treeSitterParser) refineCss(summary *CSSFileSummary, content []byte) error {
+    root, err := parseTree(css.GetLanguage(), content)
+    if err != nil {
+        return err
+    }
+
+    summary.Rules = cssTreeRules(root, content, "")
+    summary.DuplicateSelectors, summary.Conflicts = findCssConflicts(summary.Rules)
+    summary.ZIndexValues = extractZIndexes(summary.Rules)
+    return nil
+}
+
+// cssTreeRules converts the rule_set nodes under node, tagging them with the enclosing media query
+func cssTreeRules(node *sitter.Node, content []byte, mediaQuery string) []CSSRule {
+    var rules []CSSRule
+    for _, child := range namedChildren(node) {
+        switch child.Type() {
+        case "rule_set":
+            rule := CSSRule{
+                Selector:   strings.TrimSpace(nodeText(childOfType(child, "selectors"), content)),
+                Properties: make(map[string]string),
+                Line:       nodeLine(child),
+                MediaQuery: mediaQuery,
+            }
+            for _, declaration := range namedChildren(childOfType(child, "block")) {
+                if declaration.Type() != "declaration" {
+                    continue
+                }
+                property := nodeText(childOfType(declaration, "property_name"), content)
+                text := nodeText(declaration, content)
+                value := strings.TrimSuffix(strings.TrimSpace(text[strings.Index(text, ":")+1:]), ";")
+                rule.Properties[property] = strings.TrimSpace(value)
+            }
+            rules = append(rules, rule)
+        case "media_statement":
+            block := childOfType(child, "block")
+            if block == nil {
+                continue
+            }
+            query := strings.TrimSpace(string(content[child.StartByte():block.StartByte()]))
+            rules = append(rules, cssTreeRules(block, content, query)...)
+        }
+    }
+    return rules
+}
+
+// refineSql splits a SQL file into statements along its syntax tree, so semicolons in
+// strings and comments no longer break statements apart. Dialects the grammar cannot
+// parse fall back to the regex result
+func (treeSitterParser) refineSql(summary *SQLFileSummary, content []byte) error {
+    root, err := parseTree(sql.GetLanguage(), content)
+    if err != nil {
+        return err
+    }
+    if root.HasError() {
+        return fmt.Errorf("syntax not recognized by the SQL grammar")
+    }
+
+    var statements []SQLStatement
+    for _, node := range namedChildren(root) {
+        if node.Type() == "comment" || node.Type() == "marginalia" {
+            continue
+        }
+        stmt := strings.TrimSpace(nodeText(node, content))
+        if !strings.HasSuffix(stmt, ";") {
+            stmt += ";"
+        }
+        sqlStmt := parseSqlStatement(removeSqlComments(stmt), nodeLine(node))
+        if sqlStmt.Type != "" {
+            statements = append(statements, sqlStmt)
+        }
+    }
+    summary.Statements = statements
+    return nil
+}
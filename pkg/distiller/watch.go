@@ -0,0 +1,127 @@
+package distiller
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/fsnotify/fsnotify"
+)
+
+// watchSettleTime is how long Watch waits after the last file event before analyzing again,
+// so saving several files or checking out a branch gives one summary rather than dozens
+const watchSettleTime = 200 * time.Millisecond
+
+// Watch analyzes the directory, then again whenever files under it change, handing each
+// summary to update along with the changed paths relative to the directory (none for the
+// first summary). Files are re-parsed on every change unless the config sets a CacheDir.
+// Watch runs until ctx is cancelled or update returns an error.
+func (analyzer *Analyzer) Watch(ctx context.Context, update func(summary Summary, changed []string) error) error {
+    watcher, err := fsnotify.NewWatcher()
+    if err != nil {
+        return err
+    }
+    defer watcher.Close()
+    if err := analyzer.watchTree(watcher, analyzer.config.Directory); err != nil {
+        return err
+    }
+
+    summary, err := analyzer.Analyze(ctx)
+    if err != nil {
+        return err
+    }
+    if err := update(summary, nil); err != nil {
+        return err
+    }
+
+    changed := make(map[string]bool)
+    var settled <-chan time.Time
+    for {
+        select {
+        case <-ctx.Done():
+            return ctx.Err()
+
+        case err := <-watcher.Errors:
+            return err
+
+        case event := <-watcher.Events:
+            if event.Op == fsnotify.Chmod || analyzer.watchIgnores(event.Name) {
+                continue
+            }
+
+            // Directories created after the start need watches of their own
+            if event.Has(fsnotify.Create) {
+                if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+                    if err := analyzer.watchTree(watcher, event.Name); err != nil {
+                        return err
+                    }
+                }
+            }
+            relPath, err := filepath.Rel(analyzer.config.Directory, event.Name)
+            if err != nil {
+                relPath = event.Name
+            }
+            changed[filepath.ToSlash(relPath)] = true
+            settled = time.After(watchSettleTime)
+
+        case <-settled:
+            settled = nil
+            paths := make([]string, 0, len(changed))
+            for path := range changed {
+                paths = append(paths, path)
+            }
+            sort.Strings(paths)
+            changed = make(map[string]bool)
+
+            summary, err := analyzer.Analyze(ctx)
+            if err != nil {
+                return err
+            }
+            if err := update(summary, paths); err != nil {
+                return err
+            }
+        }
+    }
+}
+
+// watchTree adds a watch for root and every directory below it that the analysis
+// doesn't exclude, since fsnotify only reports changes to a directory's direct entries
+func (analyzer *Analyzer) watchTree(watcher *fsnotify.Watcher, root string) error {
+    return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+        if err != nil || !info.IsDir() {
+            return nil
+        }
+        for _, pattern := range analyzer.config.ExcludePatterns {
+            if matched, _ := filepath.Match(pattern, info.Name()); matched {
+                return filepath.SkipDir
+            }
+        }
+        if analyzer.watchIgnores(path) {
+            return filepath.SkipDir
+        }
+        return watcher.Add(path)
+    })
+}
+
+// watchIgnores reports whether a path is the output file or lies in the cache directory,
+// which the analysis writes itself and would otherwise trigger another round
+func (analyzer *Analyzer) watchIgnores(path string) bool {
+    absPath, err := filepath.Abs(path)
+    if err != nil {
+        return false
+    }
+    if analyzer.config.OutputFile != "" {
+        if output, err := filepath.Abs(analyzer.config.OutputFile); err == nil && absPath == output {
+            return true
+        }
+    }
+    if analyzer.config.CacheDir != "" {
+        if cache, err := filepath.Abs(analyzer.config.CacheDir); err == nil {
+            return absPath == cache || strings.HasPrefix(absPath, cache+string(filepath.Separator))
+        }
+    }
+    return false
+}